@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
@@ -36,19 +37,21 @@ func (c *CLIOutput) Success(message string, data interface{}) {
 	fmt.Printf("%s %s\n", successSymbol, message)
 }
 
-// Error prints an error message or JSON error response
+// Error prints an error message, or an ErrorJSON on stdout in JSON mode
 func (c *CLIOutput) Error(message string, code string) {
 	if c.jsonMode {
-		c.printJSON(map[string]interface{}{
-			"success": false,
-			"error":   message,
-			"code":    code,
-		})
+		c.printJSON(newErrorJSON(code, message))
 		return
 	}
 	fmt.Fprintf(os.Stderr, "Error: %s\n", message)
 }
 
+// Fail prints an error like Error and exits with the code's exit status
+func (c *CLIOutput) Fail(message string, code string) {
+	c.Error(message, code)
+	os.Exit(exitCodeFor(code))
+}
+
 // Print prints data (human-readable or JSON)
 func (c *CLIOutput) Print(humanOutput string, jsonData interface{}) {
 	if c.quietMode {
@@ -78,7 +81,7 @@ const (
 	bulletSymbol  = "•"
 )
 
-// Error codes
+// Error codes, shared with the daemon API
 const (
 	ErrCodeNotFound         = "NOT_FOUND"
 	ErrCodeAlreadyExists    = "ALREADY_EXISTS"
@@ -86,8 +89,24 @@ const (
 	ErrCodeInvalidOperation = "INVALID_OPERATION"
 	ErrCodeGroupNotEmpty    = "GROUP_NOT_EMPTY"
 	ErrCodeMCPNotAvailable  = "MCP_NOT_AVAILABLE"
+	ErrCodeInternal         = "INTERNAL" // Storage, tmux or config failures
+)
+
+// Exit codes
+const (
+	exitError    = 1 // Any error without a more specific code
+	exitNotFound = 2 // A session, group, MCP or job doesn't exist
+	exitTimeout  = 3 // `session wait` or `orchestrate` ran out of time (result still printed)
 )
 
+// exitCodeFor returns the exit code for an error code
+func exitCodeFor(code string) int {
+	if code == ErrCodeNotFound {
+		return exitNotFound
+	}
+	return exitError
+}
+
 // ResolveSession finds a session by flexible matching (title, ID prefix, or path)
 // Returns the matched session or nil with an error message
 func ResolveSession(identifier string, instances []*session.Instance) (*session.Instance, string, string) {
@@ -193,6 +212,8 @@ func StatusSymbol(status session.Status) string {
 		return "○"
 	case session.StatusError:
 		return "✕"
+	case session.StatusExited:
+		return "⊘"
 	default:
 		return "?"
 	}
@@ -209,6 +230,8 @@ func StatusString(status session.Status) string {
 		return "idle"
 	case session.StatusError:
 		return "error"
+	case session.StatusExited:
+		return "exited"
 	default:
 		return "unknown"
 	}
@@ -233,3 +256,46 @@ func FormatPath(path string) string {
 	}
 	return path
 }
+
+// describeLayout summarizes a session's layout, e.g. "dev, pane: npm test"
+func describeLayout(inst *session.Instance) string {
+	var parts []string
+	if inst.Layout != "" {
+		parts = append(parts, inst.Layout)
+	}
+	for _, p := range inst.LayoutPanes {
+		kind := "pane"
+		if p.Window {
+			kind = "window"
+		}
+		if p.Command != "" {
+			parts = append(parts, fmt.Sprintf("%s: %s", kind, p.Command))
+		} else {
+			parts = append(parts, kind)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sessionGitStatus returns the git state of a local session's project path
+func sessionGitStatus(inst *session.Instance) (git.Status, error) {
+	if inst.IsWorktree() {
+		return inst.WorktreeStatus()
+	}
+	if inst.IsRemote() || !git.IsRepo(inst.ProjectPath) {
+		return git.Status{}, fmt.Errorf("not a local git repository")
+	}
+	return git.GetStatus(inst.ProjectPath)
+}
+
+// describeGitStatus formats git state for display: "feature/x (dirty, 2 unmerged)"
+func describeGitStatus(st git.Status) string {
+	state := "clean"
+	if st.Dirty {
+		state = "dirty"
+	}
+	if st.Unmerged > 0 {
+		state += fmt.Sprintf(", %d unmerged", st.Unmerged)
+	}
+	return fmt.Sprintf("%s (%s)", st.Branch, state)
+}
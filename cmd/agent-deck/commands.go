@@ -0,0 +1,541 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// commandSpec describes a CLI command for `help --json` and shell completion.
+// Keep it in sync with the command's flag.FlagSet; TestCommandSpecsMatchFlags
+// checks every spec against the real command's flags.
+type commandSpec struct {
+	Name        string        `json:"name"`
+	Aliases     []string      `json:"aliases,omitempty"`
+	Summary     string        `json:"summary"`
+	Args        []argSpec     `json:"args,omitempty"`
+	Flags       []flagSpec    `json:"flags,omitempty"`
+	Subcommands []commandSpec `json:"subcommands,omitempty"`
+}
+
+// argSpec describes a positional argument
+type argSpec struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind,omitempty"`   // What it completes to (see kind constants)
+	Values     []string `json:"values,omitempty"` // Fixed choices
+	Optional   bool     `json:"optional,omitempty"`
+	Repeatable bool     `json:"repeatable,omitempty"`
+}
+
+// flagSpec describes a flag; Short is a one-letter alias of the same flag
+type flagSpec struct {
+	Name        string   `json:"name"`
+	Short       string   `json:"short,omitempty"`
+	Type        string   `json:"type"`           // bool, string or duration
+	Kind        string   `json:"kind,omitempty"` // What the value completes to
+	Values      []string `json:"values,omitempty"`
+	Repeatable  bool     `json:"repeatable,omitempty"`
+	Description string   `json:"description"`
+}
+
+// Kinds of arguments and flag values, completed from live data
+const (
+	kindSession  = "session"
+	kindGroup    = "group"
+	kindProfile  = "profile"
+	kindMCP      = "mcp"
+	kindTemplate = "template"
+	kindLayout   = "layout"
+	kindTool     = "tool"
+	kindFile     = "file"
+	kindDir      = "dir"
+	kindText     = "text" // Free text, nothing to complete
+)
+
+// Flags shared by many commands
+var (
+	jsonFlag      = flagSpec{Name: "json", Type: "bool", Description: "Output as JSON"}
+	quietFlags    = []flagSpec{{Name: "quiet", Short: "q", Type: "bool", Description: "Minimal output"}}
+	quietOnlyFlag = flagSpec{Name: "q", Type: "bool", Description: "Quiet mode"}
+)
+
+// outputFlags are --json, --quiet and -q
+func outputFlags(extra ...flagSpec) []flagSpec {
+	return append(append([]flagSpec{jsonFlag}, quietFlags...), extra...)
+}
+
+// sessionArg is a required session argument
+func sessionArg(name string) argSpec {
+	return argSpec{Name: name, Kind: kindSession}
+}
+
+// optionalSessionArg is a session argument defaulting to the current session
+func optionalSessionArg() argSpec {
+	return argSpec{Name: "id", Kind: kindSession, Optional: true}
+}
+
+var messageArg = argSpec{Name: "message", Kind: kindText, Optional: true}
+
+// sessionSetFields are the fields `session set` can change
+var sessionSetFields = []string{
+	"title", "path", "command", "tool", "claude-session-id", "gemini-session-id",
+	"session-id", "env", "claude-config-dir", "restart-policy", "restart-max-retries", "restart-backoff",
+}
+
+// completionShells are the shells `completion` writes scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// globalFlags apply to every command
+var globalFlags = []flagSpec{
+	{Name: "profile", Short: "p", Type: "string", Kind: kindProfile, Description: "Use specific profile (default: 'default')"},
+}
+
+// commandSpecs describes every CLI command
+var commandSpecs = []commandSpec{
+	{
+		Name:    "add",
+		Summary: "Add a new session",
+		Args:    []argSpec{{Name: "path", Kind: kindDir, Optional: true}},
+		Flags: []flagSpec{
+			{Name: "title", Short: "t", Type: "string", Description: "Session title (defaults to folder name)"},
+			{Name: "group", Short: "g", Type: "string", Kind: kindGroup, Description: "Group path (defaults to parent folder)"},
+			{Name: "cmd", Short: "c", Type: "string", Kind: kindTool, Description: "Command to run (e.g., 'claude', 'opencode')"},
+			{Name: "parent", Type: "string", Kind: kindSession, Description: "Parent session (creates sub-session, inherits group)"},
+			{Name: "mcp", Type: "string", Kind: kindMCP, Repeatable: true, Description: "MCP to attach"},
+			{Name: "host", Type: "string", Description: "Remote host from config.toml [hosts.<name>] (path is on that host)"},
+			{Name: "worktree", Type: "string", Description: "Run in a new git worktree of the repo at path, on this branch"},
+			{Name: "template", Type: "string", Kind: kindTemplate, Description: "Session template from config.toml [templates.<name>]"},
+			{Name: "layout", Type: "string", Kind: kindLayout, Description: "Named layout from config.toml [layouts.<name>]"},
+			{Name: "pane", Type: "string", Repeatable: true, Description: "Extra pane beside the agent running this command"},
+			{Name: "window", Type: "string", Repeatable: true, Description: "Extra window running this command"},
+			jsonFlag,
+		},
+	},
+	{
+		Name:    "list",
+		Aliases: []string{"ls"},
+		Summary: "List all sessions",
+		Flags: []flagSpec{
+			jsonFlag,
+			{Name: "all", Type: "bool", Description: "List sessions from all profiles"},
+		},
+	},
+	{
+		Name:    "remove",
+		Aliases: []string{"rm"},
+		Summary: "Remove a session",
+		Args:    []argSpec{sessionArg("id")},
+		Flags: []flagSpec{
+			{Name: "delete-worktree", Type: "bool", Description: "Also delete the session's git worktree"},
+			{Name: "force", Type: "bool", Description: "With --delete-worktree: delete even with uncommitted or unmerged changes"},
+			jsonFlag,
+		},
+	},
+	{
+		Name:    "status",
+		Summary: "Show session status summary",
+		Flags: []flagSpec{
+			{Name: "verbose", Short: "v", Type: "bool", Description: "Show detailed session list"},
+			{Name: "quiet", Short: "q", Type: "bool", Description: "Only output waiting count (for scripts)"},
+			jsonFlag,
+		},
+	},
+	{
+		Name:    "watch",
+		Summary: "Stream session status changes",
+		Flags: []flagSpec{
+			{Name: "json", Type: "bool", Description: "Output events as JSON lines"},
+			{Name: "group", Type: "string", Kind: kindGroup, Description: "Only watch this group (and its subgroups)"},
+			{Name: "format", Type: "string", Kind: kindText, Description: "Print a summary line whenever it changes"},
+			{Name: "interval", Type: "duration", Description: "How often to check session status"},
+			{Name: "heartbeat", Type: "duration", Description: "Also emit a heartbeat this often (0 disables)"},
+			{Name: "once", Type: "bool", Description: "Print the current state and exit"},
+		},
+	},
+	{
+		Name:    "session",
+		Summary: "Manage session lifecycle",
+		Subcommands: []commandSpec{
+			{
+				Name:    "start",
+				Summary: "Start a session's tmux process",
+				Args:    []argSpec{sessionArg("id")},
+				Flags: outputFlags(
+					flagSpec{Name: "message", Short: "m", Type: "string", Description: "Initial message to send once agent is ready"},
+				),
+			},
+			{Name: "stop", Summary: "Stop session process", Args: []argSpec{sessionArg("id")}, Flags: outputFlags()},
+			{Name: "restart", Summary: "Restart session (reload MCPs)", Args: []argSpec{sessionArg("id")}, Flags: outputFlags()},
+			{
+				Name:    "fork",
+				Summary: "Fork Claude session with context",
+				Args:    []argSpec{sessionArg("id")},
+				Flags: outputFlags(
+					flagSpec{Name: "title", Short: "t", Type: "string", Description: "Title for forked session"},
+					flagSpec{Name: "group", Short: "g", Type: "string", Kind: kindGroup, Description: "Group for forked session"},
+					flagSpec{Name: "worktree", Type: "bool", Description: "Fork into a new git worktree at the session's HEAD"},
+					flagSpec{Name: "branch", Type: "string", Description: "Branch for the worktree (implies --worktree)"},
+					flagSpec{Name: "carry", Type: "bool", Description: "With --worktree: copy uncommitted changes into the new worktree"},
+				),
+			},
+			{Name: "attach", Summary: "Attach to session interactively", Args: []argSpec{sessionArg("id")}},
+			{Name: "show", Summary: "Show session details", Args: []argSpec{optionalSessionArg()}, Flags: outputFlags()},
+			{Name: "current", Summary: "Show the current session and profile", Flags: outputFlags()},
+			{
+				Name:    "set-parent",
+				Summary: "Make a session a sub-session of another",
+				Args:    []argSpec{sessionArg("session"), sessionArg("parent")},
+				Flags:   outputFlags(),
+			},
+			{Name: "unset-parent", Summary: "Make a sub-session top-level", Args: []argSpec{sessionArg("session")}, Flags: outputFlags()},
+			{
+				Name:    "set",
+				Summary: "Update a session property",
+				Args:    []argSpec{sessionArg("id"), {Name: "field", Values: sessionSetFields}, {Name: "value", Kind: kindText}},
+				Flags:   outputFlags(),
+			},
+			{
+				Name:    "send",
+				Summary: "Send a message to running sessions",
+				Args:    []argSpec{sessionArg("id"), messageArg},
+				Flags: []flagSpec{
+					jsonFlag,
+					quietOnlyFlag,
+					{Name: "no-wait", Type: "bool", Description: "Don't wait for agent to be ready (send immediately)"},
+					{Name: "file", Type: "string", Kind: kindFile, Description: "Read the message from a file (\"-\" for stdin)"},
+					{Name: "keys", Type: "string", Description: "tmux keys to press first, space separated"},
+					{Name: "group", Type: "string", Kind: kindGroup, Description: "Send to every running session in this group"},
+					{Name: "all", Type: "bool", Description: "Send to every running session"},
+				},
+			},
+			{
+				Name:    "output",
+				Summary: "Show the last response of a session",
+				Args:    []argSpec{optionalSessionArg()},
+				Flags: outputFlags(
+					flagSpec{Name: "follow", Short: "f", Type: "bool", Description: "Stream new responses as JSON lines"},
+					flagSpec{Name: "tools", Type: "bool", Description: "With --follow, also stream tool calls and results"},
+				),
+			},
+			{
+				Name:    "wait",
+				Summary: "Wait until sessions reach a status",
+				Args:    []argSpec{{Name: "id", Kind: kindSession, Repeatable: true}},
+				Flags: []flagSpec{
+					{Name: "until", Type: "string", Values: []string{"waiting", "idle", "exited", "any-change"}, Description: "Condition to wait for"},
+					{Name: "timeout", Type: "duration", Description: "Give up after this long, e.g. 10m (0 waits forever)"},
+					{Name: "all", Type: "bool", Description: "Wait until every session meets the condition (default)"},
+					{Name: "any", Type: "bool", Description: "Return as soon as one session meets the condition"},
+					{Name: "after-active", Type: "bool", Description: "Only count a session once it has been seen running during the wait"},
+					jsonFlag,
+					{Name: "q", Type: "bool", Description: "Quiet mode (exit code only)"},
+				},
+			},
+			{
+				Name:    "transcript",
+				Summary: "Export a session's conversation",
+				Args:    []argSpec{optionalSessionArg()},
+				Flags: []flagSpec{
+					{Name: "format", Type: "string", Values: []string{"md", "json", "html"}, Description: "Output format"},
+					{Name: "since", Type: "string", Description: "Only entries since a time or for a duration (2h)"},
+				},
+			},
+			{
+				Name:    "diff",
+				Summary: "Diff the changes of two sessions",
+				Args:    []argSpec{sessionArg("a"), sessionArg("b")},
+				Flags: []flagSpec{
+					jsonFlag,
+					{Name: "stat", Type: "bool", Description: "Show a diffstat instead of the full diff"},
+					{Name: "name-only", Type: "bool", Description: "Show only the names of changed files"},
+				},
+			},
+		},
+	},
+	{
+		Name:    "mcp",
+		Summary: "Manage MCP servers",
+		Subcommands: []commandSpec{
+			{Name: "list", Aliases: []string{"ls"}, Summary: "List available MCPs from config.toml", Flags: outputFlags()},
+			{Name: "attached", Summary: "Show MCPs attached to a session", Args: []argSpec{optionalSessionArg()}, Flags: outputFlags()},
+			{
+				Name:    "attach",
+				Summary: "Attach MCP to session",
+				Args:    []argSpec{sessionArg("id"), {Name: "mcp", Kind: kindMCP}},
+				Flags: outputFlags(
+					flagSpec{Name: "global", Type: "bool", Description: "Attach to global config instead of local .mcp.json"},
+					flagSpec{Name: "restart", Type: "bool", Description: "Restart session to load MCP immediately"},
+				),
+			},
+			{
+				Name:    "detach",
+				Summary: "Detach MCP from session",
+				Args:    []argSpec{sessionArg("id"), {Name: "mcp", Kind: kindMCP}},
+				Flags: outputFlags(
+					flagSpec{Name: "global", Type: "bool", Description: "Remove from global config instead of local .mcp.json"},
+					flagSpec{Name: "restart", Type: "bool", Description: "Restart session to unload MCP immediately"},
+				),
+			},
+		},
+	},
+	{
+		Name:    "group",
+		Summary: "Manage groups",
+		Subcommands: []commandSpec{
+			{Name: "list", Aliases: []string{"ls"}, Summary: "List all groups", Flags: outputFlags()},
+			{
+				Name:    "create",
+				Aliases: []string{"new"},
+				Summary: "Create a new group",
+				Args:    []argSpec{{Name: "name", Kind: kindText}},
+				Flags:   outputFlags(flagSpec{Name: "parent", Type: "string", Kind: kindGroup, Description: "Create as subgroup under this parent"}),
+			},
+			{
+				Name:    "delete",
+				Aliases: []string{"rm"},
+				Summary: "Delete a group",
+				Args:    []argSpec{{Name: "name", Kind: kindGroup}},
+				Flags:   outputFlags(flagSpec{Name: "force", Type: "bool", Description: "Move sessions to parent and delete"}),
+			},
+			{
+				Name:    "move",
+				Aliases: []string{"mv"},
+				Summary: "Move session to group",
+				Args:    []argSpec{sessionArg("id"), {Name: "group", Kind: kindGroup}},
+				Flags:   outputFlags(),
+			},
+			{
+				Name:    "set",
+				Summary: "Update a group property",
+				Args:    []argSpec{{Name: "group", Kind: kindGroup}, {Name: "field", Values: []string{"claude-config-dir"}}, {Name: "value", Kind: kindText}},
+				Flags:   outputFlags(),
+			},
+			{
+				Name:    "send",
+				Summary: "Send a message to the group's running sessions",
+				Args:    []argSpec{{Name: "group", Kind: kindGroup}, messageArg},
+				Flags: []flagSpec{
+					jsonFlag,
+					quietOnlyFlag,
+					{Name: "no-wait", Type: "bool", Description: "Don't wait for agents to be ready (send immediately)"},
+					{Name: "file", Type: "string", Kind: kindFile, Description: "Read the message from a file (\"-\" for stdin)"},
+					{Name: "keys", Type: "string", Description: "tmux keys to press first, space separated"},
+				},
+			},
+		},
+	},
+	{
+		Name:    "profile",
+		Summary: "Manage profiles",
+		Subcommands: []commandSpec{
+			{Name: "list", Aliases: []string{"ls"}, Summary: "List all profiles", Flags: []flagSpec{jsonFlag}},
+			{Name: "create", Aliases: []string{"new"}, Summary: "Create a new profile", Args: []argSpec{{Name: "name", Kind: kindText}}, Flags: []flagSpec{jsonFlag}},
+			{
+				Name:    "delete",
+				Aliases: []string{"rm"},
+				Summary: "Delete a profile",
+				Args:    []argSpec{{Name: "name", Kind: kindProfile}},
+				Flags: []flagSpec{
+					{Name: "yes", Type: "bool", Description: "Don't ask for confirmation"},
+					jsonFlag,
+				},
+			},
+			{Name: "default", Summary: "Show or set default profile", Args: []argSpec{{Name: "name", Kind: kindProfile, Optional: true}}, Flags: []flagSpec{jsonFlag}},
+		},
+	},
+	{
+		Name:    "schedule",
+		Summary: "Manage scheduled prompts",
+		Subcommands: []commandSpec{
+			{
+				Name:    "add",
+				Aliases: []string{"new"},
+				Summary: "Schedule a prompt",
+				Args:    []argSpec{sessionArg("id"), {Name: "message", Kind: kindText}},
+				Flags: []flagSpec{
+					{Name: "cron", Type: "string", Description: "Cron expression for a recurring prompt"},
+					{Name: "in", Type: "string", Description: "Send once after a delay (e.g. 30m, 2h)"},
+					{Name: "at", Type: "string", Description: "Send once at a time (15:04 or \"2006-01-02 15:04\")"},
+					{Name: "start", Type: "bool", Description: "Start the session first if it isn't running"},
+					{Name: "restart", Type: "bool", Description: "Restart the session (with resume) before sending"},
+					jsonFlag,
+					quietOnlyFlag,
+				},
+			},
+			{Name: "list", Aliases: []string{"ls"}, Summary: "List scheduled prompts", Flags: []flagSpec{jsonFlag}},
+			{
+				Name:    "remove",
+				Aliases: []string{"rm"},
+				Summary: "Remove a scheduled prompt",
+				Args:    []argSpec{{Name: "job-id", Kind: kindText}},
+				Flags:   []flagSpec{jsonFlag, quietOnlyFlag},
+			},
+		},
+	},
+	{
+		Name:    "daemon",
+		Summary: "Run headless and serve a local API",
+		Subcommands: []commandSpec{
+			{Name: "run", Summary: "Run the daemon in the foreground"},
+			{Name: "status", Summary: "Show whether the daemon is running", Flags: []flagSpec{jsonFlag}},
+			{Name: "stop", Summary: "Stop the daemon", Flags: []flagSpec{jsonFlag, quietOnlyFlag}},
+		},
+	},
+	{
+		Name:    "up",
+		Summary: "Create and start sessions from agentdeck.toml",
+		Flags: outputFlags(
+			flagSpec{Name: "f", Type: "string", Kind: kindFile, Description: "Manifest file"},
+			flagSpec{Name: "no-start", Type: "bool", Description: "Create missing sessions without starting them"},
+		),
+	},
+	{
+		Name:    "down",
+		Summary: "Stop sessions from agentdeck.toml",
+		Flags:   outputFlags(flagSpec{Name: "f", Type: "string", Kind: kindFile, Description: "Manifest file"}),
+	},
+	{
+		Name:    "export-manifest",
+		Summary: "Write agentdeck.toml from existing sessions",
+		Flags: outputFlags(
+			flagSpec{Name: "o", Type: "string", Kind: kindFile, Description: "Output file (- for stdout)"},
+			flagSpec{Name: "group", Type: "string", Kind: kindGroup, Description: "Export this group (and its subgroups)"},
+			flagSpec{Name: "all", Type: "bool", Description: "Export every session in the profile"},
+			flagSpec{Name: "force", Type: "bool", Description: "Overwrite an existing manifest"},
+		),
+	},
+	{
+		Name:    "orchestrate",
+		Summary: "Run tasks in child sessions and collect a report",
+		Args:    []argSpec{{Name: "tasks.toml", Kind: kindFile, Optional: true}},
+		Flags: []flagSpec{
+			{Name: "parent", Type: "string", Kind: kindSession, Description: "Parent session (default: current session)"},
+			{Name: "task", Type: "string", Kind: kindText, Repeatable: true, Description: "Task prompt (instead of a task file)"},
+			{Name: "template", Type: "string", Kind: kindTemplate, Description: "Template for tasks that don't set one"},
+			{Name: "cleanup", Type: "string", Values: []string{"keep", "stop", "remove", "remove-on-success"}, Description: "What to do with children afterwards"},
+			{Name: "report", Type: "string", Kind: kindFile, Description: "Write the report to a file (\"-\" for stdout)"},
+			{Name: "timeout", Type: "duration", Description: "Give up on unfinished tasks after this long (default 1h)"},
+			jsonFlag,
+			quietOnlyFlag,
+		},
+	},
+	{
+		Name:    "msg",
+		Summary: "Send and read messages between sessions",
+		Subcommands: []commandSpec{
+			{
+				Name:    "send",
+				Summary: "Send a message to another session",
+				Args:    []argSpec{messageArg},
+				Flags: []flagSpec{
+					{Name: "to", Type: "string", Kind: kindSession, Description: "Recipient session"},
+					{Name: "from", Type: "string", Kind: kindSession, Description: "Sending session (default: current session)"},
+					{Name: "no-inject", Type: "bool", Description: "Only put the message in the mailbox"},
+					{Name: "file", Type: "string", Kind: kindFile, Description: "Read the message from a file (\"-\" for stdin)"},
+					jsonFlag,
+					quietOnlyFlag,
+				},
+			},
+			{
+				Name:    "read",
+				Summary: "Show unread messages and mark them read",
+				Args:    []argSpec{optionalSessionArg()},
+				Flags: []flagSpec{
+					{Name: "all", Type: "bool", Description: "Show every message in the mailbox (marks nothing)"},
+					{Name: "peek", Type: "bool", Description: "Don't mark the messages read"},
+					jsonFlag,
+					quietOnlyFlag,
+				},
+			},
+			{Name: "mcp", Summary: "Serve messaging tools to agents over MCP (stdio)"},
+		},
+	},
+	{
+		Name:    "completion",
+		Summary: "Print a shell completion script",
+		Args:    []argSpec{{Name: "shell", Values: completionShells}},
+	},
+	{
+		Name:    "update",
+		Summary: "Check for and install updates",
+		Flags: []flagSpec{
+			{Name: "check", Type: "bool", Description: "Only check for updates, don't install"},
+			{Name: "force", Type: "bool", Description: "Force check (ignore cache)"},
+		},
+	},
+	{
+		Name:    "version",
+		Summary: "Show version",
+		Flags:   []flagSpec{{Name: "json", Type: "bool", Description: "Output the version and JSON schema version as JSON"}},
+	},
+	{
+		Name:    "help",
+		Summary: "Show help",
+		Flags:   []flagSpec{{Name: "json", Type: "bool", Description: "Describe every command and flag as JSON"}},
+	},
+}
+
+// exitCodeDoc documents an exit code in `help --json`
+type exitCodeDoc struct {
+	Code    int    `json:"code"`
+	Meaning string `json:"meaning"`
+}
+
+var exitCodeDocs = []exitCodeDoc{
+	{0, "Success"},
+	{exitError, "Error (any error code but NOT_FOUND)"},
+	{exitNotFound, "Not found (NOT_FOUND)"},
+	{exitTimeout, "Timed out (session wait, orchestrate); the result is still printed"},
+}
+
+// errorCodes are the codes of ErrorJSON
+var errorCodes = []string{
+	ErrCodeNotFound, ErrCodeAlreadyExists, ErrCodeAmbiguous, ErrCodeInvalidOperation,
+	ErrCodeGroupNotEmpty, ErrCodeMCPNotAvailable, ErrCodeInternal,
+}
+
+// findCommand returns the spec named name (or one of its aliases) in specs
+func findCommand(specs []commandSpec, name string) *commandSpec {
+	for i := range specs {
+		if specs[i].Name == name {
+			return &specs[i]
+		}
+		for _, alias := range specs[i].Aliases {
+			if alias == name {
+				return &specs[i]
+			}
+		}
+	}
+	return nil
+}
+
+// findFlag returns the flag of spec (or a global flag) named name, with or
+// without leading dashes
+func findFlag(spec *commandSpec, name string) *flagSpec {
+	for _, flags := range [][]flagSpec{globalFlags, spec.Flags} {
+		for i := range flags {
+			if flags[i].Name == name || (flags[i].Short != "" && flags[i].Short == name) {
+				return &flags[i]
+			}
+		}
+	}
+	return nil
+}
+
+// handleHelpJSON prints every command and flag as JSON
+func handleHelpJSON() {
+	data, err := json.MarshalIndent(struct {
+		Name          string        `json:"name"`
+		Version       string        `json:"version"`
+		SchemaVersion int           `json:"schema_version"` // Of the --json output
+		GlobalFlags   []flagSpec    `json:"global_flags"`
+		Commands      []commandSpec `json:"commands"`
+		ExitCodes     []exitCodeDoc `json:"exit_codes"`
+		ErrorCodes    []string      `json:"error_codes"`
+	}{"agent-deck", Version, JSONSchemaVersion, globalFlags, commandSpecs, exitCodeDocs, errorCodes}, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
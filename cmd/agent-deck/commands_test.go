@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// runCLIEnv makes the test binary run main() with its arguments (see TestMain)
+const runCLIEnv = "AGENTDECK_TEST_RUN_CLI"
+
+// runCLI runs agent-deck with args in a scratch HOME and returns its stdout,
+// stderr and exit code
+func runCLI(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	return runCLIInHome(t, t.TempDir(), args...)
+}
+
+// runCLIInHome is runCLI with a given HOME, for commands that build on each other
+func runCLIInHome(t *testing.T, home string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runCLIEnv+"=1", "HOME="+home)
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &outBuf, &errBuf
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("running agent-deck %v: %v", args, err)
+	}
+	return outBuf.String(), errBuf.String(), code
+}
+
+// shadowedFlags are flags the global -p/--profile hides from their command,
+// so the spec leaves them out
+var shadowedFlags = map[string][]string{
+	"add": {"p"},
+}
+
+var flagLine = regexp.MustCompile(`(?m)^\s+-(\S+)`)
+
+func TestCommandSpecsMatchFlags(t *testing.T) {
+	var check func(path []string, specs []commandSpec)
+	check = func(path []string, specs []commandSpec) {
+		for _, spec := range specs {
+			args := append(append([]string{}, path...), spec.Name)
+			if len(spec.Subcommands) > 0 {
+				check(args, spec.Subcommands)
+				continue
+			}
+			// Commands without a FlagSet have no flags to compare; help and
+			// version check for --json themselves
+			if len(spec.Flags) == 0 || spec.Name == "help" || spec.Name == "version" {
+				continue
+			}
+			name := strings.Join(args, " ")
+
+			stdout, stderr, _ := runCLI(t, append(args, "-h")...)
+			out := stdout + stderr
+			got := []string{}
+			for _, m := range flagLine.FindAllStringSubmatch(out, -1) {
+				if !slices.Contains(shadowedFlags[name], m[1]) {
+					got = append(got, m[1])
+				}
+			}
+
+			want := []string{}
+			for _, f := range spec.Flags {
+				want = append(want, f.Name)
+				if f.Short != "" {
+					want = append(want, f.Short)
+				}
+			}
+			sort.Strings(got)
+			sort.Strings(want)
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("%s: flags = %v, spec says %v", name, got, want)
+			}
+		}
+	}
+	check(nil, commandSpecs)
+}
+
+func TestCompleteWords(t *testing.T) {
+	values := func(kind, profile, cur string) []string {
+		switch kind {
+		case kindSession:
+			return []string{"api", "web " + profile}
+		case kindGroup:
+			return []string{"work", "work/backend"}
+		case kindProfile:
+			return []string{"default", "work"}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{""}, ""}, // All commands, checked below
+		{[]string{"ses"}, "session"},
+		{[]string{"session", "st"}, "start stop"},
+		{[]string{"session", "start", ""}, "api web "},
+		{[]string{"-p", "work", "session", "start", "w"}, "web work"},
+		{[]string{"--profile", ""}, "default work"},
+		{[]string{"session", "send", "--g"}, "--group"},
+		{[]string{"session", "send", "--group", ""}, "work work/backend"},
+		{[]string{"session", "send", "--no-wait", "api", ""}, ""},
+		{[]string{"session", "wait", "--until=i"}, "--until=idle"},
+		{[]string{"session", "wait", "api", "a"}, "api"},
+		{[]string{"session", "set", "api", "restart-"}, "restart-backoff restart-max-retries restart-policy"},
+		{[]string{"mcp", "attach", "api", ""}, ""},
+		{[]string{"add", "--mcp", "x", ""}, completeDirs},
+		{[]string{"orchestrate", "--report", ""}, completeFiles},
+		{[]string{"completion", "z"}, "zsh"},
+		{[]string{"ls", "--"}, "--all --json --profile"},
+		{[]string{"bogus", ""}, ""},
+	}
+	for _, tt := range tests {
+		got := completeWords(tt.words, values)
+		if tt.words[0] == "" {
+			if len(got) != len(commandSpecs) {
+				t.Errorf("completeWords(%q) = %v, want every command", tt.words, got)
+			}
+			continue
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("completeWords(%q) = %q, want %q", tt.words, strings.Join(got, " "), tt.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// Completion results asking the shell to complete a path itself
+const (
+	completeFiles = ":file"
+	completeDirs  = ":dir"
+)
+
+// builtinTools are the tools `add -c` knows without config
+var builtinTools = []string{"claude", "gemini", "codex", "opencode", "cursor", "aider", "shell"}
+
+// handleCompletion prints the completion script for a shell
+func handleCompletion(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: agent-deck completion <bash|zsh|fish>")
+		fmt.Println()
+		fmt.Println("Print a shell completion script. Load it with:")
+		fmt.Println("  bash:  source <(agent-deck completion bash)   # in ~/.bashrc")
+		fmt.Println("  zsh:   source <(agent-deck completion zsh)    # in ~/.zshrc, after compinit")
+		fmt.Println("  fish:  agent-deck completion fish > ~/.config/fish/completions/agent-deck.fish")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "bash":
+		os.Stdout.WriteString(bashCompletion)
+	case "zsh":
+		os.Stdout.WriteString(zshCompletion)
+	case "fish":
+		os.Stdout.WriteString(fishCompletion)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported shell '%s' (use bash, zsh or fish)\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// The scripts ask `agent-deck __complete <words...>` for candidates, so they
+// complete from the live sessions, groups, profiles and MCPs
+const bashCompletion = `# agent-deck bash completion
+_agent_deck() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" IFS=$'\n' i
+    local -a out
+    out=($("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    case "${out[0]}" in
+        :file) compopt -o filenames; COMPREPLY=($(compgen -f -- "$cur")) ;;
+        :dir) compopt -o filenames; COMPREPLY=($(compgen -d -- "$cur")) ;;
+        *)
+            COMPREPLY=()
+            for i in "${!out[@]}"; do
+                COMPREPLY[i]=$(printf '%q' "${out[i]}")
+            done
+            ;;
+    esac
+}
+complete -F _agent_deck agent-deck
+`
+
+const zshCompletion = `#compdef agent-deck
+# agent-deck zsh completion
+_agent_deck() {
+    local -a out
+    out=("${(@f)$("${words[1]}" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    case "$out[1]" in
+        :file) _files ;;
+        :dir) _files -/ ;;
+        '') return 1 ;;
+        *) compadd -- "${out[@]}" ;;
+    esac
+}
+compdef _agent_deck agent-deck
+`
+
+const fishCompletion = `# agent-deck fish completion
+function __agent_deck_complete
+    set -l words (commandline -opc)
+    set -l cur (commandline -ct)
+    set -l out (agent-deck __complete $words[2..-1] "$cur" 2>/dev/null)
+    switch "$out[1]"
+        case :file
+            __fish_complete_path "$cur"
+        case :dir
+            __fish_complete_directories "$cur"
+        case '*'
+            printf '%s\n' $out
+    end
+end
+complete -c agent-deck -f -a '(__agent_deck_complete)'
+`
+
+// handleComplete prints completion candidates for the words after
+// "agent-deck", the last one being the word under the cursor
+func handleComplete(args []string) {
+	for _, c := range completeWords(args, liveCompletions) {
+		fmt.Println(c)
+	}
+}
+
+// completeWords returns the candidates for the last of words. values returns
+// the live candidates of a kind (session, group, ...) in a profile.
+func completeWords(words []string, values func(kind, profile, cur string) []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := words[len(words)-1]
+	before := words[:len(words)-1]
+
+	// -p/--profile is global, so it's stripped before walking the commands
+	if len(before) > 0 && (before[len(before)-1] == "-p" || before[len(before)-1] == "--profile") {
+		return filterPrefix(values(kindProfile, "", cur), cur)
+	}
+	profile, before := extractProfileFlag(before)
+
+	var cmd *commandSpec
+	var pending *flagSpec // Flag waiting for its value
+	positional := 0
+	for _, w := range before {
+		if pending != nil {
+			pending = nil
+			continue
+		}
+		if cmd == nil || (len(cmd.Subcommands) > 0 && positional == 0 && !strings.HasPrefix(w, "-")) {
+			specs := commandSpecs
+			if cmd != nil {
+				specs = cmd.Subcommands
+			}
+			next := findCommand(specs, w)
+			if next == nil {
+				return nil
+			}
+			cmd = next
+			continue
+		}
+		if strings.HasPrefix(w, "-") && len(w) > 1 {
+			if strings.Contains(w, "=") {
+				continue
+			}
+			if f := findFlag(cmd, strings.TrimLeft(w, "-")); f != nil && f.Type != "bool" {
+				pending = f
+			}
+			continue
+		}
+		positional++
+	}
+
+	switch {
+	case pending != nil:
+		return completeValue(pending.Kind, pending.Values, profile, cur, values)
+	case cmd == nil:
+		return filterPrefix(commandNames(commandSpecs), cur)
+	case strings.HasPrefix(cur, "-"):
+		if name, value, ok := strings.Cut(cur, "="); ok {
+			f := findFlag(cmd, strings.TrimLeft(name, "-"))
+			if f == nil || f.Type == "bool" {
+				return nil
+			}
+			var out []string
+			for _, c := range completeValue(f.Kind, f.Values, profile, value, values) {
+				if c != completeFiles && c != completeDirs {
+					out = append(out, name+"="+c)
+				}
+			}
+			return out
+		}
+		return filterPrefix(flagNames(cmd), cur)
+	case len(cmd.Subcommands) > 0:
+		return filterPrefix(commandNames(cmd.Subcommands), cur)
+	}
+
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	arg := cmd.Args[len(cmd.Args)-1]
+	if positional < len(cmd.Args) {
+		arg = cmd.Args[positional]
+	} else if !arg.Repeatable {
+		return nil
+	}
+	return completeValue(arg.Kind, arg.Values, profile, cur, values)
+}
+
+// completeValue completes an argument or flag value of a kind or from fixed choices
+func completeValue(kind string, choices []string, profile, cur string, values func(kind, profile, cur string) []string) []string {
+	switch {
+	case len(choices) > 0:
+		return filterPrefix(choices, cur)
+	case kind == kindFile:
+		return []string{completeFiles}
+	case kind == kindDir:
+		return []string{completeDirs}
+	case kind == "" || kind == kindText:
+		return nil
+	}
+	return filterPrefix(values(kind, profile, cur), cur)
+}
+
+// commandNames returns the names of commands, without aliases
+func commandNames(specs []commandSpec) []string {
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
+	}
+	return names
+}
+
+// flagNames returns a command's flags as typed: --name, or -x for one letter
+func flagNames(spec *commandSpec) []string {
+	var names []string
+	dashed := func(name string) string {
+		if len(name) == 1 {
+			return "-" + name
+		}
+		return "--" + name
+	}
+	for _, flags := range [][]flagSpec{spec.Flags, globalFlags} {
+		for _, f := range flags {
+			names = append(names, dashed(f.Name))
+			if f.Short != "" {
+				names = append(names, dashed(f.Short))
+			}
+		}
+	}
+	return names
+}
+
+// filterPrefix returns the candidates starting with prefix, sorted and deduplicated
+func filterPrefix(candidates []string, prefix string) []string {
+	seen := make(map[string]bool, len(candidates))
+	var out []string
+	for _, c := range candidates {
+		if c != "" && strings.HasPrefix(c, prefix) && !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// liveCompletions returns the current sessions, groups, profiles, MCPs,
+// templates, layouts or tools. Session IDs are only offered once the user
+// has started typing, so an empty word lists just the titles.
+func liveCompletions(kind, profile, cur string) []string {
+	var out []string
+	switch kind {
+	case kindSession, kindGroup:
+		storage, err := session.NewStorageWithProfile(profile)
+		if err != nil {
+			return nil
+		}
+		instances, groups, err := storage.LoadWithGroups()
+		if err != nil {
+			return nil
+		}
+		if kind == kindGroup {
+			for _, g := range groups {
+				out = append(out, g.Path)
+			}
+			return out
+		}
+		for _, inst := range instances {
+			out = append(out, inst.Title)
+			if cur != "" {
+				out = append(out, inst.ID)
+			}
+		}
+	case kindProfile:
+		out, _ = session.ListProfiles()
+	case kindMCP:
+		for name := range session.GetAvailableMCPs() {
+			out = append(out, name)
+		}
+	case kindTemplate:
+		out = session.GetTemplateNames()
+	case kindLayout, kindTool:
+		if kind == kindTool {
+			out = append(out, builtinTools...)
+		}
+		config, err := session.LoadUserConfig()
+		if err != nil || config == nil {
+			return out
+		}
+		if kind == kindLayout {
+			for name := range config.Layouts {
+				out = append(out, name)
+			}
+		} else {
+			for name := range config.Tools {
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
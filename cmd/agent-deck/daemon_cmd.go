@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleDaemon dispatches daemon subcommands
+func handleDaemon(profile string, args []string) {
+	if len(args) == 0 {
+		handleDaemonRun(profile, nil)
+		return
+	}
+
+	switch args[0] {
+	case "run":
+		handleDaemonRun(profile, args[1:])
+	case "status":
+		handleDaemonStatus(profile, args[1:])
+	case "stop":
+		handleDaemonStop(profile, args[1:])
+	case "help", "--help", "-h":
+		printDaemonHelp()
+	default:
+		fmt.Printf("Unknown daemon command: %s\n", args[0])
+		fmt.Println()
+		printDaemonHelp()
+		os.Exit(1)
+	}
+}
+
+// printDaemonHelp prints usage for daemon commands
+func printDaemonHelp() {
+	fmt.Println("Usage: agent-deck daemon [command]")
+	fmt.Println()
+	fmt.Println("Run agent-deck headless. The daemon owns status tracking, automatic")
+	fmt.Println("restarts, scheduled prompts, the MCP pool and log maintenance for a")
+	fmt.Println("profile, and serves a local API on <profile dir>/daemon.sock.")
+	fmt.Println("While it runs, the TUI and the session commands act as its clients.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  run       Run the daemon in the foreground (default)")
+	fmt.Println("  status    Show whether the daemon is running")
+	fmt.Println("  stop      Stop the daemon")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck daemon                     # Run for the default profile")
+	fmt.Println("  agent-deck -p work daemon             # Run for the 'work' profile")
+	fmt.Println("  nohup agent-deck daemon >/dev/null &  # Run in the background")
+	fmt.Println("  agent-deck daemon status --json")
+}
+
+// handleDaemonRun runs the daemon until interrupted or stopped
+func handleDaemonRun(profile string, args []string) {
+	fs := flag.NewFlagSet("daemon run", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck daemon run")
+		fmt.Println()
+		fmt.Println("Run the daemon in the foreground. Set AGENTDECK_DEBUG=1 for logs.")
+	}
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if _, err := exec.LookPath("tmux"); err != nil {
+		fmt.Fprintln(os.Stderr, "Error: tmux not found in PATH")
+		os.Exit(1)
+	}
+	if daemon.Running(profile) {
+		fmt.Fprintln(os.Stderr, "Error: the daemon is already running for this profile")
+		os.Exit(1)
+	}
+
+	// The daemon takes the TUI's lock: a TUI started without the daemon
+	// restarts sessions and delivers schedules itself. TUIs started while the
+	// daemon runs are its clients and don't take the lock.
+	if err := acquireLock(profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\nQuit the TUI before starting the daemon.\n", err)
+		os.Exit(1)
+	}
+	defer releaseLock(profile)
+
+	if os.Getenv("AGENTDECK_DEBUG") != "" {
+		log.SetFlags(log.Ltime | log.Lmicroseconds)
+	} else {
+		log.SetOutput(io.Discard)
+	}
+
+	srv, err := daemon.NewServer(profile, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		releaseLock(profile)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	socketPath, _ := daemon.SocketPath(srv.Profile())
+	fmt.Printf("Agent Deck daemon running for profile '%s' (PID %d)\n", srv.Profile(), os.Getpid())
+	fmt.Printf("Socket: %s\n", socketPath)
+
+	if err := srv.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		releaseLock(profile)
+		os.Exit(1)
+	}
+	fmt.Println("Daemon stopped")
+}
+
+// handleDaemonStatus shows whether the daemon is running
+func handleDaemonStatus(profile string, args []string) {
+	fs := flag.NewFlagSet("daemon status", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	client, err := daemon.Connect(profile)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		out.Print("Daemon is not running\n", DaemonStatusJSON{Running: false})
+		os.Exit(1)
+	}
+	var info *daemon.Info
+	if err == nil {
+		info, err = client.Ping()
+	}
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	if *jsonOutput {
+		out.Print("", DaemonStatusJSON{Running: true, Info: info})
+		return
+	}
+	fmt.Printf("Daemon is running for profile '%s'\n", info.Profile)
+	fmt.Printf("  PID:      %d\n", info.PID)
+	if info.Version != "" {
+		fmt.Printf("  Version:  %s\n", info.Version)
+	}
+	fmt.Printf("  Uptime:   %s\n", time.Since(info.StartedAt).Round(time.Second))
+	fmt.Printf("  Sessions: %d\n", info.Sessions)
+}
+
+// handleDaemonStop asks the daemon to shut down
+func handleDaemonStop(profile string, args []string) {
+	fs := flag.NewFlagSet("daemon stop", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet)
+
+	client, err := daemon.Connect(profile)
+	if err == nil {
+		err = client.Shutdown()
+	}
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	// Wait for the socket to go away so a following command sees it stopped
+	for i := 0; i < 50 && daemon.Running(profile); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	out.Success("Daemon stopped", SuccessJSON{Success: true})
+}
+
+// daemonClient returns a client for the profile's daemon, or nil when none
+// is running and the command should act on sessions directly
+func daemonClient(profile string) *daemon.Client {
+	client, err := daemon.Connect(profile)
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// applyDaemonStatuses sets the statuses tracked by the profile's daemon on
+// the sessions; false if no daemon is running
+func applyDaemonStatuses(profile string, instances []*session.Instance) bool {
+	client := daemonClient(profile)
+	if client == nil {
+		return false
+	}
+	return client.ApplyStatuses(instances) == nil
+}
+
+// lastResponse returns a session's last agent response, read by the
+// profile's daemon when one is running
+func lastResponse(profile string, inst *session.Instance) (*session.ResponseOutput, error) {
+	client := daemonClient(profile)
+	if client == nil {
+		return inst.GetLastResponse()
+	}
+	output, err := client.Output(inst.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.ResponseOutput{
+		Tool:      output.Tool,
+		Role:      output.Role,
+		Content:   output.Content,
+		Timestamp: output.Timestamp,
+		SessionID: output.ConversationID,
+	}, nil
+}
+
+// daemonStatusCounts returns the status counts tracked by the profile's daemon
+func daemonStatusCounts(profile string) (statusCounts, bool) {
+	client := daemonClient(profile)
+	if client == nil {
+		return statusCounts{}, false
+	}
+	c, err := client.Status()
+	if err != nil {
+		return statusCounts{}, false
+	}
+	return statusCounts{
+		running: c.Running,
+		waiting: c.Waiting,
+		idle:    c.Idle,
+		err:     c.Error,
+		exited:  c.Exited,
+		total:   c.Total,
+	}, true
+}
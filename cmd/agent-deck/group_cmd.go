@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
@@ -26,6 +27,10 @@ func handleGroup(profile string, args []string) {
 		handleGroupDelete(profile, args[1:])
 	case "move", "mv":
 		handleGroupMove(profile, args[1:])
+	case "set":
+		handleGroupSet(profile, args[1:])
+	case "send":
+		handleGroupSend(profile, args[1:])
 	case "help", "--help", "-h":
 		printGroupHelp()
 		return
@@ -46,6 +51,8 @@ func printGroupHelp() {
 	fmt.Println("  create <name>     Create a new group")
 	fmt.Println("  delete <name>     Delete a group")
 	fmt.Println("  move <id> <group> Move session to a different group")
+	fmt.Println("  set <group> <field> <value>  Update group property")
+	fmt.Println("  send <group> <message>       Send a message to the group's running sessions")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  agent-deck group list")
@@ -55,6 +62,8 @@ func printGroupHelp() {
 	fmt.Println("  agent-deck group delete work --force")
 	fmt.Println("  agent-deck group move my-project work/frontend")
 	fmt.Println("  agent-deck group move my-project \"\"          # Move to root")
+	fmt.Println("  agent-deck group set work claude-config-dir work  # Claude account for the group")
+	fmt.Println("  agent-deck group send work \"Run the tests and report\"")
 }
 
 // handleGroupList lists all groups with session counts and status
@@ -82,40 +91,22 @@ func handleGroupList(profile string, args []string) {
 	// Load sessions and groups
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, groups, err := storage.LoadWithGroups()
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Build group tree
 	groupTree := session.NewGroupTreeWithGroups(instances, groups)
 
 	if *jsonOutput {
-		// Build JSON output structure
-		type groupStatusJSON struct {
-			Running int `json:"running"`
-			Waiting int `json:"waiting"`
-			Idle    int `json:"idle"`
-			Error   int `json:"error"`
-		}
-
-		type groupJSON struct {
-			Name         string           `json:"name"`
-			Path         string           `json:"path"`
-			SessionCount int              `json:"session_count"`
-			Status       *groupStatusJSON `json:"status,omitempty"`
-			Children     []groupJSON      `json:"children,omitempty"`
-		}
-
 		// Build hierarchical structure
-		buildGroupJSON := func(g *session.Group) groupJSON {
+		buildGroupJSON := func(g *session.Group) GroupJSON {
 			// Count status for this group's direct sessions
-			status := groupStatusJSON{}
+			status := StatusJSON{Total: len(g.Sessions)}
 			for _, sess := range g.Sessions {
 				_ = sess.UpdateStatus() // Refresh status
 				switch sess.Status {
@@ -127,13 +118,16 @@ func handleGroupList(profile string, args []string) {
 					status.Idle++
 				case session.StatusError:
 					status.Error++
+				case session.StatusExited:
+					status.Exited++
 				}
 			}
 
-			gj := groupJSON{
-				Name:         g.Name,
-				Path:         g.Path,
-				SessionCount: len(g.Sessions),
+			gj := GroupJSON{
+				Name:            g.Name,
+				Path:            g.Path,
+				SessionCount:    len(g.Sessions),
+				ClaudeConfigDir: g.ClaudeConfigDir,
 			}
 			if len(g.Sessions) > 0 {
 				gj.Status = &status
@@ -142,7 +136,7 @@ func handleGroupList(profile string, args []string) {
 		}
 
 		// Build top-level groups with their children
-		groupsJSON := []groupJSON{}
+		groupsJSON := []GroupJSON{}
 		processedPaths := make(map[string]bool)
 
 		for _, g := range groupTree.GroupList {
@@ -178,10 +172,10 @@ func handleGroupList(profile string, args []string) {
 		totalGroups := len(groupTree.Groups)
 		totalSessions := groupTree.SessionCount()
 
-		out.Print("", map[string]interface{}{
-			"groups":         groupsJSON,
-			"total_groups":   totalGroups,
-			"total_sessions": totalSessions,
+		out.Print("", GroupListJSON{
+			Groups:        groupsJSON,
+			TotalGroups:   totalGroups,
+			TotalSessions: totalSessions,
 		})
 		return
 	}
@@ -262,6 +256,9 @@ func handleGroupList(profile string, args []string) {
 			}
 			statusStr = strings.Join(parts, " ")
 		}
+		if g.ClaudeConfigDir != "" {
+			statusStr = strings.TrimSpace(statusStr + "  claude: " + session.ClaudeAccountLabel(session.ResolveClaudeConfigDir(g.ClaudeConfigDir)))
+		}
 
 		name := indent + prefix + g.Name
 		sb.WriteString(fmt.Sprintf("%-20s %-10d %s\n", truncateGroupName(name, 20), sessCount, statusStr))
@@ -304,22 +301,22 @@ func handleGroupCreate(profile string, args []string) {
 
 	name := fs.Arg(0)
 	if name == "" {
-		out.Error("group name is required", ErrCodeNotFound)
-		fmt.Println("Usage: agent-deck group create <name> [--parent <group>]")
+		out.Error("group name is required", ErrCodeInvalidOperation)
+		if !*jsonOutput {
+			fmt.Println("Usage: agent-deck group create <name> [--parent <group>]")
+		}
 		os.Exit(1)
 	}
 
 	// Load sessions and groups
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, groups, err := storage.LoadWithGroups()
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Build group tree
@@ -332,8 +329,7 @@ func handleGroupCreate(profile string, args []string) {
 		// Verify parent exists
 		parentPath := normalizeGroupPath(*parent)
 		if _, exists := groupTree.Groups[parentPath]; !exists {
-			out.Error(fmt.Sprintf("parent group '%s' not found", *parent), ErrCodeNotFound)
-			os.Exit(2)
+			out.Fail(fmt.Sprintf("parent group '%s' not found", *parent), ErrCodeNotFound)
 		}
 		newGroup = groupTree.CreateSubgroup(parentPath, name)
 		fullPath = newGroup.Path
@@ -353,22 +349,21 @@ func handleGroupCreate(profile string, args []string) {
 
 	// Save
 	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
-		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
 	if existingGroup {
-		out.Success(fmt.Sprintf("Group already exists: %s", fullPath), map[string]interface{}{
-			"success": true,
-			"name":    newGroup.Name,
-			"path":    fullPath,
-			"existed": true,
+		out.Success(fmt.Sprintf("Group already exists: %s", fullPath), GroupCreateJSON{
+			Success: true,
+			Name:    newGroup.Name,
+			Path:    fullPath,
+			Existed: true,
 		})
 	} else {
-		out.Success(fmt.Sprintf("Created group: %s", fullPath), map[string]interface{}{
-			"success": true,
-			"name":    newGroup.Name,
-			"path":    fullPath,
+		out.Success(fmt.Sprintf("Created group: %s", fullPath), GroupCreateJSON{
+			Success: true,
+			Name:    newGroup.Name,
+			Path:    fullPath,
 		})
 	}
 }
@@ -402,22 +397,22 @@ func handleGroupDelete(profile string, args []string) {
 
 	name := fs.Arg(0)
 	if name == "" {
-		out.Error("group name is required", ErrCodeNotFound)
-		fmt.Println("Usage: agent-deck group delete <name> [--force]")
+		out.Error("group name is required", ErrCodeInvalidOperation)
+		if !*jsonOutput {
+			fmt.Println("Usage: agent-deck group delete <name> [--force]")
+		}
 		os.Exit(1)
 	}
 
 	// Load sessions and groups
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, groups, err := storage.LoadWithGroups()
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Build group tree
@@ -439,14 +434,12 @@ func handleGroupDelete(profile string, args []string) {
 	}
 
 	if !exists {
-		out.Error(fmt.Sprintf("group '%s' not found", name), ErrCodeNotFound)
-		os.Exit(2)
+		out.Fail(fmt.Sprintf("group '%s' not found", name), ErrCodeNotFound)
 	}
 
 	// Check if group is protected (default group)
 	if groupPath == session.DefaultGroupPath {
-		out.Error("cannot delete the default group", ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail("cannot delete the default group", ErrCodeInvalidOperation)
 	}
 
 	// Count sessions in group and subgroups
@@ -459,8 +452,7 @@ func handleGroupDelete(profile string, args []string) {
 
 	// Check if group has sessions and --force not specified
 	if sessionCount > 0 && !*force {
-		out.Error(fmt.Sprintf("group '%s' has %d sessions. Use --force to move them to parent.", name, sessionCount), ErrCodeGroupNotEmpty)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("group '%s' has %d sessions. Use --force to move them to parent.", name, sessionCount), ErrCodeGroupNotEmpty)
 	}
 
 	// Determine where sessions will be moved
@@ -485,15 +477,14 @@ func handleGroupDelete(profile string, args []string) {
 
 	// Save
 	if err := storage.SaveWithGroups(groupTree.GetAllInstances(), groupTree); err != nil {
-		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
-	out.Success(fmt.Sprintf("Deleted group: %s", name), map[string]interface{}{
-		"success":        true,
-		"name":           name,
-		"sessions_moved": len(movedSessions),
-		"moved_to":       movedTo,
+	out.Success(fmt.Sprintf("Deleted group: %s", name), GroupDeleteJSON{
+		Success:       true,
+		Name:          name,
+		SessionsMoved: len(movedSessions),
+		MovedTo:       movedTo,
 	})
 }
 
@@ -532,38 +523,36 @@ func handleGroupMove(profile string, args []string) {
 	targetGroup := fs.Arg(1)
 
 	if sessionID == "" {
-		out.Error("session identifier is required", ErrCodeNotFound)
-		fmt.Println("Usage: agent-deck group move <session-id> <group>")
+		out.Error("session identifier is required", ErrCodeInvalidOperation)
+		if !*jsonOutput {
+			fmt.Println("Usage: agent-deck group move <session-id> <group>")
+		}
 		os.Exit(1)
 	}
 
 	if fs.NArg() < 2 {
-		out.Error("target group is required", ErrCodeNotFound)
-		fmt.Println("Usage: agent-deck group move <session-id> <group>")
+		out.Error("target group is required", ErrCodeInvalidOperation)
+		if !*jsonOutput {
+			fmt.Println("Usage: agent-deck group move <session-id> <group>")
+		}
 		os.Exit(1)
 	}
 
 	// Load sessions and groups
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, groups, err := storage.LoadWithGroups()
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Find the session
 	inst, errMsg, errCode := ResolveSession(sessionID, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Normalize target group
@@ -595,8 +584,7 @@ func handleGroupMove(profile string, args []string) {
 
 	// Save
 	if err := storage.SaveWithGroups(groupTree.GetAllInstances(), groupTree); err != nil {
-		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
 	toGroup := targetGroupPath
@@ -604,14 +592,143 @@ func handleGroupMove(profile string, args []string) {
 		toGroup = session.DefaultGroupPath
 	}
 
-	out.Success(fmt.Sprintf("Moved %s to %s", inst.Title, toGroup), map[string]interface{}{
-		"success": true,
-		"session": inst.Title,
-		"from":    fromGroup,
-		"to":      toGroup,
+	out.Success(fmt.Sprintf("Moved %s to %s", inst.Title, toGroup), GroupMoveJSON{
+		Success: true,
+		Session: inst.Title,
+		From:    fromGroup,
+		To:      toGroup,
+	})
+}
+
+// handleGroupSet updates a group property
+func handleGroupSet(profile string, args []string) {
+	fs := flag.NewFlagSet("group set", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck group set <group> <field> <value>")
+		fmt.Println()
+		fmt.Println("Update a group property.")
+		fmt.Println()
+		fmt.Println("Fields:")
+		fmt.Println("  claude-config-dir  Claude account for the group's sessions: a [claude.accounts]")
+		fmt.Println("                     name or config directory (\"\" = inherit from parent/profile)")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck group set work claude-config-dir work")
+		fmt.Println("  agent-deck group set personal claude-config-dir ~/.claude")
+		fmt.Println("  agent-deck group set work claude-config-dir \"\"")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if fs.NArg() < 3 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+	groupPath := normalizeGroupPath(fs.Arg(0))
+	field := fs.Arg(1)
+	value := fs.Arg(2)
+
+	if field != "claude-config-dir" {
+		out.Fail(fmt.Sprintf("invalid field: %s\nValid fields: claude-config-dir", field), ErrCodeInvalidOperation)
+	}
+	if err := session.ValidateClaudeAccount(value); err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	// Load sessions and groups
+	storage, err := session.NewStorageWithProfile(profile)
+	if err != nil {
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
+	}
+
+	instances, groups, err := storage.LoadWithGroups()
+	if err != nil {
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
+	}
+
+	groupTree := session.NewGroupTreeWithGroups(instances, groups)
+	group, exists := groupTree.Groups[groupPath]
+	if !exists {
+		out.Fail(fmt.Sprintf("group '%s' not found", groupPath), ErrCodeNotFound)
+	}
+
+	oldValue := group.ClaudeConfigDir
+	groupTree.SetClaudeConfigDir(groupPath, value, storage.Profile())
+
+	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
+	}
+
+	out.Success(fmt.Sprintf("Updated %s %s: %q -> %q (applies on next start/restart)", group.Path, field, oldValue, value), GroupUpdateJSON{
+		Success:  true,
+		Group:    group.Path,
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: value,
 	})
 }
 
+// handleGroupSend sends a message to every running session in a group
+// (like `session send --group`)
+func handleGroupSend(profile string, args []string) {
+	fs := flag.NewFlagSet("group send", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+	noWait := fs.Bool("no-wait", false, "Don't wait for agents to be ready (send immediately)")
+	file := fs.String("file", "", "Read the message from a file (\"-\" for stdin)")
+	keys := fs.String("keys", "", "tmux keys to press first, space separated (e.g. \"Escape\")")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck group send [options] <group> [message]")
+		fmt.Println()
+		fmt.Println("Send a message to every running session in a group and its subgroups,")
+		fmt.Println("each once its agent is ready. Sessions that aren't running are skipped.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck group send work \"Run the tests and report\"")
+		fmt.Println("  agent-deck group send --file review.md reviews")
+		fmt.Println("  agent-deck group send --json work/backend \"Summarize your progress\"")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	keyNames := strings.Fields(*keys)
+	message, err := readSendMessage(*file, fs.Args()[1:], len(keyNames) == 0)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	if message == "" && len(keyNames) == 0 {
+		out.Fail("message is empty (pass text, --file, stdin or --keys)", ErrCodeInvalidOperation)
+	}
+
+	_, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+	sendToGroup(out, profile, fs.Arg(0), instances, groups, daemon.SendRequest{Message: message, Keys: keyNames, NoWait: *noWait})
+}
+
 // getParentGroupPath returns the parent path of a group path
 func getParentGroupPath(path string) string {
 	if idx := strings.LastIndex(path, "/"); idx != -1 {
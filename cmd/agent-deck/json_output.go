@@ -0,0 +1,485 @@
+package main
+
+import (
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
+	"github.com/asheshgoplani/agent-deck/internal/mailbox"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// JSONSchemaVersion is the version of the --json output contract. Fields may
+// be added within a version; renaming or removing one, or changing its type,
+// bumps it. `agent-deck version --json` and `help --json` report it.
+const JSONSchemaVersion = 1
+
+// ErrorJSON is printed on stdout for every failure in JSON mode
+type ErrorJSON struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody is the code and message of an ErrorJSON
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newErrorJSON(code, message string) ErrorJSON {
+	return ErrorJSON{Error: ErrorBody{Code: code, Message: message}}
+}
+
+// VersionJSON is the output of `version --json`
+type VersionJSON struct {
+	Version       string `json:"version"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// SessionJSON is a session as listed by `list` and `watch`
+type SessionJSON struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Path      string    `json:"path"`
+	Group     string    `json:"group"`
+	Tool      string    `json:"tool"`
+	Command   string    `json:"command,omitempty"`
+	Status    string    `json:"status"`
+	Profile   string    `json:"profile"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionJSON(inst *session.Instance, profile string) SessionJSON {
+	return SessionJSON{
+		ID:        inst.ID,
+		Title:     inst.Title,
+		Path:      inst.ProjectPath,
+		Group:     inst.GroupPath,
+		Tool:      inst.Tool,
+		Command:   inst.Command,
+		Status:    StatusString(inst.Status),
+		Profile:   profile,
+		ParentID:  inst.ParentSessionID,
+		CreatedAt: inst.CreatedAt,
+	}
+}
+
+// SessionDetailJSON is a session as shown by `session show`
+type SessionDetailJSON struct {
+	SessionJSON
+	ClaudeSessionID string                `json:"claude_session_id,omitempty"`
+	AgentSessionID  string                `json:"session_id,omitempty"`
+	CanFork         *bool                 `json:"can_fork,omitempty"`
+	CanRestart      *bool                 `json:"can_restart,omitempty"`
+	MCPs            *MCPScopesJSON        `json:"mcps,omitempty"`
+	MCPConfig       string                `json:"mcp_config,omitempty"`
+	Host            string                `json:"host,omitempty"`
+	Layout          string                `json:"layout,omitempty"`
+	LayoutPanes     []session.LayoutPane  `json:"layout_panes,omitempty"`
+	Env             map[string]string     `json:"env,omitempty"` // Unresolved, so ${VAR} references don't leak secrets
+	ClaudeConfigDir string                `json:"claude_config_dir,omitempty"`
+	ClaudeAccount   string                `json:"claude_account,omitempty"`
+	RestartPolicy   session.RestartPolicy `json:"restart_policy"`
+	ExitStatus      *int                  `json:"exit_status,omitempty"`
+	WorktreePath    string                `json:"worktree_path,omitempty"`
+	WorktreeRepo    string                `json:"worktree_repo,omitempty"`
+	ForkedFromID    string                `json:"forked_from_id,omitempty"`
+	ForkIDs         []string              `json:"fork_ids,omitempty"`
+	Git             *GitJSON              `json:"git,omitempty"`
+	TmuxSession     string                `json:"tmux_session,omitempty"`
+	AgentPane       string                `json:"agent_pane,omitempty"`
+}
+
+// AddJSON is the output of `add`
+type AddJSON struct {
+	Success bool `json:"success"`
+	SessionJSON
+	Host           string   `json:"host,omitempty"`
+	WorktreeBranch string   `json:"worktree_branch,omitempty"`
+	MCPs           []string `json:"mcps,omitempty"`
+	Template       string   `json:"template,omitempty"`
+}
+
+// RemoveJSON is the output of `remove`
+type RemoveJSON struct {
+	Success  bool                 `json:"success"`
+	Profile  string               `json:"profile"`
+	Sessions []RemovedSessionJSON `json:"sessions"`
+}
+
+// RemovedSessionJSON is a session removed by `remove`
+type RemovedSessionJSON struct {
+	ID               string `json:"id"`
+	Title            string `json:"title"`
+	SchedulesRemoved int    `json:"schedules_removed"`
+	Worktree         string `json:"worktree,omitempty"`
+	WorktreeDeleted  bool   `json:"worktree_deleted"`
+}
+
+// SessionActionJSON is the output of `session start`, `stop` and `restart`
+type SessionActionJSON struct {
+	Success        bool   `json:"success"`
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Tmux           string `json:"tmux,omitempty"`
+	Message        string `json:"message,omitempty"`         // Initial message of `session start`
+	MessagePending bool   `json:"message_pending,omitempty"` // Sent once the agent is ready
+}
+
+// FieldUpdateJSON is the output of `session set`
+type FieldUpdateJSON struct {
+	Success  bool   `json:"success"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Field    string `json:"field"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// WaitJSON is the output of `session wait`
+type WaitJSON struct {
+	Until          string            `json:"until"`
+	Mode           string            `json:"mode"`
+	Met            bool              `json:"met"`
+	TimedOut       bool              `json:"timed_out"`
+	ElapsedSeconds float64           `json:"elapsed_seconds"`
+	Sessions       []WaitSessionJSON `json:"sessions"`
+}
+
+// WaitSessionJSON is a session's final state in a WaitJSON
+type WaitSessionJSON struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	Status        string `json:"status"`
+	InitialStatus string `json:"initial_status"`
+	Met           bool   `json:"met"`
+}
+
+// SuccessJSON is the output of commands that report nothing else, like `daemon stop`
+type SuccessJSON struct {
+	Success bool `json:"success"`
+}
+
+// ForkJSON is the output of `session fork`
+type ForkJSON struct {
+	Success      bool   `json:"success"`
+	ParentID     string `json:"parent_id"`
+	NewID        string `json:"new_id"`
+	NewTitle     string `json:"new_title"`
+	WorktreePath string `json:"worktree_path,omitempty"`
+	Branch       string `json:"branch,omitempty"`
+}
+
+// UnregisteredSessionJSON is the output of `session show` inside a tmux
+// session agent-deck doesn't know
+type UnregisteredSessionJSON struct {
+	TmuxSession string `json:"tmux_session"`
+	Title       string `json:"title"`
+	Path        string `json:"path"`
+	Window      string `json:"window"`
+	Registered  bool   `json:"registered"`
+	IDFragment  string `json:"id_fragment,omitempty"` // ID part of a stale agentdeck_ name
+}
+
+// ParentLinkJSON is the output of `session set-parent`
+type ParentLinkJSON struct {
+	Success        bool   `json:"success"`
+	SessionID      string `json:"session_id"`
+	SessionTitle   string `json:"session_title"`
+	ParentID       string `json:"parent_id"`
+	ParentTitle    string `json:"parent_title"`
+	InheritedGroup string `json:"inherited_group"`
+}
+
+// ParentUnlinkJSON is the output of `session unset-parent`
+type ParentUnlinkJSON struct {
+	Success      bool   `json:"success"`
+	SessionID    string `json:"session_id"`
+	SessionTitle string `json:"session_title"`
+	FormerParent string `json:"former_parent"`
+}
+
+// SessionOutputJSON is the output of `session output`. ConversationID is the
+// agent's own session ID for every tool; claude_session_id and
+// gemini_session_id repeat it for those tools and are kept for scripts
+// written against the first version of this output.
+type SessionOutputJSON struct {
+	Success         bool   `json:"success"`
+	SessionID       string `json:"session_id"`
+	SessionTitle    string `json:"session_title"`
+	Tool            string `json:"tool"`
+	Role            string `json:"role"`
+	Content         string `json:"content"`
+	Timestamp       string `json:"timestamp"`
+	ConversationID  string `json:"conversation_id,omitempty"`
+	ClaudeSessionID string `json:"claude_session_id,omitempty"` // Deprecated: use conversation_id
+	GeminiSessionID string `json:"gemini_session_id,omitempty"` // Deprecated: use conversation_id
+}
+
+// CurrentSessionJSON is the output of `session current`
+type CurrentSessionJSON struct {
+	Session string `json:"session"`
+	Profile string `json:"profile"`
+	ID      string `json:"id"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+	Group   string `json:"group,omitempty"`
+}
+
+// DiffJSON is the output of `session diff`
+type DiffJSON struct {
+	Success bool           `json:"success"`
+	A       DiffSideJSON   `json:"a"`
+	B       DiffSideJSON   `json:"b"`
+	Files   []DiffFileJSON `json:"files"`
+	Diff    string         `json:"diff"`
+}
+
+// DiffSideJSON is one of the sessions a DiffJSON compares
+type DiffSideJSON struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Path  string `json:"path"` // Top of the session's working tree
+}
+
+// DiffFileJSON is a changed file in a DiffJSON, with its git status letter
+type DiffFileJSON struct {
+	Status string `json:"status"`
+	Path   string `json:"path"`
+}
+
+// SendJSON is the output of `session send` to one session
+type SendJSON struct {
+	Success      bool     `json:"success"`
+	SessionID    string   `json:"session_id"`
+	SessionTitle string   `json:"session_title"`
+	Message      string   `json:"message"`
+	Keys         []string `json:"keys"`
+}
+
+// SendResultsJSON is the output of `session send --all/--group` and `group send`
+type SendResultsJSON struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Sent    int          `json:"sent"`
+	Failed  int          `json:"failed"`
+	Skipped int          `json:"skipped"`
+	Results []sendResult `json:"results"`
+}
+
+// GroupCreateJSON is the output of `group create`
+type GroupCreateJSON struct {
+	Success bool   `json:"success"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Existed bool   `json:"existed,omitempty"`
+}
+
+// GroupDeleteJSON is the output of `group delete`
+type GroupDeleteJSON struct {
+	Success       bool   `json:"success"`
+	Name          string `json:"name"`
+	SessionsMoved int    `json:"sessions_moved"`
+	MovedTo       string `json:"moved_to"`
+}
+
+// GroupMoveJSON is the output of `group move`
+type GroupMoveJSON struct {
+	Success bool   `json:"success"`
+	Session string `json:"session"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// GroupUpdateJSON is the output of `group set`
+type GroupUpdateJSON struct {
+	Success  bool   `json:"success"`
+	Group    string `json:"group"`
+	Field    string `json:"field"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// DaemonStatusJSON is the output of `daemon status`; the daemon's details
+// are left out when it isn't running
+type DaemonStatusJSON struct {
+	Running bool `json:"running"`
+	*daemon.Info
+}
+
+// ManifestJSON is the output of `up` and `down`
+type ManifestJSON struct {
+	Success  bool                  `json:"success"`
+	Manifest string                `json:"manifest"`
+	Profile  string                `json:"profile"`
+	Sessions []manifestSessionJSON `json:"sessions"`
+}
+
+// ManifestExportJSON is the output of `export-manifest`
+type ManifestExportJSON struct {
+	Success  bool   `json:"success"`
+	Manifest string `json:"manifest"`
+	Sessions int    `json:"sessions"`
+}
+
+// MsgSendJSON is the output of `msg send`
+type MsgSendJSON struct {
+	Success   bool   `json:"success"`
+	ID        string `json:"id"`
+	From      string `json:"from"`
+	FromTitle string `json:"from_title"`
+	To        string `json:"to"`
+	ToTitle   string `json:"to_title"`
+	Status    string `json:"status"` // delivered, queued or mailbox
+}
+
+// MsgReadJSON is the output of `msg read`
+type MsgReadJSON struct {
+	SessionID    string             `json:"session_id"`
+	SessionTitle string             `json:"session_title"`
+	Messages     []*mailbox.Message `json:"messages"`
+}
+
+// OrchestrateJSON is the output of `orchestrate`
+type OrchestrateJSON struct {
+	Success        bool               `json:"success"`
+	ParentID       string             `json:"parent_id"`
+	ParentTitle    string             `json:"parent_title"`
+	Report         string             `json:"report"`
+	ReportFile     string             `json:"report_file"`
+	Cleanup        string             `json:"cleanup"`
+	ElapsedSeconds int                `json:"elapsed_seconds"`
+	Tasks          []*orchestrateTask `json:"tasks"`
+}
+
+// ScheduleAddJSON is the output of `schedule add`
+type ScheduleAddJSON struct {
+	Success      bool   `json:"success"`
+	ID           string `json:"id"`
+	SessionID    string `json:"session_id"`
+	SessionTitle string `json:"session_title"`
+	NextRun      string `json:"next_run"` // RFC 3339
+}
+
+// ScheduleRemoveJSON is the output of `schedule rm`
+type ScheduleRemoveJSON struct {
+	Success bool   `json:"success"`
+	ID      string `json:"id"`
+}
+
+// GitJSON is the git state of a session's project
+type GitJSON struct {
+	Branch   string `json:"branch"`
+	Dirty    bool   `json:"dirty"`
+	Unmerged int    `json:"unmerged"`
+}
+
+// StatusJSON counts sessions by status
+type StatusJSON struct {
+	Waiting int `json:"waiting"`
+	Running int `json:"running"`
+	Idle    int `json:"idle"`
+	Error   int `json:"error"`
+	Exited  int `json:"exited"`
+	Total   int `json:"total"`
+}
+
+func newStatusJSON(c statusCounts) StatusJSON {
+	return StatusJSON{
+		Waiting: c.waiting,
+		Running: c.running,
+		Idle:    c.idle,
+		Error:   c.err,
+		Exited:  c.exited,
+		Total:   c.total,
+	}
+}
+
+// WatchEventJSON is a line of `watch --json`. A snapshot lists every
+// session; added, removed and changed carry the session (changed also the
+// previous values of the fields that changed); heartbeat carries only counts.
+type WatchEventJSON struct {
+	Type     string           `json:"type"`
+	Time     time.Time        `json:"time"`
+	Session  *SessionJSON     `json:"session,omitempty"`
+	Previous *WatchChangeJSON `json:"previous,omitempty"`
+	Sessions []SessionJSON    `json:"sessions,omitempty"`
+	Counts   StatusJSON       `json:"counts"`
+}
+
+// WatchChangeJSON holds the previous values of the fields a change event changed
+type WatchChangeJSON struct {
+	Status string `json:"status,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Group  string `json:"group,omitempty"`
+}
+
+// GroupJSON is a group as listed by `group list`
+type GroupJSON struct {
+	Name            string      `json:"name"`
+	Path            string      `json:"path"`
+	SessionCount    int         `json:"session_count"`
+	ClaudeConfigDir string      `json:"claude_config_dir,omitempty"`
+	Status          *StatusJSON `json:"status,omitempty"` // Of the group's own sessions
+	Children        []GroupJSON `json:"children,omitempty"`
+}
+
+// GroupListJSON is the output of `group list`
+type GroupListJSON struct {
+	Groups        []GroupJSON `json:"groups"`
+	TotalGroups   int         `json:"total_groups"`
+	TotalSessions int         `json:"total_sessions"`
+}
+
+// MCPJSON is an MCP server from config.toml
+type MCPJSON struct {
+	Name        string            `json:"name"`
+	Command     string            `json:"command"`
+	Args        []string          `json:"args"`
+	Env         map[string]string `json:"env,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// MCPListJSON is the output of `mcp list`
+type MCPListJSON struct {
+	MCPs []MCPJSON `json:"mcps"`
+}
+
+// MCPScopesJSON are MCP names attached to a session, by where they're configured
+type MCPScopesJSON struct {
+	Local   []string `json:"local"`
+	Global  []string `json:"global"`
+	Project []string `json:"project"`
+}
+
+// AttachedMCPsJSON is the output of `mcp attached`
+type AttachedMCPsJSON struct {
+	Session   string `json:"session"`
+	SessionID string `json:"session_id"`
+	MCPScopesJSON
+}
+
+// MCPChangeJSON is the output of `mcp attach` and `mcp detach`
+type MCPChangeJSON struct {
+	Success   bool   `json:"success"`
+	Session   string `json:"session"`
+	MCP       string `json:"mcp"`
+	Scope     string `json:"scope"`
+	Restarted bool   `json:"restarted"`
+}
+
+// ProfileJSON is a profile as listed by `profile list`
+type ProfileJSON struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+}
+
+// ProfileActionJSON is the output of `profile create`, `delete` and `default <name>`
+type ProfileActionJSON struct {
+	Success bool   `json:"success"`
+	Name    string `json:"name"`
+}
+
+// ProfileListJSON is the output of `profile list`
+type ProfileListJSON struct {
+	Profiles []ProfileJSON `json:"profiles"`
+}
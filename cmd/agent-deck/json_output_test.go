@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// jsonFields returns the JSON names of a struct's fields, flattening embedded
+// structs and skipping unexported fields
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			names = append(names, jsonFields(ft)...)
+			continue
+		}
+		names = append(names, strings.Split(f.Tag.Get("json"), ",")[0])
+	}
+	return names
+}
+
+// TestJSONSchema locks the field names of the --json output. Adding a field
+// is fine (add it here too); renaming or removing one needs a new
+// JSONSchemaVersion.
+func TestJSONSchema(t *testing.T) {
+	if JSONSchemaVersion != 1 {
+		t.Fatalf("JSONSchemaVersion = %d; update the expected fields below", JSONSchemaVersion)
+	}
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{ErrorJSON{}, "error"},
+		{ErrorBody{}, "code message"},
+		{VersionJSON{}, "version schema_version"},
+		{SessionJSON{}, "id title path group tool command status profile parent_id created_at"},
+		{SessionDetailJSON{}, "id title path group tool command status profile parent_id created_at " +
+			"claude_session_id session_id can_fork can_restart mcps mcp_config host layout layout_panes env " +
+			"claude_config_dir claude_account restart_policy exit_status worktree_path worktree_repo " +
+			"forked_from_id fork_ids git tmux_session agent_pane"},
+		{AddJSON{}, "success id title path group tool command status profile parent_id created_at " +
+			"host worktree_branch mcps template"},
+		{RemoveJSON{}, "success profile sessions"},
+		{RemovedSessionJSON{}, "id title schedules_removed worktree worktree_deleted"},
+		{SessionActionJSON{}, "success id title tmux message message_pending"},
+		{FieldUpdateJSON{}, "success id title field old_value new_value"},
+		{WaitJSON{}, "until mode met timed_out elapsed_seconds sessions"},
+		{WaitSessionJSON{}, "id title status initial_status met"},
+		{SuccessJSON{}, "success"},
+		{ForkJSON{}, "success parent_id new_id new_title worktree_path branch"},
+		{UnregisteredSessionJSON{}, "tmux_session title path window registered id_fragment"},
+		{ParentLinkJSON{}, "success session_id session_title parent_id parent_title inherited_group"},
+		{ParentUnlinkJSON{}, "success session_id session_title former_parent"},
+		{SessionOutputJSON{}, "success session_id session_title tool role content timestamp " +
+			"conversation_id claude_session_id gemini_session_id"},
+		{CurrentSessionJSON{}, "session profile id path status group"},
+		{DiffJSON{}, "success a b files diff"},
+		{DiffSideJSON{}, "id title path"},
+		{DiffFileJSON{}, "status path"},
+		{SendJSON{}, "success session_id session_title message keys"},
+		{SendResultsJSON{}, "success message sent failed skipped results"},
+		{sendResult{}, "session_id session_title status error"},
+		{GroupCreateJSON{}, "success name path existed"},
+		{GroupDeleteJSON{}, "success name sessions_moved moved_to"},
+		{GroupMoveJSON{}, "success session from to"},
+		{GroupUpdateJSON{}, "success group field old_value new_value"},
+		{DaemonStatusJSON{}, "running profile pid version started_at sessions"},
+		{ManifestJSON{}, "success manifest profile sessions"},
+		{manifestSessionJSON{}, "title id created started stopped error"},
+		{ManifestExportJSON{}, "success manifest sessions"},
+		{MsgSendJSON{}, "success id from from_title to to_title status"},
+		{MsgReadJSON{}, "session_id session_title messages"},
+		{OrchestrateJSON{}, "success parent_id parent_title report report_file cleanup elapsed_seconds tasks"},
+		{orchestrateTask{}, "title id status error elapsed_seconds response cleanup"},
+		{ScheduleAddJSON{}, "success id session_id session_title next_run"},
+		{ScheduleRemoveJSON{}, "success id"},
+		{GitJSON{}, "branch dirty unmerged"},
+		{StatusJSON{}, "waiting running idle error exited total"},
+		{WatchEventJSON{}, "type time session previous sessions counts"},
+		{WatchChangeJSON{}, "status title group"},
+		{GroupJSON{}, "name path session_count claude_config_dir status children"},
+		{GroupListJSON{}, "groups total_groups total_sessions"},
+		{MCPJSON{}, "name command args env description"},
+		{MCPListJSON{}, "mcps"},
+		{MCPScopesJSON{}, "local global project"},
+		{AttachedMCPsJSON{}, "session session_id local global project"},
+		{MCPChangeJSON{}, "success session mcp scope restarted"},
+		{ProfileJSON{}, "name default"},
+		{ProfileActionJSON{}, "success name"},
+		{ProfileListJSON{}, "profiles"},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.v)
+		if got := strings.Join(jsonFields(typ), " "); got != tt.want {
+			t.Errorf("%s fields = %q, want %q", typ.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestExitCodeFor(t *testing.T) {
+	if got := exitCodeFor(ErrCodeNotFound); got != 2 {
+		t.Errorf("exitCodeFor(NOT_FOUND) = %d, want 2", got)
+	}
+	for _, code := range errorCodes {
+		if code != ErrCodeNotFound && exitCodeFor(code) != 1 {
+			t.Errorf("exitCodeFor(%s) = %d, want 1", code, exitCodeFor(code))
+		}
+	}
+}
+
+// decodeStrict decodes stdout into v, rejecting unknown fields and trailing output
+func decodeStrict(stdout string, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("more than one JSON document")
+	}
+	return nil
+}
+
+// TestJSONErrorContract checks that failures in JSON mode print only an
+// ErrorJSON on stdout and exit with the code's exit status
+func TestJSONErrorContract(t *testing.T) {
+	tests := []struct {
+		args []string
+		code string
+	}{
+		{[]string{"session", "show", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"session", "start", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"session", "set", "--json", "nope", "title", "x"}, ErrCodeNotFound},
+		{[]string{"session", "send", "--json", "nope", "hi"}, ErrCodeNotFound},
+		{[]string{"group", "delete", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"group", "create", "--json"}, ErrCodeInvalidOperation},
+		{[]string{"mcp", "attached", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"schedule", "rm", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"msg", "read", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"daemon", "stop", "--json"}, ErrCodeInvalidOperation},
+		{[]string{"watch", "--json", "--group", "nope"}, ErrCodeNotFound},
+		{[]string{"add", "--json", "/nonexistent/path"}, ErrCodeNotFound},
+		{[]string{"add", "--json", "--template", "nope"}, ErrCodeNotFound},
+		{[]string{"remove", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"remove", "--json"}, ErrCodeInvalidOperation},
+		{[]string{"session", "wait", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"session", "wait", "--json", "--until", "soon", "nope"}, ErrCodeInvalidOperation},
+		{[]string{"profile", "create", "--json"}, ErrCodeInvalidOperation},
+		{[]string{"profile", "delete", "--json", "--yes", "nope"}, ErrCodeNotFound},
+		{[]string{"profile", "default", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"session", "output", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"session", "fork", "--json", "nope"}, ErrCodeNotFound},
+		{[]string{"session", "diff", "--json", "nope"}, ErrCodeInvalidOperation},
+		{[]string{"session", "transcript", "--format", "json", "nope"}, ErrCodeNotFound},
+		{[]string{"group", "move", "--json", "nope", "team"}, ErrCodeNotFound},
+		{[]string{"schedule", "add", "--json", "--in", "1h", "nope", "hi"}, ErrCodeNotFound},
+		{[]string{"msg", "send", "--json", "--to", "nope", "hi"}, ErrCodeNotFound},
+	}
+	for _, tt := range tests {
+		stdout, _, exit := runCLI(t, tt.args...)
+		var got ErrorJSON
+		if err := decodeStrict(stdout, &got); err != nil {
+			t.Errorf("%v: stdout is not a single ErrorJSON (%v): %q", tt.args, err, stdout)
+			continue
+		}
+		if got.Error.Code != tt.code || got.Error.Message == "" {
+			t.Errorf("%v: error = %+v, want code %s", tt.args, got.Error, tt.code)
+		}
+		if exit != exitCodeFor(tt.code) {
+			t.Errorf("%v: exit code = %d, want %d", tt.args, exit, exitCodeFor(tt.code))
+		}
+	}
+
+	// Commands without --json keep the exit codes and print errors on stderr
+	plain := []struct {
+		args []string
+		code string
+	}{
+		{[]string{"session", "attach", "nope"}, ErrCodeNotFound},
+		{[]string{"mcp", "nope"}, ErrCodeInvalidOperation},
+	}
+	for _, tt := range plain {
+		_, stderr, exit := runCLI(t, tt.args...)
+		if exit != exitCodeFor(tt.code) || !strings.Contains(stderr, "Error: ") {
+			t.Errorf("%v: exit code %d, stderr %q; want %d and an error", tt.args, exit, stderr, exitCodeFor(tt.code))
+		}
+	}
+}
+
+// TestJSONOutputContract checks the shape of read-only commands on an empty profile
+func TestJSONOutputContract(t *testing.T) {
+	tests := []struct {
+		args []string
+		into interface{}
+	}{
+		{[]string{"list", "--json"}, &[]SessionJSON{}},
+		{[]string{"list", "--json", "--all"}, &[]SessionJSON{}},
+		{[]string{"status", "--json"}, &StatusJSON{}},
+		{[]string{"watch", "--json", "--once"}, &WatchEventJSON{}},
+		{[]string{"group", "list", "--json"}, &GroupListJSON{}},
+		{[]string{"profile", "list", "--json"}, &ProfileListJSON{}},
+		{[]string{"version", "--json"}, &VersionJSON{}},
+	}
+	for _, tt := range tests {
+		stdout, stderr, exit := runCLI(t, tt.args...)
+		if exit != 0 {
+			t.Errorf("%v: exit code %d: %s", tt.args, exit, stderr)
+			continue
+		}
+		dec := json.NewDecoder(strings.NewReader(stdout))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(tt.into); err != nil {
+			t.Errorf("%v: %v in %q", tt.args, err, stdout)
+		}
+	}
+
+	stdout, _, _ := runCLI(t, "version", "--json")
+	var v VersionJSON
+	if err := json.Unmarshal([]byte(stdout), &v); err != nil || v.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("version --json = %q", stdout)
+	}
+}
+
+// TestJSONMutationContract checks the output of commands that change a profile
+func TestJSONMutationContract(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	run := func(into interface{}, wantExit int, args ...string) {
+		t.Helper()
+		stdout, stderr, exit := runCLIInHome(t, home, args...)
+		if exit != wantExit {
+			t.Fatalf("%v: exit code %d, want %d: %s%s", args, exit, wantExit, stdout, stderr)
+		}
+		if err := decodeStrict(stdout, into); err != nil {
+			t.Fatalf("%v: %v in %q", args, err, stdout)
+		}
+	}
+
+	var added AddJSON
+	run(&added, 0, "add", "--json", "-t", "contract", project)
+	if !added.Success || added.ID == "" || added.Title != "contract" || added.Path != project {
+		t.Errorf("add --json = %+v", added)
+	}
+
+	var dup ErrorJSON
+	run(&dup, 1, "add", "--json", project)
+	if dup.Error.Code != ErrCodeAlreadyExists {
+		t.Errorf("duplicate add: error = %+v, want %s", dup.Error, ErrCodeAlreadyExists)
+	}
+
+	// Never started, so its status can't change; the wait times out
+	var waited WaitJSON
+	run(&waited, exitTimeout, "session", "wait", "--json", "--until", "any-change", "--timeout", "1s", "contract")
+	if !waited.TimedOut || len(waited.Sessions) != 1 || waited.Sessions[0].ID != added.ID {
+		t.Errorf("session wait --json = %+v", waited)
+	}
+
+	var child AddJSON
+	run(&child, 0, "add", "--json", "-t", "contract-child", t.TempDir())
+
+	var groupCreated GroupCreateJSON
+	run(&groupCreated, 0, "group", "create", "--json", "team")
+	if !groupCreated.Success || groupCreated.Path != "team" || groupCreated.Existed {
+		t.Errorf("group create --json = %+v", groupCreated)
+	}
+	var moved GroupMoveJSON
+	run(&moved, 0, "group", "move", "--json", "contract", "team")
+	if !moved.Success || moved.Session != "contract" || moved.To != "team" {
+		t.Errorf("group move --json = %+v", moved)
+	}
+	configDir := t.TempDir()
+	var groupSet GroupUpdateJSON
+	run(&groupSet, 0, "group", "set", "--json", "team", "claude-config-dir", configDir)
+	if !groupSet.Success || groupSet.Field != "claude-config-dir" || groupSet.NewValue != configDir {
+		t.Errorf("group set --json = %+v", groupSet)
+	}
+
+	var linked ParentLinkJSON
+	run(&linked, 0, "session", "set-parent", "--json", "contract-child", "contract")
+	if !linked.Success || linked.SessionID != child.ID || linked.ParentID != added.ID || linked.InheritedGroup != "team" {
+		t.Errorf("session set-parent --json = %+v", linked)
+	}
+	var unlinked ParentUnlinkJSON
+	run(&unlinked, 0, "session", "unset-parent", "--json", "contract-child")
+	if !unlinked.Success || unlinked.FormerParent != "contract" {
+		t.Errorf("session unset-parent --json = %+v", unlinked)
+	}
+
+	var sentMsg MsgSendJSON
+	run(&sentMsg, 0, "msg", "send", "--json", "--from", "contract-child", "--to", "contract", "hello")
+	if !sentMsg.Success || sentMsg.From != child.ID || sentMsg.To != added.ID || sentMsg.Status != msgMailbox {
+		t.Errorf("msg send --json = %+v", sentMsg)
+	}
+	var inbox MsgReadJSON
+	run(&inbox, 0, "msg", "read", "--json", "contract")
+	if inbox.SessionID != added.ID || len(inbox.Messages) != 1 || inbox.Messages[0].Body != "hello" {
+		t.Errorf("msg read --json = %+v", inbox)
+	}
+
+	var scheduled ScheduleAddJSON
+	run(&scheduled, 0, "schedule", "add", "--json", "--in", "1h", "contract", "later")
+	if !scheduled.Success || scheduled.ID == "" || scheduled.SessionID != added.ID || scheduled.NextRun == "" {
+		t.Errorf("schedule add --json = %+v", scheduled)
+	}
+	var unscheduled ScheduleRemoveJSON
+	run(&unscheduled, 0, "schedule", "rm", "--json", scheduled.ID)
+	if !unscheduled.Success || unscheduled.ID != scheduled.ID {
+		t.Errorf("schedule rm --json = %+v", unscheduled)
+	}
+
+	// Nothing runs, so a send to all sessions skips both
+	var sentAll SendResultsJSON
+	run(&sentAll, 0, "session", "send", "--json", "--all", "hi")
+	if !sentAll.Success || sentAll.Skipped != 2 || len(sentAll.Results) != 2 {
+		t.Errorf("session send --all --json = %+v", sentAll)
+	}
+
+	var exported ManifestExportJSON
+	run(&exported, 0, "export-manifest", "--json", "--all", "-o", filepath.Join(t.TempDir(), "agentdeck.toml"))
+	if !exported.Success || exported.Sessions != 2 {
+		t.Errorf("export-manifest --json = %+v", exported)
+	}
+
+	var daemonStatus DaemonStatusJSON
+	run(&daemonStatus, 1, "daemon", "status", "--json")
+	if daemonStatus.Running || daemonStatus.Info != nil {
+		t.Errorf("daemon status --json = %+v, want not running", daemonStatus)
+	}
+
+	var removed RemoveJSON
+	run(&removed, 0, "remove", "--json", "contract")
+	if !removed.Success || len(removed.Sessions) != 1 || removed.Sessions[0].ID != added.ID {
+		t.Errorf("remove --json = %+v", removed)
+	}
+	var groupDeleted GroupDeleteJSON
+	run(&groupDeleted, 0, "group", "delete", "--json", "--force", "team") // contract-child joined it with its parent
+	if !groupDeleted.Success || groupDeleted.Name != "team" || groupDeleted.SessionsMoved != 1 {
+		t.Errorf("group delete --json = %+v", groupDeleted)
+	}
+
+	var created, setDefault, deleted ProfileActionJSON
+	run(&created, 0, "profile", "create", "--json", "contract")
+	if !created.Success || created.Name != "contract" {
+		t.Errorf("profile create --json = %+v", created)
+	}
+	var exists ErrorJSON
+	run(&exists, 1, "profile", "create", "--json", "contract")
+	if exists.Error.Code != ErrCodeAlreadyExists {
+		t.Errorf("duplicate profile create: error = %+v, want %s", exists.Error, ErrCodeAlreadyExists)
+	}
+	run(&setDefault, 0, "profile", "default", "--json", "contract")
+	var current ProfileJSON
+	run(&current, 0, "profile", "default", "--json")
+	if current.Name != "contract" || !current.Default {
+		t.Errorf("profile default --json = %+v", current)
+	}
+	run(&deleted, 0, "profile", "delete", "--json", "--yes", "contract")
+	if !deleted.Success || deleted.Name != "contract" {
+		t.Errorf("profile delete --json = %+v", deleted)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -10,12 +9,16 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
-	"time"
 
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
+	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/ledger"
+	"github.com/asheshgoplani/agent-deck/internal/mailbox"
+	"github.com/asheshgoplani/agent-deck/internal/scheduler"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/asheshgoplani/agent-deck/internal/ui"
 	"github.com/asheshgoplani/agent-deck/internal/update"
@@ -176,6 +179,12 @@ func initColorProfile() {
 }
 
 func main() {
+	// Shell completion parses -p/--profile itself, among the words being completed
+	if len(os.Args) > 1 && os.Args[1] == "__complete" {
+		handleComplete(os.Args[2:])
+		return
+	}
+
 	// Extract global -p/--profile flag before subcommand dispatch
 	profile, args := extractProfileFlag(os.Args[1:])
 
@@ -183,9 +192,17 @@ func main() {
 	if len(args) > 0 {
 		switch args[0] {
 		case "version", "--version", "-v":
+			if len(args) > 1 && args[1] == "--json" {
+				NewCLIOutput(true, false).Print("", VersionJSON{Version: Version, SchemaVersion: JSONSchemaVersion})
+				return
+			}
 			fmt.Printf("Agent Deck v%s\n", Version)
 			return
 		case "help", "--help", "-h":
+			if len(args) > 1 && args[1] == "--json" {
+				handleHelpJSON()
+				return
+			}
 			printHelp()
 			return
 		case "add":
@@ -215,6 +232,33 @@ func main() {
 		case "group":
 			handleGroup(profile, args[1:])
 			return
+		case "schedule":
+			handleSchedule(profile, args[1:])
+			return
+		case "daemon":
+			handleDaemon(profile, args[1:])
+			return
+		case "up":
+			handleUp(profile, args[1:])
+			return
+		case "down":
+			handleDown(profile, args[1:])
+			return
+		case "export-manifest":
+			handleExportManifest(profile, args[1:])
+			return
+		case "orchestrate":
+			handleOrchestrate(profile, args[1:])
+			return
+		case "msg":
+			handleMsg(profile, args[1:])
+			return
+		case "watch":
+			handleWatch(profile, args[1:])
+			return
+		case "completion":
+			handleCompletion(args[1:])
+			return
 		}
 	}
 
@@ -235,12 +279,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Acquire lock to prevent duplicate instances
-	if err := acquireLock(profile); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+	// Acquire lock to prevent duplicate instances. With a daemon running, TUIs
+	// are its clients (it owns restarts, schedules and log maintenance), so
+	// any number of them may run; the daemon holds the lock itself.
+	if !daemon.Running(profile) {
+		if err := acquireLock(profile); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer releaseLock(profile)
 	}
-	defer releaseLock(profile)
 
 	// Initialize ledger manager for decision tracking
 	ledgerMgr := ledger.GetManager()
@@ -339,6 +387,23 @@ func handleAdd(profile string, args []string) {
 		return nil
 	})
 
+	host := fs.String("host", "", "Remote host from config.toml [hosts.<name>] (path is on that host)")
+	worktree := fs.String("worktree", "", "Run in a new git worktree of the repo at path, on this branch (created if missing)")
+	template := fs.String("template", "", "Session template from config.toml [templates.<name>] (flags override it)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	// Layout flags - named layout from config.toml plus extra panes/windows
+	layout := fs.String("layout", "", "Named layout from config.toml [layouts.<name>]")
+	var layoutPanes []session.LayoutPane
+	fs.Func("pane", "Extra pane beside the agent running this command (can specify multiple times)", func(s string) error {
+		layoutPanes = append(layoutPanes, session.LayoutPane{Command: s})
+		return nil
+	})
+	fs.Func("window", "Extra window running this command (can specify multiple times)", func(s string) error {
+		layoutPanes = append(layoutPanes, session.LayoutPane{Command: s, Window: true})
+		return nil
+	})
+
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck add [path] [options]")
 		fmt.Println()
@@ -358,39 +423,62 @@ func handleAdd(profile string, args []string) {
 		fmt.Println("  agent-deck -p work add               # Add to 'work' profile")
 		fmt.Println("  agent-deck add -t \"Sub-task\" --parent \"Main Project\"  # Create sub-session")
 		fmt.Println("  agent-deck add -t \"Research\" -c claude --mcp memory --mcp sequential-thinking /tmp/x")
+		fmt.Println("  agent-deck add -c claude --host devbox ~/src/api     # Run on a remote host")
+		fmt.Println("  agent-deck add -c claude --worktree feature/login .  # Isolated git worktree")
+		fmt.Println("  agent-deck add --template review ~/src/api           # Tool, group, MCPs, prompt from a template")
+		fmt.Println("  agent-deck add -c claude --layout dev .")
+		fmt.Println("  agent-deck add -c claude --pane \"npm test -- --watch\" --window \"npm run dev\" .")
 	}
 
 	if err := fs.Parse(args); err != nil {
 		os.Exit(1)
 	}
 
+	out := NewCLIOutput(*jsonOutput, false)
+
+	// Validate template name before anything else
+	var tmpl session.TemplateDef
+	if *template != "" {
+		var ok bool
+		if tmpl, ok = session.GetTemplate(*template); !ok {
+			out.Fail(fmt.Sprintf("template '%s' not found in config.toml [templates.%s]", *template, *template), ErrCodeNotFound)
+		}
+	}
+
 	// Get path argument (defaults to current directory)
 	path := fs.Arg(0)
-	if path == "" || path == "." {
+	if *host != "" {
+		// Remote sessions: the path lives on the host, so it can't be resolved
+		// or checked here. "~" is expanded by the remote shell.
+		if _, ok := session.GetHosts()[*host]; !ok {
+			out.Fail(fmt.Sprintf("host '%s' not found in config.toml [hosts.%s]", *host, *host), ErrCodeNotFound)
+		}
+		if path == "" || path == "." {
+			path = "~"
+		}
+	} else if path == "" || path == "." {
 		var err error
 		path, err = os.Getwd()
 		if err != nil {
-			fmt.Printf("Error: failed to get current directory: %v\n", err)
-			os.Exit(1)
+			out.Fail(fmt.Sprintf("failed to get current directory: %v", err), ErrCodeInternal)
 		}
 	} else {
 		var err error
 		path, err = filepath.Abs(path)
 		if err != nil {
-			fmt.Printf("Error: failed to resolve path: %v\n", err)
-			os.Exit(1)
+			out.Fail(fmt.Sprintf("failed to resolve path: %v", err), ErrCodeInvalidOperation)
 		}
 	}
 
 	// Verify path exists and is a directory
-	info, err := os.Stat(path)
-	if err != nil {
-		fmt.Printf("Error: path does not exist: %s\n", path)
-		os.Exit(1)
-	}
-	if !info.IsDir() {
-		fmt.Printf("Error: path is not a directory: %s\n", path)
-		os.Exit(1)
+	if *host == "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			out.Fail(fmt.Sprintf("path does not exist: %s", path), ErrCodeNotFound)
+		}
+		if !info.IsDir() {
+			out.Fail(fmt.Sprintf("path is not a directory: %s", path), ErrCodeInvalidOperation)
+		}
 	}
 
 	// Merge short and long flags
@@ -398,48 +486,100 @@ func handleAdd(profile string, args []string) {
 	sessionGroup := mergeFlags(*group, *groupShort)
 	sessionCommand := mergeFlags(*command, *commandShort)
 	sessionParent := mergeFlags(*parent, *parentShort)
+	worktreeBranch := *worktree
 
-	// Default title to folder name
+	// Template fills in whatever the flags left empty
+	if *template != "" {
+		if sessionTitle == "" && tmpl.Title != "" {
+			sessionTitle = session.ExpandTemplateVars(tmpl.Title, path)
+		}
+		if sessionGroup == "" {
+			sessionGroup = tmpl.Group
+		}
+		if sessionCommand == "" {
+			sessionCommand = tmpl.CommandLine()
+		}
+		if sessionParent == "" {
+			sessionParent = tmpl.Parent
+		}
+		if worktreeBranch == "" && tmpl.Worktree != "" {
+			worktreeBranch = session.ExpandTemplateVars(tmpl.Worktree, path)
+		}
+		for _, name := range tmpl.MCPs {
+			if !slices.Contains(mcpFlags, name) {
+				mcpFlags = append(mcpFlags, name)
+			}
+		}
+	}
+
+	if worktreeBranch != "" && *host != "" {
+		out.Fail("--worktree cannot be combined with --host", ErrCodeInvalidOperation)
+	}
+
+	// Default title to folder name (plus branch for worktrees)
 	if sessionTitle == "" {
 		sessionTitle = filepath.Base(path)
+		if worktreeBranch != "" {
+			sessionTitle += "-" + git.SanitizeBranchName(worktreeBranch)
+		}
+	}
+
+	// Validate layout name before creating anything
+	if *layout != "" {
+		if _, ok := session.GetLayout(*layout); !ok {
+			out.Fail(fmt.Sprintf("layout '%s' not found in config.toml", *layout), ErrCodeNotFound)
+		}
+	}
+
+	// Validate MCPs exist in config.toml
+	if len(mcpFlags) > 0 {
+		availableMCPs := session.GetAvailableMCPs()
+		for _, mcpName := range mcpFlags {
+			if _, exists := availableMCPs[mcpName]; !exists {
+				names := make([]string, 0, len(availableMCPs))
+				for name := range availableMCPs {
+					names = append(names, name)
+				}
+				slices.Sort(names)
+				out.Fail(fmt.Sprintf("MCP '%s' not found in config.toml (available: %s)", mcpName, strings.Join(names, ", ")), ErrCodeMCPNotAvailable)
+			}
+		}
 	}
 
 	// Load existing sessions with profile
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		fmt.Printf("Error: failed to initialize storage: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, groups, err := storage.LoadWithGroups()
 	if err != nil {
-		fmt.Printf("Error: failed to load sessions: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Resolve parent session if specified
 	var parentInstance *session.Instance
 	if sessionParent != "" {
-		var errMsg string
-		parentInstance, errMsg, _ = ResolveSession(sessionParent, instances)
+		var errMsg, errCode string
+		parentInstance, errMsg, errCode = ResolveSession(sessionParent, instances)
 		if parentInstance == nil {
-			fmt.Printf("Error: %s\n", errMsg)
-			os.Exit(1)
+			out.Fail(errMsg, errCode)
 		}
 		// Sub-sessions cannot have sub-sessions (single level only)
 		if parentInstance.IsSubSession() {
-			fmt.Printf("Error: cannot create sub-session of a sub-session (single level only)\n")
-			os.Exit(1)
+			out.Fail("cannot create sub-session of a sub-session (single level only)", ErrCodeInvalidOperation)
 		}
 		// Inherit group from parent
 		sessionGroup = parentInstance.GroupPath
 	}
 
-	// Check for duplicate (same path)
-	for _, inst := range instances {
-		if inst.ProjectPath == path {
-			fmt.Printf("Session already exists: %s (%s)\n", inst.Title, inst.ID)
-			os.Exit(0)
+	// Check for duplicate (same path on the same host).
+	// Worktree sessions get a fresh path; git rejects an existing one.
+	if worktreeBranch == "" {
+		for _, inst := range instances {
+			if inst.ProjectPath == path && inst.Host == *host {
+				out.Fail(fmt.Sprintf("session already exists: %s (%s)", inst.Title, inst.ID), ErrCodeAlreadyExists)
+			}
 		}
 	}
 
@@ -459,8 +599,30 @@ func handleAdd(profile string, args []string) {
 	// Set command if provided
 	if sessionCommand != "" {
 		newInstance.Command = sessionCommand
-		// Detect tool from command
-		newInstance.Tool = detectTool(sessionCommand)
+		// Detect tool from command ([tools] entries first, by name or command)
+		if tool, cmd, ok := session.ResolveCustomTool(sessionCommand); ok {
+			newInstance.Tool = tool
+			newInstance.Command = cmd
+		} else {
+			newInstance.Tool = session.DetectTool(sessionCommand)
+		}
+		// A template's tool wins over detection when it runs the template's command
+		if *template != "" && tmpl.Tool != "" && sessionCommand == tmpl.CommandLine() {
+			newInstance.Tool = tmpl.Tool
+		}
+	}
+	if *template != "" {
+		newInstance.ApplyTemplate(tmpl)
+	}
+
+	newInstance.SetHost(*host)
+	newInstance.Layout = *layout
+	newInstance.LayoutPanes = layoutPanes
+
+	if worktreeBranch != "" {
+		if err := newInstance.CreateWorktree(worktreeBranch); err != nil {
+			out.Fail(fmt.Sprintf("failed to create worktree: %v", err), ErrCodeInvalidOperation)
+		}
 	}
 
 	// Add to instances
@@ -474,35 +636,37 @@ func handleAdd(profile string, args []string) {
 	}
 
 	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
-		fmt.Printf("Error: failed to save session: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save session: %v", err), ErrCodeInternal)
 	}
 
-	// Attach MCPs if specified
+	// Write MCPs to .mcp.json
 	if len(mcpFlags) > 0 {
-		// Validate MCPs exist in config.toml
-		availableMCPs := session.GetAvailableMCPs()
-		for _, mcpName := range mcpFlags {
-			if _, exists := availableMCPs[mcpName]; !exists {
-				fmt.Printf("Error: MCP '%s' not found in config.toml\n", mcpName)
-				fmt.Println("\nAvailable MCPs:")
-				for name := range availableMCPs {
-					fmt.Printf("  • %s\n", name)
-				}
-				os.Exit(1)
-			}
+		if err := session.WriteMCPJsonFromConfig(newInstance.ProjectPath, mcpFlags); err != nil {
+			out.Fail(fmt.Sprintf("failed to write MCPs: %v", err), ErrCodeInternal)
 		}
+	}
 
-		// Write MCPs to .mcp.json
-		if err := session.WriteMCPJsonFromConfig(path, mcpFlags); err != nil {
-			fmt.Printf("Error: failed to write MCPs: %v\n", err)
-			os.Exit(1)
-		}
+	if *jsonOutput {
+		out.Print("", AddJSON{
+			Success:        true,
+			SessionJSON:    newSessionJSON(newInstance, storage.Profile()),
+			Host:           *host,
+			WorktreeBranch: newInstance.WorktreeBranch,
+			MCPs:           mcpFlags,
+			Template:       *template,
+		})
+		return
 	}
 
 	fmt.Printf("✓ Added session: %s\n", sessionTitle)
 	fmt.Printf("  Profile: %s\n", storage.Profile())
-	fmt.Printf("  Path:    %s\n", path)
+	fmt.Printf("  Path:    %s\n", newInstance.ProjectPath)
+	if *host != "" {
+		fmt.Printf("  Host:    %s\n", *host)
+	}
+	if newInstance.IsWorktree() {
+		fmt.Printf("  Branch:  %s (worktree of %s)\n", newInstance.WorktreeBranch, newInstance.WorktreeRepo)
+	}
 	fmt.Printf("  Group:   %s\n", newInstance.GroupPath)
 	fmt.Printf("  ID:      %s\n", newInstance.ID)
 	if sessionCommand != "" {
@@ -511,9 +675,18 @@ func handleAdd(profile string, args []string) {
 	if len(mcpFlags) > 0 {
 		fmt.Printf("  MCPs:    %s\n", strings.Join(mcpFlags, ", "))
 	}
+	if newInstance.HasLayout() {
+		fmt.Printf("  Layout:  %s\n", describeLayout(newInstance))
+	}
 	if parentInstance != nil {
 		fmt.Printf("  Parent:  %s (%s)\n", parentInstance.Title, parentInstance.ID[:8])
 	}
+	if *template != "" {
+		fmt.Printf("  Template: %s\n", *template)
+	}
+	if newInstance.InitialMessage != "" {
+		fmt.Printf("  Message: sent on first start (agent-deck session start %s)\n", newInstance.ID[:8])
+	}
 }
 
 // handleList lists all sessions
@@ -545,54 +718,30 @@ func handleList(profile string, args []string) {
 		return
 	}
 
+	out := NewCLIOutput(*jsonOutput, false)
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		fmt.Printf("Error: failed to initialize storage: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, _, err := storage.LoadWithGroups()
 	if err != nil {
-		fmt.Printf("Error: failed to load sessions: %v\n", err)
-		os.Exit(1)
-	}
-
-	if len(instances) == 0 {
-		fmt.Printf("No sessions found in profile '%s'.\n", storage.Profile())
-		return
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
+	applyDaemonStatuses(profile, instances)
 
 	if *jsonOutput {
 		// JSON output for scripting
-		type sessionJSON struct {
-			ID        string    `json:"id"`
-			Title     string    `json:"title"`
-			Path      string    `json:"path"`
-			Group     string    `json:"group"`
-			Tool      string    `json:"tool"`
-			Command   string    `json:"command,omitempty"`
-			Profile   string    `json:"profile"`
-			CreatedAt time.Time `json:"created_at"`
-		}
-		sessions := make([]sessionJSON, len(instances))
+		sessions := make([]SessionJSON, len(instances))
 		for i, inst := range instances {
-			sessions[i] = sessionJSON{
-				ID:        inst.ID,
-				Title:     inst.Title,
-				Path:      inst.ProjectPath,
-				Group:     inst.GroupPath,
-				Tool:      inst.Tool,
-				Command:   inst.Command,
-				Profile:   storage.Profile(),
-				CreatedAt: inst.CreatedAt,
-			}
+			sessions[i] = newSessionJSON(inst, storage.Profile())
 		}
-		output, err := json.MarshalIndent(sessions, "", "  ")
-		if err != nil {
-			fmt.Printf("Error: failed to format JSON output: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Println(string(output))
+		out.Print("", sessions)
+		return
+	}
+
+	if len(instances) == 0 {
+		fmt.Printf("No sessions found in profile '%s'.\n", storage.Profile())
 		return
 	}
 
@@ -619,30 +768,14 @@ func handleList(profile string, args []string) {
 
 // handleListAllProfiles lists sessions from all profiles
 func handleListAllProfiles(jsonOutput bool) {
+	out := NewCLIOutput(jsonOutput, false)
 	profiles, err := session.ListProfiles()
 	if err != nil {
-		fmt.Printf("Error: failed to list profiles: %v\n", err)
-		os.Exit(1)
-	}
-
-	if len(profiles) == 0 {
-		fmt.Println("No profiles found.")
-		return
+		out.Fail(fmt.Sprintf("failed to list profiles: %v", err), ErrCodeInternal)
 	}
 
 	if jsonOutput {
-		type sessionJSON struct {
-			ID        string    `json:"id"`
-			Title     string    `json:"title"`
-			Path      string    `json:"path"`
-			Group     string    `json:"group"`
-			Tool      string    `json:"tool"`
-			Command   string    `json:"command,omitempty"`
-			Profile   string    `json:"profile"`
-			CreatedAt time.Time `json:"created_at"`
-		}
-		var allSessions []sessionJSON
-
+		allSessions := []SessionJSON{}
 		for _, profileName := range profiles {
 			storage, err := session.NewStorageWithProfile(profileName)
 			if err != nil {
@@ -653,25 +786,15 @@ func handleListAllProfiles(jsonOutput bool) {
 				continue
 			}
 			for _, inst := range instances {
-				allSessions = append(allSessions, sessionJSON{
-					ID:        inst.ID,
-					Title:     inst.Title,
-					Path:      inst.ProjectPath,
-					Group:     inst.GroupPath,
-					Tool:      inst.Tool,
-					Command:   inst.Command,
-					Profile:   profileName,
-					CreatedAt: inst.CreatedAt,
-				})
+				allSessions = append(allSessions, newSessionJSON(inst, profileName))
 			}
 		}
+		out.Print("", allSessions)
+		return
+	}
 
-		output, err := json.MarshalIndent(allSessions, "", "  ")
-		if err != nil {
-			fmt.Printf("Error: failed to format JSON output: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Println(string(output))
+	if len(profiles) == 0 {
+		fmt.Println("No profiles found.")
 		return
 	}
 
@@ -716,14 +839,21 @@ func handleListAllProfiles(jsonOutput bool) {
 // handleRemove removes a session by ID or title
 func handleRemove(profile string, args []string) {
 	fs := flag.NewFlagSet("remove", flag.ExitOnError)
+	deleteWorktree := fs.Bool("delete-worktree", false, "Also delete the session's git worktree (refused if it has unmerged changes)")
+	force := fs.Bool("force", false, "With --delete-worktree: delete even with uncommitted or unmerged changes")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	fs.Usage = func() {
-		fmt.Println("Usage: agent-deck remove <id|title>")
+		fmt.Println("Usage: agent-deck remove <id|title> [options]")
 		fmt.Println()
 		fmt.Println("Remove a session by ID or title.")
 		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  agent-deck remove abc12345")
 		fmt.Println("  agent-deck remove \"My Project\"")
+		fmt.Println("  agent-deck remove --delete-worktree abc12345")
 		fmt.Println("  agent-deck -p work remove abc12345   # Remove from 'work' profile")
 	}
 
@@ -731,38 +861,65 @@ func handleRemove(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	out := NewCLIOutput(*jsonOutput, false)
+
 	identifier := fs.Arg(0)
 	if identifier == "" {
-		fmt.Println("Error: session ID or title is required")
-		fs.Usage()
-		os.Exit(1)
+		if !*jsonOutput {
+			fs.Usage()
+		}
+		out.Fail("session ID or title is required", ErrCodeInvalidOperation)
 	}
 
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		fmt.Printf("Error: failed to initialize storage: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, groups, err := storage.LoadWithGroups()
 	if err != nil {
-		fmt.Printf("Error: failed to load sessions: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
+	}
+
+	// Warnings go to stderr in JSON mode so stdout stays a single document
+	warn := func(format string, a ...interface{}) {
+		if *jsonOutput {
+			fmt.Fprintf(os.Stderr, format, a...)
+			return
+		}
+		fmt.Printf(format, a...)
+	}
+
+	matches := func(inst *session.Instance) bool {
+		return inst.ID == identifier || strings.HasPrefix(inst.ID, identifier) || inst.Title == identifier
+	}
+
+	// Refuse before touching anything if a worktree would lose work
+	if *deleteWorktree && !*force {
+		for _, inst := range instances {
+			if matches(inst) && inst.IsWorktree() {
+				if err := inst.CheckWorktreeRemovable(); err != nil {
+					out.Fail(fmt.Sprintf("%v (commit or merge the changes, or use --force to discard them)", err), ErrCodeInvalidOperation)
+				}
+			}
+		}
 	}
 
 	// Find and remove the session
 	found := false
 	var removedTitle string
+	var removed []*session.Instance
 	newInstances := make([]*session.Instance, 0, len(instances))
 	for _, inst := range instances {
-		if inst.ID == identifier || strings.HasPrefix(inst.ID, identifier) || inst.Title == identifier {
+		if matches(inst) {
 			found = true
 			removedTitle = inst.Title
+			removed = append(removed, inst)
 			// Kill tmux session if it exists
 			if inst.Exists() {
 				if err := inst.Kill(); err != nil {
-					fmt.Printf("Warning: failed to kill tmux session: %v\n", err)
-					fmt.Println("Session removed from Agent Deck but may still be running in tmux")
+					warn("Warning: failed to kill tmux session: %v\n", err)
+					warn("Session removed from Agent Deck but may still be running in tmux\n")
 				}
 			}
 		} else {
@@ -771,19 +928,65 @@ func handleRemove(profile string, args []string) {
 	}
 
 	if !found {
-		fmt.Printf("Error: session not found in profile '%s': %s\n", storage.Profile(), identifier)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("session not found in profile '%s': %s", storage.Profile(), identifier), ErrCodeNotFound)
 	}
 
 	// Rebuild group tree and save
 	groupTree := session.NewGroupTreeWithGroups(newInstances, groups)
 
 	if err := storage.SaveWithGroups(newInstances, groupTree); err != nil {
-		fmt.Printf("Error: failed to save: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
-	fmt.Printf("✓ Removed session: %s (from profile '%s')\n", removedTitle, storage.Profile())
+	result := RemoveJSON{Success: true, Profile: storage.Profile(), Sessions: make([]RemovedSessionJSON, len(removed))}
+	for i, inst := range removed {
+		result.Sessions[i] = RemovedSessionJSON{ID: inst.ID, Title: inst.Title, Worktree: inst.WorktreePath}
+	}
+	if !*jsonOutput {
+		fmt.Printf("✓ Removed session: %s (from profile '%s')\n", removedTitle, storage.Profile())
+	}
+
+	// Drop the removed sessions' scheduled prompts
+	if store, err := scheduler.NewStore(storage.Profile()); err == nil {
+		for i, inst := range removed {
+			if n, err := store.RemoveSession(inst.ID); err == nil && n > 0 {
+				result.Sessions[i].SchedulesRemoved = n
+				if !*jsonOutput {
+					fmt.Printf("  Removed %d scheduled prompt(s) of %s\n", n, inst.Title)
+				}
+			}
+		}
+	}
+
+	if store, err := mailbox.NewStore(storage.Profile()); err == nil {
+		for _, inst := range removed {
+			_, _ = store.RemoveSession(inst.ID)
+		}
+	}
+
+	for i, inst := range removed {
+		if !inst.IsWorktree() {
+			continue
+		}
+		if !*deleteWorktree {
+			if !*jsonOutput {
+				fmt.Printf("  Worktree kept: %s (remove with --delete-worktree)\n", inst.WorktreePath)
+			}
+			continue
+		}
+		if err := inst.RemoveWorktree(*force); err != nil {
+			warn("Warning: failed to delete worktree %s: %v\n", inst.WorktreePath, err)
+			continue
+		}
+		result.Sessions[i].WorktreeDeleted = true
+		if !*jsonOutput {
+			fmt.Printf("✓ Deleted worktree: %s (branch %s kept)\n", inst.WorktreePath, inst.WorktreeBranch)
+		}
+	}
+
+	if *jsonOutput {
+		out.Print("", result)
+	}
 }
 
 // statusCounts holds session counts by status
@@ -792,14 +995,22 @@ type statusCounts struct {
 	waiting int
 	idle    int
 	err     int
+	exited  int
 	total   int
 }
 
-// countByStatus counts sessions by their status
+// countByStatus refreshes the sessions' status from tmux and counts them
 func countByStatus(instances []*session.Instance) statusCounts {
+	for _, inst := range instances {
+		_ = inst.UpdateStatus()
+	}
+	return tallyByStatus(instances)
+}
+
+// tallyByStatus counts sessions by the status they have
+func tallyByStatus(instances []*session.Instance) statusCounts {
 	var counts statusCounts
 	for _, inst := range instances {
-		_ = inst.UpdateStatus() // Refresh status from tmux
 		switch inst.Status {
 		case session.StatusRunning:
 			counts.running++
@@ -809,6 +1020,8 @@ func countByStatus(instances []*session.Instance) statusCounts {
 			counts.idle++
 		case session.StatusError:
 			counts.err++
+		case session.StatusExited:
+			counts.exited++
 		}
 		counts.total++
 	}
@@ -844,21 +1057,20 @@ func handleStatus(profile string, args []string) {
 	}
 
 	// Load sessions
+	out := NewCLIOutput(*jsonOutput, false)
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		fmt.Printf("Error: failed to initialize storage: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, _, err := storage.LoadWithGroups()
 	if err != nil {
-		fmt.Printf("Error: failed to load sessions: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	if len(instances) == 0 {
 		if *jsonOutput {
-			fmt.Println(`{"waiting": 0, "running": 0, "idle": 0, "error": 0, "total": 0}`)
+			out.Print("", StatusJSON{})
 		} else if *quiet || *quietShort {
 			fmt.Println("0")
 		} else {
@@ -867,26 +1079,19 @@ func handleStatus(profile string, args []string) {
 		return
 	}
 
-	// Count by status
-	counts := countByStatus(instances)
+	// Count by status. A running daemon already tracks statuses, so ask it
+	// rather than probing tmux (verbose output needs the sessions themselves)
+	counts, fromDaemon := statusCounts{}, false
+	if !*verbose && !*verboseShort {
+		counts, fromDaemon = daemonStatusCounts(profile)
+	}
+	if !fromDaemon {
+		counts = countByStatus(instances)
+	}
 
 	// Output based on flags
 	if *jsonOutput {
-		type statusJSON struct {
-			Waiting int `json:"waiting"`
-			Running int `json:"running"`
-			Idle    int `json:"idle"`
-			Error   int `json:"error"`
-			Total   int `json:"total"`
-		}
-		output, _ := json.Marshal(statusJSON{
-			Waiting: counts.waiting,
-			Running: counts.running,
-			Idle:    counts.idle,
-			Error:   counts.err,
-			Total:   counts.total,
-		})
-		fmt.Println(string(output))
+		out.Print("", newStatusJSON(counts))
 	} else if *quiet || *quietShort {
 		fmt.Println(counts.waiting)
 	} else if *verbose || *verboseShort {
@@ -916,13 +1121,19 @@ func handleStatus(profile string, args []string) {
 		printStatusGroup("WAITING", "◐", session.StatusWaiting)
 		printStatusGroup("RUNNING", "●", session.StatusRunning)
 		printStatusGroup("IDLE", "○", session.StatusIdle)
+		printStatusGroup("EXITED", "⊘", session.StatusExited)
 		printStatusGroup("ERROR", "✕", session.StatusError)
 
 		fmt.Printf("Total: %d sessions in profile '%s'\n", counts.total, storage.Profile())
 	} else {
 		// Compact output
-		fmt.Printf("%d waiting • %d running • %d idle\n",
-			counts.waiting, counts.running, counts.idle)
+		if counts.exited > 0 {
+			fmt.Printf("%d waiting • %d running • %d idle • %d exited\n",
+				counts.waiting, counts.running, counts.idle, counts.exited)
+		} else {
+			fmt.Printf("%d waiting • %d running • %d idle\n",
+				counts.waiting, counts.running, counts.idle)
+		}
 	}
 
 	// Show update notice if available (skip for JSON/quiet output)
@@ -935,39 +1146,19 @@ func handleStatus(profile string, args []string) {
 func handleProfile(args []string) {
 	if len(args) == 0 {
 		// Default to list
-		handleProfileList()
+		handleProfileList(nil)
 		return
 	}
 
 	switch args[0] {
 	case "list", "ls":
-		handleProfileList()
+		handleProfileList(args[1:])
 	case "create", "new":
-		if len(args) < 2 {
-			fmt.Println("Error: profile name is required")
-			fmt.Println("Usage: agent-deck profile create <name>")
-			os.Exit(1)
-		}
-		handleProfileCreate(args[1])
+		handleProfileCreate(args[1:])
 	case "delete", "rm":
-		if len(args) < 2 {
-			fmt.Println("Error: profile name is required")
-			fmt.Println("Usage: agent-deck profile delete <name>")
-			os.Exit(1)
-		}
-		handleProfileDelete(args[1])
+		handleProfileDelete(args[1:])
 	case "default":
-		if len(args) < 2 {
-			// Show current default
-			config, err := session.LoadConfig()
-			if err != nil {
-				fmt.Printf("Error: failed to load config: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Default profile: %s\n", config.DefaultProfile)
-			return
-		}
-		handleProfileSetDefault(args[1])
+		handleProfileDefault(args[1:])
 	default:
 		fmt.Printf("Unknown profile command: %s\n", args[0])
 		fmt.Println()
@@ -982,11 +1173,17 @@ func handleProfile(args []string) {
 	}
 }
 
-func handleProfileList() {
+func handleProfileList(args []string) {
+	fs := flag.NewFlagSet("profile list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
 	profiles, err := session.ListProfiles()
 	if err != nil {
-		fmt.Printf("Error: failed to list profiles: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to list profiles: %v", err), ErrCodeInternal)
 	}
 
 	config, _ := session.LoadConfig()
@@ -995,6 +1192,15 @@ func handleProfileList() {
 		defaultProfile = config.DefaultProfile
 	}
 
+	if *jsonOutput {
+		list := ProfileListJSON{Profiles: []ProfileJSON{}}
+		for _, p := range profiles {
+			list.Profiles = append(list.Profiles, ProfileJSON{Name: p, Default: p == defaultProfile})
+		}
+		out.Print("", list)
+		return
+	}
+
 	if len(profiles) == 0 {
 		fmt.Println("No profiles found.")
 		fmt.Println("Run 'agent-deck' to create the default profile automatically.")
@@ -1012,38 +1218,91 @@ func handleProfileList() {
 	fmt.Printf("\nTotal: %d profiles\n", len(profiles))
 }
 
-func handleProfileCreate(name string) {
-	if err := session.CreateProfile(name); err != nil {
-		fmt.Printf("Error: %v\n", err)
+// profileNameArg parses a profile subcommand's flags and returns its
+// output and the profile name argument ("" if not given and optional)
+func profileNameArg(fs *flag.FlagSet, args []string, required bool) (*CLIOutput, string) {
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(args); err != nil {
 		os.Exit(1)
 	}
-	fmt.Printf("✓ Created profile: %s\n", name)
-	fmt.Printf("  Use with: agent-deck -p %s\n", name)
+	out := NewCLIOutput(*jsonOutput, false)
+	name := fs.Arg(0)
+	if name == "" && required {
+		out.Fail(fmt.Sprintf("profile name is required (usage: agent-deck profile %s <name>)", strings.TrimPrefix(fs.Name(), "profile ")), ErrCodeInvalidOperation)
+	}
+	return out, name
 }
 
-func handleProfileDelete(name string) {
-	// Confirm deletion
-	fmt.Printf("Are you sure you want to delete profile '%s'? This will remove all sessions in this profile. [y/N] ", name)
-	var response string
-	_, _ = fmt.Scanln(&response)
-	if response != "y" && response != "Y" {
-		fmt.Println("Cancelled.")
-		return
+func handleProfileCreate(args []string) {
+	fs := flag.NewFlagSet("profile create", flag.ExitOnError)
+	out, name := profileNameArg(fs, args, true)
+
+	if exists, _ := session.ProfileExists(name); exists {
+		out.Fail(fmt.Sprintf("profile '%s' already exists", name), ErrCodeAlreadyExists)
+	}
+	if err := session.CreateProfile(name); err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+	out.Print(fmt.Sprintf("✓ Created profile: %s\n  Use with: agent-deck -p %s\n", name, name),
+		ProfileActionJSON{Success: true, Name: name})
+}
+
+func handleProfileDelete(args []string) {
+	fs := flag.NewFlagSet("profile delete", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "Don't ask for confirmation")
+	out, name := profileNameArg(fs, args, true)
+
+	if exists, _ := session.ProfileExists(name); !exists {
+		out.Fail(fmt.Sprintf("profile '%s' does not exist", name), ErrCodeNotFound)
+	}
+
+	// Confirm deletion (the prompt goes to stderr in JSON mode)
+	if !*yes {
+		prompt := fmt.Sprintf("Are you sure you want to delete profile '%s'? This will remove all sessions in this profile. [y/N] ", name)
+		if out.jsonMode {
+			fmt.Fprint(os.Stderr, prompt)
+		} else {
+			fmt.Print(prompt)
+		}
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			if out.jsonMode {
+				out.Fail("cancelled", ErrCodeInvalidOperation)
+			}
+			fmt.Println("Cancelled.")
+			return
+		}
 	}
 
 	if err := session.DeleteProfile(name); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
 	}
-	fmt.Printf("✓ Deleted profile: %s\n", name)
+	out.Print(fmt.Sprintf("✓ Deleted profile: %s\n", name), ProfileActionJSON{Success: true, Name: name})
 }
 
-func handleProfileSetDefault(name string) {
+// handleProfileDefault shows the default profile, or sets it when a name is given
+func handleProfileDefault(args []string) {
+	fs := flag.NewFlagSet("profile default", flag.ExitOnError)
+	out, name := profileNameArg(fs, args, false)
+
+	if name == "" {
+		config, err := session.LoadConfig()
+		if err != nil {
+			out.Fail(fmt.Sprintf("failed to load config: %v", err), ErrCodeInternal)
+		}
+		out.Print(fmt.Sprintf("Default profile: %s\n", config.DefaultProfile),
+			ProfileJSON{Name: config.DefaultProfile, Default: true})
+		return
+	}
+
+	if exists, _ := session.ProfileExists(name); !exists {
+		out.Fail(fmt.Sprintf("profile '%s' does not exist", name), ErrCodeNotFound)
+	}
 	if err := session.SetDefaultProfile(name); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
-	fmt.Printf("✓ Default profile set to: %s\n", name)
+	out.Print(fmt.Sprintf("✓ Default profile set to: %s\n", name), ProfileActionJSON{Success: true, Name: name})
 }
 
 // handleUpdate checks for and performs updates
@@ -1126,13 +1385,22 @@ func printHelp() {
 	fmt.Println("  list, ls         List all sessions")
 	fmt.Println("  remove, rm       Remove a session")
 	fmt.Println("  status           Show session status summary")
+	fmt.Println("  watch            Stream session status changes (--json, --format)")
 	fmt.Println("  session          Manage session lifecycle")
 	fmt.Println("  mcp              Manage MCP servers")
 	fmt.Println("  group            Manage groups")
 	fmt.Println("  profile          Manage profiles")
+	fmt.Println("  schedule         Manage scheduled prompts")
+	fmt.Println("  daemon           Run headless and serve a local API")
+	fmt.Println("  up               Create and start sessions from agentdeck.toml")
+	fmt.Println("  down             Stop sessions from agentdeck.toml")
+	fmt.Println("  export-manifest  Write agentdeck.toml from existing sessions")
+	fmt.Println("  orchestrate      Run tasks in child sessions and collect a report")
+	fmt.Println("  msg              Send and read messages between sessions")
+	fmt.Println("  completion       Print a shell completion script (bash, zsh, fish)")
 	fmt.Println("  update           Check for and install updates")
 	fmt.Println("  version          Show version")
-	fmt.Println("  help             Show this help")
+	fmt.Println("  help [--json]    Show this help (--json: every command and flag)")
 	fmt.Println()
 	fmt.Println("Session Commands:")
 	fmt.Println("  session start <id>        Start a session's tmux process")
@@ -1153,6 +1421,17 @@ func printHelp() {
 	fmt.Println("  group create <name>       Create a new group")
 	fmt.Println("  group delete <name>       Delete a group")
 	fmt.Println("  group move <id> <group>   Move session to group")
+	fmt.Println("  group send <group> <msg>  Send a message to the group's running sessions")
+	fmt.Println()
+	fmt.Println("Schedule Commands:")
+	fmt.Println("  schedule add <id> <msg>   Schedule a prompt (--cron, --in, --at)")
+	fmt.Println("  schedule list             List scheduled prompts")
+	fmt.Println("  schedule rm <job-id>      Remove a scheduled prompt")
+	fmt.Println()
+	fmt.Println("Daemon Commands:")
+	fmt.Println("  daemon [run]              Run the daemon in the foreground")
+	fmt.Println("  daemon status             Show whether the daemon is running")
+	fmt.Println("  daemon stop               Stop the daemon")
 	fmt.Println()
 	fmt.Println("Profile Commands:")
 	fmt.Println("  profile list              List all profiles")
@@ -1206,25 +1485,6 @@ func truncate(s string, max int) string {
 	return s[:max-3] + "..."
 }
 
-// detectTool determines the tool type from command
-func detectTool(cmd string) string {
-	cmd = strings.ToLower(cmd)
-	switch {
-	case strings.Contains(cmd, "claude"):
-		return "claude"
-	case strings.Contains(cmd, "opencode") || strings.Contains(cmd, "open-code"):
-		return "opencode"
-	case strings.Contains(cmd, "gemini"):
-		return "gemini"
-	case strings.Contains(cmd, "codex"):
-		return "codex"
-	case strings.Contains(cmd, "cursor"):
-		return "cursor"
-	default:
-		return "shell"
-	}
-}
-
 // getLockFilePath returns the path to the lock file for a profile
 func getLockFilePath(profile string) string {
 	if profile == "" {
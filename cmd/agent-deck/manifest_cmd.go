@@ -0,0 +1,412 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// manifestSessionJSON reports what up/down did with one manifest session
+type manifestSessionJSON struct {
+	Title   string `json:"title"`
+	ID      string `json:"id,omitempty"`
+	Created bool   `json:"created,omitempty"`
+	Started bool   `json:"started,omitempty"`
+	Stopped bool   `json:"stopped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// loadManifestOrExit reads the manifest, reporting a missing file as NOT_FOUND
+func loadManifestOrExit(out *CLIOutput, file string) *session.Manifest {
+	if _, err := os.Stat(file); err != nil {
+		out.Fail(fmt.Sprintf("manifest not found: %s (create one with: agent-deck export-manifest)", file), ErrCodeNotFound)
+	}
+	manifest, err := session.LoadManifest(file)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	return manifest
+}
+
+// handleUp creates the manifest's missing sessions and starts them
+func handleUp(profile string, args []string) {
+	fs := flag.NewFlagSet("up", flag.ExitOnError)
+	file := fs.String("f", session.ManifestFileName, "Manifest file")
+	noStart := fs.Bool("no-start", false, "Create missing sessions without starting them")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck up [options]")
+		fmt.Println()
+		fmt.Println("Create the sessions described in a workspace manifest and start them.")
+		fmt.Println("Sessions that already exist (same title and path) are reused.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck up                        # Uses ./agentdeck.toml")
+		fmt.Println("  agent-deck up -f ~/clients/acme.toml")
+		fmt.Println("  agent-deck -p acme up --no-start")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+	manifest := loadManifestOrExit(out, *file)
+
+	// Validate MCPs before creating anything
+	availableMCPs := session.GetAvailableMCPs()
+	for _, s := range manifest.Sessions {
+		for _, name := range s.MCPs {
+			if _, ok := availableMCPs[name]; !ok {
+				out.Fail(fmt.Sprintf("session '%s': MCP '%s' not found in config.toml", s.Title, name), ErrCodeMCPNotAvailable)
+			}
+		}
+	}
+
+	storage, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+
+	// Create what's missing, parents first so sub-sessions can link to them
+	var results []manifestSessionJSON
+	var sessions []*session.Instance
+	byTitle := make(map[string]*session.Instance)
+	var createErr error
+	for _, s := range manifest.Ordered() {
+		result := manifestSessionJSON{Title: s.Title}
+		inst := manifest.FindInstance(s, instances)
+		if inst == nil {
+			inst, createErr = newManifestInstance(manifest, s, byTitle)
+			if createErr != nil {
+				createErr = fmt.Errorf("session '%s': %w", s.Title, createErr)
+				break
+			}
+			instances = append(instances, inst)
+			result.Created = true
+		}
+		result.ID = inst.ID
+		byTitle[s.Title] = inst
+		sessions = append(sessions, inst)
+		results = append(results, result)
+	}
+
+	// Save before starting so created sessions survive a failed start
+	groupTree := session.NewGroupTreeWithGroups(instances, groups)
+	for _, inst := range sessions {
+		if inst.GroupPath != "" {
+			groupTree.CreateGroup(inst.GroupPath)
+		}
+	}
+	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
+		out.Fail(fmt.Sprintf("failed to save sessions: %v", err), ErrCodeInternal)
+	}
+	if createErr != nil {
+		out.Fail(createErr.Error(), ErrCodeInvalidOperation)
+	}
+	// New sessions pick up their group's or profile's Claude account
+	session.ApplyClaudeConfigDirs(instances, groupTree.ClaudeConfigDirs(), storage.Profile())
+
+	// Start everything that isn't running, then deliver first prompts in
+	// parallel (each waits for its own agent to become ready)
+	type pendingMessage struct {
+		idx     int
+		message string
+	}
+	var pending []pendingMessage
+	if !*noStart {
+		for idx, inst := range sessions {
+			if inst.Exists() {
+				continue
+			}
+			if err := inst.Start(); err != nil {
+				results[idx].Error = fmt.Sprintf("failed to start: %v", err)
+				continue
+			}
+			results[idx].Started = true
+			if inst.InitialMessage != "" {
+				pending = append(pending, pendingMessage{idx, inst.InitialMessage})
+				inst.InitialMessage = ""
+			}
+		}
+		if err := storage.SaveWithGroups(instances, groupTree); err != nil {
+			out.Fail(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInternal)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range pending {
+		wg.Add(1)
+		go func(p pendingMessage) {
+			defer wg.Done()
+			if err := sessions[p.idx].SendMessageWhenReady(p.message); err != nil {
+				results[p.idx].Error = fmt.Sprintf("failed to send message: %v", err)
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	var human strings.Builder
+	created, started, failed := 0, 0, 0
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			failed++
+			fmt.Fprintf(&human, "%s %s: %s\n", errorSymbol, r.Title, r.Error)
+		case r.Created:
+			created++
+			fmt.Fprintf(&human, "%s %s (created)\n", successSymbol, r.Title)
+		case r.Started:
+			fmt.Fprintf(&human, "%s %s (started)\n", successSymbol, r.Title)
+		case *noStart:
+			fmt.Fprintf(&human, "%s %s (exists)\n", bulletSymbol, r.Title)
+		default:
+			fmt.Fprintf(&human, "%s %s (already running)\n", bulletSymbol, r.Title)
+		}
+		if r.Started {
+			started++
+		}
+	}
+	fmt.Fprintf(&human, "\n%d sessions: %d created, %d started\n", len(results), created, started)
+
+	out.Print(human.String(), ManifestJSON{
+		Success:  failed == 0,
+		Manifest: *file,
+		Profile:  storage.Profile(),
+		Sessions: results,
+	})
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// newManifestInstance creates (without starting) the session for manifest entry s
+func newManifestInstance(manifest *session.Manifest, s session.ManifestSession, byTitle map[string]*session.Instance) (*session.Instance, error) {
+	path := manifest.SessionPath(s)
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", path)
+	}
+
+	// Sub-sessions live in their parent's group
+	group := manifest.SessionGroup(s)
+	var parent *session.Instance
+	if s.Parent != "" {
+		parent = byTitle[s.Parent]
+		group = parent.GroupPath
+	}
+
+	var inst *session.Instance
+	if group != "" {
+		inst = session.NewInstanceWithGroup(s.Title, path, group)
+	} else {
+		inst = session.NewInstance(s.Title, path)
+	}
+	if parent != nil {
+		inst.SetParent(parent.ID)
+	}
+
+	command := s.Command
+	if command == "" {
+		command = s.Tool
+	}
+	if command != "" {
+		inst.Command = command
+		if tool, cmd, ok := session.ResolveCustomTool(command); ok {
+			inst.Tool = tool
+			inst.Command = cmd
+		} else {
+			inst.Tool = session.DetectTool(command)
+		}
+		if s.Tool != "" {
+			inst.Tool = s.Tool
+		}
+	}
+	inst.ApplyTemplate(session.TemplateDef{Env: s.Env, Message: s.Message})
+
+	if s.Worktree != "" {
+		if err := inst.CreateWorktree(session.ExpandTemplateVars(s.Worktree, path)); err != nil {
+			return nil, fmt.Errorf("failed to create worktree: %w", err)
+		}
+	}
+	if len(s.MCPs) > 0 {
+		if err := session.WriteMCPJsonFromConfig(inst.ProjectPath, s.MCPs); err != nil {
+			return nil, fmt.Errorf("failed to write MCPs: %w", err)
+		}
+	}
+	return inst, nil
+}
+
+// handleDown stops the manifest's running sessions
+func handleDown(profile string, args []string) {
+	fs := flag.NewFlagSet("down", flag.ExitOnError)
+	file := fs.String("f", session.ManifestFileName, "Manifest file")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck down [options]")
+		fmt.Println()
+		fmt.Println("Stop the sessions described in a workspace manifest.")
+		fmt.Println("Sessions stay in Agent Deck; `agent-deck up` starts them again.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+	manifest := loadManifestOrExit(out, *file)
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+
+	var results []manifestSessionJSON
+	var human strings.Builder
+	stopped, failed := 0, 0
+	for _, s := range manifest.Sessions {
+		inst := manifest.FindInstance(s, instances)
+		if inst == nil || !inst.Exists() {
+			continue
+		}
+		result := manifestSessionJSON{Title: s.Title, ID: inst.ID}
+		if err := inst.Kill(); err != nil {
+			result.Error = fmt.Sprintf("failed to stop: %v", err)
+			failed++
+			fmt.Fprintf(&human, "%s %s: %s\n", errorSymbol, s.Title, result.Error)
+		} else {
+			result.Stopped = true
+			stopped++
+			fmt.Fprintf(&human, "%s %s (stopped)\n", successSymbol, s.Title)
+		}
+		results = append(results, result)
+	}
+
+	if err := saveSessionData(storage, instances); err != nil {
+		out.Fail(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInternal)
+	}
+
+	fmt.Fprintf(&human, "Stopped %d sessions\n", stopped)
+	out.Print(human.String(), ManifestJSON{
+		Success:  failed == 0,
+		Manifest: *file,
+		Profile:  storage.Profile(),
+		Sessions: results,
+	})
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// handleExportManifest writes a manifest describing existing sessions
+func handleExportManifest(profile string, args []string) {
+	fs := flag.NewFlagSet("export-manifest", flag.ExitOnError)
+	output := fs.String("o", session.ManifestFileName, "Output file (- for stdout)")
+	group := fs.String("group", "", "Export this group (and its subgroups) instead of sessions under the current directory")
+	all := fs.Bool("all", false, "Export every session in the profile")
+	force := fs.Bool("force", false, "Overwrite an existing manifest")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck export-manifest [options]")
+		fmt.Println()
+		fmt.Println("Write a workspace manifest for `agent-deck up` from existing sessions.")
+		fmt.Println("By default exports sessions whose path is inside the current directory.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck export-manifest                 # ./agentdeck.toml")
+		fmt.Println("  agent-deck export-manifest --group clients/acme -o acme.toml")
+		fmt.Println("  agent-deck export-manifest -o -            # Print to stdout")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	// Relative paths in the manifest are relative to where it is written
+	dir, err := os.Getwd()
+	if err != nil {
+		out.Fail(fmt.Sprintf("failed to get current directory: %v", err), ErrCodeInvalidOperation)
+	}
+	if *output != "-" {
+		abs, err := filepath.Abs(*output)
+		if err != nil {
+			out.Fail(fmt.Sprintf("failed to resolve path: %v", err), ErrCodeInvalidOperation)
+		}
+		*output = abs
+		dir = filepath.Dir(abs)
+		if _, err := os.Stat(abs); err == nil && !*force {
+			out.Fail(fmt.Sprintf("%s already exists (use --force to overwrite)", abs), ErrCodeAlreadyExists)
+		}
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+
+	var selected []*session.Instance
+	for _, inst := range instances {
+		switch {
+		case *all:
+		case *group != "":
+			if inst.GroupPath != *group && !strings.HasPrefix(inst.GroupPath, *group+"/") {
+				continue
+			}
+		default:
+			path := inst.ProjectPath
+			if inst.IsWorktree() {
+				path = inst.WorktreeRepo
+			}
+			if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+				continue
+			}
+		}
+		selected = append(selected, inst)
+	}
+	if len(selected) == 0 {
+		out.Fail("no sessions to export", ErrCodeNotFound)
+	}
+
+	manifest := session.ExportManifest(selected, dir)
+	if *output == "-" {
+		data, err := manifest.Encode()
+		if err != nil {
+			out.Fail(fmt.Sprintf("failed to encode manifest: %v", err), ErrCodeInvalidOperation)
+		}
+		os.Stdout.Write(data)
+		return
+	}
+	if err := manifest.WriteFile(*output); err != nil {
+		out.Fail(fmt.Sprintf("failed to write manifest: %v", err), ErrCodeInvalidOperation)
+	}
+
+	out.Success(fmt.Sprintf("Exported %d sessions to %s", len(selected), *output), ManifestExportJSON{
+		Success:  true,
+		Manifest: *output,
+		Sessions: len(selected),
+	})
+}
@@ -4,8 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -31,9 +31,8 @@ func handleMCP(profile string, args []string) {
 	case "help", "-h", "--help":
 		printMCPHelp()
 	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown mcp command '%s'\n", args[0])
 		printMCPHelp()
-		os.Exit(1)
+		NewCLIOutput(false, false).Fail(fmt.Sprintf("unknown mcp command '%s'", args[0]), ErrCodeInvalidOperation)
 	}
 }
 
@@ -86,9 +85,7 @@ func handleMCPList(args []string) {
 
 	if len(mcps) == 0 {
 		if *jsonOutput {
-			out.Print("", map[string]interface{}{
-				"mcps": []interface{}{},
-			})
+			out.Print("", MCPListJSON{MCPs: []MCPJSON{}})
 		} else if !quietMode {
 			fmt.Println("No MCPs configured.")
 			fmt.Println()
@@ -104,17 +101,9 @@ func handleMCPList(args []string) {
 
 	if *jsonOutput {
 		// Build JSON output
-		type mcpJSON struct {
-			Name        string            `json:"name"`
-			Command     string            `json:"command"`
-			Args        []string          `json:"args"`
-			Env         map[string]string `json:"env,omitempty"`
-			Description string            `json:"description,omitempty"`
-		}
-
-		mcpList := make([]mcpJSON, 0, len(mcps))
+		mcpList := make([]MCPJSON, 0, len(mcps))
 		for name, def := range mcps {
-			mcpList = append(mcpList, mcpJSON{
+			mcpList = append(mcpList, MCPJSON{
 				Name:        name,
 				Command:     def.Command,
 				Args:        def.Args,
@@ -123,9 +112,8 @@ func handleMCPList(args []string) {
 			})
 		}
 
-		out.Print("", map[string]interface{}{
-			"mcps": mcpList,
-		})
+		sort.Slice(mcpList, func(i, j int) bool { return mcpList[i].Name < mcpList[j].Name })
+		out.Print("", MCPListJSON{MCPs: mcpList})
 		return
 	}
 
@@ -207,37 +195,32 @@ func handleMCPAttached(profile string, args []string) {
 	// Load sessions
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, _, err := storage.LoadWithGroups()
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Resolve session
 	identifier := fs.Arg(0)
 	inst, errMsg, errCode := ResolveSessionOrCurrent(identifier, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		os.Exit(2)
+		out.Fail(errMsg, errCode)
 	}
 
-	// Get MCP info for this session
-	mcpInfo := session.GetMCPInfo(inst.ProjectPath)
+	// Get MCP info for this session (global/project MCPs from its Claude account)
+	mcpInfo := session.GetMCPInfoForConfigDir(inst.GetClaudeConfigDir(), inst.ProjectPath)
 	globalMCPs := mcpInfo.Global
 	projectMCPs := mcpInfo.Project
 	localMCPs := mcpInfo.Local() // Call method for backward compatibility
 
 	if *jsonOutput {
-		out.Print("", map[string]interface{}{
-			"session":    inst.Title,
-			"session_id": TruncateID(inst.ID),
-			"local":      localMCPs,
-			"global":     globalMCPs,
-			"project":    projectMCPs,
+		out.Print("", AttachedMCPsJSON{
+			Session:       inst.Title,
+			SessionID:     TruncateID(inst.ID),
+			MCPScopesJSON: MCPScopesJSON{Local: localMCPs, Global: globalMCPs, Project: projectMCPs},
 		})
 		return
 	}
@@ -283,7 +266,7 @@ func handleMCPAttached(profile string, args []string) {
 
 	if len(globalMCPs) > 0 {
 		hasAny = true
-		configDir := session.GetClaudeConfigDir()
+		configDir := inst.GetClaudeConfigDir()
 		configPath := filepath.Join(configDir, ".claude.json")
 		fmt.Printf("GLOBAL (%s):\n", FormatPath(configPath))
 		for _, name := range globalMCPs {
@@ -351,21 +334,18 @@ func handleMCPAttach(profile string, args []string) {
 	// Load sessions
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, _, err := storage.LoadWithGroups()
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(sessionID, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		os.Exit(2)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Verify MCP exists in config.toml
@@ -389,19 +369,17 @@ func handleMCPAttach(profile string, args []string) {
 	// Attach the MCP
 	if *global {
 		// Add to global config
-		currentGlobal := session.GetGlobalMCPNames()
+		currentGlobal := session.GetGlobalMCPNames(inst.GetClaudeConfigDir())
 		// Check if already attached
 		for _, name := range currentGlobal {
 			if name == mcpName {
-				out.Error(fmt.Sprintf("MCP '%s' is already attached globally", mcpName), ErrCodeAlreadyExists)
-				os.Exit(1)
+				out.Fail(fmt.Sprintf("MCP '%s' is already attached globally", mcpName), ErrCodeAlreadyExists)
 			}
 		}
 		// Add to list
 		newGlobal := append(currentGlobal, mcpName)
-		if err := session.WriteGlobalMCP(newGlobal); err != nil {
-			out.Error(fmt.Sprintf("failed to write global config: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+		if err := session.WriteGlobalMCP(inst.GetClaudeConfigDir(), newGlobal); err != nil {
+			out.Fail(fmt.Sprintf("failed to write global config: %v", err), ErrCodeInvalidOperation)
 		}
 	} else {
 		// Add to local .mcp.json
@@ -409,15 +387,13 @@ func handleMCPAttach(profile string, args []string) {
 		// Check if already attached locally
 		for _, name := range mcpInfo.Local() {
 			if name == mcpName {
-				out.Error(fmt.Sprintf("MCP '%s' is already attached locally", mcpName), ErrCodeAlreadyExists)
-				os.Exit(1)
+				out.Fail(fmt.Sprintf("MCP '%s' is already attached locally", mcpName), ErrCodeAlreadyExists)
 			}
 		}
 		// Add to local MCPs
 		newLocal := append(mcpInfo.Local(), mcpName)
 		if err := session.WriteMCPJsonFromConfig(inst.ProjectPath, newLocal); err != nil {
-			out.Error(fmt.Sprintf("failed to write .mcp.json: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+			out.Fail(fmt.Sprintf("failed to write .mcp.json: %v", err), ErrCodeInvalidOperation)
 		}
 	}
 
@@ -438,20 +414,20 @@ func handleMCPAttach(profile string, args []string) {
 			time.Sleep(2 * time.Second)
 			if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
 				// Send "continue" and Enter to resume the conversation
-				_ = exec.Command("tmux", "send-keys", "-l", "-t", tmuxSess.Name, "continue").Run()
-				_ = exec.Command("tmux", "send-keys", "-t", tmuxSess.Name, "Enter").Run()
+				_ = tmuxSess.SendKeys("continue")
+				_ = tmuxSess.SendEnter()
 			}
 		}
 	}
 
 	// Output result
 	if *jsonOutput {
-		out.Print("", map[string]interface{}{
-			"success":   true,
-			"session":   inst.Title,
-			"mcp":       mcpName,
-			"scope":     scope,
-			"restarted": restarted,
+		out.Print("", MCPChangeJSON{
+			Success:   true,
+			Session:   inst.Title,
+			MCP:       mcpName,
+			Scope:     scope,
+			Restarted: restarted,
 		})
 	} else {
 		message := fmt.Sprintf("Attached %s to %s (%s)", mcpName, inst.Title, scope)
@@ -507,21 +483,18 @@ func handleMCPDetach(profile string, args []string) {
 	// Load sessions
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeInternal)
 	}
 
 	instances, _, err := storage.LoadWithGroups()
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(sessionID, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		os.Exit(2)
+		out.Fail(errMsg, errCode)
 	}
 
 	scope := "local"
@@ -532,7 +505,7 @@ func handleMCPDetach(profile string, args []string) {
 	// Detach the MCP
 	if *global {
 		// Remove from global config
-		currentGlobal := session.GetGlobalMCPNames()
+		currentGlobal := session.GetGlobalMCPNames(inst.GetClaudeConfigDir())
 		found := false
 		newGlobal := make([]string, 0, len(currentGlobal))
 		for _, name := range currentGlobal {
@@ -543,12 +516,10 @@ func handleMCPDetach(profile string, args []string) {
 			}
 		}
 		if !found {
-			out.Error(fmt.Sprintf("MCP '%s' is not attached globally", mcpName), ErrCodeNotFound)
-			os.Exit(2)
+			out.Fail(fmt.Sprintf("MCP '%s' is not attached globally", mcpName), ErrCodeNotFound)
 		}
-		if err := session.WriteGlobalMCP(newGlobal); err != nil {
-			out.Error(fmt.Sprintf("failed to write global config: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+		if err := session.WriteGlobalMCP(inst.GetClaudeConfigDir(), newGlobal); err != nil {
+			out.Fail(fmt.Sprintf("failed to write global config: %v", err), ErrCodeInvalidOperation)
 		}
 	} else {
 		// Remove from local .mcp.json
@@ -564,12 +535,10 @@ func handleMCPDetach(profile string, args []string) {
 			}
 		}
 		if !found {
-			out.Error(fmt.Sprintf("MCP '%s' is not attached locally", mcpName), ErrCodeNotFound)
-			os.Exit(2)
+			out.Fail(fmt.Sprintf("MCP '%s' is not attached locally", mcpName), ErrCodeNotFound)
 		}
 		if err := session.WriteMCPJsonFromConfig(inst.ProjectPath, newLocal); err != nil {
-			out.Error(fmt.Sprintf("failed to write .mcp.json: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+			out.Fail(fmt.Sprintf("failed to write .mcp.json: %v", err), ErrCodeInvalidOperation)
 		}
 	}
 
@@ -590,20 +559,20 @@ func handleMCPDetach(profile string, args []string) {
 			time.Sleep(2 * time.Second)
 			if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
 				// Send "continue" and Enter to resume the conversation
-				_ = exec.Command("tmux", "send-keys", "-l", "-t", tmuxSess.Name, "continue").Run()
-				_ = exec.Command("tmux", "send-keys", "-t", tmuxSess.Name, "Enter").Run()
+				_ = tmuxSess.SendKeys("continue")
+				_ = tmuxSess.SendEnter()
 			}
 		}
 	}
 
 	// Output result
 	if *jsonOutput {
-		out.Print("", map[string]interface{}{
-			"success":   true,
-			"session":   inst.Title,
-			"mcp":       mcpName,
-			"scope":     scope,
-			"restarted": restarted,
+		out.Print("", MCPChangeJSON{
+			Success:   true,
+			Session:   inst.Title,
+			MCP:       mcpName,
+			Scope:     scope,
+			Restarted: restarted,
 		})
 	} else {
 		message := fmt.Sprintf("Detached %s from %s (%s)", mcpName, inst.Title, scope)
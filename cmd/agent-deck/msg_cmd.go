@@ -0,0 +1,446 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/mailbox"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// Delivery states reported by `msg send`
+const (
+	msgDelivered = "delivered" // Injected into the recipient's agent
+	msgQueued    = "queued"    // Injected once the recipient is waiting
+	msgMailbox   = "mailbox"   // Only in the mailbox (--no-inject, or a shell)
+)
+
+// handleMsg dispatches msg subcommands
+func handleMsg(profile string, args []string) {
+	if len(args) == 0 {
+		printMsgHelp()
+		return
+	}
+
+	switch args[0] {
+	case "send":
+		handleMsgSend(profile, args[1:])
+	case "read":
+		handleMsgRead(profile, args[1:])
+	case "mcp":
+		handleMsgMCP(profile, args[1:])
+	case "help", "-h", "--help":
+		printMsgHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown msg command: %s\n", args[0])
+		fmt.Fprintln(os.Stderr)
+		printMsgHelp()
+		os.Exit(1)
+	}
+}
+
+// printMsgHelp prints help for msg commands
+func printMsgHelp() {
+	fmt.Println("Usage: agent-deck msg <command> [options]")
+	fmt.Println()
+	fmt.Println("Send messages between sessions. Each session has a mailbox; messages")
+	fmt.Println("are also typed into the recipient's agent once it is waiting for input.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  send --to <id> [message]  Send a message (from the current session)")
+	fmt.Println("  read [id]                 Show unread messages and mark them read")
+	fmt.Println("  mcp                       Serve send/read tools to agents over MCP (stdio)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck msg send --to planner \"The API tests pass now\"")
+	fmt.Println("  agent-deck msg send --to reviewer --from dev --no-inject \"PR is up\"")
+	fmt.Println("  agent-deck msg read")
+	fmt.Println("  agent-deck msg read --all --json reviewer")
+}
+
+// msgSessions loads the sessions of the profile the messages belong to and
+// finds the current session. Without an explicit profile, the current
+// session's profile is used.
+func msgSessions(profile string) (string, []*session.Instance, *session.Instance, error) {
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if os.Getenv("TMUX") == "" {
+		return profile, instances, nil, nil
+	}
+	if current := findSessionByTmux(instances); current != nil || profile != "" {
+		return profile, instances, current, nil
+	}
+	current, found := findSessionByTmuxAcrossProfiles()
+	if current == nil {
+		return profile, instances, nil, nil
+	}
+	if _, instances, _, err = loadSessionData(found); err != nil {
+		return "", nil, nil, err
+	}
+	for _, inst := range instances {
+		if inst.ID == current.ID {
+			return found, instances, inst, nil
+		}
+	}
+	return found, instances, nil, nil
+}
+
+// resolveRecipient finds the session a message goes to: a relative of the
+// sender (parent, sibling or sub-session) with that title first, then any
+// session by title, ID prefix or path
+func resolveRecipient(ref string, sender *session.Instance, instances []*session.Instance) (*session.Instance, string, string) {
+	if sender != nil {
+		for _, inst := range relatives(sender, instances) {
+			if inst.Title == ref {
+				return inst, "", ""
+			}
+		}
+	}
+	return ResolveSession(ref, instances)
+}
+
+// relatives returns a session's parent, siblings and sub-sessions
+func relatives(inst *session.Instance, instances []*session.Instance) []*session.Instance {
+	var related []*session.Instance
+	for _, other := range instances {
+		switch {
+		case other.ID == inst.ID:
+		case other.ID == inst.ParentSessionID,
+			other.ParentSessionID == inst.ID,
+			inst.ParentSessionID != "" && other.ParentSessionID == inst.ParentSessionID:
+			related = append(related, other)
+		}
+	}
+	return related
+}
+
+// sendMailMessage stores a message and injects it right away if the
+// recipient is waiting. Returns the delivery state.
+func sendMailMessage(profile string, from, to *session.Instance, body string, inject bool) (*mailbox.Message, string, error) {
+	if from != nil && from.ID == to.ID {
+		return nil, "", fmt.Errorf("can't send a message to the sending session")
+	}
+	store, err := mailbox.NewStore(profile)
+	if err != nil {
+		return nil, "", err
+	}
+	// Plain shells only get messages in their mailbox
+	inject = inject && to.Tool != "shell"
+	msg := &mailbox.Message{To: to.ID, ToTitle: to.Title, Body: body, Inject: inject}
+	if from != nil {
+		msg.From, msg.FromTitle = from.ID, from.Title
+	}
+	if err := store.Send(msg); err != nil {
+		return nil, "", err
+	}
+	if !inject {
+		return msg, msgMailbox, nil
+	}
+
+	tmux.RefreshExistingSessions()
+	_ = to.UpdateStatus()
+	if !mailbox.Ready(to) {
+		return msg, msgQueued, nil
+	}
+	delivered, err := mailbox.DeliverReady(store, session.Unlocked(func(id string) *session.Instance {
+		if id == to.ID {
+			return to
+		}
+		return nil
+	}))
+	if err != nil || delivered == 0 {
+		return msg, msgQueued, nil
+	}
+	return msg, msgDelivered, nil
+}
+
+// handleMsgSend sends a message to a session's mailbox
+func handleMsgSend(profile string, args []string) {
+	fs := flag.NewFlagSet("msg send", flag.ExitOnError)
+	to := fs.String("to", "", "Recipient session (title, ID prefix or path)")
+	from := fs.String("from", "", "Sending session (default: current session)")
+	noInject := fs.Bool("no-inject", false, "Only put the message in the mailbox; don't type it into the agent")
+	file := fs.String("file", "", "Read the message from a file (\"-\" for stdin)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck msg send --to <id> [options] [message]")
+		fmt.Println()
+		fmt.Println("Send a message to another session. It is typed into the recipient's")
+		fmt.Println("agent now if it is waiting, or as soon as it is (while agent-deck or")
+		fmt.Println("its daemon is running), and kept in its mailbox until then.")
+		fmt.Println("The message comes from the arguments, --file, or stdin when piped.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet)
+
+	if *to == "" {
+		out.Fail("usage: agent-deck msg send --to <id> <message>", ErrCodeInvalidOperation)
+	}
+	body, err := readSendMessage(*file, fs.Args(), true)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	if strings.TrimSpace(body) == "" {
+		out.Fail("message is empty (pass text, --file or stdin)", ErrCodeInvalidOperation)
+	}
+
+	profile, instances, sender, err := msgSessions(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+	if *from != "" {
+		var errMsg, errCode string
+		if sender, errMsg, errCode = ResolveSession(*from, instances); sender == nil {
+			out.Fail(errMsg, errCode)
+		}
+	}
+	recipient, errMsg, errCode := resolveRecipient(*to, sender, instances)
+	if recipient == nil {
+		out.Fail(errMsg, errCode)
+	}
+
+	msg, state, err := sendMailMessage(profile, sender, recipient, body, !*noInject)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	var human string
+	switch state {
+	case msgDelivered:
+		human = fmt.Sprintf("Sent message to '%s' (delivered)", recipient.Title)
+	case msgQueued:
+		human = fmt.Sprintf("Sent message to '%s' (delivered once it is waiting)", recipient.Title)
+	default:
+		human = fmt.Sprintf("Sent message to '%s' (in its mailbox)", recipient.Title)
+	}
+	out.Success(human, MsgSendJSON{
+		Success:   true,
+		ID:        msg.ID,
+		From:      msg.From,
+		FromTitle: msg.FromTitle,
+		To:        recipient.ID,
+		ToTitle:   recipient.Title,
+		Status:    state,
+	})
+}
+
+// handleMsgRead shows a session's unread messages and marks them read
+func handleMsgRead(profile string, args []string) {
+	fs := flag.NewFlagSet("msg read", flag.ExitOnError)
+	all := fs.Bool("all", false, "Show every message in the mailbox, read or not (marks nothing)")
+	peek := fs.Bool("peek", false, "Don't mark the messages read")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck msg read [options] [id]")
+		fmt.Println()
+		fmt.Println("Show a session's unread messages (default: current session) and mark")
+		fmt.Println("them read.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet)
+
+	profile, instances, current, err := msgSessions(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+	inst := current
+	if fs.NArg() > 0 || inst == nil {
+		var errMsg, errCode string
+		if inst, errMsg, errCode = ResolveSessionOrCurrent(fs.Arg(0), instances); inst == nil {
+			out.Fail(errMsg, errCode)
+		}
+	}
+
+	store, err := mailbox.NewStore(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	var msgs []*mailbox.Message
+	if *all {
+		msgs, err = store.Inbox(inst.ID)
+	} else {
+		msgs, err = store.TakeUnread(inst.ID, *peek)
+	}
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	if msgs == nil {
+		msgs = []*mailbox.Message{}
+	}
+
+	var sb strings.Builder
+	if len(msgs) == 0 {
+		fmt.Fprintf(&sb, "No unread messages for '%s'\n", inst.Title)
+	}
+	for _, m := range msgs {
+		fmt.Fprintf(&sb, "From %s · %s", m.Sender(), m.SentAt.Local().Format("2006-01-02 15:04"))
+		if !m.DeliveredAt.IsZero() {
+			sb.WriteString(" · delivered")
+		}
+		fmt.Fprintf(&sb, "\n%s\n\n", m.Body)
+	}
+	out.Print(sb.String(), MsgReadJSON{
+		SessionID:    inst.ID,
+		SessionTitle: inst.Title,
+		Messages:     msgs,
+	})
+}
+
+// handleMsgMCP serves messaging tools to the agent of the current session
+// over MCP, so agents can message their parent or siblings by title
+func handleMsgMCP(profile string, args []string) {
+	fs := flag.NewFlagSet("msg mcp", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck msg mcp")
+		fmt.Println()
+		fmt.Println("Serve send_message, read_messages and list_sessions tools over the MCP")
+		fmt.Println("stdio transport, for the agent of the session it runs in. Add it to")
+		fmt.Println("config.toml and attach it to sessions like any other MCP:")
+		fmt.Println()
+		fmt.Println("  [mcps.agent-deck]")
+		fmt.Println("  command = \"agent-deck\"")
+		fmt.Println("  args = [\"msg\", \"mcp\"]")
+	}
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	// stdout is the MCP transport: errors go to stderr
+	out := NewCLIOutput(false, false)
+	profile, _, current, err := msgSessions(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+	var selfID string
+	if current != nil {
+		selfID = current.ID
+	}
+
+	// Sessions are reloaded on every call: they change while the agent runs
+	load := func() ([]*session.Instance, *session.Instance, error) {
+		_, instances, _, err := loadSessionData(profile)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, inst := range instances {
+			if inst.ID == selfID {
+				return instances, inst, nil
+			}
+		}
+		return instances, nil, nil
+	}
+
+	tools := []mailbox.Tool{
+		{
+			Name: "send_message",
+			Description: "Send a message to another agent-deck session, usually your parent, a sibling or a sub-session, by title. " +
+				"It is typed into that session's agent once it is waiting for input.",
+			Params: map[string]string{
+				"to":      "Title of the session to message",
+				"message": "The message",
+			},
+			Required: []string{"to", "message"},
+			Call: func(args map[string]string) (string, error) {
+				instances, self, err := load()
+				if err != nil {
+					return "", err
+				}
+				to, errMsg, _ := resolveRecipient(args["to"], self, instances)
+				if to == nil {
+					return "", fmt.Errorf("%s", errMsg)
+				}
+				_, state, err := sendMailMessage(profile, self, to, args["message"], true)
+				if err != nil {
+					return "", err
+				}
+				switch state {
+				case msgDelivered:
+					return fmt.Sprintf("Message delivered to '%s'.", to.Title), nil
+				case msgMailbox:
+					return fmt.Sprintf("Message left in the mailbox of '%s'.", to.Title), nil
+				}
+				return fmt.Sprintf("Message sent to '%s'; it will be delivered when its agent is waiting.", to.Title), nil
+			},
+		},
+		{
+			Name:        "read_messages",
+			Description: "Read the unread messages other agent-deck sessions sent you (marks them read).",
+			Call: func(map[string]string) (string, error) {
+				if selfID == "" {
+					return "", fmt.Errorf("not running inside an agent-deck session")
+				}
+				store, err := mailbox.NewStore(profile)
+				if err != nil {
+					return "", err
+				}
+				msgs, err := store.TakeUnread(selfID, false)
+				if err != nil {
+					return "", err
+				}
+				if len(msgs) == 0 {
+					return "No unread messages.", nil
+				}
+				var sb strings.Builder
+				for _, m := range msgs {
+					fmt.Fprintf(&sb, "From %s at %s:\n%s\n\n", m.Sender(), m.SentAt.Local().Format("15:04"), m.Body)
+				}
+				return strings.TrimSpace(sb.String()), nil
+			},
+		},
+		{
+			Name:        "list_sessions",
+			Description: "List the agent-deck sessions you can message: your parent, siblings and sub-sessions, with their status.",
+			Call: func(map[string]string) (string, error) {
+				instances, self, err := load()
+				if err != nil {
+					return "", err
+				}
+				if self == nil {
+					return "", fmt.Errorf("not running inside an agent-deck session")
+				}
+				tmux.RefreshExistingSessions()
+				var sb strings.Builder
+				for _, inst := range relatives(self, instances) {
+					_ = inst.UpdateStatus()
+					role := "sibling"
+					switch {
+					case inst.ID == self.ParentSessionID:
+						role = "parent"
+					case inst.ParentSessionID == self.ID:
+						role = "sub-session"
+					}
+					fmt.Fprintf(&sb, "%s (%s, %s, %s)\n", inst.Title, role, inst.Tool, StatusString(inst.Status))
+				}
+				if sb.Len() == 0 {
+					return "No parent, siblings or sub-sessions.", nil
+				}
+				return strings.TrimSpace(sb.String()), nil
+			},
+		},
+	}
+
+	if err := mailbox.ServeMCP(os.Stdin, os.Stdout, Version, tools); err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+}
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
+	"github.com/asheshgoplani/agent-deck/internal/mailbox"
+	"github.com/asheshgoplani/agent-deck/internal/scheduler"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// Outcomes of an orchestrated task
+const (
+	taskRunning  = "running"
+	taskDone     = "done"
+	taskFailed   = "failed"
+	taskTimedOut = "timed_out"
+)
+
+// orchestratePollInterval is how often children's statuses are checked
+const orchestratePollInterval = 2 * time.Second
+
+// orchestrateSettle is how long a child that never showed as working must sit
+// idle after its prompt before it counts as done (e.g. it answered instantly)
+const orchestrateSettle = 30 * time.Second
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// orchestrateTask tracks one child session of an orchestrate run
+type orchestrateTask struct {
+	Title          string `json:"title"`
+	ID             string `json:"id,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+	ElapsedSeconds int    `json:"elapsed_seconds"`
+	Response       string `json:"response,omitempty"`
+	Cleanup        string `json:"cleanup,omitempty"` // What cleanup did: stopped or removed
+
+	prompt     string
+	inst       *session.Instance
+	sawRunning bool
+	finishedAt time.Time
+
+	mu        sync.Mutex
+	sentAt    time.Time // When the prompt was delivered (zero until then)
+	sendError error
+}
+
+// handleOrchestrate spawns child sessions of a parent from a task list, waits
+// for each to finish, sends the parent an aggregated report and cleans up
+func handleOrchestrate(profile string, args []string) {
+	fs := flag.NewFlagSet("orchestrate", flag.ExitOnError)
+	parentRef := fs.String("parent", "", "Parent session (default: current session)")
+	var prompts stringList
+	fs.Var(&prompts, "task", "Task prompt (repeatable; instead of a task file)")
+	template := fs.String("template", "", "Template for tasks that don't set one")
+	cleanup := fs.String("cleanup", "", "What to do with children afterwards: keep, stop, remove, remove-on-success")
+	report := fs.String("report", "", "Write the report to a file (\"-\" for stdout) instead of sending it to the parent")
+	timeout := fs.Duration("timeout", 0, "Give up on unfinished tasks after this long (default 1h)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck orchestrate [options] [tasks.toml]")
+		fmt.Println()
+		fmt.Println("Run tasks in child sessions of a parent and collect a report.")
+		fmt.Println("Each task gets its own session (template, worktree, MCPs) under the")
+		fmt.Println("parent. Once every child has finished, their last responses are sent")
+		fmt.Println("to the parent as one report, and the children are cleaned up.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Task file:")
+		fmt.Println("  template = \"reviewer\"              # Defaults for every task")
+		fmt.Println("  cleanup  = \"remove-on-success\"")
+		fmt.Println("  timeout  = \"30m\"")
+		fmt.Println()
+		fmt.Println("  [[tasks]]")
+		fmt.Println("  title    = \"api-tests\"")
+		fmt.Println("  message  = \"Fix the failing API tests\"")
+		fmt.Println("  worktree = \"fix/api-tests\"")
+		fmt.Println("  mcps     = [\"github\"]")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck orchestrate tasks.toml")
+		fmt.Println("  agent-deck orchestrate --task \"Research X\" --task \"Research Y\" --cleanup remove")
+		fmt.Println("  agent-deck orchestrate --parent lead --report report.md tasks.toml")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet)
+
+	var o *session.Orchestration
+	switch {
+	case fs.NArg() > 0 && len(prompts) > 0:
+		out.Fail("pass a task file or --task, not both", ErrCodeInvalidOperation)
+	case fs.NArg() > 0:
+		var err error
+		if o, err = session.LoadOrchestration(fs.Arg(0)); err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+	case len(prompts) > 0:
+		o = &session.Orchestration{}
+		for _, p := range prompts {
+			o.Tasks = append(o.Tasks, session.ManifestSession{Message: p})
+		}
+	default:
+		out.Fail("usage: agent-deck orchestrate [options] <tasks.toml> (or --task)", ErrCodeInvalidOperation)
+	}
+	// Flags override the task file
+	if *template != "" {
+		o.Template = *template
+	}
+	if *cleanup != "" {
+		o.Cleanup = *cleanup
+	}
+	if *report != "" {
+		o.Report = *report
+	}
+	if *timeout > 0 {
+		o.Timeout = timeout.String()
+	}
+
+	storage, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+	parent, errMsg, errCode := ResolveSessionOrCurrent(*parentRef, instances)
+	if parent == nil {
+		out.Fail(errMsg, errCode)
+	}
+
+	cwd, _ := os.Getwd()
+	if err := o.Prepare(cwd, parent); err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	availableMCPs := session.GetAvailableMCPs()
+	for _, t := range o.Tasks {
+		for _, name := range t.MCPs {
+			if _, ok := availableMCPs[name]; !ok {
+				out.Fail(fmt.Sprintf("task '%s': MCP '%s' not found in config.toml", t.Title, name), ErrCodeMCPNotAvailable)
+			}
+		}
+		for _, inst := range instances {
+			if inst.Title == t.Title && inst.ParentSessionID == parent.ID {
+				out.Fail(fmt.Sprintf("'%s' already has a child session '%s'", parent.Title, t.Title), ErrCodeAlreadyExists)
+			}
+		}
+	}
+
+	// Create the children, save, then start them. All or nothing: a rerun
+	// after a failure would otherwise stop at the children already saved.
+	manifest := o.Manifest()
+	byTitle := map[string]*session.Instance{parent.Title: parent}
+	tasks := make([]*orchestrateTask, len(o.Tasks))
+	for idx, t := range o.Tasks {
+		tasks[idx] = &orchestrateTask{Title: t.Title, prompt: t.Message}
+		inst, err := newManifestInstance(manifest, t, byTitle)
+		if err != nil {
+			discardTasks(tasks[:idx])
+			out.Fail(fmt.Sprintf("task '%s': %v", t.Title, err), ErrCodeInvalidOperation)
+		}
+		tasks[idx].inst = inst
+		tasks[idx].ID = inst.ID
+		instances = append(instances, inst)
+	}
+	groupTree := session.NewGroupTreeWithGroups(instances, groups)
+	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
+		discardTasks(tasks)
+		out.Fail(fmt.Sprintf("failed to save sessions: %v", err), ErrCodeInternal)
+	}
+	session.ApplyClaudeConfigDirs(instances, groupTree.ClaudeConfigDirs(), storage.Profile())
+
+	started := time.Now()
+	for _, t := range tasks {
+		t.Status = taskRunning
+		if err := t.inst.Start(); err != nil {
+			t.finish(taskFailed, fmt.Sprintf("failed to start: %v", err))
+		}
+		t.inst.InitialMessage = ""
+	}
+	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
+		out.Fail(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInternal)
+	}
+	progress(out, "Started %d task(s) for '%s'\n", len(tasks), parent.Title)
+
+	// Deliver the prompts in parallel (each waits for its own agent)
+	for _, t := range tasks {
+		if t.Status != taskRunning {
+			continue
+		}
+		go func(t *orchestrateTask) {
+			err := t.inst.SendMessageWhenReady(t.prompt)
+			t.mu.Lock()
+			t.sentAt, t.sendError = time.Now(), err
+			t.mu.Unlock()
+		}(t)
+	}
+
+	timedOut := monitorTasks(out, tasks, started.Add(o.TimeoutDuration()))
+
+	reportText := orchestrationReport(parent, tasks, time.Since(started))
+	reportFile := deliverReport(out, profile, parent, o.Report, reportText)
+	cleanupTasks(out, profile, o.Cleanup, tasks)
+
+	var human strings.Builder
+	failed := 0
+	for _, t := range tasks {
+		switch t.Status {
+		case taskDone:
+			fmt.Fprintf(&human, "%s %s (%s)\n", successSymbol, t.Title, formatTaskElapsed(t))
+		case taskTimedOut:
+			failed++
+			fmt.Fprintf(&human, "%s %s: timed out\n", errorSymbol, t.Title)
+		default:
+			failed++
+			fmt.Fprintf(&human, "%s %s: %s\n", errorSymbol, t.Title, t.Error)
+		}
+	}
+	fmt.Fprintf(&human, "\n%d of %d task(s) done", len(tasks)-failed, len(tasks))
+	if reportFile != "" {
+		fmt.Fprintf(&human, ", report written to %s", reportFile)
+	}
+	human.WriteString("\n")
+
+	out.Print(human.String(), OrchestrateJSON{
+		Success:        failed == 0,
+		ParentID:       parent.ID,
+		ParentTitle:    parent.Title,
+		Report:         reportText,
+		ReportFile:     reportFile,
+		Cleanup:        o.Cleanup,
+		ElapsedSeconds: int(time.Since(started).Seconds()),
+		Tasks:          tasks,
+	})
+	if timedOut {
+		os.Exit(exitTimeout)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// progress prints a progress line to stderr (not in JSON or quiet mode)
+func progress(out *CLIOutput, format string, args ...interface{}) {
+	if out.jsonMode || out.quietMode {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// finish records a task's outcome
+func (t *orchestrateTask) finish(status, errMsg string) {
+	t.Status = status
+	t.Error = errMsg
+	t.finishedAt = time.Now()
+	t.ElapsedSeconds = int(t.finishedAt.Sub(t.inst.CreatedAt).Seconds())
+}
+
+// monitorTasks polls the children until every task has finished or deadline
+// passes. A child is done once it goes idle after working on its prompt; its
+// last response is collected then. Returns whether the deadline was hit.
+func monitorTasks(out *CLIOutput, tasks []*orchestrateTask, deadline time.Time) bool {
+	ticker := time.NewTicker(orchestratePollInterval)
+	defer ticker.Stop()
+
+	for {
+		tmux.RefreshExistingSessions()
+		pending := 0
+		for _, t := range tasks {
+			if t.Status != taskRunning {
+				continue
+			}
+			t.mu.Lock()
+			sentAt, sendErr := t.sentAt, t.sendError
+			t.mu.Unlock()
+
+			_ = t.inst.UpdateStatus()
+			switch {
+			case sendErr != nil:
+				t.finish(taskFailed, fmt.Sprintf("failed to send prompt: %v", sendErr))
+			case t.inst.Status == session.StatusExited || t.inst.Status == session.StatusError:
+				t.finish(taskFailed, fmt.Sprintf("session %s", t.inst.Status))
+			case t.inst.Status == session.StatusRunning:
+				t.sawRunning = !sentAt.IsZero() || t.sawRunning
+			case !sentAt.IsZero() && (t.sawRunning || time.Since(sentAt) > orchestrateSettle):
+				t.finish(taskDone, "")
+				t.collectResponse()
+			}
+
+			switch t.Status {
+			case taskRunning:
+				pending++
+			case taskDone:
+				progress(out, "%s %s done (%s)\n", successSymbol, t.Title, formatTaskElapsed(t))
+			default:
+				progress(out, "%s %s: %s\n", errorSymbol, t.Title, t.Error)
+			}
+		}
+		if pending == 0 {
+			return false
+		}
+		if time.Now().After(deadline) {
+			for _, t := range tasks {
+				if t.Status == taskRunning {
+					t.finish(taskTimedOut, "timed out")
+					t.collectResponse()
+				}
+			}
+			return true
+		}
+		<-ticker.C
+	}
+}
+
+// collectResponse stores the child's last response (partial for timeouts)
+func (t *orchestrateTask) collectResponse() {
+	resp, err := t.inst.GetLastResponse()
+	if err != nil {
+		if t.Status == taskDone {
+			t.Error = fmt.Sprintf("no response: %v", err)
+		}
+		return
+	}
+	t.Response = strings.TrimSpace(resp.Content)
+}
+
+// formatTaskElapsed formats how long a task took
+func formatTaskElapsed(t *orchestrateTask) string {
+	if t.finishedAt.IsZero() {
+		return "-"
+	}
+	return formatDuration(t.finishedAt.Sub(t.inst.CreatedAt))
+}
+
+// formatDuration formats d like "4m10s", to the second
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+// orchestrationReport builds the aggregated Markdown report for the parent
+func orchestrationReport(parent *session.Instance, tasks []*orchestrateTask, elapsed time.Duration) string {
+	done := 0
+	for _, t := range tasks {
+		if t.Status == taskDone {
+			done++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Orchestration report: %s\n\n", parent.Title)
+	fmt.Fprintf(&sb, "%d of %d task(s) done in %s\n", done, len(tasks), formatDuration(elapsed))
+	for _, t := range tasks {
+		symbol := successSymbol
+		if t.Status != taskDone {
+			symbol = errorSymbol
+		}
+		fmt.Fprintf(&sb, "\n## %s %s (%s", symbol, t.Title, strings.ReplaceAll(t.Status, "_", " "))
+		if t.Status == taskDone {
+			fmt.Fprintf(&sb, " in %s", formatTaskElapsed(t))
+		}
+		sb.WriteString(")\n\n")
+		for _, line := range strings.Split(t.prompt, "\n") {
+			fmt.Fprintf(&sb, "> %s\n", line)
+		}
+		sb.WriteString("\n")
+		switch {
+		case t.Response != "":
+			sb.WriteString(t.Response + "\n")
+		case t.Error != "":
+			fmt.Fprintf(&sb, "_%s_\n", t.Error)
+		default:
+			sb.WriteString("_No response_\n")
+		}
+	}
+	return sb.String()
+}
+
+// deliverReport writes the report to file, prints it ("-", or when running
+// inside the parent itself; in JSON mode it is part of the output instead) or
+// sends it to the parent. A written file is announced to the parent instead.
+// Returns the file written, if any.
+func deliverReport(out *CLIOutput, profile string, parent *session.Instance, file, report string) string {
+	inParent := GetCurrentSessionID() == parent.ID
+	message := report
+	switch {
+	case file == "-":
+		if !out.jsonMode {
+			fmt.Print(report)
+		}
+		return ""
+	case file != "":
+		if err := os.WriteFile(file, []byte(report), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write report: %v\n", err)
+			fmt.Print(report)
+			return ""
+		}
+		message = fmt.Sprintf("Orchestration finished. The report is in %s", file)
+	case inParent:
+		if !out.jsonMode {
+			fmt.Print(report)
+		}
+		return ""
+	}
+	if inParent {
+		return file
+	}
+
+	if !parent.Exists() {
+		fmt.Fprintf(os.Stderr, "Warning: parent '%s' is not running; report not sent\n", parent.Title)
+		if file == "" && !out.jsonMode {
+			fmt.Print(report)
+		}
+		return file
+	}
+	if err := sendToSession(daemonClient(profile), parent, daemon.SendRequest{Message: message}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to send report to '%s': %v\n", parent.Title, err)
+	}
+	return file
+}
+
+// discardTasks undoes the creation of children that were never saved: only
+// their git worktrees exist outside this process
+func discardTasks(tasks []*orchestrateTask) {
+	for _, t := range tasks {
+		if t.inst == nil || !t.inst.IsWorktree() {
+			continue
+		}
+		if err := t.inst.RemoveWorktree(true); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to delete worktree %s: %v\n", t.inst.WorktreePath, err)
+		}
+	}
+}
+
+// cleanupTasks applies the cleanup policy to the children
+func cleanupTasks(out *CLIOutput, profile, policy string, tasks []*orchestrateTask) {
+	if policy == "" || policy == session.CleanupKeep {
+		return
+	}
+
+	remove := make(map[string]bool)
+	for _, t := range tasks {
+		if t.inst == nil {
+			continue
+		}
+		if policy == session.CleanupRemove || (policy == session.CleanupRemoveOnSuccess && t.Status == taskDone) {
+			remove[t.ID] = true
+		} else if policy != session.CleanupStop {
+			continue
+		}
+		if t.inst.Exists() {
+			if err := t.inst.Kill(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to stop '%s': %v\n", t.Title, err)
+				continue
+			}
+		}
+		t.Cleanup = "stopped"
+	}
+	if len(remove) == 0 {
+		return
+	}
+
+	// Reload: sessions may have changed while the tasks ran
+	storage, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove children: %v\n", err)
+		return
+	}
+	kept := make([]*session.Instance, 0, len(instances))
+	for _, inst := range instances {
+		if !remove[inst.ID] {
+			kept = append(kept, inst)
+		}
+	}
+	if err := storage.SaveWithGroups(kept, session.NewGroupTreeWithGroups(kept, groups)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove children: %v\n", err)
+		return
+	}
+	store, storeErr := scheduler.NewStore(storage.Profile())
+	mail, mailErr := mailbox.NewStore(storage.Profile())
+	for _, t := range tasks {
+		if !remove[t.ID] {
+			continue
+		}
+		t.Cleanup = "removed"
+		if storeErr == nil {
+			_, _ = store.RemoveSession(t.ID)
+		}
+		if mailErr == nil {
+			_, _ = mail.RemoveSession(t.ID)
+		}
+		if err := t.inst.RemoveWorktree(false); err != nil {
+			progress(out, "Worktree of %s kept: %v\n", t.Title, err)
+		}
+	}
+}
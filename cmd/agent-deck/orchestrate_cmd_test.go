@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOrchestrateSavesNoPartialChildren checks that a task that can't be
+// created leaves no children of the tasks before it behind
+func TestOrchestrateSavesNoPartialChildren(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	if _, stderr, exit := runCLIInHome(t, home, "add", "--json", "-t", "lead", project); exit != 0 {
+		t.Fatalf("add: exit code %d: %s", exit, stderr)
+	}
+
+	tasks := filepath.Join(t.TempDir(), "tasks.toml")
+	manifest := "[[tasks]]\ntitle = \"ok\"\nmessage = \"hi\"\n\n" +
+		"[[tasks]]\ntitle = \"broken\"\nmessage = \"hi\"\npath = \"/nonexistent/path\"\n"
+	if err := os.WriteFile(tasks, []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, exit := runCLIInHome(t, home, "orchestrate", "--json", "--parent", "lead", tasks)
+	var failed ErrorJSON
+	if err := decodeStrict(stdout, &failed); err != nil || exit != 1 || failed.Error.Code != ErrCodeInvalidOperation {
+		t.Fatalf("orchestrate = exit %d, %q", exit, stdout)
+	}
+
+	stdout, _, _ = runCLIInHome(t, home, "list", "--json")
+	var sessions []SessionJSON
+	if err := decodeStrict(stdout, &sessions); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Title != "lead" {
+		t.Errorf("sessions after a failed orchestrate = %+v, want only the parent", sessions)
+	}
+}
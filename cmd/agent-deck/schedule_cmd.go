@@ -0,0 +1,292 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/scheduler"
+)
+
+// handleSchedule dispatches schedule subcommands
+func handleSchedule(profile string, args []string) {
+	if len(args) == 0 {
+		// Default to list
+		handleScheduleList(profile, nil)
+		return
+	}
+
+	switch args[0] {
+	case "add", "new":
+		handleScheduleAdd(profile, args[1:])
+	case "list", "ls":
+		handleScheduleList(profile, args[1:])
+	case "remove", "rm":
+		handleScheduleRemove(profile, args[1:])
+	case "help", "--help", "-h":
+		printScheduleHelp()
+	default:
+		fmt.Printf("Unknown schedule command: %s\n", args[0])
+		fmt.Println()
+		printScheduleHelp()
+		os.Exit(1)
+	}
+}
+
+// printScheduleHelp prints usage for schedule commands
+func printScheduleHelp() {
+	fmt.Println("Usage: agent-deck schedule <command> [options]")
+	fmt.Println()
+	fmt.Println("Send prompts to sessions on a schedule. Prompts are delivered like")
+	fmt.Println("'session send' while the TUI or 'agent-deck daemon' is running.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  add <id> <message>  Schedule a prompt (--cron, --in or --at)")
+	fmt.Println("  list                List scheduled prompts")
+	fmt.Println("  rm <job-id>         Remove a scheduled prompt")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck schedule add --cron \"0 9 * * 1-5\" my-app \"Summarize yesterday's commits\"")
+	fmt.Println("  agent-deck schedule add --cron @hourly --start my-app \"Run the test suite\"")
+	fmt.Println("  agent-deck schedule add --in 30m my-app \"Check if the build finished\"")
+	fmt.Println("  agent-deck schedule add --at 18:00 --restart my-app \"Write a status report\"")
+	fmt.Println("  agent-deck schedule list --json")
+	fmt.Println("  agent-deck schedule rm 3f2a")
+}
+
+// parseScheduleAt parses --at: "15:04" (today, or tomorrow if already past),
+// "2006-01-02 15:04" or RFC 3339
+func parseScheduleAt(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("15:04", value, time.Local); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' (use 15:04, \"2006-01-02 15:04\" or RFC 3339)", value)
+}
+
+// handleScheduleAdd schedules a prompt for a session
+func handleScheduleAdd(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule add", flag.ExitOnError)
+	cronExpr := fs.String("cron", "", "Cron expression for a recurring prompt (e.g. \"0 9 * * 1-5\", @hourly)")
+	in := fs.String("in", "", "Send once after a delay (e.g. 30m, 2h)")
+	at := fs.String("at", "", "Send once at a time (15:04 or \"2006-01-02 15:04\")")
+	start := fs.Bool("start", false, "Start the session first if it isn't running")
+	restart := fs.Bool("restart", false, "Restart the session (with resume) before sending")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck schedule add [options] <id|title> <message>")
+		fmt.Println()
+		fmt.Println("Schedule a prompt for a session. Use exactly one of --cron, --in or --at.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	remaining := fs.Args()
+
+	out := NewCLIOutput(*jsonOutput, *quiet)
+
+	if len(remaining) < 2 {
+		out.Fail("usage: agent-deck schedule add [--cron expr | --in duration | --at time] <id> <message>", ErrCodeInvalidOperation)
+	}
+
+	set := 0
+	for _, v := range []string{*cronExpr, *in, *at} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		out.Fail("use exactly one of --cron, --in or --at", ErrCodeInvalidOperation)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+
+	inst, errMsg, errCode := ResolveSession(remaining[0], instances)
+	if inst == nil {
+		out.Fail(errMsg, errCode)
+	}
+
+	job := &scheduler.Job{
+		SessionID: inst.ID,
+		Message:   strings.Join(remaining[1:], " "),
+		Cron:      *cronExpr,
+		Start:     *start,
+		Restart:   *restart,
+	}
+	now := time.Now()
+	switch {
+	case *in != "":
+		d, err := time.ParseDuration(*in)
+		if err != nil || d <= 0 {
+			out.Fail(fmt.Sprintf("invalid delay '%s' (use a duration like 30m or 2h)", *in), ErrCodeInvalidOperation)
+		}
+		job.At = now.Add(d)
+	case *at != "":
+		if job.At, err = parseScheduleAt(*at, now); err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+		if !job.At.After(now) {
+			out.Fail(fmt.Sprintf("time '%s' is in the past", *at), ErrCodeInvalidOperation)
+		}
+	}
+
+	store, err := scheduler.NewStore(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	if err := store.Add(job); err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	out.Success(fmt.Sprintf("Scheduled prompt %s for '%s' (%s, next %s)",
+		job.ID, inst.Title, job.Describe(), job.NextRun.Local().Format("2006-01-02 15:04")), ScheduleAddJSON{
+		Success:      true,
+		ID:           job.ID,
+		SessionID:    inst.ID,
+		SessionTitle: inst.Title,
+		NextRun:      job.NextRun.Format(time.RFC3339),
+	})
+}
+
+// handleScheduleList lists scheduled prompts
+func handleScheduleList(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	store, err := scheduler.NewStore(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	jobs, err := store.List()
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	titles := make(map[string]string)
+	if _, instances, _, err := loadSessionData(profile); err == nil {
+		for _, inst := range instances {
+			titles[inst.ID] = inst.Title
+		}
+	}
+
+	if *jsonOutput {
+		type jobJSON struct {
+			ID           string     `json:"id"`
+			SessionID    string     `json:"session_id"`
+			SessionTitle string     `json:"session_title,omitempty"`
+			Message      string     `json:"message"`
+			Cron         string     `json:"cron,omitempty"`
+			At           *time.Time `json:"at,omitempty"`
+			Start        bool       `json:"start"`
+			Restart      bool       `json:"restart"`
+			NextRun      *time.Time `json:"next_run,omitempty"`
+			LastRun      *time.Time `json:"last_run,omitempty"`
+			LastError    string     `json:"last_error,omitempty"`
+		}
+		optTime := func(t time.Time) *time.Time {
+			if t.IsZero() {
+				return nil
+			}
+			return &t
+		}
+		items := make([]jobJSON, 0, len(jobs))
+		for _, job := range jobs {
+			items = append(items, jobJSON{
+				ID:           job.ID,
+				SessionID:    job.SessionID,
+				SessionTitle: titles[job.SessionID],
+				Message:      job.Message,
+				Cron:         job.Cron,
+				At:           optTime(job.At),
+				Start:        job.Start,
+				Restart:      job.Restart,
+				NextRun:      optTime(job.NextRun),
+				LastRun:      optTime(job.LastRun),
+				LastError:    job.LastError,
+			})
+		}
+		out.Print("", items)
+		return
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("No scheduled prompts.")
+		return
+	}
+
+	fmt.Printf("%-10s %-20s %-18s %-17s %s\n", "ID", "SESSION", "SCHEDULE", "NEXT RUN", "MESSAGE")
+	for _, job := range jobs {
+		title := titles[job.SessionID]
+		if title == "" {
+			title = "(missing)"
+		}
+		schedule := job.Cron
+		if !job.Recurring() {
+			schedule = "once"
+		}
+		next := "done"
+		if !job.NextRun.IsZero() {
+			next = job.NextRun.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%-10s %-20s %-18s %-17s %s\n",
+			job.ID, truncate(title, 20), truncate(schedule, 18), next, truncate(strings.ReplaceAll(job.Message, "\n", " "), 40))
+		if job.LastError != "" {
+			fmt.Printf("           %s last run failed: %s\n", errorSymbol, job.LastError)
+		}
+	}
+}
+
+// handleScheduleRemove removes a scheduled prompt
+func handleScheduleRemove(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule rm", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet)
+
+	if fs.NArg() != 1 {
+		out.Fail("usage: agent-deck schedule rm <job-id>", ErrCodeInvalidOperation)
+	}
+
+	store, err := scheduler.NewStore(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	job, err := store.Remove(fs.Arg(0))
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeNotFound)
+	}
+
+	out.Success(fmt.Sprintf("Removed scheduled prompt %s", job.ID), ScheduleRemoveJSON{
+		Success: true,
+		ID:      job.ID,
+	})
+}
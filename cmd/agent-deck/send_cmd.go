@@ -0,0 +1,272 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleSessionSend sends a message (and/or keys) to one running session,
+// every session of a group, or all sessions.
+// Waits for each agent to be ready before sending (Claude, Gemini, etc.)
+func handleSessionSend(profile string, args []string) {
+	fs := flag.NewFlagSet("session send", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("q", false, "Quiet mode")
+	noWait := fs.Bool("no-wait", false, "Don't wait for agent to be ready (send immediately)")
+	file := fs.String("file", "", "Read the message from a file (\"-\" for stdin)")
+	keys := fs.String("keys", "", "tmux keys to press first, space separated (e.g. \"Escape C-c\")")
+	group := fs.String("group", "", "Send to every running session in this group (and its subgroups)")
+	all := fs.Bool("all", false, "Send to every running session")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session send [options] <id> [message]")
+		fmt.Println("       agent-deck session send [options] --group <path> [message]")
+		fmt.Println("       agent-deck session send [options] --all [message]")
+		fmt.Println()
+		fmt.Println("Send a message to running sessions once their agents are ready.")
+		fmt.Println("The message comes from the arguments, --file, or stdin when piped.")
+		fmt.Println("Multi-line messages are pasted as a whole (bracketed paste) and then")
+		fmt.Println("submitted; see [send.<tool>] in config.toml to change how per tool.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session send my-project \"Run the tests\"")
+		fmt.Println("  agent-deck session send --file prompt.md my-project")
+		fmt.Println("  git diff | agent-deck session send my-project")
+		fmt.Println("  agent-deck session send --keys \"Escape\" my-project          # Interrupt the agent")
+		fmt.Println("  agent-deck session send --keys \"C-c\" --no-wait my-shell")
+		fmt.Println("  agent-deck session send --group work/backend \"Pull and rebase on main\"")
+		fmt.Println("  agent-deck session send --all --json \"Summarize your progress\"")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	remaining := fs.Args()
+	out := NewCLIOutput(*jsonOutput, *quiet)
+
+	if *all && *group != "" {
+		out.Fail("use either --group or --all", ErrCodeInvalidOperation)
+	}
+	multi := *all || *group != ""
+	if !multi {
+		if len(remaining) == 0 {
+			out.Fail("usage: agent-deck session send <id> <message>", ErrCodeInvalidOperation)
+		}
+		remaining = remaining[1:]
+	}
+
+	keyNames := strings.Fields(*keys)
+	message, err := readSendMessage(*file, remaining, len(keyNames) == 0)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+	if message == "" && len(keyNames) == 0 {
+		out.Fail("message is empty (pass text, --file, stdin or --keys)", ErrCodeInvalidOperation)
+	}
+	req := daemon.SendRequest{Message: message, Keys: keyNames, NoWait: *noWait}
+
+	_, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+
+	if *all {
+		reportSendResults(out, message, sendToSessions(profile, instances, req))
+		return
+	}
+	if *group != "" {
+		sendToGroup(out, profile, *group, instances, groups, req)
+		return
+	}
+
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Fail(errMsg, errCode)
+	}
+
+	// Send keys and message (waits for the agent unless --no-wait)
+	if err := sendToSession(daemonClient(profile), inst, req); err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	human := fmt.Sprintf("Sent message to '%s'", inst.Title)
+	if message == "" {
+		human = fmt.Sprintf("Sent keys to '%s'", inst.Title)
+	}
+	if keyNames == nil {
+		keyNames = []string{}
+	}
+	out.Success(human, SendJSON{
+		Success:      true,
+		SessionID:    inst.ID,
+		SessionTitle: inst.Title,
+		Message:      message,
+		Keys:         keyNames,
+	})
+}
+
+// readSendMessage returns the message to send: from --file ("-" = stdin),
+// the arguments ("-" = stdin), or stdin when it is piped, there are no
+// arguments and autoStdin is set (no --keys). Trailing newlines are dropped
+// so the tool's submit keys decide when the message is sent.
+func readSendMessage(file string, args []string, autoStdin bool) (string, error) {
+	var data []byte
+	var err error
+	text := strings.Join(args, " ")
+	switch {
+	case file != "" && text != "":
+		return "", fmt.Errorf("pass the message as arguments or --file, not both")
+	case file == "-" || text == "-":
+		data, err = io.ReadAll(os.Stdin)
+	case file != "":
+		data, err = os.ReadFile(file)
+	case text == "" && autoStdin && stdinPiped():
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		return text, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read message: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// stdinPiped reports whether stdin is a pipe or file rather than a terminal
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// sendToGroup sends to the running sessions of a group and its subgroups
+// and reports the results
+func sendToGroup(out *CLIOutput, profile, group string, instances []*session.Instance, groups []*session.GroupData, req daemon.SendRequest) {
+	path := normalizeGroupPath(group)
+	tree := session.NewGroupTreeWithGroups(instances, groups)
+	if _, ok := tree.Groups[path]; !ok {
+		out.Fail(fmt.Sprintf("group '%s' not found", path), ErrCodeNotFound)
+	}
+	reportSendResults(out, req.Message, sendToSessions(profile, groupInstances(instances, path), req))
+}
+
+// groupInstances returns the sessions in a group and its subgroups
+func groupInstances(instances []*session.Instance, path string) []*session.Instance {
+	var matched []*session.Instance
+	for _, inst := range instances {
+		if inst.GroupPath == path || strings.HasPrefix(inst.GroupPath, path+"/") {
+			matched = append(matched, inst)
+		}
+	}
+	return matched
+}
+
+// sendToSession sends keys and then the message to one session, through the
+// daemon when one is running
+func sendToSession(client *daemon.Client, inst *session.Instance, req daemon.SendRequest) error {
+	if client != nil {
+		return client.Send(inst.ID, req)
+	}
+	if len(req.Keys) > 0 {
+		if err := inst.SendKeys(req.Keys); err != nil {
+			return err
+		}
+	}
+	if req.Message == "" {
+		return nil
+	}
+	return inst.Send(req.Message, req.NoWait)
+}
+
+// Outcomes of sending to one of several sessions
+const (
+	sendSent    = "sent"
+	sendFailed  = "failed"
+	sendSkipped = "skipped" // Not running
+)
+
+// sendResult is the outcome of sending to one session
+type sendResult struct {
+	SessionID    string `json:"session_id"`
+	SessionTitle string `json:"session_title"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// sendToSessions sends to every running session in targets at once, so each
+// agent's ready-wait runs in parallel. Sessions that aren't running are skipped.
+func sendToSessions(profile string, targets []*session.Instance, req daemon.SendRequest) []sendResult {
+	client := daemonClient(profile)
+	results := make([]sendResult, len(targets))
+	var wg sync.WaitGroup
+	for idx, inst := range targets {
+		results[idx] = sendResult{SessionID: inst.ID, SessionTitle: inst.Title}
+		if !inst.Exists() {
+			results[idx].Status = sendSkipped
+			results[idx].Error = "not running"
+			continue
+		}
+		wg.Add(1)
+		go func(r *sendResult, inst *session.Instance) {
+			defer wg.Done()
+			if err := sendToSession(client, inst, req); err != nil {
+				r.Status, r.Error = sendFailed, err.Error()
+				return
+			}
+			r.Status = sendSent
+		}(&results[idx], inst)
+	}
+	wg.Wait()
+	return results
+}
+
+// reportSendResults prints the per-session outcome of a multi-session send,
+// exiting 1 if any send failed
+func reportSendResults(out *CLIOutput, message string, results []sendResult) {
+	var sent, failed, skipped int
+	var sb strings.Builder
+	for _, r := range results {
+		switch r.Status {
+		case sendSent:
+			sent++
+			fmt.Fprintf(&sb, "%s %s\n", successSymbol, r.SessionTitle)
+		case sendFailed:
+			failed++
+			fmt.Fprintf(&sb, "%s %s: %s\n", errorSymbol, r.SessionTitle, r.Error)
+		default:
+			skipped++
+			fmt.Fprintf(&sb, "%s %s: skipped (%s)\n", bulletSymbol, r.SessionTitle, r.Error)
+		}
+	}
+	fmt.Fprintf(&sb, "\nSent to %d session(s)", sent)
+	if failed > 0 {
+		fmt.Fprintf(&sb, ", %d failed", failed)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&sb, ", %d not running", skipped)
+	}
+	sb.WriteString("\n")
+
+	if results == nil {
+		results = []sendResult{}
+	}
+	out.Print(sb.String(), SendResultsJSON{
+		Success: failed == 0,
+		Message: message,
+		Sent:    sent,
+		Failed:  failed,
+		Skipped: skipped,
+		Results: results,
+	})
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/profile"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/asheshgoplani/agent-deck/internal/tmux"
@@ -46,6 +51,12 @@ func handleSession(profile string, args []string) {
 		handleSessionSend(profile, args[1:])
 	case "output":
 		handleSessionOutput(profile, args[1:])
+	case "wait":
+		handleSessionWait(profile, args[1:])
+	case "transcript":
+		handleSessionTranscript(profile, args[1:])
+	case "diff":
+		handleSessionDiff(profile, args[1:])
 	case "help", "--help", "-h":
 		printSessionHelp()
 	default:
@@ -70,8 +81,11 @@ func printSessionHelp() {
 	fmt.Println("  show [id]               Show session details (auto-detect current if no id)")
 	fmt.Println("  current                 Show current session and profile (auto-detect)")
 	fmt.Println("  set <id> <field> <value>  Update session property")
-	fmt.Println("  send <id> <message>     Send a message to a running session")
+	fmt.Println("  send <id> [message]     Send a message or keys to running sessions")
 	fmt.Println("  output <id>             Get the last response from a session")
+	fmt.Println("  wait <id>...            Wait for sessions to reach a status")
+	fmt.Println("  transcript <id>         Export the conversation (md, json, html)")
+	fmt.Println("  diff <a> <b>            Compare two sessions' working trees (git)")
 	fmt.Println("  set-parent <id> <parent>  Link session as sub-session of parent")
 	fmt.Println("  unset-parent <id>       Remove sub-session link")
 	fmt.Println()
@@ -85,13 +99,20 @@ func printSessionHelp() {
 	fmt.Println("  agent-deck session stop abc123")
 	fmt.Println("  agent-deck session restart my-project")
 	fmt.Println("  agent-deck session fork my-project -t \"my-project-fork\"")
+	fmt.Println("  agent-deck session fork my-project --worktree --carry  # Fork code and conversation")
+	fmt.Println("  agent-deck session diff my-project my-project-fork")
 	fmt.Println("  agent-deck session attach my-project")
 	fmt.Println("  agent-deck session show                  # Auto-detect current session")
 	fmt.Println("  agent-deck session show my-project --json")
 	fmt.Println("  agent-deck session set-parent sub-task main-project  # Make sub-task a sub-session")
 	fmt.Println("  agent-deck session unset-parent sub-task             # Remove sub-session link")
+	fmt.Println("  agent-deck session send --file prompt.md my-project  # Paste a multi-line prompt")
+	fmt.Println("  agent-deck session send --group work \"Rebase on main\"  # Every running session in 'work'")
 	fmt.Println("  agent-deck session output my-project                 # Get last response from session")
 	fmt.Println("  agent-deck session output my-project --json          # Get response as JSON")
+	fmt.Println("  agent-deck session wait --timeout 10m task-1 task-2  # Block until both are waiting")
+	fmt.Println("  agent-deck session output -f my-project              # Stream new responses (JSONL)")
+	fmt.Println("  agent-deck session transcript --format html my-project > chat.html")
 	fmt.Println()
 	fmt.Println("Set command fields:")
 	fmt.Println("  title              Session title")
@@ -100,11 +121,20 @@ func printSessionHelp() {
 	fmt.Println("  tool               Tool type (claude, gemini, shell, etc.)")
 	fmt.Println("  claude-session-id  Claude conversation ID (for fork/resume)")
 	fmt.Println("  gemini-session-id  Gemini conversation ID (for resume)")
+	fmt.Println("  session-id         Codex/OpenCode conversation ID (for resume)")
+	fmt.Println("  env                Environment variable KEY=VALUE (empty value removes it)")
+	fmt.Println("  claude-config-dir  Claude account: [claude.accounts] name or config dir (\"\" = inherit)")
+	fmt.Println("  restart-policy     When the agent exits: never, on-failure, always (\"\" = [restart] default)")
+	fmt.Println("  restart-max-retries  Consecutive automatic restarts before giving up")
+	fmt.Println("  restart-backoff    Delay before the first automatic restart, doubled per retry")
 	fmt.Println()
 	fmt.Println("Set examples:")
 	fmt.Println("  agent-deck session set my-project title \"New Title\"")
 	fmt.Println("  agent-deck session set my-project claude-session-id \"abc123-def456\"")
 	fmt.Println("  agent-deck session set my-project tool claude")
+	fmt.Println("  agent-deck session set my-project env 'API_KEY=${MY_API_KEY}'")
+	fmt.Println("  agent-deck session set my-project claude-config-dir work")
+	fmt.Println("  agent-deck session set my-project restart-policy on-failure")
 }
 
 // handleSessionStart starts a session's tmux process
@@ -144,57 +174,51 @@ func handleSessionStart(profile string, args []string) {
 	// Load sessions
 	storage, instances, _, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(identifier, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Check if already running
 	if inst.Exists() {
-		out.Error(fmt.Sprintf("session '%s' is already running", inst.Title), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("session '%s' is already running", inst.Title), ErrCodeInvalidOperation)
 	}
 
-	// Start the session (with or without initial message)
-	if initialMessage != "" {
-		if err := inst.StartWithMessage(initialMessage); err != nil {
-			out.Error(fmt.Sprintf("failed to start session: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+	// Start the session (a pending message from its template is sent on first start)
+	var tmuxName string
+	if client := daemonClient(profile); client != nil {
+		if initialMessage == "" {
+			initialMessage = inst.InitialMessage
 		}
+		started, err := client.Start(inst.ID, initialMessage)
+		if err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+		tmuxName = started.TmuxSession
 	} else {
-		if err := inst.Start(); err != nil {
-			out.Error(fmt.Sprintf("failed to start session: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+		initialMessage, err = inst.StartPending(initialMessage)
+		if err != nil {
+			out.Fail(fmt.Sprintf("failed to start session: %v", err), ErrCodeInvalidOperation)
 		}
-	}
 
-	// Save updated state
-	if err := saveSessionData(storage, instances); err != nil {
-		out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		// Save updated state
+		if err := saveSessionData(storage, instances); err != nil {
+			out.Fail(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInternal)
+		}
+		if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
+			tmuxName = tmuxSess.Name
+		}
 	}
 
 	// Output success
-	jsonData := map[string]interface{}{
-		"success": true,
-		"id":      inst.ID,
-		"title":   inst.Title,
-	}
-	if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
-		jsonData["tmux"] = tmuxSess.Name
-	}
+	jsonData := SessionActionJSON{Success: true, ID: inst.ID, Title: inst.Title, Tmux: tmuxName}
 	if initialMessage != "" {
-		jsonData["message"] = initialMessage
-		jsonData["message_pending"] = true
+		jsonData.Message = initialMessage
+		jsonData.MessagePending = true
 		out.Success(fmt.Sprintf("Started session: %s (message will be sent when ready)", inst.Title), jsonData)
 	} else {
 		out.Success(fmt.Sprintf("Started session: %s", inst.Title), jsonData)
@@ -228,44 +252,38 @@ func handleSessionStop(profile string, args []string) {
 	// Load sessions
 	storage, instances, _, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(identifier, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Check if not running
 	if !inst.Exists() {
-		out.Error(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
 	}
 
 	// Stop the session by killing the tmux session
-	if err := inst.Kill(); err != nil {
-		out.Error(fmt.Sprintf("failed to stop session: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
+	if client := daemonClient(profile); client != nil {
+		if _, err := client.Stop(inst.ID); err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+	} else {
+		if err := inst.Kill(); err != nil {
+			out.Fail(fmt.Sprintf("failed to stop session: %v", err), ErrCodeInvalidOperation)
+		}
 
-	// Save updated state
-	if err := saveSessionData(storage, instances); err != nil {
-		out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		// Save updated state
+		if err := saveSessionData(storage, instances); err != nil {
+			out.Fail(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInternal)
+		}
 	}
 
 	// Output success
-	out.Success(fmt.Sprintf("Stopped session: %s", inst.Title), map[string]interface{}{
-		"success": true,
-		"id":      inst.ID,
-		"title":   inst.Title,
-	})
+	out.Success(fmt.Sprintf("Stopped session: %s", inst.Title), SessionActionJSON{Success: true, ID: inst.ID, Title: inst.Title})
 }
 
 // handleSessionRestart restarts a session
@@ -295,38 +313,33 @@ func handleSessionRestart(profile string, args []string) {
 	// Load sessions
 	storage, instances, _, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(identifier, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Restart the session
-	if err := inst.Restart(); err != nil {
-		out.Error(fmt.Sprintf("failed to restart session: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
+	if client := daemonClient(profile); client != nil {
+		if _, err := client.Restart(inst.ID); err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+	} else {
+		if err := inst.Restart(); err != nil {
+			out.Fail(fmt.Sprintf("failed to restart session: %v", err), ErrCodeInvalidOperation)
+		}
 
-	// Save updated state
-	if err := saveSessionData(storage, instances); err != nil {
-		out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		// Save updated state
+		if err := saveSessionData(storage, instances); err != nil {
+			out.Fail(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInternal)
+		}
 	}
 
 	// Output success
-	out.Success(fmt.Sprintf("Restarted session: %s", inst.Title), map[string]interface{}{
-		"success": true,
-		"id":      inst.ID,
-		"title":   inst.Title,
-	})
+	out.Success(fmt.Sprintf("Restarted session: %s", inst.Title), SessionActionJSON{Success: true, ID: inst.ID, Title: inst.Title})
 }
 
 // handleSessionFork forks a Claude session
@@ -339,6 +352,9 @@ func handleSessionFork(profile string, args []string) {
 	titleShort := fs.String("t", "", "Title for forked session (short)")
 	group := fs.String("group", "", "Group for forked session")
 	groupShort := fs.String("g", "", "Group for forked session (short)")
+	worktree := fs.Bool("worktree", false, "Fork into a new git worktree at the session's HEAD")
+	branch := fs.String("branch", "", "Branch for the worktree (implies --worktree; default: fork/<title>)")
+	carry := fs.Bool("carry", false, "With --worktree: copy uncommitted changes into the new worktree")
 
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck session fork <id|title> [options]")
@@ -352,6 +368,8 @@ func handleSessionFork(profile string, args []string) {
 		fmt.Println("  agent-deck session fork my-project")
 		fmt.Println("  agent-deck session fork my-project -t \"my-fork\"")
 		fmt.Println("  agent-deck session fork my-project -t \"my-fork\" -g \"experiments\"")
+		fmt.Println("  agent-deck session fork my-project --worktree --carry   # Separate checkout with current changes")
+		fmt.Println("  agent-deck session fork my-project --branch try-redis")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -369,30 +387,19 @@ func handleSessionFork(profile string, args []string) {
 	// Load sessions
 	storage, instances, groupsData, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(identifier, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
 	}
 
-	// Verify it's a Claude session
-	if inst.Tool != "claude" {
-		out.Error(fmt.Sprintf("session '%s' is not a Claude session (tool: %s)", inst.Title, inst.Tool), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
-	// Verify it can be forked
+	// Verify it can be forked (the tool must support forking and the
+	// conversation ID must be known)
 	if !inst.CanFork() {
-		out.Error(fmt.Sprintf("session '%s' cannot be forked: no active Claude session ID", inst.Title), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("session '%s' cannot be forked: no active session ID, or %s does not support forking", inst.Title, inst.Tool), ErrCodeInvalidOperation)
 	}
 
 	// Default title if not provided
@@ -406,16 +413,23 @@ func handleSessionFork(profile string, args []string) {
 	}
 
 	// Create the forked instance
-	forkedInst, _, err := inst.CreateForkedInstance(forkTitle, forkGroup)
+	useWorktree := *worktree || *branch != ""
+	if *carry && !useWorktree {
+		out.Fail("--carry requires --worktree or --branch", ErrCodeInvalidOperation)
+	}
+	var forkedInst *session.Instance
+	if useWorktree {
+		forkedInst, _, err = inst.CreateForkedInstanceInWorktree(forkTitle, forkGroup, *branch, *carry)
+	} else {
+		forkedInst, _, err = inst.CreateForkedInstance(forkTitle, forkGroup)
+	}
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to create fork: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to create fork: %v", err), ErrCodeInvalidOperation)
 	}
 
 	// Start the forked session
 	if err := forkedInst.Start(); err != nil {
-		out.Error(fmt.Sprintf("failed to start forked session: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to start forked session: %v", err), ErrCodeInvalidOperation)
 	}
 
 	// Add to instances
@@ -429,17 +443,23 @@ func handleSessionFork(profile string, args []string) {
 
 	// Save
 	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
-		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
 	// Output success
-	out.Success(fmt.Sprintf("Forked session: %s -> %s (%s)", inst.Title, forkedInst.Title, TruncateID(forkedInst.ID)), map[string]interface{}{
-		"success":   true,
-		"parent_id": inst.ID,
-		"new_id":    forkedInst.ID,
-		"new_title": forkedInst.Title,
-	})
+	msg := fmt.Sprintf("Forked session: %s -> %s (%s)", inst.Title, forkedInst.Title, TruncateID(forkedInst.ID))
+	data := ForkJSON{
+		Success:  true,
+		ParentID: inst.ID,
+		NewID:    forkedInst.ID,
+		NewTitle: forkedInst.Title,
+	}
+	if forkedInst.IsWorktree() {
+		msg += fmt.Sprintf("\n  Worktree: %s (branch %s)", forkedInst.WorktreePath, forkedInst.WorktreeBranch)
+		data.WorktreePath = forkedInst.WorktreePath
+		data.Branch = forkedInst.WorktreeBranch
+	}
+	out.Success(msg, data)
 }
 
 // handleSessionAttach attaches to a session interactively
@@ -458,43 +478,36 @@ func handleSessionAttach(profile string, args []string) {
 	}
 
 	identifier := fs.Arg(0)
+	out := NewCLIOutput(false, false)
 
 	// Load sessions
 	_, instances, _, err := loadSessionData(profile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve session (allow current session detection)
 	inst, errMsg, errCode := ResolveSessionOrCurrent(identifier, instances)
 	if inst == nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", errMsg)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Check if session exists
 	if !inst.Exists() {
-		fmt.Fprintf(os.Stderr, "Error: session '%s' is not running\n", inst.Title)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
 	}
 
 	// Attach to the session
 	tmuxSession := inst.GetTmuxSession()
 	if tmuxSession == nil {
-		fmt.Fprintf(os.Stderr, "Error: no tmux session for '%s'\n", inst.Title)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("no tmux session for '%s'", inst.Title), ErrCodeInvalidOperation)
 	}
 
 	// Create context for attach
 	ctx := context.Background()
 
 	if err := tmuxSession.Attach(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to attach: %v\n", err)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to attach: %v", err), ErrCodeInternal)
 	}
 }
 
@@ -525,8 +538,7 @@ func handleSessionShow(profile string, args []string) {
 	// Load sessions
 	_, instances, _, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve session (allow current session detection)
@@ -552,57 +564,101 @@ func handleSessionShow(profile string, args []string) {
 				return
 			}
 		} else {
-			out.Error(errMsg, errCode)
-			if errCode == ErrCodeNotFound {
-				os.Exit(2)
-			}
-			os.Exit(1)
+			out.Fail(errMsg, errCode)
 		}
 	}
 
-	// Update status
-	_ = inst.UpdateStatus()
-
-	// Get MCP info if Claude session
-	var mcpInfo *session.MCPInfo
-	if inst.Tool == "claude" {
-		mcpInfo = inst.GetMCPInfo()
+	// Update status (a running daemon already tracks it)
+	if !applyDaemonStatuses(profile, []*session.Instance{inst}) {
+		_ = inst.UpdateStatus()
 	}
 
+	// Get MCP info (nil for tools without MCP support)
+	mcpInfo := inst.GetMCPInfo()
+
 	// Prepare JSON output
-	jsonData := map[string]interface{}{
-		"id":         inst.ID,
-		"title":      inst.Title,
-		"profile":    profile,
-		"status":     StatusString(inst.Status),
-		"path":       inst.ProjectPath,
-		"group":      inst.GroupPath,
-		"tool":       inst.Tool,
-		"created_at": inst.CreatedAt.Format(time.RFC3339),
+	jsonData := SessionDetailJSON{
+		SessionJSON:   newSessionJSON(inst, session.GetEffectiveProfile(profile)),
+		MCPConfig:     inst.MCPConfigPath(),
+		RestartPolicy: inst.GetRestartPolicy(),
 	}
 
-	if inst.Command != "" {
-		jsonData["command"] = inst.Command
+	if inst.Tool == "claude" || session.GetToolAdapter(inst.Tool) != nil {
+		canFork, canRestart := inst.CanFork(), inst.CanRestart()
+		jsonData.CanFork, jsonData.CanRestart = &canFork, &canRestart
+		if inst.Tool == "claude" {
+			jsonData.ClaudeSessionID = inst.ClaudeSessionID
+		} else {
+			jsonData.AgentSessionID = inst.AgentSessionID()
+		}
+	}
+
+	if mcpInfo != nil && mcpInfo.HasAny() {
+		jsonData.MCPs = &MCPScopesJSON{
+			Local:   mcpInfo.Local(),
+			Global:  mcpInfo.Global,
+			Project: mcpInfo.Project,
+		}
 	}
 
+	if inst.IsRemote() {
+		jsonData.Host = inst.Host
+	}
+
+	if inst.HasLayout() {
+		jsonData.Layout = inst.Layout
+		jsonData.LayoutPanes = inst.LayoutPanes
+	}
+
+	jsonData.Env = inst.Env
+
 	if inst.Tool == "claude" {
-		jsonData["claude_session_id"] = inst.ClaudeSessionID
-		jsonData["can_fork"] = inst.CanFork()
-		jsonData["can_restart"] = inst.CanRestart()
-
-		if mcpInfo != nil && mcpInfo.HasAny() {
-			jsonData["mcps"] = map[string]interface{}{
-				"local":   mcpInfo.Local,
-				"global":  mcpInfo.Global,
-				"project": mcpInfo.Project,
-			}
+		jsonData.ClaudeConfigDir = inst.GetClaudeConfigDir()
+		jsonData.ClaudeAccount = inst.ClaudeAccount()
+	}
+
+	if inst.Status == session.StatusExited {
+		if code, known, _ := inst.LastExit(); known {
+			jsonData.ExitStatus = &code
+		}
+	}
+
+	if inst.IsWorktree() {
+		jsonData.WorktreePath = inst.WorktreePath
+		jsonData.WorktreeRepo = inst.WorktreeRepo
+	}
+
+	// Fork links in both directions
+	var forkedFrom *session.Instance
+	var forks []*session.Instance
+	for _, other := range instances {
+		if inst.ForkedFromID != "" && other.ID == inst.ForkedFromID {
+			forkedFrom = other
+		}
+		if other.ForkedFromID == inst.ID {
+			forks = append(forks, other)
+		}
+	}
+	jsonData.ForkedFromID = inst.ForkedFromID
+	for _, f := range forks {
+		jsonData.ForkIDs = append(jsonData.ForkIDs, f.ID)
+	}
+
+	// Git state of the project (local git repositories only)
+	gitStatus, gitErr := sessionGitStatus(inst)
+	if gitErr == nil {
+		jsonData.Git = &GitJSON{
+			Branch:   gitStatus.Branch,
+			Dirty:    gitStatus.Dirty,
+			Unmerged: gitStatus.Unmerged,
 		}
 	}
 
 	if inst.Exists() {
 		tmuxSession := inst.GetTmuxSession()
 		if tmuxSession != nil {
-			jsonData["tmux_session"] = tmuxSession.Name
+			jsonData.TmuxSession = tmuxSession.Name
+			jsonData.AgentPane = tmuxSession.AgentPane()
 		}
 	}
 
@@ -615,6 +671,25 @@ func handleSessionShow(profile string, args []string) {
 	sb.WriteString(fmt.Sprintf("Status:  %s %s\n", StatusSymbol(inst.Status), StatusString(inst.Status)))
 	sb.WriteString(fmt.Sprintf("Path:    %s\n", FormatPath(inst.ProjectPath)))
 
+	if inst.IsRemote() {
+		sb.WriteString(fmt.Sprintf("Host:    %s\n", inst.Host))
+	}
+
+	if gitErr == nil {
+		sb.WriteString(fmt.Sprintf("Branch:  %s\n", describeGitStatus(gitStatus)))
+	}
+	if inst.IsWorktree() {
+		sb.WriteString(fmt.Sprintf("Repo:    %s (worktree)\n", FormatPath(inst.WorktreeRepo)))
+	}
+	if forkedFrom != nil {
+		sb.WriteString(fmt.Sprintf("Fork of: %s (%s)\n", forkedFrom.Title, TruncateID(forkedFrom.ID)))
+	} else if inst.ForkedFromID != "" {
+		sb.WriteString(fmt.Sprintf("Fork of: %s (removed)\n", TruncateID(inst.ForkedFromID)))
+	}
+	for _, f := range forks {
+		sb.WriteString(fmt.Sprintf("Fork:    %s (%s)\n", f.Title, TruncateID(f.ID)))
+	}
+
 	if inst.GroupPath != "" {
 		sb.WriteString(fmt.Sprintf("Group:   %s\n", inst.GroupPath))
 	}
@@ -639,19 +714,48 @@ func handleSessionShow(profile string, args []string) {
 		} else {
 			sb.WriteString("Claude:  no session ID detected\n")
 		}
+	} else if session.GetToolAdapter(inst.Tool) != nil {
+		if id := inst.AgentSessionID(); id != "" {
+			sb.WriteString(fmt.Sprintf("Session: %s (resumed on restart)\n", id))
+		} else {
+			sb.WriteString("Session: no session ID detected\n")
+		}
+	}
 
-		if mcpInfo != nil && mcpInfo.HasAny() {
-			var mcpParts []string
-			for _, name := range mcpInfo.Local() {
-				mcpParts = append(mcpParts, name+" (local)")
-			}
-			for _, name := range mcpInfo.Global {
-				mcpParts = append(mcpParts, name+" (global)")
-			}
-			for _, name := range mcpInfo.Project {
-				mcpParts = append(mcpParts, name+" (project)")
-			}
-			sb.WriteString(fmt.Sprintf("MCPs:    %s\n", strings.Join(mcpParts, ", ")))
+	if mcpInfo != nil && mcpInfo.HasAny() {
+		var mcpParts []string
+		for _, name := range mcpInfo.Local() {
+			mcpParts = append(mcpParts, name+" (local)")
+		}
+		for _, name := range mcpInfo.Global {
+			mcpParts = append(mcpParts, name+" (global)")
+		}
+		for _, name := range mcpInfo.Project {
+			mcpParts = append(mcpParts, name+" (project)")
+		}
+		sb.WriteString(fmt.Sprintf("MCPs:    %s\n", strings.Join(mcpParts, ", ")))
+	}
+
+	if inst.HasLayout() {
+		sb.WriteString(fmt.Sprintf("Layout:  %s\n", describeLayout(inst)))
+	}
+
+	if len(inst.Env) > 0 {
+		sb.WriteString(fmt.Sprintf("Env:     %s\n", strings.Join(session.FormatEnv(inst.Env), " ")))
+	}
+
+	if inst.Tool == "claude" {
+		sb.WriteString(fmt.Sprintf("Account: %s (%s)\n", inst.ClaudeAccount(), FormatPath(inst.GetClaudeConfigDir())))
+	}
+
+	if policy := inst.GetRestartPolicy(); policy.When != "" && policy.When != session.RestartNever {
+		sb.WriteString(fmt.Sprintf("Restart: %s\n", policy))
+	}
+	if inst.Status == session.StatusExited {
+		if code, known, _ := inst.LastExit(); known {
+			sb.WriteString(fmt.Sprintf("Exit:    status %d\n", code))
+		} else {
+			sb.WriteString("Exit:    status unknown\n")
 		}
 	}
 
@@ -690,6 +794,17 @@ func handleSessionSet(profile string, args []string) {
 		fmt.Println("  tool               Tool type (claude, gemini, shell, etc.)")
 		fmt.Println("  claude-session-id  Claude conversation ID")
 		fmt.Println("  gemini-session-id  Gemini conversation ID")
+		fmt.Println("  session-id         Conversation ID for other tools (codex, opencode)")
+		fmt.Println("  env                Environment variable as KEY=VALUE; KEY= removes it.")
+		fmt.Println("                     ${VAR} and ${VAR:-default} are resolved from agent-deck's")
+		fmt.Println("                     environment at start/restart, so secrets stay out of sessions.json")
+		fmt.Println("  claude-config-dir  Claude account: a [claude.accounts] name or config directory.")
+		fmt.Println("                     \"\" inherits from the group or profile")
+		fmt.Println("  restart-policy     What happens when the agent exits: never, on-failure or always.")
+		fmt.Println("                     \"\" uses the [restart] default from config.toml")
+		fmt.Println("  restart-max-retries  Consecutive automatic restarts before giving up (default 3)")
+		fmt.Println("  restart-backoff    Delay before the first automatic restart, doubled for each")
+		fmt.Println("                     following one (default 5s)")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -698,6 +813,11 @@ func handleSessionSet(profile string, args []string) {
 		fmt.Println("  agent-deck session set my-project title \"New Title\"")
 		fmt.Println("  agent-deck session set my-project claude-session-id \"abc123-def456\"")
 		fmt.Println("  agent-deck session set my-project path /new/path/to/project")
+		fmt.Println("  agent-deck session set my-project env 'API_KEY=${MY_API_KEY}'")
+		fmt.Println("  agent-deck session set my-project env DEBUG=")
+		fmt.Println("  agent-deck session set my-project claude-config-dir ~/.claude-work")
+		fmt.Println("  agent-deck session set my-project restart-policy always")
+		fmt.Println("  agent-deck session set my-project restart-backoff 30s")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -717,34 +837,48 @@ func handleSessionSet(profile string, args []string) {
 
 	// Validate field name
 	validFields := map[string]bool{
-		"title":              true,
-		"path":               true,
-		"command":            true,
-		"tool":               true,
-		"claude-session-id":  true,
-		"gemini-session-id":  true,
+		"title":               true,
+		"path":                true,
+		"command":             true,
+		"tool":                true,
+		"claude-session-id":   true,
+		"gemini-session-id":   true,
+		"session-id":          true,
+		"env":                 true,
+		"claude-config-dir":   true,
+		"restart-policy":      true,
+		"restart-max-retries": true,
+		"restart-backoff":     true,
 	}
 
 	if !validFields[field] {
-		out.Error(fmt.Sprintf("invalid field: %s\nValid fields: title, path, command, tool, claude-session-id, gemini-session-id", field), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("invalid field: %s\nValid fields: title, path, command, tool, claude-session-id, gemini-session-id, session-id, env, claude-config-dir, restart-policy, restart-max-retries, restart-backoff", field), ErrCodeInvalidOperation)
+	}
+
+	if field == "claude-config-dir" {
+		if err := session.ValidateClaudeAccount(value); err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+	}
+
+	var envKey, envValue string
+	if field == "env" {
+		var err error
+		if envKey, envValue, err = session.ParseEnvAssignment(value); err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
 	}
 
 	// Load sessions
 	storage, instances, groupsData, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(identifier, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Store old value for output
@@ -770,7 +904,7 @@ func handleSessionSet(profile string, args []string) {
 		inst.ClaudeDetectedAt = time.Now()
 		// Also update tmux environment if session is running
 		if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil && tmuxSess.Exists() {
-			_ = exec.Command("tmux", "set-environment", "-t", tmuxSess.Name, "CLAUDE_SESSION_ID", value).Run()
+			_ = tmuxSess.SetEnvironment("CLAUDE_SESSION_ID", value)
 		}
 	case "gemini-session-id":
 		oldValue = inst.GeminiSessionID
@@ -778,25 +912,64 @@ func handleSessionSet(profile string, args []string) {
 		inst.GeminiDetectedAt = time.Now()
 		// Also update tmux environment if session is running
 		if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil && tmuxSess.Exists() {
-			_ = exec.Command("tmux", "set-environment", "-t", tmuxSess.Name, "GEMINI_SESSION_ID", value).Run()
+			_ = tmuxSess.SetEnvironment("GEMINI_SESSION_ID", value)
+		}
+	case "session-id":
+		oldValue = inst.ToolSessionID
+		inst.ToolSessionID = value
+		inst.ToolDetectedAt = time.Now()
+	case "env":
+		// Applied on the next start/restart; the running agent keeps its environment
+		field = "env." + envKey
+		oldValue = inst.Env[envKey]
+		value = envValue
+		inst.SetEnv(envKey, envValue)
+	case "claude-config-dir":
+		// Applied on the next start/restart, like env
+		oldValue = inst.ClaudeConfigDir
+		inst.SetClaudeConfigDir(value)
+	case "restart-policy", "restart-max-retries", "restart-backoff":
+		// Start from the effective policy so unset fields keep the default's values
+		policy := inst.GetRestartPolicy()
+		switch field {
+		case "restart-policy":
+			oldValue = policy.When
+			policy.When = value
+		case "restart-max-retries":
+			oldValue = strconv.Itoa(policy.MaxRetries)
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				out.Fail(fmt.Sprintf("invalid number: %s", value), ErrCodeInvalidOperation)
+			}
+			policy.MaxRetries = n
+		case "restart-backoff":
+			oldValue = policy.Backoff
+			policy.Backoff = value
+		}
+		if err := policy.Validate(); err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+		if field == "restart-policy" && value == "" {
+			inst.RestartPolicy = nil
+		} else {
+			inst.RestartPolicy = &policy
 		}
 	}
 
 	// Save
 	groupTree := session.NewGroupTreeWithGroups(instances, groupsData)
 	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
-		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
 	// Output success
-	out.Success(fmt.Sprintf("Updated %s: %q -> %q", field, oldValue, value), map[string]interface{}{
-		"success":   true,
-		"id":        inst.ID,
-		"title":     inst.Title,
-		"field":     field,
-		"old_value": oldValue,
-		"new_value": value,
+	out.Success(fmt.Sprintf("Updated %s: %q -> %q", field, oldValue, value), FieldUpdateJSON{
+		Success:  true,
+		ID:       inst.ID,
+		Title:    inst.Title,
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: value,
 	})
 }
 
@@ -908,8 +1081,7 @@ func showTmuxSessionInfo(out *CLIOutput, jsonOutput bool) {
 		"#{session_name}\t#{pane_current_path}\t#{session_created}\t#{window_name}")
 	output, err := cmd.Output()
 	if err != nil {
-		out.Error("failed to get tmux session info", ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail("failed to get tmux session info", ErrCodeInternal)
 	}
 
 	parts := strings.Split(strings.TrimSpace(string(output)), "\t")
@@ -938,15 +1110,12 @@ func showTmuxSessionInfo(out *CLIOutput, jsonOutput bool) {
 		}
 	}
 
-	jsonData := map[string]interface{}{
-		"tmux_session": sessionName,
-		"title":        title,
-		"path":         currentPath,
-		"window":       windowName,
-		"registered":   false,
-	}
-	if idFragment != "" {
-		jsonData["id_fragment"] = idFragment
+	jsonData := UnregisteredSessionJSON{
+		TmuxSession: sessionName,
+		Title:       title,
+		Path:        currentPath,
+		Window:      windowName,
+		IDFragment:  idFragment,
 	}
 
 	var sb strings.Builder
@@ -1000,41 +1169,35 @@ func handleSessionSetParent(profile string, args []string) {
 	// Load sessions
 	storage, instances, groupsData, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve the session to be linked
 	inst, errMsg, errCode := ResolveSession(sessionID, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		os.Exit(2)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Resolve the parent session
 	parentInst, errMsg, errCode := ResolveSession(parentID, instances)
 	if parentInst == nil {
-		out.Error(errMsg, errCode)
-		os.Exit(2)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Validate: can't set self as parent
 	if inst.ID == parentInst.ID {
-		out.Error("cannot set session as its own parent", ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail("cannot set session as its own parent", ErrCodeInvalidOperation)
 	}
 
 	// Validate: parent can't be a sub-session (single level only)
 	if parentInst.IsSubSession() {
-		out.Error("cannot set parent to a sub-session (single level only)", ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail("cannot set parent to a sub-session (single level only)", ErrCodeInvalidOperation)
 	}
 
 	// Validate: session can't already have sub-sessions
 	for _, other := range instances {
 		if other.ParentSessionID == inst.ID {
-			out.Error(fmt.Sprintf("session '%s' already has sub-sessions, cannot become a sub-session", inst.Title), ErrCodeInvalidOperation)
-			os.Exit(1)
+			out.Fail(fmt.Sprintf("session '%s' already has sub-sessions, cannot become a sub-session", inst.Title), ErrCodeInvalidOperation)
 		}
 	}
 
@@ -1045,17 +1208,16 @@ func handleSessionSetParent(profile string, args []string) {
 	// Save
 	groupTree := session.NewGroupTreeWithGroups(instances, groupsData)
 	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
-		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
-	out.Success(fmt.Sprintf("Linked '%s' as sub-session of '%s'", inst.Title, parentInst.Title), map[string]interface{}{
-		"success":           true,
-		"session_id":        inst.ID,
-		"session_title":     inst.Title,
-		"parent_id":         parentInst.ID,
-		"parent_title":      parentInst.Title,
-		"inherited_group":   inst.GroupPath,
+	out.Success(fmt.Sprintf("Linked '%s' as sub-session of '%s'", inst.Title, parentInst.Title), ParentLinkJSON{
+		Success:        true,
+		SessionID:      inst.ID,
+		SessionTitle:   inst.Title,
+		ParentID:       parentInst.ID,
+		ParentTitle:    parentInst.Title,
+		InheritedGroup: inst.GroupPath,
 	})
 }
 
@@ -1092,21 +1254,18 @@ func handleSessionUnsetParent(profile string, args []string) {
 	// Load sessions
 	storage, instances, groupsData, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Resolve the session
 	inst, errMsg, errCode := ResolveSession(sessionID, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		os.Exit(2)
+		out.Fail(errMsg, errCode)
 	}
 
 	// Check if it's actually a sub-session
 	if !inst.IsSubSession() {
-		out.Error(fmt.Sprintf("session '%s' is not a sub-session", inst.Title), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("session '%s' is not a sub-session", inst.Title), ErrCodeInvalidOperation)
 	}
 
 	// Get parent title for output
@@ -1124,140 +1283,210 @@ func handleSessionUnsetParent(profile string, args []string) {
 	// Save
 	groupTree := session.NewGroupTreeWithGroups(instances, groupsData)
 	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
-		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to save: %v", err), ErrCodeInternal)
 	}
 
-	out.Success(fmt.Sprintf("Removed sub-session link from '%s' (was linked to '%s')", inst.Title, parentTitle), map[string]interface{}{
-		"success":        true,
-		"session_id":     inst.ID,
-		"session_title":  inst.Title,
-		"former_parent":  parentTitle,
+	out.Success(fmt.Sprintf("Removed sub-session link from '%s' (was linked to '%s')", inst.Title, parentTitle), ParentUnlinkJSON{
+		Success:      true,
+		SessionID:    inst.ID,
+		SessionTitle: inst.Title,
+		FormerParent: parentTitle,
 	})
 }
 
-// handleSessionSend sends a message to a running session
-// Waits for the agent to be ready before sending (Claude, Gemini, etc.)
-func handleSessionSend(profile string, args []string) {
-	fs := flag.NewFlagSet("session send", flag.ExitOnError)
+// waitPollInterval matches the TUI's status refresh
+const waitPollInterval = 500 * time.Millisecond
+
+// waitConditionMet reports whether a session's status satisfies a
+// `session wait --until` condition
+func waitConditionMet(until string, initial, status session.Status) bool {
+	switch until {
+	case "waiting":
+		return status == session.StatusWaiting
+	case "idle":
+		// Not working: finished and waiting for input, or already seen
+		return status == session.StatusWaiting || status == session.StatusIdle
+	case "exited":
+		return status == session.StatusExited || status == session.StatusError
+	case "any-change":
+		return status != initial
+	}
+	return false
+}
+
+// handleSessionWait blocks until sessions reach a status condition
+func handleSessionWait(profile string, args []string) {
+	fs := flag.NewFlagSet("session wait", flag.ExitOnError)
+	until := fs.String("until", "waiting", "Condition: waiting, idle, exited or any-change")
+	timeout := fs.Duration("timeout", 0, "Give up after this long, e.g. 10m (0 waits forever)")
+	all := fs.Bool("all", false, "Wait until every session meets the condition (default)")
+	anyMode := fs.Bool("any", false, "Return as soon as one session meets the condition")
+	afterActive := fs.Bool("after-active", false, "Only count a session once it has been seen running during the wait")
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
-	quiet := fs.Bool("q", false, "Quiet mode")
-	noWait := fs.Bool("no-wait", false, "Don't wait for agent to be ready (send immediately)")
+	quiet := fs.Bool("q", false, "Quiet mode (exit code only)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session wait [options] <id|title>...")
+		fmt.Println()
+		fmt.Println("Block until sessions reach a status, then print their statuses (as JSON")
+		fmt.Println("with --json, which scripts should use).")
+		fmt.Println()
+		fmt.Println("Conditions:")
+		fmt.Println("  waiting     The agent finished and is waiting for input")
+		fmt.Println("  idle        The agent is not working (waiting or idle)")
+		fmt.Println("  exited      The agent or its tmux session is gone")
+		fmt.Println("  any-change  The status differs from when the wait started")
+		fmt.Println()
+		fmt.Println("A condition that already holds is met immediately, unless --after-active")
+		fmt.Println("is given: then a session first has to be seen running, so a wait right")
+		fmt.Println("after `session send` doesn't end before the agent picked up the message.")
+		fmt.Println("Each session counts as done once it has met the condition.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Exit codes:")
+		fmt.Println("  0  Condition met")
+		fmt.Println("  1  Error")
+		fmt.Println("  2  Session not found")
+		fmt.Println("  3  Timed out")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session wait worker-1")
+		fmt.Println("  agent-deck session wait --json --timeout 10m worker-1 worker-2 worker-3")
+		fmt.Println("  agent-deck session wait --any --until exited worker-1 worker-2")
+		fmt.Println("  agent-deck session send worker-1 \"next step\" && agent-deck session wait --after-active worker-1")
+	}
+
 	if err := fs.Parse(args); err != nil {
 		os.Exit(1)
 	}
-	remaining := fs.Args()
+	refs := fs.Args()
 
 	out := NewCLIOutput(*jsonOutput, *quiet)
 
-	if len(remaining) < 2 {
-		out.Error("usage: agent-deck session send <id> <message>", ErrCodeInvalidOperation)
-		os.Exit(1)
+	if len(refs) == 0 {
+		out.Fail("usage: agent-deck session wait [options] <id|title>...", ErrCodeInvalidOperation)
+	}
+	switch *until {
+	case "waiting", "idle", "exited", "any-change":
+	default:
+		out.Fail(fmt.Sprintf("invalid --until '%s' (use waiting, idle, exited or any-change)", *until), ErrCodeInvalidOperation)
+	}
+	if *all && *anyMode {
+		out.Fail("--all and --any are mutually exclusive", ErrCodeInvalidOperation)
 	}
-
-	sessionRef := remaining[0]
-	message := strings.Join(remaining[1:], " ")
 
 	// Load sessions
 	_, instances, _, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
-	// Resolve session
-	inst, errMsg, errCode := ResolveSession(sessionRef, instances)
-	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
+	// Resolve sessions (each once)
+	var targets []*session.Instance
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		inst, errMsg, errCode := ResolveSession(ref, instances)
+		if inst == nil {
+			out.Fail(errMsg, errCode)
 		}
-		os.Exit(1)
-	}
-
-	// Check if session is running
-	if !inst.Exists() {
-		out.Error(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
-	// Get tmux session
-	tmuxSess := inst.GetTmuxSession()
-	if tmuxSess == nil {
-		out.Error("could not determine tmux session", ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
-	// Wait for agent to be ready (unless --no-wait is specified)
-	if !*noWait {
-		if err := waitForAgentReady(tmuxSess, inst.Tool); err != nil {
-			out.Error(fmt.Sprintf("timeout waiting for agent: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+		if !seen[inst.ID] {
+			seen[inst.ID] = true
+			targets = append(targets, inst)
 		}
 	}
 
-	// Send message via tmux
-	tmuxName := tmuxSess.Name
-	cmd := exec.Command("tmux", "send-keys", "-l", "-t", tmuxName, message)
-	if err := cmd.Run(); err != nil {
-		out.Error(fmt.Sprintf("failed to send message: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
-	// Send Enter
-	cmd = exec.Command("tmux", "send-keys", "-t", tmuxName, "Enter")
-	if err := cmd.Run(); err != nil {
-		out.Error(fmt.Sprintf("failed to send Enter: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
-	out.Success(fmt.Sprintf("Sent message to '%s'", inst.Title), map[string]interface{}{
-		"success":       true,
-		"session_id":    inst.ID,
-		"session_title": inst.Title,
-		"message":       message,
-	})
-}
+	initial := make([]session.Status, len(targets))
+	met := make([]bool, len(targets))
+	active := make([]bool, len(targets))
+	started := time.Now()
 
-// waitForAgentReady waits for Claude/Gemini/other agents to be ready for input
-// Uses status detection: waits for "active" → "waiting" transition
-func waitForAgentReady(tmuxSess *tmux.Session, tool string) error {
-	sawActive := false
-	waitingCount := 0
-	maxAttempts := 300 // 60 seconds max (300 * 200ms)
+	// With a daemon the statuses come from it (it already tracks them);
+	// otherwise from tmux directly
+	client := daemonClient(profile)
 
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		time.Sleep(200 * time.Millisecond)
+	var deadline <-chan time.Time
+	if *timeout > 0 {
+		timer := time.NewTimer(*timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
 
-		status, err := tmuxSess.GetStatus()
-		if err != nil {
-			waitingCount = 0
-			continue
+	for first := true; ; first = false {
+		if client == nil || client.ApplyStatuses(targets) != nil {
+			tmux.RefreshExistingSessions()
+			for _, inst := range targets {
+				_ = inst.UpdateStatus()
+			}
 		}
-
-		if status == "active" {
-			sawActive = true
-			waitingCount = 0
-			continue
+		metCount := 0
+		for i, inst := range targets {
+			if first {
+				initial[i] = inst.Status
+			}
+			if inst.Status == session.StatusRunning {
+				active[i] = true
+			}
+			if !met[i] && (active[i] || !*afterActive) && waitConditionMet(*until, initial[i], inst.Status) {
+				met[i] = true
+			}
+			if met[i] {
+				metCount++
+			}
 		}
 
-		if status == "waiting" {
-			waitingCount++
-		} else {
-			waitingCount = 0
+		if metCount == len(targets) || (*anyMode && metCount > 0) {
+			printWaitResult(out, *until, *anyMode, targets, initial, met, started, false)
+			return
 		}
 
-		// Agent is ready when:
-		// 1. We've seen "active" (loading) and now see "waiting" (ready)
-		// 2. We've seen "waiting" 10+ times (already ready)
-		alreadyReady := waitingCount >= 10 && attempt >= 15 // At least 3s elapsed
-		if (sawActive && status == "waiting") || alreadyReady {
-			time.Sleep(300 * time.Millisecond) // Small delay for UI to render
-			return nil
+		select {
+		case <-deadline:
+			printWaitResult(out, *until, *anyMode, targets, initial, met, started, true)
+			os.Exit(exitTimeout)
+		case <-ticker.C:
 		}
 	}
+}
 
-	return fmt.Errorf("agent not ready after 60 seconds")
+// printWaitResult prints the final statuses of a `session wait`
+func printWaitResult(out *CLIOutput, until string, anyMode bool, targets []*session.Instance,
+	initial []session.Status, met []bool, started time.Time, timedOut bool) {
+	result := WaitJSON{
+		Until:          until,
+		Mode:           "all",
+		Met:            !timedOut,
+		TimedOut:       timedOut,
+		ElapsedSeconds: time.Since(started).Round(time.Millisecond).Seconds(),
+	}
+	if anyMode {
+		result.Mode = "any"
+	}
+	for i, inst := range targets {
+		result.Sessions = append(result.Sessions, WaitSessionJSON{
+			ID:            inst.ID,
+			Title:         inst.Title,
+			Status:        string(inst.Status),
+			InitialStatus: string(initial[i]),
+			Met:           met[i],
+		})
+	}
+
+	var sb strings.Builder
+	for _, s := range result.Sessions {
+		symbol := successSymbol
+		if !s.Met {
+			symbol = bulletSymbol
+		}
+		fmt.Fprintf(&sb, "%s %s: %s\n", symbol, s.Title, s.Status)
+	}
+	if timedOut {
+		fmt.Fprintf(&sb, "Timed out after %.0fs waiting for '%s'\n", result.ElapsedSeconds, until)
+	}
+	out.Print(sb.String(), result)
 }
 
 // handleSessionOutput gets the last response from a session
@@ -1266,14 +1495,22 @@ func handleSessionOutput(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("quiet", false, "Minimal output")
 	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	follow := fs.Bool("follow", false, "Stream new responses as JSON lines (Claude and Gemini)")
+	followShort := fs.Bool("f", false, "Stream new responses as JSON lines (short)")
+	tools := fs.Bool("tools", false, "With --follow, also stream tool calls and results")
 
 	fs.Usage = func() {
-		fmt.Println("Usage: agent-deck session output [id|title] [options]")
+		fmt.Println("Usage: agent-deck session output [options] [id|title]")
 		fmt.Println()
 		fmt.Println("Get the last response from a session. If no ID is provided, auto-detects current session.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session output my-project")
+		fmt.Println("  agent-deck session output -f my-project            # Stream new responses until Ctrl+C")
+		fmt.Println("  agent-deck session output -f --tools my-project    # Include tool calls")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -1287,25 +1524,24 @@ func handleSessionOutput(profile string, args []string) {
 	// Load sessions
 	_, instances, _, err := loadSessionData(profile)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
 	}
 
 	// Resolve session (allow current session detection)
 	inst, errMsg, errCode := ResolveSessionOrCurrent(identifier, instances)
 	if inst == nil {
-		out.Error(errMsg, errCode)
-		if errCode == ErrCodeNotFound {
-			os.Exit(2)
-		}
-		os.Exit(1)
+		out.Fail(errMsg, errCode)
+	}
+
+	if *follow || *followShort {
+		followSessionOutput(out, inst, *tools)
+		return
 	}
 
 	// Get the last response
-	response, err := inst.GetLastResponse()
+	response, err := lastResponse(profile, inst)
 	if err != nil {
-		out.Error(fmt.Sprintf("failed to get response: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
+		out.Fail(fmt.Sprintf("failed to get response: %v", err), ErrCodeInvalidOperation)
 	}
 
 	// Quiet mode: just print raw content
@@ -1314,26 +1550,21 @@ func handleSessionOutput(profile string, args []string) {
 		return
 	}
 
-	// Build JSON data with tool-specific conversation session ID key
-	jsonData := map[string]interface{}{
-		"success":       true,
-		"session_id":    inst.ID,
-		"session_title": inst.Title,
-		"tool":          response.Tool,
-		"role":          response.Role,
-		"content":       response.Content,
-		"timestamp":     response.Timestamp,
-	}
-	// Add tool-specific conversation session ID
-	if response.SessionID != "" {
-		switch response.Tool {
-		case "claude":
-			jsonData["claude_session_id"] = response.SessionID
-		case "gemini":
-			jsonData["gemini_session_id"] = response.SessionID
-		default:
-			jsonData["conversation_id"] = response.SessionID
-		}
+	jsonData := SessionOutputJSON{
+		Success:        true,
+		SessionID:      inst.ID,
+		SessionTitle:   inst.Title,
+		Tool:           response.Tool,
+		Role:           response.Role,
+		Content:        response.Content,
+		Timestamp:      response.Timestamp,
+		ConversationID: response.SessionID,
+	}
+	switch response.Tool {
+	case "claude":
+		jsonData.ClaudeSessionID = response.SessionID
+	case "gemini":
+		jsonData.GeminiSessionID = response.SessionID
 	}
 
 	// Build human-readable output
@@ -1373,8 +1604,7 @@ func handleSessionCurrent(profileArg string, args []string) {
 
 	// Check if we're in a tmux session
 	if os.Getenv("TMUX") == "" {
-		out.Error("not in a tmux session", ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail("not in a tmux session", ErrCodeNotFound)
 	}
 
 	// Detect profile: use explicit arg if provided, otherwise auto-detect
@@ -1386,8 +1616,7 @@ func handleSessionCurrent(profileArg string, args []string) {
 	// Load sessions for detected profile first
 	_, instances, _, err := loadSessionData(detectedProfile)
 	if err != nil {
-		out.Error(err.Error(), ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail(err.Error(), ErrCodeInternal)
 	}
 
 	// Try to find session in detected profile
@@ -1403,8 +1632,7 @@ func handleSessionCurrent(profileArg string, args []string) {
 	}
 
 	if inst == nil {
-		out.Error("current tmux session is not an agent-deck session\nHint: Run 'agent-deck list' to see available sessions", ErrCodeNotFound)
-		os.Exit(1)
+		out.Fail("current tmux session is not an agent-deck session\nHint: Run 'agent-deck list' to see available sessions", ErrCodeNotFound)
 	}
 
 	// Update status
@@ -1417,16 +1645,13 @@ func handleSessionCurrent(profileArg string, args []string) {
 	}
 
 	// Prepare JSON output
-	jsonData := map[string]interface{}{
-		"session": inst.Title,
-		"profile": detectedProfile,
-		"id":      inst.ID,
-		"path":    inst.ProjectPath,
-		"status":  StatusString(inst.Status),
-	}
-
-	if inst.GroupPath != "" {
-		jsonData["group"] = inst.GroupPath
+	jsonData := CurrentSessionJSON{
+		Session: inst.Title,
+		Profile: detectedProfile,
+		ID:      inst.ID,
+		Path:    inst.ProjectPath,
+		Status:  StatusString(inst.Status),
+		Group:   inst.GroupPath,
 	}
 
 	// Build human-readable output
@@ -1442,3 +1667,136 @@ func handleSessionCurrent(profileArg string, args []string) {
 
 	out.Print(sb.String(), jsonData)
 }
+
+// followSessionOutput streams new assistant messages (and with tools, tool
+// calls and results) as JSON lines until interrupted
+func followSessionOutput(out *CLIOutput, inst *session.Instance, tools bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enc := json.NewEncoder(os.Stdout)
+	err := inst.FollowTranscript(ctx, 500*time.Millisecond, func(e session.TranscriptEntry) {
+		switch e.Type {
+		case session.EntryAssistant:
+		case session.EntryToolUse, session.EntryToolResult:
+			if !tools {
+				return
+			}
+		default:
+			return
+		}
+		_ = enc.Encode(e)
+	})
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+}
+
+// handleSessionDiff compares the working trees of two sessions (typically a
+// session and its worktree fork), including uncommitted and untracked files
+func handleSessionDiff(profile string, args []string) {
+	fs := flag.NewFlagSet("session diff", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	stat := fs.Bool("stat", false, "Show a diffstat instead of the full diff")
+	nameOnly := fs.Bool("name-only", false, "Show only the names of changed files")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session diff [options] <a> <b>")
+		fmt.Println()
+		fmt.Println("Compare the working trees of two sessions in the same git repository,")
+		fmt.Println("e.g. a session and a fork created with 'session fork --worktree'.")
+		fmt.Println("Uncommitted and untracked files are included.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session diff my-project my-project-fork")
+		fmt.Println("  agent-deck session diff --stat my-project my-project-fork")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() != 2 {
+		out.Error("two sessions are required", ErrCodeInvalidOperation)
+		if !*jsonOutput {
+			fs.Usage()
+		}
+		os.Exit(1)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
+	}
+
+	var sides [2]*session.Instance
+	var trees [2]string
+	for idx := range sides {
+		inst, errMsg, errCode := ResolveSession(fs.Arg(idx), instances)
+		if inst == nil {
+			out.Fail(errMsg, errCode)
+		}
+		tree, err := sessionWorkTree(inst)
+		if err != nil {
+			out.Fail(fmt.Sprintf("session '%s': %v", inst.Title, err), ErrCodeInvalidOperation)
+		}
+		sides[idx], trees[idx] = inst, tree
+	}
+
+	var diffArgs []string
+	switch {
+	case *stat:
+		diffArgs = []string{"--stat"}
+	case *nameOnly:
+		diffArgs = []string{"--name-only"}
+	}
+	diff, err := git.DiffWorkingTrees(trees[0], trees[1], diffArgs...)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInvalidOperation)
+	}
+
+	if *jsonOutput {
+		files, err := git.DiffWorkingTrees(trees[0], trees[1], "--name-status")
+		if err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+		changed := []DiffFileJSON{}
+		for _, line := range strings.Split(strings.TrimSpace(files), "\n") {
+			if parts := strings.SplitN(line, "\t", 2); len(parts) == 2 {
+				changed = append(changed, DiffFileJSON{Status: parts[0], Path: parts[1]})
+			}
+		}
+		out.Print("", DiffJSON{
+			Success: true,
+			A:       DiffSideJSON{ID: sides[0].ID, Title: sides[0].Title, Path: trees[0]},
+			B:       DiffSideJSON{ID: sides[1].ID, Title: sides[1].Title, Path: trees[1]},
+			Files:   changed,
+			Diff:    diff,
+		})
+		return
+	}
+
+	if diff == "" {
+		fmt.Printf("No differences between %s and %s\n", sides[0].Title, sides[1].Title)
+		return
+	}
+	fmt.Print(diff)
+}
+
+// sessionWorkTree returns the top of the git working tree a session runs in
+func sessionWorkTree(inst *session.Instance) (string, error) {
+	if inst.IsRemote() {
+		return "", fmt.Errorf("remote sessions cannot be diffed")
+	}
+	if inst.IsWorktree() {
+		return inst.WorktreePath, nil
+	}
+	if !git.IsRepo(inst.ProjectPath) {
+		return "", fmt.Errorf("%s is not a git repository", inst.ProjectPath)
+	}
+	return git.RepoRoot(inst.ProjectPath)
+}
@@ -11,5 +11,12 @@ import (
 func TestMain(m *testing.M) {
 	// Force _test profile for all tests in this package
 	os.Setenv("AGENTDECK_PROFILE", "_test")
+
+	// runCLI re-executes the test binary as agent-deck itself
+	if os.Getenv(runCLIEnv) != "" {
+		os.Args = append([]string{"agent-deck"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
 	os.Exit(m.Run())
 }
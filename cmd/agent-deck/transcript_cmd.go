@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// transcriptFork is an ancestor of a forked session
+type transcriptFork struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"` // Empty if the session was removed
+}
+
+// transcriptExport is a session's conversation as exported by `session transcript`
+type transcriptExport struct {
+	SessionID      string                    `json:"session_id"`
+	SessionTitle   string                    `json:"session_title"`
+	Tool           string                    `json:"tool"`
+	ConversationID string                    `json:"conversation_id,omitempty"`
+	File           string                    `json:"file"`
+	ForkedFrom     []transcriptFork          `json:"forked_from,omitempty"` // Nearest ancestor first
+	Since          *time.Time                `json:"since,omitempty"`
+	ExportedAt     time.Time                 `json:"exported_at"`
+	Entries        []session.TranscriptEntry `json:"entries"`
+}
+
+// handleSessionTranscript exports a session's full conversation
+func handleSessionTranscript(profile string, args []string) {
+	fs := flag.NewFlagSet("session transcript", flag.ExitOnError)
+	format := fs.String("format", "md", "Output format: md, json or html")
+	since := fs.String("since", "", "Only entries since a time (15:04, 2006-01-02, RFC 3339) or for a duration (2h)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session transcript [options] [id|title]")
+		fmt.Println()
+		fmt.Println("Export a session's conversation with tool calls, results and timestamps")
+		fmt.Println("(Claude and Gemini). If no ID is provided, auto-detects current session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session transcript my-project > transcript.md")
+		fmt.Println("  agent-deck session transcript --format html my-project > transcript.html")
+		fmt.Println("  agent-deck session transcript --format json --since 2h my-project")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*format == "json", false)
+
+	if *format != "md" && *format != "json" && *format != "html" {
+		out.Fail(fmt.Sprintf("invalid format '%s' (use md, json or html)", *format), ErrCodeInvalidOperation)
+	}
+	var sinceTime time.Time
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			out.Fail(err.Error(), ErrCodeInvalidOperation)
+		}
+		sinceTime = t
+	}
+
+	// Load sessions
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeInternal)
+	}
+
+	// Resolve session (allow current session detection)
+	inst, errMsg, errCode := ResolveSessionOrCurrent(fs.Arg(0), instances)
+	if inst == nil {
+		out.Fail(errMsg, errCode)
+	}
+
+	transcript, err := inst.GetTranscript()
+	if err != nil {
+		out.Fail(fmt.Sprintf("failed to read transcript: %v", err), ErrCodeInvalidOperation)
+	}
+
+	export := transcriptExport{
+		SessionID:      inst.ID,
+		SessionTitle:   inst.Title,
+		Tool:           transcript.Tool,
+		ConversationID: transcript.SessionID,
+		File:           transcript.File,
+		ForkedFrom:     forkLineage(inst, instances),
+		ExportedAt:     time.Now(),
+		Entries:        transcript.Entries,
+	}
+	if !sinceTime.IsZero() {
+		export.Since = &sinceTime
+		export.Entries = transcript.Since(sinceTime)
+	}
+	if export.Entries == nil {
+		export.Entries = []session.TranscriptEntry{}
+	}
+
+	switch *format {
+	case "json":
+		out.Print("", export)
+	case "html":
+		if err := writeTranscriptHTML(os.Stdout, export); err != nil {
+			out.Fail(err.Error(), ErrCodeInternal)
+		}
+	default:
+		writeTranscriptMarkdown(os.Stdout, export)
+	}
+}
+
+// parseSince parses --since: a duration back from now (2h, 30m), a time
+// today (15:04), a date, "2006-01-02 15:04" or RFC 3339
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("15:04", value, time.Local); err == nil {
+		return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since '%s' (use 2h, 15:04, 2006-01-02 or RFC 3339)", value)
+}
+
+// forkLineage returns the sessions inst was forked from, nearest first
+func forkLineage(inst *session.Instance, instances []*session.Instance) []transcriptFork {
+	byID := make(map[string]*session.Instance, len(instances))
+	for _, other := range instances {
+		byID[other.ID] = other
+	}
+
+	var lineage []transcriptFork
+	seen := map[string]bool{inst.ID: true}
+	for id := inst.ForkedFromID; id != "" && !seen[id]; {
+		seen[id] = true
+		parent, ok := byID[id]
+		if !ok {
+			lineage = append(lineage, transcriptFork{ID: id})
+			break
+		}
+		lineage = append(lineage, transcriptFork{ID: parent.ID, Title: parent.Title})
+		id = parent.ForkedFromID
+	}
+	return lineage
+}
+
+// formatEntryTime formats an entry's timestamp in local time ("" if unknown)
+func formatEntryTime(e session.TranscriptEntry) string {
+	if t := e.Time(); !t.IsZero() {
+		return t.Local().Format("2006-01-02 15:04:05")
+	}
+	return ""
+}
+
+// prettyInput indents a tool call's JSON arguments
+func prettyInput(input json.RawMessage) string {
+	if len(input) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, input, "", "  "); err != nil {
+		return string(input)
+	}
+	return buf.String()
+}
+
+// lineageText describes a fork lineage as "a ← b"
+func lineageText(lineage []transcriptFork) string {
+	parts := make([]string, len(lineage))
+	for i, f := range lineage {
+		if f.Title != "" {
+			parts[i] = fmt.Sprintf("%s (%s)", f.Title, TruncateID(f.ID))
+		} else {
+			parts[i] = fmt.Sprintf("%s (removed)", TruncateID(f.ID))
+		}
+	}
+	return strings.Join(parts, " ← ")
+}
+
+// mdFence returns a code fence longer than any backtick run in s
+func mdFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// writeTranscriptMarkdown renders a transcript as Markdown
+func writeTranscriptMarkdown(w io.Writer, export transcriptExport) {
+	fmt.Fprintf(w, "# %s\n\n", export.SessionTitle)
+	fmt.Fprintf(w, "- Tool: %s\n", export.Tool)
+	if export.ConversationID != "" {
+		fmt.Fprintf(w, "- Conversation: %s\n", export.ConversationID)
+	}
+	if len(export.ForkedFrom) > 0 {
+		fmt.Fprintf(w, "- Forked from: %s\n", lineageText(export.ForkedFrom))
+	}
+	if export.Since != nil {
+		fmt.Fprintf(w, "- Since: %s\n", export.Since.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintf(w, "- Exported: %s\n", export.ExportedAt.Format("2006-01-02 15:04:05"))
+
+	for _, e := range export.Entries {
+		ts := formatEntryTime(e)
+		if ts != "" {
+			ts = " · " + ts
+		}
+		switch e.Type {
+		case session.EntryUser:
+			fmt.Fprintf(w, "\n## User%s\n\n%s\n", ts, e.Content)
+		case session.EntryAssistant:
+			fmt.Fprintf(w, "\n## Assistant%s\n\n%s\n", ts, e.Content)
+		case session.EntryToolUse:
+			input := prettyInput(e.Input)
+			fence := mdFence(input)
+			fmt.Fprintf(w, "\n**Tool call: %s**%s\n\n%sjson\n%s\n%s\n", e.ToolName, ts, fence, input, fence)
+		case session.EntryToolResult:
+			label := "Tool result"
+			if e.ToolName != "" {
+				label += ": " + e.ToolName
+			}
+			if e.IsError {
+				label += " (error)"
+			}
+			fence := mdFence(e.Content)
+			fmt.Fprintf(w, "\n**%s**\n\n%s\n%s\n%s\n", label, fence, e.Content, fence)
+		}
+	}
+}
+
+// transcriptHTML is a self-contained page for `--format html`
+var transcriptHTML = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time":    formatEntryTime,
+	"input":   prettyInput,
+	"lineage": lineageText,
+	"local": func(t time.Time) string {
+		return t.Local().Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.SessionTitle}} - transcript</title>
+<style>
+body { font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 900px; margin: 2em auto; padding: 0 1em; color: #222; }
+header { border-bottom: 1px solid #ddd; margin-bottom: 1.5em; }
+header dl { display: grid; grid-template-columns: max-content 1fr; gap: .2em 1em; color: #555; }
+header dt { font-weight: 600; }
+header dd { margin: 0; }
+.entry { margin: 1em 0; padding: .6em 1em; border-radius: 6px; }
+.user { background: #eef4ff; }
+.assistant { background: #f6f6f6; }
+.tool_use, .tool_result { background: #fbfaf4; border-left: 3px solid #d8c77a; font-size: 13px; }
+.tool_result.error { border-left-color: #d9534f; }
+.meta { color: #777; font-size: 12px; margin-bottom: .3em; }
+.meta b { color: #333; }
+.text { white-space: pre-wrap; }
+pre { white-space: pre-wrap; word-break: break-word; margin: 0; font: 12px/1.4 ui-monospace, Menlo, monospace; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.SessionTitle}}</h1>
+<dl>
+<dt>Tool</dt><dd>{{.Tool}}</dd>
+{{- if .ConversationID}}
+<dt>Conversation</dt><dd>{{.ConversationID}}</dd>
+{{- end}}
+{{- if .ForkedFrom}}
+<dt>Forked from</dt><dd>{{lineage .ForkedFrom}}</dd>
+{{- end}}
+{{- if .Since}}
+<dt>Since</dt><dd>{{local .Since}}</dd>
+{{- end}}
+<dt>Exported</dt><dd>{{local .ExportedAt}}</dd>
+</dl>
+</header>
+{{- range .Entries}}
+{{- if eq .Type "user"}}
+<div class="entry user"><div class="meta"><b>User</b> {{time .}}</div><div class="text">{{.Content}}</div></div>
+{{- else if eq .Type "assistant"}}
+<div class="entry assistant"><div class="meta"><b>Assistant</b> {{time .}}</div><div class="text">{{.Content}}</div></div>
+{{- else if eq .Type "tool_use"}}
+<div class="entry tool_use"><div class="meta"><b>Tool call: {{.ToolName}}</b> {{time .}}</div><pre>{{input .Input}}</pre></div>
+{{- else if eq .Type "tool_result"}}
+<div class="entry tool_result{{if .IsError}} error{{end}}"><div class="meta"><b>Tool result{{if .ToolName}}: {{.ToolName}}{{end}}{{if .IsError}} (error){{end}}</b></div><pre>{{.Content}}</pre></div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// writeTranscriptHTML renders a transcript as a standalone HTML page
+func writeTranscriptHTML(w io.Writer, export transcriptExport) error {
+	return transcriptHTML.Execute(w, export)
+}
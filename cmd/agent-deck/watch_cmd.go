@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// Event types of `watch`
+const (
+	watchSnapshot  = "snapshot"
+	watchAdded     = "added"
+	watchRemoved   = "removed"
+	watchChanged   = "changed"
+	watchHeartbeat = "heartbeat"
+)
+
+// defaultWatchFormat is the summary printed by heartbeats in text mode
+const defaultWatchFormat = "●{running} ◐{waiting} ○{idle}"
+
+// handleWatch streams session status, title and group changes until interrupted
+func handleWatch(profile string, args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output events as JSON lines")
+	group := fs.String("group", "", "Only watch this group (and its subgroups)")
+	format := fs.String("format", "", "Print a summary line whenever it changes, e.g. \"●{running} ◐{waiting} ○{idle}\"")
+	interval := fs.Duration("interval", 2*time.Second, "How often to check session status (without a daemon)")
+	heartbeat := fs.Duration("heartbeat", 0, "Also emit a heartbeat this often, e.g. 30s (0 disables)")
+	once := fs.Bool("once", false, "Print the current state and exit")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck watch [options]")
+		fmt.Println()
+		fmt.Println("Print a line whenever a session's status, title or group changes, or a")
+		fmt.Println("session is added or removed. Runs until interrupted. While the daemon")
+		fmt.Println("runs, statuses follow its events instead of being polled from tmux.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Format placeholders:")
+		fmt.Println("  {running} {waiting} {idle} {error} {exited} {total}")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck watch")
+		fmt.Println("  agent-deck watch --json --group work")
+		fmt.Println("  agent-deck watch --format \"●{running} ◐{waiting} ○{idle}\"")
+		fmt.Println("  agent-deck watch --once --format \"{waiting} waiting\"")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() > 0 {
+		out.Fail(fmt.Sprintf("unexpected argument '%s'", fs.Arg(0)), ErrCodeInvalidOperation)
+	}
+	if *jsonOutput && *format != "" {
+		out.Fail("--json and --format are mutually exclusive", ErrCodeInvalidOperation)
+	}
+	if *interval <= 0 {
+		out.Fail("--interval must be positive", ErrCodeInvalidOperation)
+	}
+
+	// Storage and tmux log debug lines that would end up in the stream
+	if os.Getenv("AGENTDECK_DEBUG") == "" {
+		log.SetOutput(io.Discard)
+	}
+
+	storage, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		out.Fail(err.Error(), ErrCodeInternal)
+	}
+	groupPath := ""
+	if *group != "" {
+		groupPath = normalizeGroupPath(*group)
+		tree := session.NewGroupTreeWithGroups(instances, groups)
+		if _, ok := tree.Groups[groupPath]; !ok {
+			out.Fail(fmt.Sprintf("group '%s' not found", groupPath), ErrCodeNotFound)
+		}
+	}
+
+	w := &watcher{profile: storage.Profile(), group: groupPath, json: *jsonOutput, format: *format}
+	w.daemon = daemonClient(profile)
+	w.poll(instances, true)
+	if *once {
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// With a daemon, every status change it publishes triggers a poll (which
+	// reads the daemon's statuses); without one, the ticker polls tmux
+	events := make(chan struct{}, 1)
+	var daemonGone chan struct{}
+	subscribe := func() {
+		gone := make(chan struct{})
+		daemonGone = gone
+		go func(client *daemon.Client) {
+			defer close(gone)
+			_ = client.Subscribe(ctx, func(daemon.Event) {
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			})
+		}(w.daemon)
+	}
+	if w.daemon != nil {
+		subscribe()
+	}
+
+	// sessions.json only exists once something was saved, so the watcher
+	// may start on a later tick
+	var reload <-chan struct{}
+	startWatcher := func() bool {
+		sw, err := session.NewStorageWatcher(storage.Path())
+		if err != nil {
+			return false
+		}
+		sw.Start()
+		reload = sw.ReloadChannel()
+		go func() {
+			<-ctx.Done()
+			_ = sw.Close()
+		}()
+		return true
+	}
+	watching := startWatcher()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	var beat <-chan time.Time
+	if *heartbeat > 0 {
+		beatTicker := time.NewTicker(*heartbeat)
+		defer beatTicker.Stop()
+		beat = beatTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload:
+			instances = w.reload(storage, instances)
+		case <-events:
+		case <-daemonGone:
+			// The daemon stopped: poll tmux until one runs again
+			w.daemon, daemonGone = nil, nil
+		case <-ticker.C:
+			if !watching && startWatcher() {
+				watching = true
+				instances = w.reload(storage, instances)
+			} else if w.daemon != nil {
+				continue
+			} else if w.daemon = daemonClient(profile); w.daemon != nil {
+				subscribe()
+			}
+		case <-beat:
+			w.heartbeat()
+			continue
+		}
+		w.poll(instances, false)
+	}
+}
+
+// watcher remembers what `watch` last printed so it only prints changes
+type watcher struct {
+	profile string
+	group   string
+	json    bool
+	format  string
+	daemon  *daemon.Client // Source of statuses while the profile's daemon runs
+
+	sessions []SessionJSON
+	counts   StatusJSON
+	summary  string // Last line printed in --format mode
+}
+
+// reload reads sessions.json again, keeping the old sessions if that fails.
+// Sessions that were already loaded keep their status tracking.
+func (w *watcher) reload(storage *session.Storage, instances []*session.Instance) []*session.Instance {
+	reloaded, _, err := storage.LoadWithGroups()
+	if err != nil {
+		log.Printf("watch: reload failed: %v", err)
+		return instances
+	}
+	old := make(map[string]*session.Instance, len(instances))
+	for _, inst := range instances {
+		old[inst.ID] = inst
+	}
+	for _, inst := range reloaded {
+		if prev, ok := old[inst.ID]; ok {
+			inst.CarryRuntimeState(prev)
+		}
+	}
+	return reloaded
+}
+
+// poll refreshes the status of the watched sessions and prints what changed
+// since the last poll, or everything on the first
+func (w *watcher) poll(instances []*session.Instance, first bool) {
+	if w.group != "" {
+		instances = groupInstances(instances, w.group)
+	}
+	if w.daemon != nil && w.daemon.ApplyStatuses(instances) == nil {
+		w.counts = newStatusJSON(tallyByStatus(instances))
+	} else {
+		tmux.RefreshExistingSessions()
+		w.counts = newStatusJSON(countByStatus(instances))
+	}
+	cur := make([]SessionJSON, len(instances))
+	for i, inst := range instances {
+		cur[i] = newSessionJSON(inst, w.profile)
+	}
+
+	now := time.Now()
+	var events []WatchEventJSON
+	if first {
+		events = []WatchEventJSON{{Type: watchSnapshot, Sessions: cur}}
+	} else {
+		events = diffWatch(w.sessions, cur)
+	}
+	w.sessions = cur
+
+	if w.format != "" {
+		if line := formatWatchSummary(w.format, w.counts); first || line != w.summary {
+			w.summary = line
+			fmt.Println(line)
+		}
+		return
+	}
+	for _, e := range events {
+		e.Time = now
+		e.Counts = w.counts
+		w.print(e)
+	}
+}
+
+// heartbeat prints the current counts, or the summary again in --format mode
+func (w *watcher) heartbeat() {
+	if w.format != "" {
+		fmt.Println(w.summary)
+		return
+	}
+	w.print(WatchEventJSON{Type: watchHeartbeat, Time: time.Now(), Counts: w.counts})
+}
+
+// print prints an event as a JSON line or as text
+func (w *watcher) print(e WatchEventJSON) {
+	if w.json {
+		data, _ := json.Marshal(e)
+		fmt.Println(string(data))
+		return
+	}
+	fmt.Printf("%s %s\n", e.Time.Format("15:04:05"), watchEventText(e))
+}
+
+// watchEventText describes an event for the text output
+func watchEventText(e WatchEventJSON) string {
+	switch e.Type {
+	case watchSnapshot:
+		return fmt.Sprintf("watching %d sessions: %s", len(e.Sessions), formatWatchSummary(defaultWatchFormat, e.Counts))
+	case watchAdded:
+		return fmt.Sprintf("+ %s (%s, %s)", e.Session.Title, e.Session.Group, e.Session.Status)
+	case watchRemoved:
+		return fmt.Sprintf("- %s", e.Session.Title)
+	case watchChanged:
+		var parts []string
+		if e.Previous.Title != "" {
+			parts = append(parts, fmt.Sprintf("renamed from '%s'", e.Previous.Title))
+		}
+		if e.Previous.Group != "" {
+			parts = append(parts, fmt.Sprintf("group %s → %s", e.Previous.Group, e.Session.Group))
+		}
+		if e.Previous.Status != "" {
+			parts = append(parts, fmt.Sprintf("%s → %s", e.Previous.Status, e.Session.Status))
+		}
+		return fmt.Sprintf("%s: %s", e.Session.Title, strings.Join(parts, ", "))
+	}
+	return formatWatchSummary(defaultWatchFormat, e.Counts)
+}
+
+// diffWatch returns the events that turn prev into cur: removed sessions
+// first, then added and changed ones in cur's order
+func diffWatch(prev, cur []SessionJSON) []WatchEventJSON {
+	before := make(map[string]SessionJSON, len(prev))
+	for _, s := range prev {
+		before[s.ID] = s
+	}
+	after := make(map[string]bool, len(cur))
+	for _, s := range cur {
+		after[s.ID] = true
+	}
+
+	var events []WatchEventJSON
+	for _, s := range prev {
+		if !after[s.ID] {
+			events = append(events, WatchEventJSON{Type: watchRemoved, Session: &s})
+		}
+	}
+	for _, s := range cur {
+		old, ok := before[s.ID]
+		if !ok {
+			events = append(events, WatchEventJSON{Type: watchAdded, Session: &s})
+			continue
+		}
+		var change WatchChangeJSON
+		if old.Status != s.Status {
+			change.Status = old.Status
+		}
+		if old.Title != s.Title {
+			change.Title = old.Title
+		}
+		if old.Group != s.Group {
+			change.Group = old.Group
+		}
+		if change != (WatchChangeJSON{}) {
+			events = append(events, WatchEventJSON{Type: watchChanged, Session: &s, Previous: &change})
+		}
+	}
+	return events
+}
+
+// formatWatchSummary expands the {running}, {waiting}, {idle}, {error},
+// {exited} and {total} placeholders of a --format template
+func formatWatchSummary(format string, c StatusJSON) string {
+	return strings.NewReplacer(
+		"{running}", strconv.Itoa(c.Running),
+		"{waiting}", strconv.Itoa(c.Waiting),
+		"{idle}", strconv.Itoa(c.Idle),
+		"{error}", strconv.Itoa(c.Error),
+		"{exited}", strconv.Itoa(c.Exited),
+		"{total}", strconv.Itoa(c.Total),
+	).Replace(format)
+}
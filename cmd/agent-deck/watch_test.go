@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDiffWatch(t *testing.T) {
+	prev := []SessionJSON{
+		{ID: "a", Title: "api", Group: "work", Status: "running"},
+		{ID: "b", Title: "web", Group: "work", Status: "idle"},
+		{ID: "c", Title: "docs", Group: "work", Status: "idle"},
+	}
+	cur := []SessionJSON{
+		{ID: "a", Title: "api", Group: "work", Status: "waiting"},
+		{ID: "b", Title: "frontend", Group: "work/ui", Status: "idle"},
+		{ID: "d", Title: "new", Group: "work", Status: "running"},
+	}
+
+	events := diffWatch(prev, cur)
+	want := []struct {
+		typ, id  string
+		previous WatchChangeJSON
+	}{
+		{watchRemoved, "c", WatchChangeJSON{}},
+		{watchChanged, "a", WatchChangeJSON{Status: "running"}},
+		{watchChanged, "b", WatchChangeJSON{Title: "web", Group: "work"}},
+		{watchAdded, "d", WatchChangeJSON{}},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Type != w.typ || e.Session == nil || e.Session.ID != w.id {
+			t.Errorf("event %d = %s %+v, want %s %s", i, e.Type, e.Session, w.typ, w.id)
+			continue
+		}
+		var got WatchChangeJSON
+		if e.Previous != nil {
+			got = *e.Previous
+		}
+		if got != w.previous {
+			t.Errorf("event %d previous = %+v, want %+v", i, got, w.previous)
+		}
+	}
+
+	if events := diffWatch(cur, cur); len(events) != 0 {
+		t.Errorf("diffWatch of unchanged sessions = %+v, want none", events)
+	}
+}
+
+func TestFormatWatchSummary(t *testing.T) {
+	counts := StatusJSON{Running: 3, Waiting: 2, Idle: 5, Error: 1, Exited: 0, Total: 11}
+	tests := []struct {
+		format, want string
+	}{
+		{"●{running} ◐{waiting} ○{idle}", "●3 ◐2 ○5"},
+		{"{error} errors, {exited} exited of {total}", "1 errors, 0 exited of 11"},
+		{"{unknown} {running}", "{unknown} 3"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := formatWatchSummary(tt.format, counts); got != tt.want {
+			t.Errorf("formatWatchSummary(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package daemon runs agent-deck headless: one process per profile that owns
+// the status loop, automatic restarts, scheduled prompts, the MCP pool and
+// log maintenance, and serves a local HTTP/JSON API on a Unix socket. It
+// takes the lock a standalone TUI holds; TUIs and CLI commands started while
+// it runs act as its clients.
+//
+// API (all bodies JSON; errors are {"error": {"code", "message"}}):
+//
+//	GET  /v1/ping                       daemon info
+//	GET  /v1/status                     session counts by status
+//	GET  /v1/sessions                   list sessions
+//	POST /v1/sessions                   create a session (CreateRequest)
+//	GET  /v1/sessions/{id}              one session
+//	POST /v1/sessions/{id}/start        start (StartRequest)
+//	POST /v1/sessions/{id}/stop         stop
+//	POST /v1/sessions/{id}/restart      restart
+//	POST /v1/sessions/{id}/send         send a prompt (SendRequest)
+//	GET  /v1/sessions/{id}/output       last agent response
+//	GET  /v1/events                     stream of Events, one JSON object per line
+//	POST /v1/shutdown                   stop the daemon
+package daemon
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+const socketFileName = "daemon.sock"
+
+// Error codes, shared with the CLI's JSON errors
+const (
+	ErrCodeNotFound         = "NOT_FOUND"
+	ErrCodeAlreadyExists    = "ALREADY_EXISTS"
+	ErrCodeInvalidOperation = "INVALID_OPERATION"
+	ErrCodeInternal         = "INTERNAL"
+)
+
+// SocketPath returns the daemon's socket for a profile ("" for the
+// effective default profile)
+func SocketPath(profile string) (string, error) {
+	dir, err := session.GetProfileDir(session.GetEffectiveProfile(profile))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, socketFileName), nil
+}
+
+// Info describes a running daemon
+type Info struct {
+	Profile   string    `json:"profile"`
+	PID       int       `json:"pid"`
+	Version   string    `json:"version,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Sessions  int       `json:"sessions"`
+}
+
+// Session is a session as reported by the daemon
+type Session struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Path        string    `json:"path"`
+	Group       string    `json:"group"`
+	Tool        string    `json:"tool"`
+	Command     string    `json:"command,omitempty"`
+	Status      string    `json:"status"`
+	TmuxSession string    `json:"tmux_session,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StatusCounts are session counts by status
+type StatusCounts struct {
+	Running int `json:"running"`
+	Waiting int `json:"waiting"`
+	Idle    int `json:"idle"`
+	Error   int `json:"error"`
+	Exited  int `json:"exited"`
+	Total   int `json:"total"`
+}
+
+// CreateRequest creates a session; Command picks the tool like `agent-deck add -c`
+type CreateRequest struct {
+	Title   string `json:"title"`
+	Path    string `json:"path"`
+	Group   string `json:"group,omitempty"`
+	Command string `json:"command,omitempty"`
+	Start   bool   `json:"start,omitempty"`
+	Message string `json:"message,omitempty"` // Sent once started and ready
+}
+
+// StartRequest starts a session, optionally with an initial message
+type StartRequest struct {
+	Message string `json:"message,omitempty"`
+}
+
+// SendRequest sends a prompt like `agent-deck session send`
+type SendRequest struct {
+	Message string   `json:"message,omitempty"`
+	Keys    []string `json:"keys,omitempty"`    // tmux key names pressed before the message
+	NoWait  bool     `json:"no_wait,omitempty"` // Don't wait for the agent to be ready
+}
+
+// Output is a session's last agent response
+type Output struct {
+	SessionID      string `json:"session_id"`
+	Tool           string `json:"tool"`
+	Role           string `json:"role"`
+	Content        string `json:"content"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	ConversationID string `json:"conversation_id,omitempty"` // The agent's own session ID, if known
+}
+
+// Event types
+const (
+	EventStatus  = "status"  // A session's status changed
+	EventAdded   = "added"   // A session appeared (created or loaded)
+	EventRemoved = "removed" // A session disappeared
+)
+
+// Event is one entry of the /v1/events stream
+type Event struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status,omitempty"`
+	Previous  string    `json:"previous,omitempty"` // Status before a status event
+}
+
+// APIError is the error body of a failed request
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// errorBody wraps an APIError as {"error": {...}}
+type errorBody struct {
+	Error *APIError `json:"error"`
+}
+
+// sessionInfo converts an instance for the API
+func sessionInfo(inst *session.Instance) Session {
+	s := Session{
+		ID:        inst.ID,
+		Title:     inst.Title,
+		Path:      inst.ProjectPath,
+		Group:     inst.GroupPath,
+		Tool:      inst.Tool,
+		Command:   inst.Command,
+		Status:    string(inst.Status),
+		CreatedAt: inst.CreatedAt,
+	}
+	if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
+		s.TmuxSession = tmuxSess.Name
+	}
+	return s
+}
@@ -0,0 +1,243 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// ErrNotRunning is returned by Connect when no daemon serves the profile
+var ErrNotRunning = errors.New("agent-deck daemon is not running")
+
+// Client talks to a profile's daemon over its Unix socket
+type Client struct {
+	http   *http.Client
+	stream *http.Client // No timeout, for /v1/events and prompts that wait for the agent
+}
+
+// Connect returns a client for the profile's daemon, or ErrNotRunning
+func Connect(profile string) (*Client, error) {
+	socketPath, err := SocketPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(socketPath); err != nil {
+		return nil, ErrNotRunning
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	c := &Client{
+		http:   &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		stream: &http.Client{Transport: transport},
+	}
+	// A daemon that doesn't answer quickly is treated as not running
+	probe := &http.Client{Transport: transport, Timeout: 2 * time.Second}
+	if err := c.do(probe, http.MethodGet, "/v1/ping", nil, nil); err != nil {
+		return nil, ErrNotRunning
+	}
+	return c, nil
+}
+
+// Running returns true if a daemon serves the profile
+func Running(profile string) bool {
+	_, err := Connect(profile)
+	return err == nil
+}
+
+// do sends a request and decodes the JSON response into out (if not nil)
+func (c *Client) do(hc *http.Client, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, "http://agent-deck"+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("daemon request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var eb errorBody
+		if err := json.NewDecoder(resp.Body).Decode(&eb); err != nil || eb.Error == nil {
+			return &APIError{Code: ErrCodeInternal, Message: fmt.Sprintf("daemon returned %s", resp.Status)}
+		}
+		return eb.Error
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// sessionPath returns the API path of a session action
+func sessionPath(id, action string) string {
+	p := "/v1/sessions/" + url.PathEscape(id)
+	if action != "" {
+		p += "/" + action
+	}
+	return p
+}
+
+// Ping returns information about the daemon
+func (c *Client) Ping() (*Info, error) {
+	var info Info
+	if err := c.do(c.http, http.MethodGet, "/v1/ping", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Status returns session counts by status
+func (c *Client) Status() (*StatusCounts, error) {
+	var counts StatusCounts
+	if err := c.do(c.http, http.MethodGet, "/v1/status", nil, &counts); err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// Sessions lists the sessions with their live status
+func (c *Client) Sessions() ([]Session, error) {
+	var list []Session
+	if err := c.do(c.http, http.MethodGet, "/v1/sessions", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ApplyStatuses sets the statuses the daemon tracks on the sessions it knows
+func (c *Client) ApplyStatuses(instances []*session.Instance) error {
+	list, err := c.Sessions()
+	if err != nil {
+		return err
+	}
+	statuses := make(map[string]session.Status, len(list))
+	for _, s := range list {
+		statuses[s.ID] = session.Status(s.Status)
+	}
+	for _, inst := range instances {
+		if status, ok := statuses[inst.ID]; ok {
+			inst.Status = status
+		}
+	}
+	return nil
+}
+
+// Session returns one session
+func (c *Client) Session(id string) (*Session, error) {
+	var s Session
+	if err := c.do(c.http, http.MethodGet, sessionPath(id, ""), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Create creates (and with req.Start, starts) a session
+func (c *Client) Create(req CreateRequest) (*Session, error) {
+	var s Session
+	if err := c.do(c.http, http.MethodPost, "/v1/sessions", req, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Start starts a session; message is sent once the agent is ready
+func (c *Client) Start(id, message string) (*Session, error) {
+	var s Session
+	if err := c.do(c.http, http.MethodPost, sessionPath(id, "start"), StartRequest{Message: message}, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Stop stops a session
+func (c *Client) Stop(id string) (*Session, error) {
+	var s Session
+	if err := c.do(c.http, http.MethodPost, sessionPath(id, "stop"), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Restart restarts a session
+func (c *Client) Restart(id string) (*Session, error) {
+	var s Session
+	if err := c.do(c.http, http.MethodPost, sessionPath(id, "restart"), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Send presses req.Keys, then sends req.Message, waiting for the agent to be
+// ready unless req.NoWait
+func (c *Client) Send(id string, req SendRequest) error {
+	return c.do(c.stream, http.MethodPost, sessionPath(id, "send"), req, nil)
+}
+
+// Output returns the session's last agent response
+func (c *Client) Output(id string) (*Output, error) {
+	var out Output
+	if err := c.do(c.http, http.MethodGet, sessionPath(id, "output"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Subscribe calls fn for each event until ctx is cancelled or the daemon
+// goes away
+func (c *Client) Subscribe(ctx context.Context, fn func(Event)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://agent-deck/v1/events", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return fmt.Errorf("daemon request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		fn(ev)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// Shutdown stops the daemon
+func (c *Client) Shutdown() error {
+	return c.do(c.http, http.MethodPost, "/v1/shutdown", nil, nil)
+}
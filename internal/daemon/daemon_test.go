@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/scheduler"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// startTestServer runs a daemon for the _test profile under a temporary HOME
+func startTestServer(t *testing.T) (*Client, <-chan error) {
+	t.Setenv("HOME", t.TempDir())
+
+	srv, err := NewServer("_test", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(context.Background()) }()
+	t.Cleanup(srv.Stop)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client, err := Connect("_test")
+		if err == nil {
+			return client, done
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon did not come up: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestConnectNotRunning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := Connect("_test"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Connect() error = %v, want ErrNotRunning", err)
+	}
+	if Running("_test") {
+		t.Error("Running() = true without a daemon")
+	}
+}
+
+func TestDaemonAPI(t *testing.T) {
+	client, done := startTestServer(t)
+
+	info, err := client.Ping()
+	if err != nil || info.Profile != "_test" || info.Sessions != 0 {
+		t.Fatalf("Ping() = %+v, %v; want _test profile with no sessions", info, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan Event, 16)
+	go func() { _ = client.Subscribe(ctx, func(ev Event) { events <- ev }) }()
+	time.Sleep(100 * time.Millisecond) // Let the subscription register
+
+	dir := t.TempDir()
+	created, err := client.Create(CreateRequest{Title: "api-test", Path: dir})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if created.Title != "api-test" || created.Path != dir || created.Tool != "shell" {
+		t.Errorf("Create() = %+v, want shell session api-test at %s", created, dir)
+	}
+	select {
+	case ev := <-events:
+		if ev.Type != EventAdded || ev.SessionID != created.ID {
+			t.Errorf("event = %+v, want added %s", ev, created.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no event for the created session")
+	}
+
+	// A second session for the same path is refused
+	var apiErr *APIError
+	if _, err := client.Create(CreateRequest{Path: dir}); !errors.As(err, &apiErr) || apiErr.Code != ErrCodeAlreadyExists {
+		t.Errorf("duplicate Create() error = %v, want %s", err, ErrCodeAlreadyExists)
+	}
+
+	list, err := client.Sessions()
+	if err != nil || len(list) != 1 || list[0].ID != created.ID {
+		t.Errorf("Sessions() = %+v, %v; want the created session", list, err)
+	}
+	counts, err := client.Status()
+	if err != nil || counts.Total != 1 {
+		t.Errorf("Status() = %+v, %v; want 1 session", counts, err)
+	}
+
+	if _, err := client.Session("nope"); !errors.As(err, &apiErr) || apiErr.Code != ErrCodeNotFound {
+		t.Errorf("Session(unknown) error = %v, want %s", err, ErrCodeNotFound)
+	}
+	if _, err := client.Stop(created.ID); !errors.As(err, &apiErr) || apiErr.Code != ErrCodeInvalidOperation {
+		t.Errorf("Stop(not running) error = %v, want %s", err, ErrCodeInvalidOperation)
+	}
+
+	if err := client.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+	if Running("_test") {
+		t.Error("Running() = true after shutdown")
+	}
+}
+
+// A scheduled prompt restarts and sends to its session under the session's
+// lock, so status ticks running meanwhile don't race it (run with -race)
+func TestScheduleDeliveryWithStatusTicks(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	srv, err := NewServer("_test", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst := session.NewInstance("schedule-race", t.TempDir())
+	if err := inst.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer func() { _ = inst.Kill() }()
+	srv.instances = append(srv.instances, inst)
+
+	job := &scheduler.Job{SessionID: inst.ID, Message: "echo scheduled", At: time.Now().Add(-time.Second), Restart: true}
+	if err := srv.schedules.Add(job); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.schedulesRunning.Store(true)
+	done := make(chan struct{})
+	go func() {
+		srv.runSchedules()
+		close(done)
+	}()
+	for ticking := true; ticking; {
+		select {
+		case <-done:
+			ticking = false
+		case <-time.After(50 * time.Millisecond):
+			srv.updateStatuses()
+		}
+	}
+
+	// A delivered one-shot job is removed; a failed one stays with its error
+	if jobs, err := srv.schedules.List(); err != nil || len(jobs) != 0 {
+		t.Errorf("List() = %+v, %v; want the job delivered", jobs, err)
+	}
+}
@@ -0,0 +1,744 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/mailbox"
+	"github.com/asheshgoplani/agent-deck/internal/scheduler"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+const (
+	statusInterval         = 2 * time.Second
+	logCheckInterval       = 10 * time.Second
+	logMaintenanceInterval = 5 * time.Minute
+
+	// subscriberBuffer is how many events a slow /v1/events client may lag
+	// behind before events are dropped for it
+	subscriberBuffer = 256
+)
+
+// Server is the daemon for one profile
+type Server struct {
+	profile   string
+	version   string
+	storage   *session.Storage
+	watcher   *session.StorageWatcher
+	schedules *scheduler.Store
+	mailbox   *mailbox.Store
+	startedAt time.Time
+
+	mu         sync.Mutex // Guards instances, groups and lastStatus
+	instances  []*session.Instance
+	groups     []*session.GroupData
+	lastStatus map[string]session.Status
+
+	// opMu serializes work on one session: an API request and the status
+	// loop's restart must not run on the same session at once
+	opMuMu sync.Mutex
+	opMu   map[string]*sync.Mutex
+
+	subsMu sync.Mutex
+	subs   map[chan Event]struct{}
+
+	schedulesRunning atomic.Bool
+	mailboxRunning   atomic.Bool
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewServer loads the profile's sessions for a new daemon
+func NewServer(profile, version string) (*Server, error) {
+	storage, err := session.NewStorageWithProfile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	instances, groups, err := storage.LoadWithGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	s := &Server{
+		profile:    storage.Profile(),
+		version:    version,
+		storage:    storage,
+		startedAt:  time.Now(),
+		instances:  instances,
+		groups:     groups,
+		lastStatus: make(map[string]session.Status, len(instances)),
+		opMu:       make(map[string]*sync.Mutex),
+		subs:       make(map[chan Event]struct{}),
+		stopCh:     make(chan struct{}),
+	}
+	for _, inst := range instances {
+		s.lastStatus[inst.ID] = inst.Status
+	}
+	if store, err := scheduler.NewStore(s.profile); err == nil {
+		s.schedules = store
+	} else {
+		log.Printf("[DAEMON] scheduled prompts disabled: %v", err)
+	}
+	if store, err := mailbox.NewStore(s.profile); err == nil {
+		s.mailbox = store
+	} else {
+		log.Printf("[DAEMON] session messages disabled: %v", err)
+	}
+	return s, nil
+}
+
+// Profile returns the profile the daemon serves
+func (s *Server) Profile() string {
+	return s.profile
+}
+
+// Stop asks Run to shut down
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+// Run serves the API on the profile's socket and runs the background loops
+// until ctx is cancelled or Stop is called
+func (s *Server) Run(ctx context.Context) error {
+	socketPath, err := SocketPath(s.profile)
+	if err != nil {
+		return err
+	}
+	listener, err := listen(socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+
+	// Pool sockets are shared: TUIs started later discover and reuse them
+	if userConfig, err := session.LoadUserConfig(); err == nil && userConfig != nil && userConfig.MCPPool.Enabled {
+		s.mu.Lock()
+		instances := append([]*session.Instance(nil), s.instances...)
+		s.mu.Unlock()
+		if pool, err := session.InitializeGlobalPool(ctx, userConfig, instances); err != nil {
+			log.Printf("[DAEMON] failed to initialize MCP pool: %v", err)
+		} else if pool != nil {
+			log.Printf("[DAEMON] MCP pool initialized (%d proxies)", len(pool.ListServers()))
+		}
+	}
+	defer func() {
+		if err := session.ShutdownGlobalPool(); err != nil {
+			log.Printf("[DAEMON] error shutting down MCP pool: %v", err)
+		}
+	}()
+
+	// Reload when CLI commands change sessions.json (the watcher needs the file)
+	if _, err := os.Stat(s.storage.Path()); os.IsNotExist(err) {
+		s.save()
+	}
+	if _, err := os.Stat(s.storage.Path()); err == nil {
+		if watcher, err := session.NewStorageWatcher(s.storage.Path()); err == nil {
+			s.watcher = watcher
+			watcher.Start()
+			defer watcher.Close()
+		} else {
+			log.Printf("[DAEMON] storage watcher unavailable: %v", err)
+		}
+	}
+
+	srv := &http.Server{Handler: s.routes()}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(listener) }()
+
+	err = s.loop(ctx, serveErr)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx) // Ends /v1/events streams too
+	s.save()
+	return err
+}
+
+// listen creates the socket, replacing a stale one left by a crashed daemon
+func listen(socketPath string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if _, err := os.Stat(socketPath); err == nil {
+		if conn, err := net.DialTimeout("unix", socketPath, 500*time.Millisecond); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a daemon is already running (socket %s)", socketPath)
+		}
+		os.Remove(socketPath)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// loop runs status updates, restarts, schedules, message delivery and log
+// maintenance
+func (s *Server) loop(ctx context.Context, serveErr <-chan error) error {
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+
+	var reloadCh <-chan struct{}
+	if s.watcher != nil {
+		reloadCh = s.watcher.ReloadChannel()
+	}
+
+	lastLogCheck, lastMaintenance, lastSchedule, lastMailbox := time.Now(), time.Now(), time.Time{}, time.Time{}
+	s.updateStatuses()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stopCh:
+			return nil
+		case err := <-serveErr:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		case <-reloadCh:
+			s.reload()
+		case <-ticker.C:
+			s.updateStatuses()
+
+			if time.Since(lastLogCheck) >= logCheckInterval {
+				lastLogCheck = time.Now()
+				go func() {
+					logSettings := session.GetLogSettings()
+					_, _ = tmux.TruncateLargeLogFiles(logSettings.MaxSizeMB, logSettings.MaxLines)
+				}()
+			}
+			if time.Since(lastMaintenance) >= logMaintenanceInterval {
+				lastMaintenance = time.Now()
+				go func() {
+					logSettings := session.GetLogSettings()
+					tmux.RunLogMaintenance(logSettings.MaxSizeMB, logSettings.MaxLines, logSettings.RemoveOrphans)
+				}()
+			}
+			if s.schedules != nil && time.Since(lastSchedule) >= scheduler.CheckInterval && s.schedulesRunning.CompareAndSwap(false, true) {
+				lastSchedule = time.Now()
+				go s.runSchedules()
+			}
+			if s.mailbox != nil && time.Since(lastMailbox) >= mailbox.CheckInterval && s.mailboxRunning.CompareAndSwap(false, true) {
+				lastMailbox = time.Now()
+				go s.deliverMessages()
+			}
+		}
+	}
+}
+
+// snapshot returns a copy of the session list
+func (s *Server) snapshot() []*session.Instance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*session.Instance(nil), s.instances...)
+}
+
+// find returns the session with the given ID
+func (s *Server) find(id string) *session.Instance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, inst := range s.instances {
+		if inst.ID == id {
+			return inst
+		}
+	}
+	return nil
+}
+
+// sessionLock returns the lock serializing work on a session
+func (s *Server) sessionLock(id string) *sync.Mutex {
+	s.opMuMu.Lock()
+	defer s.opMuMu.Unlock()
+	m, ok := s.opMu[id]
+	if !ok {
+		m = &sync.Mutex{}
+		s.opMu[id] = m
+	}
+	return m
+}
+
+// lockedFinder returns a Finder for the schedule and mailbox runners that
+// holds the session's lock until the delivery is released. Without wait, a
+// session busy with other work is reported as not found: it isn't ready for
+// a message anyway.
+func (s *Server) lockedFinder(wait bool) session.Finder {
+	return func(id string) (*session.Instance, func()) {
+		inst := s.find(id)
+		if inst == nil {
+			return nil, func() {}
+		}
+		m := s.sessionLock(id)
+		if wait {
+			m.Lock()
+		} else if !m.TryLock() {
+			return nil, func() {}
+		}
+		return inst, m.Unlock
+	}
+}
+
+// updateStatuses refreshes every session's status, publishes changes and
+// starts the restarts restart policies ask for. Sessions busy with an API
+// request (a send can wait for the agent) are left for the next tick.
+func (s *Server) updateStatuses() {
+	tmux.RefreshExistingSessions()
+
+	var due []*session.Instance
+	for _, inst := range s.snapshot() {
+		m := s.sessionLock(inst.ID)
+		if !m.TryLock() {
+			continue
+		}
+		_ = inst.UpdateStatus()
+		if inst.DueRestart() {
+			due = append(due, inst)
+		}
+		m.Unlock()
+		s.publishStatus(inst)
+	}
+	for _, inst := range due {
+		go s.autoRestart(inst)
+	}
+}
+
+// autoRestart restarts a session DueRestart asked for, off the loop goroutine
+// (a restart waits for tmux) and under the session's lock
+func (s *Server) autoRestart(inst *session.Instance) {
+	m := s.sessionLock(inst.ID)
+	m.Lock()
+	err := inst.Restart()
+	inst.RestartDone(err) // Logged by the session
+	m.Unlock()
+	if err == nil {
+		s.save()
+		s.publishStatus(inst)
+	}
+}
+
+// publishStatus sends a status event if the session's status changed
+func (s *Server) publishStatus(inst *session.Instance) {
+	s.mu.Lock()
+	prev, known := s.lastStatus[inst.ID]
+	s.lastStatus[inst.ID] = inst.Status
+	s.mu.Unlock()
+	if known && prev == inst.Status {
+		return
+	}
+	s.publish(Event{
+		Type:      EventStatus,
+		SessionID: inst.ID,
+		Title:     inst.Title,
+		Status:    string(inst.Status),
+		Previous:  string(prev),
+	})
+}
+
+// reload replaces the sessions with those in sessions.json, keeping the
+// exit and restart tracking of sessions that are still there
+func (s *Server) reload() {
+	instances, groups, err := s.storage.LoadWithGroups()
+	if err != nil {
+		log.Printf("[DAEMON] reload failed: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	old := make(map[string]*session.Instance, len(s.instances))
+	for _, inst := range s.instances {
+		old[inst.ID] = inst
+	}
+	s.instances, s.groups = instances, groups
+	s.mu.Unlock()
+
+	for _, inst := range instances {
+		if prev, ok := old[inst.ID]; ok {
+			inst.CarryRuntimeState(prev)
+			delete(old, inst.ID)
+			continue
+		}
+		s.publish(Event{Type: EventAdded, SessionID: inst.ID, Title: inst.Title, Status: string(inst.Status)})
+	}
+	for id, inst := range old {
+		s.mu.Lock()
+		delete(s.lastStatus, id)
+		s.mu.Unlock()
+		s.opMuMu.Lock()
+		delete(s.opMu, id)
+		s.opMuMu.Unlock()
+		s.publish(Event{Type: EventRemoved, SessionID: id, Title: inst.Title})
+	}
+	log.Printf("[DAEMON] reloaded %d sessions", len(instances))
+}
+
+// save writes the sessions to sessions.json
+func (s *Server) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.watcher != nil {
+		s.watcher.NotifySave()
+	}
+	groupTree := session.NewGroupTreeWithGroups(s.instances, s.groups)
+	if err := s.storage.SaveWithGroups(s.instances, groupTree); err != nil {
+		log.Printf("[DAEMON] failed to save sessions: %v", err)
+	}
+}
+
+// runSchedules delivers the scheduled prompts that are due
+func (s *Server) runSchedules() {
+	defer s.schedulesRunning.Store(false)
+	results, err := scheduler.RunDue(s.schedules, time.Now(), s.lockedFinder(true))
+	if err != nil {
+		log.Printf("[DAEMON] scheduled prompts: %v", err)
+		return
+	}
+	for _, r := range results {
+		if r.Started {
+			s.save()
+			return
+		}
+	}
+}
+
+// deliverMessages injects messages into sessions that are waiting
+func (s *Server) deliverMessages() {
+	defer s.mailboxRunning.Store(false)
+	if _, err := mailbox.DeliverReady(s.mailbox, s.lockedFinder(false)); err != nil {
+		log.Printf("[DAEMON] session messages: %v", err)
+	}
+}
+
+// subscribe registers a channel for events
+func (s *Server) subscribe() chan Event {
+	ch := make(chan Event, subscriberBuffer)
+	s.subsMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subsMu.Unlock()
+	return ch
+}
+
+// unsubscribe removes a channel registered by subscribe
+func (s *Server) unsubscribe(ch chan Event) {
+	s.subsMu.Lock()
+	delete(s.subs, ch)
+	s.subsMu.Unlock()
+}
+
+// publish sends an event to all subscribers, dropping it for those too far behind
+func (s *Server) publish(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+			log.Printf("[DAEMON] event subscriber is behind, dropping %s event", ev.Type)
+		}
+	}
+}
+
+// routes builds the API handler
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/ping", s.handlePing)
+	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/sessions", s.handleList)
+	mux.HandleFunc("POST /v1/sessions", s.handleCreate)
+	mux.HandleFunc("GET /v1/sessions/{id}", s.withSession(s.handleGet))
+	mux.HandleFunc("POST /v1/sessions/{id}/start", s.withSession(s.locked(s.handleStart)))
+	mux.HandleFunc("POST /v1/sessions/{id}/stop", s.withSession(s.locked(s.handleStop)))
+	mux.HandleFunc("POST /v1/sessions/{id}/restart", s.withSession(s.locked(s.handleRestart)))
+	mux.HandleFunc("POST /v1/sessions/{id}/send", s.withSession(s.locked(s.handleSend)))
+	mux.HandleFunc("GET /v1/sessions/{id}/output", s.withSession(s.handleOutput))
+	mux.HandleFunc("GET /v1/events", s.handleEvents)
+	mux.HandleFunc("POST /v1/shutdown", s.handleShutdown)
+	return mux
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an {"error": {...}} response
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorBody{Error: &APIError{Code: code, Message: message}})
+}
+
+// withSession resolves the {id} path value to a session
+func (s *Server) withSession(h func(http.ResponseWriter, *http.Request, *session.Instance)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		inst := s.find(id)
+		if inst == nil {
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("session not found: %s", id))
+			return
+		}
+		h(w, r, inst)
+	}
+}
+
+// locked runs a handler that changes a session with the session's lock held
+func (s *Server) locked(h func(http.ResponseWriter, *http.Request, *session.Instance)) func(http.ResponseWriter, *http.Request, *session.Instance) {
+	return func(w http.ResponseWriter, r *http.Request, inst *session.Instance) {
+		m := s.sessionLock(inst.ID)
+		m.Lock()
+		defer m.Unlock()
+		h(w, r, inst)
+	}
+}
+
+// decode reads a JSON request body into v (an empty body is allowed)
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidOperation, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	count := len(s.instances)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, Info{
+		Profile:   s.profile,
+		PID:       os.Getpid(),
+		Version:   s.version,
+		StartedAt: s.startedAt,
+		Sessions:  count,
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	var counts StatusCounts
+	for _, inst := range s.snapshot() {
+		switch inst.Status {
+		case session.StatusRunning:
+			counts.Running++
+		case session.StatusWaiting:
+			counts.Waiting++
+		case session.StatusIdle:
+			counts.Idle++
+		case session.StatusError:
+			counts.Error++
+		case session.StatusExited:
+			counts.Exited++
+		}
+		counts.Total++
+	}
+	writeJSON(w, http.StatusOK, counts)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	instances := s.snapshot()
+	list := make([]Session, 0, len(instances))
+	for _, inst := range instances {
+		list = append(list, sessionInfo(inst))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request, inst *session.Instance) {
+	writeJSON(w, http.StatusOK, sessionInfo(inst))
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Path == "" || !filepath.IsAbs(req.Path) {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidOperation, "path must be an absolute path")
+		return
+	}
+	if req.Title == "" {
+		req.Title = filepath.Base(req.Path)
+	}
+
+	var inst *session.Instance
+	if req.Group != "" {
+		inst = session.NewInstanceWithGroup(req.Title, req.Path, req.Group)
+	} else {
+		inst = session.NewInstance(req.Title, req.Path)
+	}
+	if req.Command != "" {
+		inst.Command = req.Command
+		if tool, cmd, ok := session.ResolveCustomTool(req.Command); ok {
+			inst.Tool = tool
+			inst.Command = cmd
+		} else {
+			inst.Tool = session.DetectTool(req.Command)
+		}
+	}
+
+	s.mu.Lock()
+	for _, existing := range s.instances {
+		if existing.ProjectPath == req.Path && existing.Host == "" {
+			s.mu.Unlock()
+			writeError(w, http.StatusConflict, ErrCodeAlreadyExists,
+				fmt.Sprintf("session already exists: %s (%s)", existing.Title, existing.ID))
+			return
+		}
+	}
+	// Locked before the status loop can see it
+	m := s.sessionLock(inst.ID)
+	m.Lock()
+	defer m.Unlock()
+	s.instances = append(s.instances, inst)
+	s.mu.Unlock()
+
+	if req.Start {
+		if _, err := inst.StartPending(req.Message); err != nil {
+			s.save()
+			writeError(w, http.StatusInternalServerError, ErrCodeInvalidOperation, fmt.Sprintf("failed to start session: %v", err))
+			return
+		}
+	}
+	s.save()
+	s.publish(Event{Type: EventAdded, SessionID: inst.ID, Title: inst.Title, Status: string(inst.Status)})
+	writeJSON(w, http.StatusCreated, sessionInfo(inst))
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request, inst *session.Instance) {
+	var req StartRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if inst.Exists() {
+		writeError(w, http.StatusConflict, ErrCodeInvalidOperation, fmt.Sprintf("session '%s' is already running", inst.Title))
+		return
+	}
+	if _, err := inst.StartPending(req.Message); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInvalidOperation, fmt.Sprintf("failed to start session: %v", err))
+		return
+	}
+	s.save()
+	s.publishStatus(inst)
+	writeJSON(w, http.StatusOK, sessionInfo(inst))
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request, inst *session.Instance) {
+	if !inst.Exists() {
+		writeError(w, http.StatusConflict, ErrCodeInvalidOperation, fmt.Sprintf("session '%s' is not running", inst.Title))
+		return
+	}
+	if err := inst.Kill(); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInvalidOperation, fmt.Sprintf("failed to stop session: %v", err))
+		return
+	}
+	s.save()
+	s.publishStatus(inst)
+	writeJSON(w, http.StatusOK, sessionInfo(inst))
+}
+
+func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request, inst *session.Instance) {
+	if err := inst.Restart(); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInvalidOperation, fmt.Sprintf("failed to restart session: %v", err))
+		return
+	}
+	s.save()
+	s.publishStatus(inst)
+	writeJSON(w, http.StatusOK, sessionInfo(inst))
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request, inst *session.Instance) {
+	var req SendRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Message == "" && len(req.Keys) == 0 {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidOperation, "message is empty")
+		return
+	}
+	if len(req.Keys) > 0 {
+		if err := inst.SendKeys(req.Keys); err != nil {
+			writeError(w, http.StatusConflict, ErrCodeInvalidOperation, err.Error())
+			return
+		}
+	}
+	if req.Message != "" {
+		if err := inst.Send(req.Message, req.NoWait); err != nil {
+			writeError(w, http.StatusConflict, ErrCodeInvalidOperation, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, sessionInfo(inst))
+}
+
+func (s *Server) handleOutput(w http.ResponseWriter, r *http.Request, inst *session.Instance) {
+	response, err := inst.GetLastResponse()
+	if err != nil {
+		writeError(w, http.StatusConflict, ErrCodeInvalidOperation, fmt.Sprintf("failed to get response: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, Output{
+		SessionID:      inst.ID,
+		Tool:           response.Tool,
+		Role:           response.Role,
+		Content:        response.Content,
+		Timestamp:      response.Timestamp,
+		ConversationID: response.SessionID,
+	})
+}
+
+// handleEvents streams events as JSON lines until the client disconnects
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "streaming not supported")
+		return
+	}
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.stopCh:
+			return
+		case ev := <-ch:
+			if err := enc.Encode(ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+	s.Stop()
+}
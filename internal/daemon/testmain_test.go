@@ -0,0 +1,12 @@
+package daemon
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Force test profile to prevent production data corruption
+	os.Setenv("AGENTDECK_PROFILE", "_test")
+	os.Exit(m.Run())
+}
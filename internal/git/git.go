@@ -0,0 +1,210 @@
+// Package git wraps the git commands agent-deck needs for worktree-backed sessions.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// run executes git in dir and returns trimmed stdout.
+// On failure the error carries git's stderr, which is usually the useful part.
+func run(dir string, args ...string) (string, error) {
+	out, err := runRaw(dir, nil, nil, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// runRaw executes git in dir with extra environment and optional stdin,
+// returning stdout untouched (patches need their trailing newline)
+func runRaw(dir string, env []string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return out, nil
+}
+
+// IsRepo returns true if path is inside a git working tree
+func IsRepo(path string) bool {
+	out, err := run(path, "rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true"
+}
+
+// RepoRoot returns the top-level directory of the working tree containing path.
+// For a linked worktree this is the worktree itself, not the main checkout.
+func RepoRoot(path string) (string, error) {
+	return run(path, "rev-parse", "--show-toplevel")
+}
+
+// MainRepoRoot returns the main checkout for path, following linked worktrees
+// back to the repository they were created from
+func MainRepoRoot(path string) (string, error) {
+	commonDir, err := run(path, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(path, commonDir)
+	}
+	// The common dir is <main>/.git for a normal repository
+	if filepath.Base(commonDir) == ".git" {
+		return filepath.Dir(commonDir), nil
+	}
+	return RepoRoot(path)
+}
+
+// PathPrefix returns path relative to the top of its working tree
+// ("" at the top, "pkg/api/" in a subdirectory)
+func PathPrefix(path string) (string, error) {
+	return run(path, "rev-parse", "--show-prefix")
+}
+
+// CurrentBranch returns the checked-out branch, or "HEAD" when detached
+func CurrentBranch(path string) (string, error) {
+	return run(path, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// HeadCommit returns the full hash of HEAD
+func HeadCommit(path string) (string, error) {
+	return run(path, "rev-parse", "HEAD")
+}
+
+// BranchExists returns true if a local branch with this name exists
+func BranchExists(repo, branch string) bool {
+	_, err := run(repo, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
+// ValidateBranchName checks that name is usable as a new branch name
+func ValidateBranchName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+	if err := exec.Command("git", "check-ref-format", "--branch", name).Run(); err != nil {
+		return fmt.Errorf("invalid branch name '%s'", name)
+	}
+	return nil
+}
+
+// CreateWorktree adds a worktree at path for branch. An existing branch is
+// checked out as-is; otherwise the branch is created from base (HEAD if empty).
+func CreateWorktree(repo, path, branch, base string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("worktree path already exists: %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	args := []string{"worktree", "add"}
+	if BranchExists(repo, branch) {
+		args = append(args, path, branch)
+	} else {
+		args = append(args, "-b", branch, path)
+		if base != "" {
+			args = append(args, base)
+		}
+	}
+	_, err := run(repo, args...)
+	return err
+}
+
+// RemoveWorktree removes a linked worktree. The branch is kept.
+// force discards uncommitted changes in the worktree.
+func RemoveWorktree(repo, path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	_, err := run(repo, append(args, path)...)
+	return err
+}
+
+// PruneWorktrees drops bookkeeping for worktrees whose directories are gone
+func PruneWorktrees(repo string) error {
+	_, err := run(repo, "worktree", "prune")
+	return err
+}
+
+// IsDirty returns true if the working tree has uncommitted or untracked changes
+func IsDirty(path string) (bool, error) {
+	out, err := run(path, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return out != "", nil
+}
+
+// UnmergedCommits counts commits on HEAD that no other local branch or remote
+// branch contains, i.e. work that only exists on this worktree's branch
+func UnmergedCommits(path string) (int, error) {
+	args := []string{"rev-list", "--count", "HEAD", "--not"}
+	if branch, err := CurrentBranch(path); err == nil && branch != "HEAD" {
+		// --exclude applies to the next --branches (names relative to refs/heads),
+		// so HEAD's own branch doesn't count
+		args = append(args, "--exclude="+branch)
+	}
+	args = append(args, "--branches", "--remotes")
+
+	out, err := run(path, args...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// Status summarizes a working tree for display
+type Status struct {
+	Branch   string
+	Dirty    bool
+	Unmerged int // commits not on any other branch
+}
+
+// GetStatus returns the branch, dirty state and unmerged commit count of path
+func GetStatus(path string) (Status, error) {
+	var st Status
+	branch, err := CurrentBranch(path)
+	if err != nil {
+		return st, err
+	}
+	st.Branch = branch
+	if st.Dirty, err = IsDirty(path); err != nil {
+		return st, err
+	}
+	if st.Unmerged, err = UnmergedCommits(path); err != nil {
+		return st, err
+	}
+	return st, nil
+}
+
+// SanitizeBranchName turns a branch name into a single path component:
+// "feature/login" -> "feature-login"
+func SanitizeBranchName(branch string) string {
+	var b strings.Builder
+	for _, r := range branch {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-.")
+}
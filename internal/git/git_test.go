@@ -0,0 +1,157 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initRepo creates a repository with one commit on main
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	return dir
+}
+
+func commitFile(t *testing.T, dir, name string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", name}, {"commit", "-q", "-m", "add " + name}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+}
+
+func TestWorktreeLifecycle(t *testing.T) {
+	repo := initRepo(t)
+	wt := filepath.Join(t.TempDir(), "wt", "feature-x")
+
+	if err := CreateWorktree(repo, wt, "feature/x", ""); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	if !BranchExists(repo, "feature/x") {
+		t.Error("branch feature/x should exist")
+	}
+
+	branch, err := CurrentBranch(wt)
+	if err != nil || branch != "feature/x" {
+		t.Errorf("CurrentBranch = %q, %v; want feature/x", branch, err)
+	}
+
+	main, err := MainRepoRoot(wt)
+	if err != nil {
+		t.Fatalf("MainRepoRoot: %v", err)
+	}
+	wantMain, _ := filepath.EvalSymlinks(repo)
+	gotMain, _ := filepath.EvalSymlinks(main)
+	if gotMain != wantMain {
+		t.Errorf("MainRepoRoot = %s, want %s", gotMain, wantMain)
+	}
+
+	// Fresh branch: clean and nothing unmerged
+	st, err := GetStatus(wt)
+	if err != nil {
+		t.Fatalf("GetStatus: %v", err)
+	}
+	if st.Dirty || st.Unmerged != 0 {
+		t.Errorf("fresh worktree status = %+v, want clean", st)
+	}
+
+	// Untracked file makes it dirty
+	if err := os.WriteFile(filepath.Join(wt, "scratch.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if dirty, _ := IsDirty(wt); !dirty {
+		t.Error("worktree with untracked file should be dirty")
+	}
+
+	// Committing it leaves one commit no other branch has
+	commitFile(t, wt, "scratch.txt")
+	if n, err := UnmergedCommits(wt); err != nil || n != 1 {
+		t.Errorf("UnmergedCommits = %d, %v; want 1", n, err)
+	}
+
+	// Merged into main: nothing unmerged any more
+	if out, err := exec.Command("git", "-C", repo, "merge", "-q", "--ff-only", "feature/x").CombinedOutput(); err != nil {
+		t.Fatalf("merge: %v (%s)", err, out)
+	}
+	if n, _ := UnmergedCommits(wt); n != 0 {
+		t.Errorf("UnmergedCommits after merge = %d, want 0", n)
+	}
+
+	if err := RemoveWorktree(repo, wt, false); err != nil {
+		t.Fatalf("RemoveWorktree: %v", err)
+	}
+	if _, err := os.Stat(wt); !os.IsNotExist(err) {
+		t.Error("worktree directory should be gone")
+	}
+	if !BranchExists(repo, "feature/x") {
+		t.Error("branch should be kept after removing the worktree")
+	}
+}
+
+func TestCreateWorktreeExistingBranch(t *testing.T) {
+	repo := initRepo(t)
+	if out, err := exec.Command("git", "-C", repo, "branch", "existing").CombinedOutput(); err != nil {
+		t.Fatalf("branch: %v (%s)", err, out)
+	}
+
+	wt := filepath.Join(t.TempDir(), "existing")
+	if err := CreateWorktree(repo, wt, "existing", ""); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	if branch, _ := CurrentBranch(wt); branch != "existing" {
+		t.Errorf("CurrentBranch = %q, want existing", branch)
+	}
+
+	// Same path again is rejected before git runs
+	if err := CreateWorktree(repo, wt, "other", ""); err == nil {
+		t.Error("expected error for existing worktree path")
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	tests := map[string]string{
+		"feature/login": "feature-login",
+		"fix-123":       "fix-123",
+		"user/a b":      "user-a-b",
+		"/leading/":     "leading",
+	}
+	for in, want := range tests {
+		if got := SanitizeBranchName(in); got != want {
+			t.Errorf("SanitizeBranchName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValidateBranchName(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	if err := ValidateBranchName("feature/ok"); err != nil {
+		t.Errorf("feature/ok should be valid: %v", err)
+	}
+	for _, bad := range []string{"", "a..b", "has space", "ends.lock"} {
+		if err := ValidateBranchName(bad); err == nil {
+			t.Errorf("%q should be invalid", bad)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SnapshotTree writes the working tree of path (tracked changes and untracked,
+// non-ignored files) to a tree object and returns its hash. Uses a scratch
+// copy of the index so the user's staging area is left alone.
+func SnapshotTree(path string) (string, error) {
+	indexPath, err := run(path, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(path, indexPath)
+	}
+
+	tmp, err := os.CreateTemp("", "agent-deck-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch index: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	// Seeding from the real index keeps `add -A` from rehashing unchanged files
+	if data, err := os.ReadFile(indexPath); err == nil {
+		_, _ = tmp.Write(data)
+	}
+	tmp.Close()
+
+	env := []string{"GIT_INDEX_FILE=" + tmpPath}
+	if _, err := runRaw(path, env, nil, "add", "-A"); err != nil {
+		return "", err
+	}
+	out, err := runRaw(path, env, nil, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// UncommittedPatch returns a binary patch of everything in path's working tree
+// that differs from HEAD, untracked files included. Empty when clean.
+func UncommittedPatch(path string) ([]byte, error) {
+	tree, err := SnapshotTree(path)
+	if err != nil {
+		return nil, err
+	}
+	return runRaw(path, nil, nil, "diff", "--binary", "HEAD", tree)
+}
+
+// ApplyPatch applies a patch from UncommittedPatch to the working tree at path
+// without staging it
+func ApplyPatch(path string, patch []byte) error {
+	if len(patch) == 0 {
+		return nil
+	}
+	_, err := runRaw(path, nil, patch, "apply", "--binary", "--whitespace=nowarn", "-")
+	return err
+}
+
+// DiffWorkingTrees diffs two working trees of the same repository (e.g. the
+// main checkout and a linked worktree), including uncommitted and untracked
+// files. extraArgs are passed to git diff (e.g. "--stat", "--name-status").
+func DiffWorkingTrees(a, b string, extraArgs ...string) (string, error) {
+	commonA, err := run(a, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	commonB, err := run(b, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !sameDir(absFrom(a, commonA), absFrom(b, commonB)) {
+		return "", fmt.Errorf("%s and %s are not worktrees of the same repository", a, b)
+	}
+
+	treeA, err := SnapshotTree(a)
+	if err != nil {
+		return "", err
+	}
+	treeB, err := SnapshotTree(b)
+	if err != nil {
+		return "", err
+	}
+
+	args := append([]string{"diff"}, extraArgs...)
+	out, err := runRaw(a, nil, nil, append(args, treeA, treeB)...)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// absFrom resolves a path git printed relative to dir
+func absFrom(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// sameDir compares directories after resolving symlinks
+func sameDir(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUncommittedPatchRoundTrip(t *testing.T) {
+	repo := initRepo(t)
+	commitFile(t, repo, "tracked.txt")
+
+	// Modified tracked file, staged new file, untracked file
+	if err := os.WriteFile(filepath.Join(repo, "tracked.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "staged.txt"), []byte("staged"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repo, "add", "staged.txt").CombinedOutput(); err != nil {
+		t.Fatalf("add: %v (%s)", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "untracked.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := UncommittedPatch(repo)
+	if err != nil {
+		t.Fatalf("UncommittedPatch: %v", err)
+	}
+
+	// The user's index is untouched: untracked.txt is still untracked
+	status, _ := run(repo, "status", "--porcelain", "untracked.txt")
+	if !strings.HasPrefix(status, "??") {
+		t.Errorf("untracked.txt status = %q, want untracked", status)
+	}
+
+	wt := filepath.Join(t.TempDir(), "copy")
+	if err := CreateWorktree(repo, wt, "copy", ""); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	if err := ApplyPatch(wt, patch); err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+
+	for name, want := range map[string]string{"tracked.txt": "changed", "staged.txt": "staged", "untracked.txt": "new"} {
+		got, err := os.ReadFile(filepath.Join(wt, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	diff, err := DiffWorkingTrees(repo, wt)
+	if err != nil {
+		t.Fatalf("DiffWorkingTrees: %v", err)
+	}
+	if diff != "" {
+		t.Errorf("trees should match after applying the patch, got:\n%s", diff)
+	}
+}
+
+func TestDiffWorkingTrees(t *testing.T) {
+	repo := initRepo(t)
+	wt := filepath.Join(t.TempDir(), "other")
+	if err := CreateWorktree(repo, wt, "other", ""); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "only-in-b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := DiffWorkingTrees(repo, wt, "--name-status")
+	if err != nil {
+		t.Fatalf("DiffWorkingTrees: %v", err)
+	}
+	if strings.TrimSpace(names) != "A\tonly-in-b.txt" {
+		t.Errorf("name-status = %q, want added only-in-b.txt", names)
+	}
+
+	// Unrelated repositories are rejected
+	if _, err := DiffWorkingTrees(repo, initRepo(t)); err == nil {
+		t.Error("expected error for worktrees of different repositories")
+	}
+}
+
+func TestApplyPatchEmpty(t *testing.T) {
+	if err := ApplyPatch(t.TempDir(), nil); err != nil {
+		t.Errorf("empty patch should be a no-op: %v", err)
+	}
+}
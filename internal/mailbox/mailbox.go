@@ -0,0 +1,408 @@
+// Package mailbox lets sessions message each other. Messages are stored per
+// profile; each session has a mailbox of messages addressed to it. Messages
+// are also injected into the recipient's agent once it is waiting for input,
+// by whichever agent-deck process is watching statuses (the TUI or the
+// daemon) or by the sender when the recipient is already waiting.
+package mailbox
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+const storeFileName = "mailbox.json"
+
+// CheckInterval is how often runners deliver messages and refresh unread counts
+const CheckInterval = 3 * time.Second
+
+// keepRead is how many read (or delivered) messages each session's mailbox
+// keeps; older ones are dropped the next time the mailbox is written
+const keepRead = 50
+
+// Message is one message from a session (or the user) to another session
+type Message struct {
+	ID        string `json:"id"`
+	From      string `json:"from,omitempty"` // Sender session ID ("" = sent from outside a session)
+	FromTitle string `json:"from_title,omitempty"`
+	To        string `json:"to"`
+	ToTitle   string `json:"to_title"`
+	Body      string `json:"body"`
+
+	// Inject delivers the message into the recipient's agent when it is
+	// waiting; otherwise it only lands in the mailbox
+	Inject bool `json:"inject"`
+
+	SentAt      time.Time `json:"sent_at"`
+	DeliveredAt time.Time `json:"delivered_at,omitempty"` // Injected into the agent
+	ReadAt      time.Time `json:"read_at,omitempty"`      // Read or delivered
+	LastError   string    `json:"last_error,omitempty"`   // Last failed delivery
+}
+
+// Unread returns true if the message has been neither read nor delivered
+func (m *Message) Unread() bool {
+	return m.ReadAt.IsZero()
+}
+
+// Sender names who sent the message
+func (m *Message) Sender() string {
+	if m.FromTitle != "" {
+		return m.FromTitle
+	}
+	return "the user"
+}
+
+// Prompt is the text injected into the recipient's agent
+func (m *Message) Prompt() string {
+	if m.FromTitle == "" {
+		return fmt.Sprintf("[Message from the user via agent-deck]\n%s", m.Body)
+	}
+	return fmt.Sprintf("[Message from session '%s' via agent-deck]\n%s\n\n(Reply with: agent-deck msg send --to %q \"...\")",
+		m.FromTitle, m.Body, m.FromTitle)
+}
+
+// Store holds the messages of one profile in mailbox.json in the profile directory
+type Store struct {
+	path string
+}
+
+// NewStore returns the mailbox store for a profile
+func NewStore(profile string) (*Store, error) {
+	dir, err := session.GetProfileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: filepath.Join(dir, storeFileName)}, nil
+}
+
+// Send validates a message, gives it an ID and stores it
+func (s *Store) Send(msg *Message) error {
+	if strings.TrimSpace(msg.Body) == "" {
+		return fmt.Errorf("message is empty")
+	}
+	if msg.To == "" {
+		return fmt.Errorf("no recipient")
+	}
+	msg.ID = newMessageID()
+	msg.SentAt = time.Now()
+	return s.update(func(msgs []*Message) []*Message {
+		return append(msgs, msg)
+	})
+}
+
+// Inbox returns the messages to a session, oldest first
+func (s *Store) Inbox(to string) ([]*Message, error) {
+	msgs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var inbox []*Message
+	for _, m := range msgs {
+		if m.To == to {
+			inbox = append(inbox, m)
+		}
+	}
+	sortBySent(inbox)
+	return inbox, nil
+}
+
+// TakeUnread returns the unread messages to a session, oldest first, and
+// marks them read (unless peek is set)
+func (s *Store) TakeUnread(to string, peek bool) ([]*Message, error) {
+	var unread []*Message
+	err := s.update(func(msgs []*Message) []*Message {
+		now := time.Now()
+		for _, m := range msgs {
+			if m.To != to || !m.Unread() {
+				continue
+			}
+			if !peek {
+				m.ReadAt = now
+			}
+			claimed := *m
+			unread = append(unread, &claimed)
+		}
+		return msgs
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortBySent(unread)
+	return unread, nil
+}
+
+// UnreadCounts returns the number of unread messages per recipient session
+func (s *Store) UnreadCounts() (map[string]int, error) {
+	msgs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, m := range msgs {
+		if m.Unread() {
+			counts[m.To]++
+		}
+	}
+	return counts, nil
+}
+
+// ClaimDeliverable returns the unread messages to inject into recipients for
+// which ready is true, and marks them delivered. The caller injects them and
+// reports failures with Failed.
+func (s *Store) ClaimDeliverable(ready func(to string) bool) ([]*Message, error) {
+	var claimed []*Message
+	err := s.update(func(msgs []*Message) []*Message {
+		now := time.Now()
+		for _, m := range msgs {
+			if !m.Inject || !m.Unread() || !ready(m.To) {
+				continue
+			}
+			m.DeliveredAt, m.ReadAt = now, now
+			c := *m
+			claimed = append(claimed, &c)
+		}
+		return msgs
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortBySent(claimed)
+	return claimed, nil
+}
+
+// Failed returns a claimed message to the mailbox after a failed delivery
+func (s *Store) Failed(id string, deliveryErr error) error {
+	return s.update(func(msgs []*Message) []*Message {
+		for _, m := range msgs {
+			if m.ID == id {
+				m.DeliveredAt, m.ReadAt = time.Time{}, time.Time{}
+				m.LastError = deliveryErr.Error()
+			}
+		}
+		return msgs
+	})
+}
+
+// RemoveSession deletes all messages to a session and returns how many there were
+func (s *Store) RemoveSession(sessionID string) (int, error) {
+	count := 0
+	err := s.update(func(msgs []*Message) []*Message {
+		kept := msgs[:0]
+		for _, m := range msgs {
+			if m.To == sessionID {
+				count++
+				continue
+			}
+			kept = append(kept, m)
+		}
+		return kept
+	})
+	return count, err
+}
+
+// Ready reports whether messages can be injected into a session now: an
+// agent (not a plain shell) that is waiting for input
+func Ready(inst *session.Instance) bool {
+	if inst == nil || inst.Tool == "shell" {
+		return false
+	}
+	return inst.Status == session.StatusWaiting || inst.Status == session.StatusIdle
+}
+
+// DeliverReady injects the pending messages of every session found by find
+// that is Ready (statuses must be up to date); several messages to one
+// session go in one prompt. Each session is released once its messages are
+// sent. Returns how many messages were delivered.
+func DeliverReady(store *Store, find session.Finder) (int, error) {
+	targets := make(map[string]*session.Instance)
+	releases := make(map[string]func())
+	defer func() {
+		for _, release := range releases {
+			release()
+		}
+	}()
+	msgs, err := store.ClaimDeliverable(func(to string) bool {
+		if inst, ok := targets[to]; ok {
+			return Ready(inst)
+		}
+		inst, release := find(to)
+		targets[to], releases[to] = inst, release
+		return Ready(inst)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var order []string
+	byRecipient := make(map[string][]*Message)
+	for _, m := range msgs {
+		if _, ok := byRecipient[m.To]; !ok {
+			order = append(order, m.To)
+		}
+		byRecipient[m.To] = append(byRecipient[m.To], m)
+	}
+	for to, release := range releases {
+		if _, ok := byRecipient[to]; !ok {
+			release()
+			delete(releases, to)
+		}
+	}
+
+	delivered := 0
+	for _, to := range order {
+		batch := byRecipient[to]
+		err := deliverBatch(store, targets[to], batch)
+		releases[to]()
+		delete(releases, to)
+		if err == nil {
+			delivered += len(batch)
+		}
+	}
+	return delivered, nil
+}
+
+// deliverBatch sends a recipient's claimed messages as one prompt, returning
+// them to the mailbox if that fails
+func deliverBatch(store *Store, inst *session.Instance, batch []*Message) error {
+	prompts := make([]string, len(batch))
+	for i, m := range batch {
+		prompts[i] = m.Prompt()
+	}
+	// Ready already saw the agent waiting for input, so don't wait again
+	if err := inst.Send(strings.Join(prompts, "\n\n"), true); err != nil {
+		log.Printf("[MAILBOX] delivery to %s failed: %v", batch[0].ToTitle, err)
+		for _, m := range batch {
+			if err := store.Failed(m.ID, err); err != nil {
+				log.Printf("[MAILBOX] failed to record message %s: %v", m.ID, err)
+			}
+		}
+		return err
+	}
+	log.Printf("[MAILBOX] %d message(s) delivered to %s", len(batch), batch[0].ToTitle)
+	return nil
+}
+
+// sortBySent orders messages oldest first
+func sortBySent(msgs []*Message) {
+	sort.SliceStable(msgs, func(a, b int) bool {
+		return msgs[a].SentAt.Before(msgs[b].SentAt)
+	})
+}
+
+// load reads the messages; a missing file means no messages
+func (s *Store) load() ([]*Message, error) {
+	msgs, _, err := s.loadRaw()
+	return msgs, err
+}
+
+// loadRaw is load that also returns the file's contents (nil if missing)
+func (s *Store) loadRaw() ([]*Message, []byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read mailbox: %w", err)
+	}
+	var msgs []*Message
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return msgs, data, nil
+}
+
+// pruneRead drops all but the newest keepRead read messages of each session
+func pruneRead(msgs []*Message) []*Message {
+	read := make(map[string][]*Message)
+	for _, m := range msgs {
+		if !m.Unread() {
+			read[m.To] = append(read[m.To], m)
+		}
+	}
+	drop := make(map[*Message]bool)
+	for _, list := range read {
+		if len(list) <= keepRead {
+			continue
+		}
+		sortBySent(list)
+		for _, m := range list[:len(list)-keepRead] {
+			drop[m] = true
+		}
+	}
+	if len(drop) == 0 {
+		return msgs
+	}
+	kept := msgs[:0]
+	for _, m := range msgs {
+		if !drop[m] {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// update applies fn to the stored messages under an exclusive file lock, so
+// several agent-deck processes never deliver a message twice. The file is
+// only rewritten if fn (or pruning read messages) changed something: the
+// runners call this every few seconds.
+func (s *Store) update(fn func([]*Message) []*Message) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	lock, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mailbox lock: %w", err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock mailbox: %w", err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	msgs, old, err := s.loadRaw()
+	if err != nil {
+		return err
+	}
+	msgs = pruneRead(fn(msgs))
+	if msgs == nil {
+		msgs = []*Message{}
+	}
+
+	data, err := json.MarshalIndent(msgs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode mailbox: %w", err)
+	}
+	if bytes.Equal(data, old) || (old == nil && len(msgs) == 0) {
+		return nil
+	}
+	// Atomic write
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write mailbox: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save mailbox: %w", err)
+	}
+	return nil
+}
+
+// newMessageID returns a short random message ID
+func newMessageID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,179 @@
+package mailbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func newTestStore(t *testing.T) *Store {
+	return &Store{path: filepath.Join(t.TempDir(), storeFileName)}
+}
+
+func TestStoreSendAndRead(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.Send(&Message{To: "s1", Body: "  "}); err == nil {
+		t.Error("Send() with an empty body = nil, want error")
+	}
+	if err := s.Send(&Message{Body: "hi"}); err == nil {
+		t.Error("Send() without a recipient = nil, want error")
+	}
+
+	for _, m := range []*Message{
+		{From: "s2", FromTitle: "dev", To: "s1", ToTitle: "lead", Body: "first"},
+		{To: "s1", ToTitle: "lead", Body: "second"},
+		{To: "s3", ToTitle: "other", Body: "elsewhere"},
+	} {
+		if err := s.Send(m); err != nil {
+			t.Fatalf("Send(): %v", err)
+		}
+		if m.ID == "" || m.SentAt.IsZero() {
+			t.Errorf("Send() didn't set ID and SentAt: %+v", m)
+		}
+	}
+
+	counts, err := s.UnreadCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["s1"] != 2 || counts["s3"] != 1 {
+		t.Errorf("UnreadCounts() = %v", counts)
+	}
+
+	peeked, _ := s.TakeUnread("s1", true)
+	if len(peeked) != 2 {
+		t.Fatalf("TakeUnread(peek) = %d messages, want 2", len(peeked))
+	}
+	read, _ := s.TakeUnread("s1", false)
+	if len(read) != 2 || read[0].Body != "first" || read[1].Sender() != "the user" {
+		t.Errorf("TakeUnread() = %+v", read)
+	}
+	if again, _ := s.TakeUnread("s1", false); len(again) != 0 {
+		t.Errorf("TakeUnread() after reading = %d messages, want 0", len(again))
+	}
+	if inbox, _ := s.Inbox("s1"); len(inbox) != 2 {
+		t.Errorf("Inbox() = %d messages, want 2 (read ones included)", len(inbox))
+	}
+
+	if n, _ := s.RemoveSession("s3"); n != 1 {
+		t.Errorf("RemoveSession() = %d, want 1", n)
+	}
+	if counts, _ := s.UnreadCounts(); len(counts) != 0 {
+		t.Errorf("UnreadCounts() after reading and removing = %v", counts)
+	}
+}
+
+func TestStoreClaimDeliverable(t *testing.T) {
+	s := newTestStore(t)
+	inject := &Message{To: "s1", Body: "inject me", Inject: true}
+	mailOnly := &Message{To: "s1", Body: "mailbox only"}
+	busy := &Message{To: "s2", Body: "later", Inject: true}
+	for _, m := range []*Message{inject, mailOnly, busy} {
+		if err := s.Send(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ready := func(to string) bool { return to == "s1" }
+	claimed, err := s.ClaimDeliverable(ready)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != inject.ID || claimed[0].DeliveredAt.IsZero() {
+		t.Fatalf("ClaimDeliverable() = %+v, want only the injectable message", claimed)
+	}
+	if again, _ := s.ClaimDeliverable(ready); len(again) != 0 {
+		t.Errorf("ClaimDeliverable() claimed %d messages twice", len(again))
+	}
+
+	// A failed delivery puts the message back
+	if err := s.Failed(inject.ID, errors.New("pane gone")); err != nil {
+		t.Fatal(err)
+	}
+	unread, _ := s.TakeUnread("s1", true)
+	if len(unread) != 2 || unread[0].LastError != "pane gone" {
+		t.Errorf("after Failed(), unread = %+v", unread)
+	}
+}
+
+func TestStoreSkipsUnchangedWrites(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.ClaimDeliverable(func(string) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.path); !os.IsNotExist(err) {
+		t.Errorf("claiming from an empty mailbox created %s", s.path)
+	}
+
+	if err := s.Send(&Message{To: "s1", Body: "later", Inject: true}); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := s.ClaimDeliverable(func(string) bool { return false }); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.Stat(s.path); !after.ModTime().Equal(before.ModTime()) {
+		t.Error("ClaimDeliverable() with nothing ready rewrote the mailbox")
+	}
+}
+
+func TestStoreKeepsRecentReadMessages(t *testing.T) {
+	s := newTestStore(t)
+	for i := 0; i < keepRead+5; i++ {
+		if err := s.Send(&Message{To: "s1", Body: fmt.Sprintf("read %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.TakeUnread("s1", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*Message{{To: "s1", Body: "unread"}, {To: "s2", Body: "other"}} {
+		if err := s.Send(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inbox, err := s.Inbox("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox) != keepRead+1 || inbox[0].Body != "read 5" || inbox[len(inbox)-1].Body != "unread" {
+		t.Errorf("inbox has %d messages from %q to %q, want the newest %d read ones and the unread one",
+			len(inbox), inbox[0].Body, inbox[len(inbox)-1].Body, keepRead)
+	}
+	if other, _ := s.Inbox("s2"); len(other) != 1 {
+		t.Errorf("other session's inbox = %+v, want its message", other)
+	}
+}
+
+func TestReadyAndPrompt(t *testing.T) {
+	inst := session.NewInstance("agent", t.TempDir())
+	inst.Tool = "claude"
+	inst.Status = session.StatusWaiting
+	if !Ready(inst) {
+		t.Error("Ready(waiting claude) = false")
+	}
+	inst.Status = session.StatusRunning
+	if Ready(inst) {
+		t.Error("Ready(running claude) = true")
+	}
+	inst.Tool, inst.Status = "shell", session.StatusWaiting
+	if Ready(inst) || Ready(nil) {
+		t.Error("Ready() = true for a shell or a missing session")
+	}
+
+	m := &Message{FromTitle: "dev", Body: "Tests pass"}
+	if p := m.Prompt(); !strings.Contains(p, "'dev'") || !strings.Contains(p, "Tests pass") || !strings.Contains(p, `--to "dev"`) {
+		t.Errorf("Prompt() = %q", p)
+	}
+}
@@ -0,0 +1,167 @@
+package mailbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// mcpProtocolVersion is the MCP revision served when the client doesn't ask for one
+const mcpProtocolVersion = "2024-11-05"
+
+// Tool is a tool served by ServeMCP
+type Tool struct {
+	Name        string
+	Description string
+	// Params are the tool's string parameters: name -> description
+	Params map[string]string
+	// Required lists the parameters that must be given
+	Required []string
+	// Call runs the tool; its result (or error) is returned as text
+	Call func(args map[string]string) (string, error)
+}
+
+// schema returns the tool's JSON input schema
+func (t Tool) schema() map[string]interface{} {
+	props := make(map[string]interface{}, len(t.Params))
+	for name, desc := range t.Params {
+		props[name] = map[string]string{"type": "string", "description": desc}
+	}
+	required := t.Required
+	if required == nil {
+		required = []string{}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	}
+}
+
+// rpcRequest is a JSON-RPC 2.0 request or notification (no ID)
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ServeMCP serves tools over the MCP stdio transport (newline-delimited
+// JSON-RPC on r and w) until r is closed
+func ServeMCP(r io.Reader, w io.Writer, version string, tools []Tool) error {
+	byName := make(map[string]Tool, len(tools))
+	for _, t := range tools {
+		byName[t.Name] = t
+	}
+	enc := json.NewEncoder(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		var req rpcRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			if err := enc.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &rpcError{Code: -32700, Message: "parse error"}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(req.ID) == 0 {
+			continue // Notifications (e.g. notifications/initialized) need no reply
+		}
+
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+		switch req.Method {
+		case "initialize":
+			var params struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			}
+			_ = json.Unmarshal(req.Params, &params)
+			if params.ProtocolVersion == "" {
+				params.ProtocolVersion = mcpProtocolVersion
+			}
+			resp.Result = map[string]interface{}{
+				"protocolVersion": params.ProtocolVersion,
+				"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+				"serverInfo":      map[string]string{"name": "agent-deck", "version": version},
+			}
+		case "ping":
+			resp.Result = map[string]interface{}{}
+		case "tools/list":
+			list := make([]map[string]interface{}, len(tools))
+			for i, t := range tools {
+				list[i] = map[string]interface{}{
+					"name":        t.Name,
+					"description": t.Description,
+					"inputSchema": t.schema(),
+				}
+			}
+			resp.Result = map[string]interface{}{"tools": list}
+		case "tools/call":
+			var params struct {
+				Name      string                 `json:"name"`
+				Arguments map[string]interface{} `json:"arguments"`
+			}
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				resp.Error = &rpcError{Code: -32602, Message: "invalid params"}
+				break
+			}
+			tool, ok := byName[params.Name]
+			if !ok {
+				resp.Error = &rpcError{Code: -32602, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+				break
+			}
+			resp.Result = callTool(tool, params.Arguments)
+		default:
+			resp.Error = &rpcError{Code: -32601, Message: fmt.Sprintf("method not found: %s", req.Method)}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// callTool runs a tool and wraps its result as MCP text content
+func callTool(tool Tool, arguments map[string]interface{}) map[string]interface{} {
+	args := make(map[string]string, len(arguments))
+	for k, v := range arguments {
+		if s, ok := v.(string); ok {
+			args[k] = s
+		} else {
+			args[k] = fmt.Sprint(v)
+		}
+	}
+
+	var text string
+	var err error
+	for _, name := range tool.Required {
+		if args[name] == "" {
+			err = fmt.Errorf("missing required argument '%s'", name)
+		}
+	}
+	if err == nil {
+		text, err = tool.Call(args)
+	}
+	if err != nil {
+		text = err.Error()
+	}
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": err != nil,
+	}
+}
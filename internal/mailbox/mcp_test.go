@@ -0,0 +1,81 @@
+package mailbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestServeMCP(t *testing.T) {
+	var calls []map[string]string
+	tools := []Tool{{
+		Name:        "send_message",
+		Description: "Send a message",
+		Params:      map[string]string{"to": "Recipient", "message": "Text"},
+		Required:    []string{"to", "message"},
+		Call: func(args map[string]string) (string, error) {
+			calls = append(calls, args)
+			if args["to"] == "nobody" {
+				return "", fmt.Errorf("session 'nobody' not found")
+			}
+			return "sent", nil
+		},
+	}}
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"send_message","arguments":{"to":"lead","message":"done"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"send_message","arguments":{"to":"nobody","message":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"send_message","arguments":{"to":"lead"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"resources/list"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := ServeMCP(strings.NewReader(in), &out, "1.0", tools); err != nil {
+		t.Fatalf("ServeMCP: %v", err)
+	}
+
+	var responses []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("bad response %q: %v", line, err)
+		}
+		responses = append(responses, r)
+	}
+	if len(responses) != 7 {
+		t.Fatalf("got %d responses, want 7 (none for the notification)", len(responses))
+	}
+
+	init := responses[0]["result"].(map[string]interface{})
+	if init["protocolVersion"] != "2025-03-26" {
+		t.Errorf("initialize = %v", init)
+	}
+	list := responses[1]["result"].(map[string]interface{})["tools"].([]interface{})
+	schema := list[0].(map[string]interface{})["inputSchema"].(map[string]interface{})
+	if len(list) != 1 || len(schema["required"].([]interface{})) != 2 {
+		t.Errorf("tools/list = %v", list)
+	}
+
+	text := func(r map[string]interface{}) (string, bool) {
+		result := r["result"].(map[string]interface{})
+		content := result["content"].([]interface{})[0].(map[string]interface{})
+		return content["text"].(string), result["isError"].(bool)
+	}
+	if s, isErr := text(responses[2]); s != "sent" || isErr || calls[0]["message"] != "done" {
+		t.Errorf("tools/call = %q (error %v), args %v", s, isErr, calls[0])
+	}
+	if s, isErr := text(responses[3]); !isErr || !strings.Contains(s, "not found") {
+		t.Errorf("failing tools/call = %q (error %v)", s, isErr)
+	}
+	if s, isErr := text(responses[4]); !isErr || !strings.Contains(s, "message") || len(calls) != 2 {
+		t.Errorf("tools/call without a required argument = %q (error %v)", s, isErr)
+	}
+	if responses[5]["error"] == nil || responses[6]["error"] == nil {
+		t.Errorf("unknown method / parse error responses = %v, %v", responses[5], responses[6])
+	}
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) = nil error, want error", expr)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, 1, 15, 10, 30, 20, 0, time.Local)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 31, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, time.Local)},
+		{"0 9 * * *", time.Date(2025, 1, 16, 9, 0, 0, 0, time.Local)},
+		{"30 10 * * *", time.Date(2025, 1, 16, 10, 30, 0, 0, time.Local)},
+		{"0 9 * * 1-5", time.Date(2025, 1, 16, 9, 0, 0, 0, time.Local)},
+		{"0 9 * * sat,sun", time.Date(2025, 1, 18, 9, 0, 0, 0, time.Local)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.Local)},
+		{"0 12 1 * *", time.Date(2025, 2, 1, 12, 0, 0, 0, time.Local)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.Local)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.Local)},
+		{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.Local)},
+		{"5/20 * * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, time.Local)},
+		// Both day fields restricted: either matches (the 20th or a Friday)
+		{"0 0 20 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		sched, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if got := sched.Next(from); !got.Equal(tt.want) {
+			t.Errorf("ParseCron(%q).Next = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextNever(t *testing.T) {
+	sched, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sched.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next for Feb 31 = %s, want zero", got)
+	}
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// CheckInterval is how often runners look for due jobs
+const CheckInterval = 15 * time.Second
+
+// Result is the outcome of one delivered job
+type Result struct {
+	Job   *Job
+	Title string // Session title ("" if the session no longer exists)
+
+	// Started is true if the session was started or restarted, so the
+	// caller should save the session list
+	Started bool
+	Err     error
+}
+
+// Deliver runs a job against its session: starts it (Start) or restarts it
+// (Restart) first if the job asks for that, then sends the message the same
+// way as `agent-deck session send`, waiting for the agent to be ready.
+func Deliver(inst *session.Instance, job *Job) (started bool, err error) {
+	switch {
+	case job.Restart && inst.Exists():
+		if err := inst.Restart(); err != nil {
+			return false, fmt.Errorf("failed to restart session: %w", err)
+		}
+		started = true
+	case !inst.Exists():
+		if !job.Start && !job.Restart {
+			return false, fmt.Errorf("session '%s' is not running", inst.Title)
+		}
+		if err := inst.Start(); err != nil {
+			return false, fmt.Errorf("failed to start session: %w", err)
+		}
+		started = true
+	}
+	return started, inst.Send(job.Message, false)
+}
+
+// RunDue claims the jobs due at now, delivers each to the session returned
+// by find (released once the delivery is done) and records the results in
+// the store
+func RunDue(store *Store, now time.Time, find session.Finder) ([]Result, error) {
+	due, err := store.ClaimDue(now)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(due))
+	for _, job := range due {
+		result := Result{Job: job}
+		inst, release := find(job.SessionID)
+		if inst == nil {
+			result.Err = fmt.Errorf("session %s not found", job.SessionID)
+		} else {
+			result.Title = inst.Title
+			result.Started, result.Err = Deliver(inst, job)
+		}
+		release()
+
+		if result.Err != nil {
+			log.Printf("[SCHEDULE] job %s for %s failed: %v", job.ID, job.SessionID, result.Err)
+		} else {
+			log.Printf("[SCHEDULE] job %s delivered to %s", job.ID, result.Title)
+		}
+		if err := store.Finish(job.ID, now, result.Err); err != nil {
+			log.Printf("[SCHEDULE] failed to record job %s: %v", job.ID, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,321 @@
+// Package scheduler runs scheduled prompts: messages delivered to a session
+// on a cron schedule or once after a delay. Jobs are stored per profile and
+// run by whichever agent-deck process is running the scheduler (the TUI or
+// the daemon); claiming a job is atomic, so concurrent runners never send a
+// prompt twice.
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+const (
+	storeFileName = "schedules.json"
+
+	// missedRunGrace is how late a recurring job may still run, e.g. when no
+	// agent-deck process was running at its scheduled time. Older runs are
+	// skipped and recorded as missed.
+	missedRunGrace = time.Hour
+)
+
+// Job is a scheduled prompt for one session
+type Job struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Message   string `json:"message"`
+
+	// Cron is the schedule of a recurring job; empty for a one-shot job,
+	// which runs once at At
+	Cron string    `json:"cron,omitempty"`
+	At   time.Time `json:"at,omitempty"`
+
+	// Start starts the session first if it isn't running; Restart restarts
+	// it (with resume) before every delivery
+	Start   bool `json:"start,omitempty"`
+	Restart bool `json:"restart,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	NextRun   time.Time `json:"next_run,omitempty"` // Zero once a one-shot job has run
+	LastRun   time.Time `json:"last_run,omitempty"`
+	LastError string    `json:"last_error,omitempty"` // Empty if the last run succeeded
+}
+
+// Recurring returns true for cron jobs
+func (j *Job) Recurring() bool {
+	return j.Cron != ""
+}
+
+// Describe returns the schedule in words, e.g. "cron 0 9 * * 1-5" or
+// "once at 2025-01-02 15:04"
+func (j *Job) Describe() string {
+	if j.Recurring() {
+		return "cron " + j.Cron
+	}
+	return "once at " + j.At.Local().Format("2006-01-02 15:04")
+}
+
+// Validate checks the job and computes its first run
+func (j *Job) Validate(now time.Time) error {
+	if strings.TrimSpace(j.Message) == "" {
+		return fmt.Errorf("message is empty")
+	}
+	if j.SessionID == "" {
+		return fmt.Errorf("no session")
+	}
+	if j.Recurring() {
+		sched, err := ParseCron(j.Cron)
+		if err != nil {
+			return err
+		}
+		if j.NextRun = sched.Next(now); j.NextRun.IsZero() {
+			return fmt.Errorf("cron expression '%s' never matches", j.Cron)
+		}
+		return nil
+	}
+	if j.At.IsZero() {
+		return fmt.Errorf("no schedule (set a cron expression or a time)")
+	}
+	j.NextRun = j.At
+	return nil
+}
+
+// Store holds the jobs of one profile in schedules.json in the profile directory
+type Store struct {
+	path string
+}
+
+// NewStore returns the job store for a profile
+func NewStore(profile string) (*Store, error) {
+	dir, err := session.GetProfileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: filepath.Join(dir, storeFileName)}, nil
+}
+
+// Path returns the file the jobs are stored in
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns all jobs ordered by next run (finished one-shot jobs last)
+func (s *Store) List() ([]*Job, error) {
+	jobs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(jobs, func(a, b int) bool {
+		na, nb := jobs[a].NextRun, jobs[b].NextRun
+		if na.IsZero() != nb.IsZero() {
+			return nb.IsZero()
+		}
+		return na.Before(nb)
+	})
+	return jobs, nil
+}
+
+// Add validates a job, gives it an ID and stores it
+func (s *Store) Add(job *Job) error {
+	now := time.Now()
+	if err := job.Validate(now); err != nil {
+		return err
+	}
+	job.ID = newJobID()
+	job.CreatedAt = now
+	return s.update(func(jobs []*Job) []*Job {
+		return append(jobs, job)
+	})
+}
+
+// Remove deletes the job with the given ID or unique ID prefix and returns it
+func (s *Store) Remove(ref string) (*Job, error) {
+	var removed *Job
+	var findErr error
+	err := s.update(func(jobs []*Job) []*Job {
+		idx, err := findJob(jobs, ref)
+		if err != nil {
+			findErr = err
+			return jobs
+		}
+		removed = jobs[idx]
+		return append(jobs[:idx], jobs[idx+1:]...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, findErr
+}
+
+// RemoveSession deletes all jobs of a session and returns how many there were
+func (s *Store) RemoveSession(sessionID string) (int, error) {
+	count := 0
+	err := s.update(func(jobs []*Job) []*Job {
+		kept := jobs[:0]
+		for _, job := range jobs {
+			if job.SessionID == sessionID {
+				count++
+				continue
+			}
+			kept = append(kept, job)
+		}
+		return kept
+	})
+	return count, err
+}
+
+// ClaimDue returns the jobs due at now and moves them on: recurring jobs to
+// their next run, one-shot jobs to finished. Recurring runs missed by more
+// than an hour are skipped. The caller delivers the returned jobs and
+// reports each with Finish.
+func (s *Store) ClaimDue(now time.Time) ([]*Job, error) {
+	var due []*Job
+	err := s.update(func(jobs []*Job) []*Job {
+		for _, job := range jobs {
+			if job.NextRun.IsZero() || job.NextRun.After(now) {
+				continue
+			}
+			missed := job.Recurring() && now.Sub(job.NextRun) > missedRunGrace
+			if missed {
+				job.LastRun = job.NextRun
+				job.LastError = "missed (agent-deck was not running)"
+			} else {
+				claimed := *job
+				due = append(due, &claimed)
+			}
+			if job.Recurring() {
+				if sched, err := ParseCron(job.Cron); err == nil {
+					job.NextRun = sched.Next(now)
+				} else {
+					job.NextRun = time.Time{}
+					job.LastError = err.Error()
+				}
+			} else {
+				job.NextRun = time.Time{}
+			}
+		}
+		return jobs
+	})
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// Finish records the result of running a claimed job. Successful one-shot
+// jobs are deleted; failed ones are kept so the error can be seen.
+func (s *Store) Finish(id string, at time.Time, runErr error) error {
+	return s.update(func(jobs []*Job) []*Job {
+		for idx, job := range jobs {
+			if job.ID != id {
+				continue
+			}
+			if runErr == nil && !job.Recurring() {
+				return append(jobs[:idx], jobs[idx+1:]...)
+			}
+			job.LastRun = at
+			job.LastError = ""
+			if runErr != nil {
+				job.LastError = runErr.Error()
+			}
+			break
+		}
+		return jobs
+	})
+}
+
+// findJob returns the index of the job with the given ID or unique ID prefix
+func findJob(jobs []*Job, ref string) (int, error) {
+	found := -1
+	for idx, job := range jobs {
+		if job.ID == ref {
+			return idx, nil
+		}
+		if ref != "" && strings.HasPrefix(job.ID, ref) {
+			if found >= 0 {
+				return -1, fmt.Errorf("schedule id '%s' is ambiguous", ref)
+			}
+			found = idx
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("schedule '%s' not found", ref)
+	}
+	return found, nil
+}
+
+// load reads the jobs; a missing file means no jobs
+func (s *Store) load() ([]*Job, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var jobs []*Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return jobs, nil
+}
+
+// update applies fn to the stored jobs under an exclusive file lock, so
+// runners in several agent-deck processes don't claim the same job
+func (s *Store) update(fn func([]*Job) []*Job) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	lock, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open schedules lock: %w", err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock schedules: %w", err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	jobs, err := s.load()
+	if err != nil {
+		return err
+	}
+	jobs = fn(jobs)
+	if jobs == nil {
+		jobs = []*Job{}
+	}
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedules: %w", err)
+	}
+	// Atomic write
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
+
+// newJobID returns a short random job ID
+func newJobID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
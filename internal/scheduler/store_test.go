@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	return &Store{path: filepath.Join(t.TempDir(), storeFileName)}
+}
+
+func TestStoreAddValidates(t *testing.T) {
+	s := newTestStore(t)
+	invalid := []*Job{
+		{SessionID: "s1", Cron: "0 9 * * *"},                 // No message
+		{Message: "hi", Cron: "0 9 * * *"},                   // No session
+		{SessionID: "s1", Message: "hi"},                     // No schedule
+		{SessionID: "s1", Message: "hi", Cron: "bogus"},      // Bad cron
+		{SessionID: "s1", Message: "hi", Cron: "0 0 31 2 *"}, // Never runs
+	}
+	for _, job := range invalid {
+		if err := s.Add(job); err == nil {
+			t.Errorf("Add(%+v) = nil, want error", job)
+		}
+	}
+	if jobs, _ := s.List(); len(jobs) != 0 {
+		t.Errorf("List() has %d jobs after invalid adds, want 0", len(jobs))
+	}
+}
+
+func TestStoreClaimDue(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+
+	recurring := &Job{SessionID: "s1", Message: "daily", Cron: "0 9 * * *"}
+	oneShot := &Job{SessionID: "s2", Message: "once", At: now.Add(time.Minute)}
+	later := &Job{SessionID: "s3", Message: "later", At: now.Add(time.Hour)}
+	for _, job := range []*Job{recurring, oneShot, later} {
+		if err := s.Add(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nothing is due yet
+	due, err := s.ClaimDue(now)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("ClaimDue(now) = %d jobs, %v; want none", len(due), err)
+	}
+
+	// The one-shot job is claimed exactly once
+	at := now.Add(2 * time.Minute)
+	due, _ = s.ClaimDue(at)
+	if len(due) != 1 || due[0].ID != oneShot.ID {
+		t.Fatalf("ClaimDue(+2m) = %v, want the one-shot job", due)
+	}
+	if again, _ := s.ClaimDue(at); len(again) != 0 {
+		t.Errorf("job claimed twice")
+	}
+
+	// A failed one-shot job is kept with its error, a successful one is deleted
+	if err := s.Finish(oneShot.ID, at, errors.New("session not running")); err != nil {
+		t.Fatal(err)
+	}
+	jobs, _ := s.List()
+	last := jobs[len(jobs)-1]
+	if last.ID != oneShot.ID || !last.NextRun.IsZero() || last.LastError != "session not running" {
+		t.Errorf("failed one-shot job = %+v, want finished with error", last)
+	}
+	if err := s.Finish(later.ID, at, nil); err != nil {
+		t.Fatal(err)
+	}
+	if jobs, _ := s.List(); len(jobs) != 2 {
+		t.Errorf("List() has %d jobs after finishing a one-shot job, want 2", len(jobs))
+	}
+}
+
+func TestStoreClaimDueRecurring(t *testing.T) {
+	s := newTestStore(t)
+	job := &Job{SessionID: "s1", Message: "tick", Cron: "*/5 * * * *"}
+	if err := s.Add(job); err != nil {
+		t.Fatal(err)
+	}
+
+	due, _ := s.ClaimDue(job.NextRun)
+	if len(due) != 1 {
+		t.Fatalf("ClaimDue at next run = %d jobs, want 1", len(due))
+	}
+	jobs, _ := s.List()
+	if want := job.NextRun.Add(5 * time.Minute); !jobs[0].NextRun.Equal(want) {
+		t.Errorf("NextRun after claim = %s, want %s", jobs[0].NextRun, want)
+	}
+
+	// Runs missed by more than the grace period are skipped, not delivered
+	late := jobs[0].NextRun.Add(2 * time.Hour)
+	if due, _ := s.ClaimDue(late); len(due) != 0 {
+		t.Errorf("ClaimDue two hours late = %d jobs, want 0", len(due))
+	}
+	jobs, _ = s.List()
+	if jobs[0].LastError == "" || !jobs[0].NextRun.After(late) {
+		t.Errorf("missed job = %+v, want error recorded and rescheduled", jobs[0])
+	}
+}
+
+func TestStoreRemove(t *testing.T) {
+	s := newTestStore(t)
+	a := &Job{SessionID: "s1", Message: "a", Cron: "@daily"}
+	b := &Job{SessionID: "s1", Message: "b", Cron: "@hourly"}
+	c := &Job{SessionID: "s2", Message: "c", Cron: "@hourly"}
+	for _, job := range []*Job{a, b, c} {
+		if err := s.Add(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.Remove("nope"); err == nil {
+		t.Error("Remove(unknown) = nil error, want error")
+	}
+	removed, err := s.Remove(c.ID[:4])
+	if err != nil || removed.ID != c.ID {
+		t.Errorf("Remove(prefix) = %v, %v; want job %s", removed, err, c.ID)
+	}
+	if n, err := s.RemoveSession("s1"); err != nil || n != 2 {
+		t.Errorf("RemoveSession(s1) = %d, %v; want 2", n, err)
+	}
+	if jobs, _ := s.List(); len(jobs) != 0 {
+		t.Errorf("List() has %d jobs, want 0", len(jobs))
+	}
+}
@@ -0,0 +1,224 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// ToolAdapter knows how one agent CLI stores and continues its conversations.
+// Instance methods (Start, Restart, CanFork, GetLastResponse, GetMCPInfo...)
+// go through the adapter for the session's tool instead of switching on it.
+type ToolAdapter interface {
+	// StartCommand turns the session's configured command into what runs in tmux
+	StartCommand(i *Instance, command string) string
+
+	// DiscoverSessionID returns the ID of the conversation running in i,
+	// or "" if it cannot be determined (yet)
+	DiscoverSessionID(i *Instance) string
+
+	// ResumeCommand continues conversation sessionID in a fresh process
+	ResumeCommand(i *Instance, sessionID string) string
+
+	// ForkCommand starts a copy of conversation sessionID running in workDir.
+	// Returns "" if the tool cannot fork conversations.
+	ForkCommand(i *Instance, sessionID, workDir string) string
+
+	// LastResponse reads the last assistant message from the tool's session store
+	LastResponse(i *Instance, sessionID string) (*ResponseOutput, error)
+
+	// MCPConfigPath is the file the tool reads MCP servers from for projectPath
+	// ("" if the tool has no MCP support)
+	MCPConfigPath(projectPath string) string
+
+	// MCPInfo lists the MCP servers configured for projectPath (nil if unsupported)
+	MCPInfo(projectPath string) *MCPInfo
+}
+
+// toolAdapters holds the built-in adapters by tool name
+var toolAdapters = map[string]ToolAdapter{
+	"claude":   claudeAdapter{},
+	"gemini":   geminiAdapter{},
+	"codex":    codexAdapter{},
+	"opencode": opencodeAdapter{},
+	"aider":    aiderAdapter{},
+}
+
+// GetToolAdapter returns the adapter for tool: a built-in one, one declared
+// in [tools.<name>] of config.toml, or nil for shells and unknown tools
+func GetToolAdapter(tool string) ToolAdapter {
+	if a, ok := toolAdapters[tool]; ok {
+		return a
+	}
+	return customToolAdapterFor(tool)
+}
+
+// adapter returns the adapter for this session. A session with a known
+// Claude conversation is treated as Claude even if Tool hasn't caught up yet.
+func (i *Instance) adapter() ToolAdapter {
+	if a := GetToolAdapter(i.Tool); a != nil {
+		return a
+	}
+	if i.ClaudeSessionID != "" {
+		return claudeAdapter{}
+	}
+	return nil
+}
+
+// AgentSessionID returns the tool's conversation ID for this session
+// (Claude and Gemini keep their own fields, other tools share ToolSessionID)
+func (i *Instance) AgentSessionID() string {
+	switch {
+	case i.Tool == "gemini":
+		return i.GeminiSessionID
+	case i.Tool == "claude" || GetToolAdapter(i.Tool) == nil:
+		return i.ClaudeSessionID
+	default:
+		return i.ToolSessionID
+	}
+}
+
+// agentSessionDetectedAt returns when AgentSessionID was last confirmed
+func (i *Instance) agentSessionDetectedAt() time.Time {
+	switch {
+	case i.Tool == "gemini":
+		return i.GeminiDetectedAt
+	case i.Tool == "claude" || GetToolAdapter(i.Tool) == nil:
+		return i.ClaudeDetectedAt
+	default:
+		return i.ToolDetectedAt
+	}
+}
+
+// setAgentSessionID records a discovered conversation ID
+func (i *Instance) setAgentSessionID(id string) {
+	now := time.Now()
+	switch {
+	case i.Tool == "gemini":
+		i.GeminiSessionID, i.GeminiDetectedAt = id, now
+	case i.Tool == "claude" || GetToolAdapter(i.Tool) == nil:
+		i.ClaudeSessionID, i.ClaudeDetectedAt = id, now
+	default:
+		i.ToolSessionID, i.ToolDetectedAt = id, now
+	}
+}
+
+// sessionIDScanInterval - how often UpdateStatus looks for a not-yet-known
+// session ID. Discovery may scan the tool's session store on disk.
+const sessionIDScanInterval = 5 * time.Second
+
+// updateAgentSession refreshes AgentSessionID through the adapter
+// (best-effort, rate-limited so the status poll stays cheap)
+func (i *Instance) updateAgentSession() {
+	a := i.adapter()
+	if a == nil {
+		return
+	}
+	if i.AgentSessionID() != "" && time.Since(i.agentSessionDetectedAt()) < sessionIDRefreshInterval {
+		return
+	}
+	if time.Since(i.lastSessionIDScan) < sessionIDScanInterval {
+		return
+	}
+	i.lastSessionIDScan = time.Now()
+
+	if id := a.DiscoverSessionID(i); id != "" {
+		i.setAgentSessionID(id)
+	}
+}
+
+// MCPConfigPath returns the file this session's tool reads MCP servers from
+// ("" for tools without MCP support)
+func (i *Instance) MCPConfigPath() string {
+	if a := i.adapter(); a != nil {
+		return a.MCPConfigPath(i.ProjectPath)
+	}
+	return ""
+}
+
+// resolvedPath returns path with symlinks resolved (macOS: /tmp -> /private/tmp),
+// or path itself if it cannot be resolved
+func resolvedPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// samePath reports whether a and b name the same directory
+func samePath(a, b string) bool {
+	return filepath.Clean(resolvedPath(a)) == filepath.Clean(resolvedPath(b))
+}
+
+// claudeAdapter: conversation ID comes from the capture-resume pattern via the
+// tmux environment, transcripts are JSONL under CLAUDE_CONFIG_DIR/projects
+type claudeAdapter struct{}
+
+func (claudeAdapter) StartCommand(i *Instance, command string) string {
+	return i.buildClaudeCommand(command)
+}
+
+func (claudeAdapter) DiscoverSessionID(i *Instance) string {
+	return i.GetSessionIDFromTmux()
+}
+
+func (claudeAdapter) ResumeCommand(i *Instance, sessionID string) string {
+	return buildClaudeResumeCommand(i.GetClaudeConfigDir(), sessionID)
+}
+
+func (claudeAdapter) ForkCommand(i *Instance, sessionID, workDir string) string {
+	return buildClaudeForkCommand(i.GetClaudeConfigDir(), sessionID, workDir)
+}
+
+func (claudeAdapter) LastResponse(i *Instance, sessionID string) (*ResponseOutput, error) {
+	return i.getClaudeLastResponse()
+}
+
+func (claudeAdapter) MCPConfigPath(projectPath string) string {
+	return filepath.Join(projectPath, ".mcp.json")
+}
+
+func (claudeAdapter) MCPInfo(projectPath string) *MCPInfo {
+	return GetMCPInfo(projectPath)
+}
+
+// geminiAdapter: conversation ID comes from GEMINI_SESSION_ID in the tmux
+// environment, sessions are JSON files under ~/.gemini/tmp/<project hash>
+type geminiAdapter struct{}
+
+func (geminiAdapter) StartCommand(i *Instance, command string) string {
+	return i.buildGeminiCommand(command)
+}
+
+func (geminiAdapter) DiscoverSessionID(i *Instance) string {
+	if i.tmuxSession == nil {
+		return ""
+	}
+	id, err := i.tmuxSession.GetEnvironment("GEMINI_SESSION_ID")
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+func (geminiAdapter) ResumeCommand(i *Instance, sessionID string) string {
+	// Set GEMINI_SESSION_ID in tmux env so detection works after restart
+	return fmt.Sprintf("tmux set-environment GEMINI_SESSION_ID %s && gemini --resume %s", sessionID, sessionID)
+}
+
+// ForkCommand: Gemini CLI has no equivalent of Claude's --fork-session
+func (geminiAdapter) ForkCommand(i *Instance, sessionID, workDir string) string {
+	return ""
+}
+
+func (geminiAdapter) LastResponse(i *Instance, sessionID string) (*ResponseOutput, error) {
+	return i.getGeminiLastResponse()
+}
+
+func (geminiAdapter) MCPConfigPath(projectPath string) string {
+	return filepath.Join(GetGeminiConfigDir(), "settings.json")
+}
+
+func (geminiAdapter) MCPInfo(projectPath string) *MCPInfo {
+	return GetGeminiMCPInfo(projectPath)
+}
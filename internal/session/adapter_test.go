@@ -0,0 +1,205 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetToolAdapter(t *testing.T) {
+	for _, tool := range []string{"claude", "gemini", "codex", "opencode", "aider"} {
+		if GetToolAdapter(tool) == nil {
+			t.Errorf("GetToolAdapter(%q) = nil", tool)
+		}
+	}
+	if GetToolAdapter("shell") != nil {
+		t.Error("shell sessions should have no adapter")
+	}
+}
+
+func TestCodexAdapter(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CODEX_HOME", home)
+	project := t.TempDir()
+	other := t.TempDir()
+
+	inst := NewInstanceWithTool("codex-test", project, "codex")
+	inst.CreatedAt = time.Now().Add(-time.Minute)
+
+	day := filepath.Join(home, "sessions", time.Now().Format("2006/01/02"))
+	id := "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+	writeTestFile(t, filepath.Join(day, "rollout-2025-10-01T10-00-00-"+id+".jsonl"),
+		`{"timestamp":"2025-10-01T10:00:00Z","type":"session_meta","payload":{"id":"`+id+`","cwd":"`+project+`"}}
+{"timestamp":"2025-10-01T10:00:01Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"hi"}]}}
+{"timestamp":"2025-10-01T10:00:02Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"first"}]}}
+{"timestamp":"2025-10-01T10:00:03Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"second"}]}}
+`)
+	// Another project's session must not be picked up
+	writeTestFile(t, filepath.Join(day, "rollout-2025-10-01T10-05-00-other.jsonl"),
+		`{"type":"session_meta","payload":{"id":"other","cwd":"`+other+`"}}`+"\n")
+
+	inst.updateAgentSession()
+	if inst.ToolSessionID != id {
+		t.Fatalf("ToolSessionID = %q, want %q", inst.ToolSessionID, id)
+	}
+	if inst.AgentSessionID() != id {
+		t.Errorf("AgentSessionID() = %q, want %q", inst.AgentSessionID(), id)
+	}
+
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.Content != "second" || resp.Tool != "codex" || resp.SessionID != id {
+		t.Errorf("GetLastResponse = %+v", resp)
+	}
+
+	if got := inst.resumeCommand(); got != "codex resume "+id {
+		t.Errorf("resumeCommand() = %q", got)
+	}
+	if !inst.CanRestart() {
+		t.Error("CanRestart() should be true with a known Codex session")
+	}
+	if inst.CanFork() {
+		t.Error("Codex sessions cannot fork")
+	}
+}
+
+func TestParseCodexLastAssistantMessage_Legacy(t *testing.T) {
+	data := `{"id":"legacy-id","timestamp":"2025-05-01T00:00:00Z","instructions":""}
+{"type":"message","role":"assistant","content":[{"type":"output_text","text":"old format"}]}
+`
+	resp, err := parseCodexLastAssistantMessage([]byte(data), "legacy-id")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if resp.Content != "old format" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+}
+
+func TestGetCodexMCPInfo(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CODEX_HOME", home)
+	writeTestFile(t, filepath.Join(home, "config.toml"), `model = "o4"
+
+[mcp_servers.github]
+command = "npx"
+
+[mcp_servers.docs]
+url = "http://localhost:9000"
+`)
+	info := NewInstanceWithTool("c", t.TempDir(), "codex").GetMCPInfo()
+	if info == nil || strings.Join(info.Global, ",") != "docs,github" {
+		t.Errorf("GetMCPInfo() = %+v, want global docs,github", info)
+	}
+}
+
+func TestOpenCodeAdapter(t *testing.T) {
+	data := t.TempDir()
+	config := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	t.Setenv("XDG_CONFIG_HOME", config)
+	project := t.TempDir()
+
+	inst := NewInstanceWithTool("opencode-test", project, "opencode")
+	inst.CreatedAt = time.Now().Add(-time.Minute)
+	now := time.Now().UnixMilli()
+	storage := filepath.Join(data, "opencode", "storage")
+
+	writeTestFile(t, filepath.Join(storage, "session", "proj1", "ses_main.json"),
+		`{"id":"ses_main","directory":"`+project+`","time":{"created":`+strconv.FormatInt(now-1000, 10)+`,"updated":`+strconv.FormatInt(now, 10)+`}}`)
+	// Subagent sessions share the directory but aren't the conversation
+	writeTestFile(t, filepath.Join(storage, "session", "proj1", "ses_child.json"),
+		`{"id":"ses_child","parentID":"ses_main","directory":"`+project+`","time":{"created":`+strconv.FormatInt(now, 10)+`,"updated":`+strconv.FormatInt(now+5000, 10)+`}}`)
+	writeTestFile(t, filepath.Join(storage, "message", "ses_main", "msg_1.json"),
+		`{"id":"msg_1","role":"user","time":{"created":`+strconv.FormatInt(now-900, 10)+`}}`)
+	writeTestFile(t, filepath.Join(storage, "message", "ses_main", "msg_2.json"),
+		`{"id":"msg_2","role":"assistant","time":{"created":`+strconv.FormatInt(now-800, 10)+`}}`)
+	writeTestFile(t, filepath.Join(storage, "part", "msg_2", "prt_1.json"), `{"id":"prt_1","type":"text","text":"hello"}`)
+	writeTestFile(t, filepath.Join(storage, "part", "msg_2", "prt_2.json"), `{"id":"prt_2","type":"tool","text":""}`)
+	writeTestFile(t, filepath.Join(storage, "part", "msg_2", "prt_3.json"), `{"id":"prt_3","type":"text","text":"world"}`)
+
+	inst.updateAgentSession()
+	if inst.ToolSessionID != "ses_main" {
+		t.Fatalf("ToolSessionID = %q, want ses_main", inst.ToolSessionID)
+	}
+
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.Content != "hello\nworld" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if got := inst.resumeCommand(); got != "opencode --session ses_main" {
+		t.Errorf("resumeCommand() = %q", got)
+	}
+
+	writeTestFile(t, filepath.Join(config, "opencode", "opencode.json"), `{"mcp":{"global-one":{}}}`)
+	writeTestFile(t, filepath.Join(project, "opencode.json"), `{"mcp":{"local-one":{}}}`)
+	info := inst.GetMCPInfo()
+	if strings.Join(info.Global, ",") != "global-one" || strings.Join(info.Project, ",") != "local-one" {
+		t.Errorf("GetMCPInfo() = %+v", info)
+	}
+	if inst.MCPConfigPath() != filepath.Join(project, "opencode.json") {
+		t.Errorf("MCPConfigPath() = %q", inst.MCPConfigPath())
+	}
+}
+
+func TestAiderAdapter(t *testing.T) {
+	project := t.TempDir()
+	inst := NewInstanceWithTool("aider-test", project, "aider")
+	inst.Command = "aider --model sonnet"
+
+	inst.updateAgentSession()
+	if inst.AgentSessionID() != "" {
+		t.Errorf("no chat history yet, got session %q", inst.AgentSessionID())
+	}
+	inst.lastSessionIDScan = time.Time{}
+
+	writeTestFile(t, filepath.Join(project, aiderHistoryFile), `
+# aider chat started at 2025-10-01 10:00:00
+
+#### add a test
+
+Sure, here is the test.
+
+> Tokens: 1k sent, 200 received.
+
+#### now fix the bug
+
+The bug was an off-by-one.
+Fixed in main.go.
+
+> Applied edit to main.go
+`)
+	inst.updateAgentSession()
+	if inst.AgentSessionID() == "" {
+		t.Fatal("chat history should be discovered")
+	}
+	if got := inst.resumeCommand(); got != "aider --model sonnet --restore-chat-history" {
+		t.Errorf("resumeCommand() = %q", got)
+	}
+
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.Content != "The bug was an off-by-one.\nFixed in main.go." {
+		t.Errorf("Content = %q", resp.Content)
+	}
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// aiderHistoryFile is where aider appends the chat transcript (markdown),
+// relative to the directory it runs in
+const aiderHistoryFile = ".aider.chat.history.md"
+
+// parseAiderLastResponse extracts the assistant text after the last user
+// turn of an aider chat history. User messages are "#### " lines, aider's
+// own notices (token counts, applied edits) are "> " lines.
+func parseAiderLastResponse(data string) (*ResponseOutput, error) {
+	lines := strings.Split(data, "\n")
+
+	lastUser := -1
+	for idx, line := range lines {
+		if strings.HasPrefix(line, "#### ") {
+			lastUser = idx
+		}
+	}
+	if lastUser < 0 {
+		return nil, fmt.Errorf("no assistant response found in chat history")
+	}
+
+	var response []string
+	for _, line := range lines[lastUser+1:] {
+		if strings.HasPrefix(line, "#### ") || strings.HasPrefix(line, "> ") || line == ">" {
+			continue
+		}
+		response = append(response, line)
+	}
+	content := strings.TrimSpace(strings.Join(response, "\n"))
+	if content == "" {
+		return nil, fmt.Errorf("no assistant response found in chat history")
+	}
+	return &ResponseOutput{
+		Tool:    "aider",
+		Role:    "assistant",
+		Content: content,
+	}, nil
+}
+
+// aiderAdapter: aider has no conversation IDs, only a chat history file per
+// directory that --restore-chat-history loads back. The "session ID" is the
+// path of that file once it exists.
+type aiderAdapter struct{}
+
+func (aiderAdapter) StartCommand(i *Instance, command string) string {
+	return command
+}
+
+func (aiderAdapter) DiscoverSessionID(i *Instance) string {
+	if i.IsRemote() {
+		return ""
+	}
+	path := filepath.Join(i.ProjectPath, aiderHistoryFile)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+func (aiderAdapter) ResumeCommand(i *Instance, sessionID string) string {
+	command := i.Command
+	if command == "" {
+		command = "aider"
+	}
+	if strings.Contains(command, "--restore-chat-history") {
+		return command
+	}
+	return command + " --restore-chat-history"
+}
+
+// ForkCommand: every aider in a directory shares one history file
+func (aiderAdapter) ForkCommand(i *Instance, sessionID, workDir string) string {
+	return ""
+}
+
+func (aiderAdapter) LastResponse(i *Instance, sessionID string) (*ResponseOutput, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("no aider chat history found for this instance")
+	}
+	data, err := os.ReadFile(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	return parseAiderLastResponse(string(data))
+}
+
+// MCPConfigPath: aider has no MCP support
+func (aiderAdapter) MCPConfigPath(projectPath string) string {
+	return ""
+}
+
+func (aiderAdapter) MCPInfo(projectPath string) *MCPInfo {
+	return nil
+}
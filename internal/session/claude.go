@@ -90,12 +90,19 @@ type projectMCPConfig struct {
 	MCPServers map[string]json.RawMessage `json:"mcpServers"`
 }
 
+// mcpCacheKey identifies cached MCP info: the same project can have
+// different global/project MCPs under different Claude accounts
+type mcpCacheKey struct {
+	configDir   string
+	projectPath string
+}
+
 // MCP info cache (30 second TTL to avoid re-reading files on every render)
 var (
-	mcpInfoCache   = make(map[string]*MCPInfo)
+	mcpInfoCache   = make(map[mcpCacheKey]*MCPInfo)
 	mcpInfoCacheMu sync.RWMutex
 	mcpCacheExpiry = 30 * time.Second
-	mcpCacheTimes  = make(map[string]time.Time)
+	mcpCacheTimes  = make(map[mcpCacheKey]time.Time)
 )
 
 // MCPServer represents an MCP with its enabled state
@@ -127,10 +134,18 @@ const (
 // 2. Project MCPs: CLAUDE_CONFIG_DIR/.claude.json → projects[projectPath].mcpServers
 // 3. Local MCPs: {projectPath}/.mcp.json → mcpServers
 func GetMCPInfo(projectPath string) *MCPInfo {
+	return GetMCPInfoForConfigDir(GetClaudeConfigDir(), projectPath)
+}
+
+// GetMCPInfoForConfigDir is GetMCPInfo reading .claude.json from configDir
+// (a session's Claude account) instead of the default config directory
+func GetMCPInfoForConfigDir(configDir, projectPath string) *MCPInfo {
+	key := mcpCacheKey{configDir: configDir, projectPath: projectPath}
+
 	// Check cache first
 	mcpInfoCacheMu.RLock()
-	if cached, ok := mcpInfoCache[projectPath]; ok {
-		if time.Since(mcpCacheTimes[projectPath]) < mcpCacheExpiry {
+	if cached, ok := mcpInfoCache[key]; ok {
+		if time.Since(mcpCacheTimes[key]) < mcpCacheExpiry {
 			mcpInfoCacheMu.RUnlock()
 			return cached
 		}
@@ -138,21 +153,20 @@ func GetMCPInfo(projectPath string) *MCPInfo {
 	mcpInfoCacheMu.RUnlock()
 
 	// Cache miss or expired - fetch fresh data
-	info := getMCPInfoUncached(projectPath)
+	info := getMCPInfoUncached(configDir, projectPath)
 
 	// Update cache
 	mcpInfoCacheMu.Lock()
-	mcpInfoCache[projectPath] = info
-	mcpCacheTimes[projectPath] = time.Now()
+	mcpInfoCache[key] = info
+	mcpCacheTimes[key] = time.Now()
 	mcpInfoCacheMu.Unlock()
 
 	return info
 }
 
 // getMCPInfoUncached reads MCP info from disk (called by cached wrapper)
-func getMCPInfoUncached(projectPath string) *MCPInfo {
+func getMCPInfoUncached(configDir, projectPath string) *MCPInfo {
 	info := &MCPInfo{}
-	configDir := GetClaudeConfigDir()
 
 	// Read .claude.json for global and project MCPs
 	configFile := filepath.Join(configDir, ".claude.json")
@@ -262,13 +276,24 @@ func GetClaudeSessionID(projectPath string) (string, error) {
 	return "", fmt.Errorf("no session found for project: %s", projectPath)
 }
 
+// claudeProjectDir returns the directory Claude keeps a project's transcripts in.
+// Claude replaces every non-alphanumeric character of the path with '-':
+// /Users/ashesh/claude-deck -> -Users-ashesh-claude-deck
+// /home/me/.agent-deck/worktrees/app -> -home-me--agent-deck-worktrees-app
+func claudeProjectDir(configDir, projectPath string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, projectPath)
+	return filepath.Join(configDir, "projects", name)
+}
+
 // findActiveSessionID looks for the most recently modified session file
 // This finds the CURRENTLY RUNNING session, not the last completed one
 func findActiveSessionID(configDir, projectPath string) string {
-	// Convert project path to Claude's directory format
-	// /Users/ashesh/claude-deck -> -Users-ashesh-claude-deck
-	projectDirName := strings.ReplaceAll(projectPath, "/", "-")
-	projectDir := filepath.Join(configDir, "projects", projectDirName)
+	projectDir := claudeProjectDir(configDir, projectPath)
 
 	// Check if project directory exists
 	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
@@ -440,11 +465,15 @@ func isMCPEnabled(name string, settings *ProjectMCPSettings, mode MCPMode) bool
 	}
 }
 
-// ClearMCPCache invalidates the MCP cache for a project path
+// ClearMCPCache invalidates the MCP cache for a project path (all accounts)
 func ClearMCPCache(projectPath string) {
 	mcpInfoCacheMu.Lock()
-	delete(mcpInfoCache, projectPath)
-	delete(mcpCacheTimes, projectPath)
+	for key := range mcpInfoCache {
+		if key.projectPath == projectPath {
+			delete(mcpInfoCache, key)
+			delete(mcpCacheTimes, key)
+		}
+	}
 	mcpInfoCacheMu.Unlock()
 }
 
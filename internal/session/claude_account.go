@@ -0,0 +1,214 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A Claude account is a Claude config directory (CLAUDE_CONFIG_DIR). Sessions
+// pick one explicitly, or inherit it from the nearest group that sets one,
+// then from the profile ([profiles.<name>] claude_config_dir), then fall back
+// to GetClaudeConfigDir(). Values are either a [claude.accounts] name or a path.
+
+// ResolveClaudeConfigDir turns an account name or path into a config directory.
+// "" resolves to the default directory.
+func ResolveClaudeConfigDir(value string) string {
+	if value == "" {
+		return GetClaudeConfigDir()
+	}
+	if userConfig, _ := LoadUserConfig(); userConfig != nil {
+		if dir, ok := userConfig.Claude.Accounts[value]; ok {
+			return expandTilde(dir)
+		}
+	}
+	return expandTilde(value)
+}
+
+// GetClaudeAccountNames returns the [claude.accounts] names, sorted
+func GetClaudeAccountNames() []string {
+	userConfig, _ := LoadUserConfig()
+	if userConfig == nil {
+		return nil
+	}
+	names := make([]string, 0, len(userConfig.Claude.Accounts))
+	for name := range userConfig.Claude.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClaudeAccountLabel names the account using configDir: its [claude.accounts]
+// name, "default" for the default directory, otherwise the path
+func ClaudeAccountLabel(configDir string) string {
+	configDir = filepath.Clean(configDir)
+	if userConfig, _ := LoadUserConfig(); userConfig != nil {
+		for _, name := range GetClaudeAccountNames() {
+			if filepath.Clean(expandTilde(userConfig.Claude.Accounts[name])) == configDir {
+				return name
+			}
+		}
+	}
+	if configDir == filepath.Clean(GetClaudeConfigDir()) {
+		return "default"
+	}
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(configDir, home+string(filepath.Separator)) {
+		return "~" + strings.TrimPrefix(configDir, home)
+	}
+	return configDir
+}
+
+// GetClaudeConfigDirs returns every configured Claude config directory: the
+// default, the named accounts and the profiles' (for indexing all accounts)
+func GetClaudeConfigDirs() []string {
+	dirs := []string{GetClaudeConfigDir()}
+	if userConfig, _ := LoadUserConfig(); userConfig != nil {
+		for _, name := range GetClaudeAccountNames() {
+			dirs = append(dirs, expandTilde(userConfig.Claude.Accounts[name]))
+		}
+		for _, p := range userConfig.Profiles {
+			if p.ClaudeConfigDir != "" {
+				dirs = append(dirs, ResolveClaudeConfigDir(p.ClaudeConfigDir))
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(dirs))
+	unique := dirs[:0]
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			unique = append(unique, dir)
+		}
+	}
+	return unique
+}
+
+// GroupClaudeConfigDirs maps group paths to the Claude account they set
+func GroupClaudeConfigDirs(groups []*GroupData) map[string]string {
+	dirs := make(map[string]string)
+	for _, g := range groups {
+		if g.ClaudeConfigDir != "" {
+			dirs[g.Path] = g.ClaudeConfigDir
+		}
+	}
+	return dirs
+}
+
+// ApplyClaudeConfigDirs sets the Claude account sessions inherit: from the
+// nearest group (or parent group) in groupDirs, else from profile's settings.
+// Call after loading sessions and whenever groups or memberships change.
+func ApplyClaudeConfigDirs(instances []*Instance, groupDirs map[string]string, profile string) {
+	profileDir := ""
+	if userConfig, _ := LoadUserConfig(); userConfig != nil {
+		profileDir = userConfig.Profiles[GetEffectiveProfile(profile)].ClaudeConfigDir
+	}
+
+	for _, inst := range instances {
+		inherited := profileDir
+		for path := inst.GroupPath; path != ""; {
+			if dir, ok := groupDirs[path]; ok {
+				inherited = dir
+				break
+			}
+			idx := strings.LastIndex(path, "/")
+			if idx < 0 {
+				break
+			}
+			path = path[:idx]
+		}
+		inst.inheritedClaudeConfigDir = inherited
+	}
+}
+
+// GetClaudeConfigDir returns the Claude config directory this session uses
+func (i *Instance) GetClaudeConfigDir() string {
+	if i.ClaudeConfigDir != "" {
+		return ResolveClaudeConfigDir(i.ClaudeConfigDir)
+	}
+	return ResolveClaudeConfigDir(i.inheritedClaudeConfigDir)
+}
+
+// SetClaudeConfigDir switches the session's Claude account ("" inherits).
+// Conversations don't carry across accounts, so a change forgets the
+// conversation ID: the next start or restart begins a new conversation.
+func (i *Instance) SetClaudeConfigDir(value string) {
+	before := i.GetClaudeConfigDir()
+	i.ClaudeConfigDir = value
+	i.forgetConversationIfAccountChanged(before)
+}
+
+// forgetConversationIfAccountChanged clears the Claude conversation ID if the
+// session's account is no longer the one in before
+func (i *Instance) forgetConversationIfAccountChanged(before string) {
+	if i.Tool != "claude" || filepath.Clean(i.GetClaudeConfigDir()) == filepath.Clean(before) {
+		return
+	}
+	i.ClaudeSessionID = ""
+	i.ClaudeDetectedAt = time.Time{}
+	// The running agent still reports its ID through the tmux environment
+	if i.tmuxSession != nil && i.tmuxSession.Exists() {
+		_ = i.tmuxSession.SetEnvironment("CLAUDE_SESSION_ID", "")
+	}
+}
+
+// SetClaudeConfigDir sets the Claude account of group path ("" inherits) and
+// re-resolves the accounts of all sessions (see Instance.SetClaudeConfigDir).
+// Returns false if the group doesn't exist.
+func (t *GroupTree) SetClaudeConfigDir(path, value, profile string) bool {
+	group, ok := t.Groups[path]
+	if !ok {
+		return false
+	}
+	group.ClaudeConfigDir = value
+	t.RefreshClaudeConfigDirs(profile)
+	return true
+}
+
+// RefreshClaudeConfigDirs re-resolves the accounts sessions inherit after
+// group or membership changes, forgetting conversations of sessions whose
+// account changed
+func (t *GroupTree) RefreshClaudeConfigDirs(profile string) {
+	instances := t.GetAllInstances()
+	before := make([]string, len(instances))
+	for idx, inst := range instances {
+		before[idx] = inst.GetClaudeConfigDir()
+	}
+	ApplyClaudeConfigDirs(instances, t.ClaudeConfigDirs(), profile)
+	for idx, inst := range instances {
+		inst.forgetConversationIfAccountChanged(before[idx])
+	}
+}
+
+// hasClaudeAccount returns true if the session's account is set on the
+// session, its group or its profile (rather than the default)
+func (i *Instance) hasClaudeAccount() bool {
+	return i.ClaudeConfigDir != "" || i.inheritedClaudeConfigDir != ""
+}
+
+// ClaudeAccount returns the display name of the session's Claude account
+func (i *Instance) ClaudeAccount() string {
+	return ClaudeAccountLabel(i.GetClaudeConfigDir())
+}
+
+// ValidateClaudeAccount checks value is "" (inherit), a [claude.accounts]
+// name or an existing directory
+func ValidateClaudeAccount(value string) error {
+	if value == "" {
+		return nil
+	}
+	if userConfig, _ := LoadUserConfig(); userConfig != nil {
+		if _, ok := userConfig.Claude.Accounts[value]; ok {
+			return nil
+		}
+	}
+	if info, err := os.Stat(expandTilde(value)); err != nil || !info.IsDir() {
+		return fmt.Errorf("'%s' is neither a [claude.accounts] name nor a directory", value)
+	}
+	return nil
+}
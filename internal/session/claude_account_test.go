@@ -0,0 +1,131 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveClaudeConfigDir(t *testing.T) {
+	defaultDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", defaultDir)
+	withUserConfig(t, &UserConfig{Claude: ClaudeSettings{
+		Accounts: map[string]string{"work": "/accounts/work"},
+	}})
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", defaultDir},
+		{"work", "/accounts/work"},
+		{"/some/dir", "/some/dir"},
+	}
+	for _, tt := range tests {
+		if got := ResolveClaudeConfigDir(tt.value); got != tt.want {
+			t.Errorf("ResolveClaudeConfigDir(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	if got := ClaudeAccountLabel("/accounts/work/"); got != "work" {
+		t.Errorf("ClaudeAccountLabel(work dir) = %q, want %q", got, "work")
+	}
+	if got := ClaudeAccountLabel(defaultDir); got != "default" {
+		t.Errorf("ClaudeAccountLabel(default dir) = %q, want %q", got, "default")
+	}
+}
+
+func TestApplyClaudeConfigDirs(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	withUserConfig(t, &UserConfig{
+		Claude: ClaudeSettings{Accounts: map[string]string{
+			"work":     "/accounts/work",
+			"personal": "/accounts/personal",
+			"client":   "/accounts/client",
+		}},
+		Profiles: map[string]ProfileSettings{"test": {ClaudeConfigDir: "personal"}},
+	})
+
+	inGroup := NewInstanceWithGroupAndTool("a", "/tmp", "work", "claude")
+	inSubgroup := NewInstanceWithGroupAndTool("b", "/tmp", "work/api", "claude")
+	elsewhere := NewInstanceWithGroupAndTool("c", "/tmp", "other", "claude")
+	explicit := NewInstanceWithGroupAndTool("d", "/tmp", "work", "claude")
+	explicit.ClaudeConfigDir = "client"
+
+	ApplyClaudeConfigDirs(
+		[]*Instance{inGroup, inSubgroup, elsewhere, explicit},
+		map[string]string{"work": "work"},
+		"test",
+	)
+
+	tests := []struct {
+		inst *Instance
+		want string
+	}{
+		{inGroup, "/accounts/work"},
+		{inSubgroup, "/accounts/work"},
+		{elsewhere, "/accounts/personal"},
+		{explicit, "/accounts/client"},
+	}
+	for _, tt := range tests {
+		if got := tt.inst.GetClaudeConfigDir(); got != tt.want {
+			t.Errorf("%s: GetClaudeConfigDir() = %q, want %q", tt.inst.Title, got, tt.want)
+		}
+	}
+
+	env := inGroup.resolvedEnv()
+	if env["CLAUDE_CONFIG_DIR"] != "/accounts/work" {
+		t.Errorf("resolvedEnv CLAUDE_CONFIG_DIR = %q, want %q", env["CLAUDE_CONFIG_DIR"], "/accounts/work")
+	}
+}
+
+func TestSetClaudeConfigDirForgetsConversation(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	withUserConfig(t, &UserConfig{Claude: ClaudeSettings{
+		Accounts: map[string]string{"work": "/accounts/work"},
+	}})
+
+	inst := NewInstanceWithTool("a", "/tmp", "claude")
+	inst.ClaudeSessionID = "abc-123"
+
+	inst.SetClaudeConfigDir("")
+	if inst.ClaudeSessionID != "abc-123" {
+		t.Error("unchanged account should keep the conversation")
+	}
+
+	inst.SetClaudeConfigDir("work")
+	if inst.ClaudeSessionID != "" {
+		t.Errorf("ClaudeSessionID = %q after switching accounts, want empty", inst.ClaudeSessionID)
+	}
+
+	// Group changes reach sessions through the tree
+	inst.GroupPath = "team"
+	inst.ClaudeConfigDir = ""
+	inst.ClaudeSessionID = "def-456"
+	tree := NewGroupTreeWithGroups([]*Instance{inst}, []*GroupData{{Name: "team", Path: "team"}})
+	tree.RefreshClaudeConfigDirs("")
+	if !tree.SetClaudeConfigDir("team", "work", "") {
+		t.Fatal("SetClaudeConfigDir on existing group returned false")
+	}
+	if got := inst.GetClaudeConfigDir(); got != "/accounts/work" {
+		t.Errorf("GetClaudeConfigDir() = %q, want %q", got, "/accounts/work")
+	}
+	if inst.ClaudeSessionID != "" {
+		t.Errorf("ClaudeSessionID = %q after group switch, want empty", inst.ClaudeSessionID)
+	}
+}
+
+func TestValidateClaudeAccount(t *testing.T) {
+	dir := t.TempDir()
+	withUserConfig(t, &UserConfig{Claude: ClaudeSettings{
+		Accounts: map[string]string{"work": "/accounts/work"},
+	}})
+
+	for _, value := range []string{"", "work", dir} {
+		if err := ValidateClaudeAccount(value); err != nil {
+			t.Errorf("ValidateClaudeAccount(%q) = %v, want nil", value, err)
+		}
+	}
+	if err := ValidateClaudeAccount(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ValidateClaudeAccount(missing dir) = nil, want error")
+	}
+}
@@ -0,0 +1,271 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// GetCodexHome returns $CODEX_HOME, or ~/.codex
+func GetCodexHome() string {
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		return expandTilde(dir)
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".codex")
+}
+
+// codexSessionsDir is where Codex writes rollout files:
+// sessions/YYYY/MM/DD/rollout-<timestamp>-<uuid>.jsonl
+func codexSessionsDir() string {
+	return filepath.Join(GetCodexHome(), "sessions")
+}
+
+// codexMaxScanDays bounds how many day directories session discovery reads
+const codexMaxScanDays = 7
+
+// codexRolloutLine is one line of a rollout file. Current Codex wraps
+// everything in {type, payload}; older versions wrote the session header
+// and response items at the top level.
+type codexRolloutLine struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+
+	// Legacy header / legacy response item fields
+	ID      string              `json:"id"`
+	Role    string              `json:"role"`
+	Content []codexContentBlock `json:"content"`
+}
+
+type codexSessionMeta struct {
+	ID        string `json:"id"`
+	Cwd       string `json:"cwd"`
+	Timestamp string `json:"timestamp"`
+}
+
+type codexResponseItem struct {
+	Type    string              `json:"type"`
+	Role    string              `json:"role"`
+	Content []codexContentBlock `json:"content"`
+}
+
+type codexContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// readCodexSessionMeta reads the session header (first line) of a rollout file
+func readCodexSessionMeta(path string) (codexSessionMeta, error) {
+	var meta codexSessionMeta
+	f, err := os.Open(path)
+	if err != nil {
+		return meta, err
+	}
+	defer f.Close()
+
+	first, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return meta, err
+	}
+
+	var line codexRolloutLine
+	if err := json.Unmarshal(first, &line); err != nil {
+		return meta, fmt.Errorf("invalid rollout header: %w", err)
+	}
+	if line.Type == "session_meta" {
+		if err := json.Unmarshal(line.Payload, &meta); err != nil {
+			return meta, fmt.Errorf("invalid session_meta: %w", err)
+		}
+		return meta, nil
+	}
+	// Legacy header: {"id": ..., "timestamp": ..., "instructions": ...} (no cwd)
+	meta.ID = line.ID
+	return meta, nil
+}
+
+// codexRolloutFiles lists rollout files from day directories since `since`
+// (at most codexMaxScanDays back), newest first by modification time
+func codexRolloutFiles(since time.Time) []string {
+	now := time.Now()
+	oldest := now.AddDate(0, 0, -codexMaxScanDays)
+	if since.After(oldest) {
+		oldest = since
+	}
+
+	type rollout struct {
+		path    string
+		modTime time.Time
+	}
+	var files []rollout
+	// Day directories use local time; scan one extra day on either side
+	// so sessions around midnight or in other timezones are still found
+	for day := oldest.AddDate(0, 0, -1); !day.After(now.AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		dir := filepath.Join(codexSessionsDir(), day.Format("2006"), day.Format("01"), day.Format("02"))
+		matches, _ := filepath.Glob(filepath.Join(dir, "rollout-*.jsonl"))
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && !info.ModTime().Before(since) {
+				files = append(files, rollout{m, info.ModTime()})
+			}
+		}
+	}
+
+	sort.Slice(files, func(a, b int) bool { return files[a].modTime.After(files[b].modTime) })
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// findCodexSessionFile returns the newest rollout file of sessionID
+// (file names end in the session UUID)
+func findCodexSessionFile(sessionID string) string {
+	matches, _ := filepath.Glob(filepath.Join(codexSessionsDir(), "*", "*", "*", "rollout-*-"+sessionID+".jsonl"))
+	var newest string
+	var newestTime time.Time
+	for _, m := range matches {
+		if info, err := os.Stat(m); err == nil && info.ModTime().After(newestTime) {
+			newest, newestTime = m, info.ModTime()
+		}
+	}
+	return newest
+}
+
+// parseCodexLastAssistantMessage extracts the last assistant message from a
+// rollout file (both the {type, payload} and the legacy top-level format)
+func parseCodexLastAssistantMessage(data []byte, sessionID string) (*ResponseOutput, error) {
+	var last string
+	var timestamp string
+	for _, raw := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		var line struct {
+			codexRolloutLine
+			Timestamp string `json:"timestamp"`
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			continue
+		}
+
+		item := codexResponseItem{Type: line.Type, Role: line.Role, Content: line.Content}
+		if line.Type == "response_item" {
+			if err := json.Unmarshal(line.Payload, &item); err != nil {
+				continue
+			}
+		}
+		if item.Type != "message" || item.Role != "assistant" {
+			continue
+		}
+
+		var text []string
+		for _, block := range item.Content {
+			if block.Type == "output_text" && block.Text != "" {
+				text = append(text, block.Text)
+			}
+		}
+		if len(text) > 0 {
+			last = strings.Join(text, "\n")
+			timestamp = line.Timestamp
+		}
+	}
+
+	if last == "" {
+		return nil, fmt.Errorf("no assistant response found in session")
+	}
+	return &ResponseOutput{
+		Tool:      "codex",
+		Role:      "assistant",
+		Content:   last,
+		Timestamp: timestamp,
+		SessionID: sessionID,
+	}, nil
+}
+
+// codexConfig is the part of $CODEX_HOME/config.toml agent-deck reads
+type codexConfig struct {
+	MCPServers map[string]toml.Primitive `toml:"mcp_servers"`
+}
+
+// GetCodexMCPInfo lists MCP servers from $CODEX_HOME/config.toml.
+// Codex has no per-project MCP config, so all of them are global.
+func GetCodexMCPInfo() *MCPInfo {
+	info := &MCPInfo{}
+	var config codexConfig
+	if _, err := toml.DecodeFile(filepath.Join(GetCodexHome(), "config.toml"), &config); err != nil {
+		return info
+	}
+	for name := range config.MCPServers {
+		info.Global = append(info.Global, name)
+	}
+	sort.Strings(info.Global)
+	return info
+}
+
+// codexAdapter: Codex CLI writes one rollout JSONL per conversation under
+// $CODEX_HOME/sessions and resumes them with `codex resume <id>`
+type codexAdapter struct{}
+
+func (codexAdapter) StartCommand(i *Instance, command string) string {
+	return command
+}
+
+// DiscoverSessionID picks the most recently written rollout for the
+// session's directory since it started
+func (codexAdapter) DiscoverSessionID(i *Instance) string {
+	if i.IsRemote() {
+		return ""
+	}
+	since := i.CreatedAt
+	if i.lastStartTime.After(since) {
+		since = i.lastStartTime
+	}
+	for _, path := range codexRolloutFiles(since) {
+		meta, err := readCodexSessionMeta(path)
+		if err == nil && meta.ID != "" && meta.Cwd != "" && samePath(meta.Cwd, i.ProjectPath) {
+			return meta.ID
+		}
+	}
+	return ""
+}
+
+func (codexAdapter) ResumeCommand(i *Instance, sessionID string) string {
+	return "codex resume " + sessionID
+}
+
+// ForkCommand: Codex CLI cannot branch a conversation
+func (codexAdapter) ForkCommand(i *Instance, sessionID, workDir string) string {
+	return ""
+}
+
+func (codexAdapter) LastResponse(i *Instance, sessionID string) (*ResponseOutput, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("no Codex session ID available for this instance")
+	}
+	path := findCodexSessionFile(sessionID)
+	if path == "" {
+		return nil, fmt.Errorf("session file not found for ID: %s", sessionID)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	return parseCodexLastAssistantMessage(data, sessionID)
+}
+
+func (codexAdapter) MCPConfigPath(projectPath string) string {
+	return filepath.Join(GetCodexHome(), "config.toml")
+}
+
+func (codexAdapter) MCPInfo(projectPath string) *MCPInfo {
+	return GetCodexMCPInfo()
+}
@@ -0,0 +1,481 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// HasAdapter returns true if the definition declares any resume, session,
+// transcript or MCP settings
+func (d *ToolDef) HasAdapter() bool {
+	return d.ResumeCommand != "" || d.ForkCommand != "" ||
+		d.Session.Env != "" || d.Session.Glob != "" ||
+		d.Transcript.Path != "" || d.MCP.Path != ""
+}
+
+// Validate reports settings agent-deck can't use
+func (d *ToolDef) Validate() error {
+	if d.Session.Env != "" && d.Session.Glob != "" {
+		return fmt.Errorf("session: set either env or glob, not both")
+	}
+	if f := d.Transcript.Format; f != "" && f != "jsonl" {
+		return fmt.Errorf("transcript: unsupported format %q (supported: jsonl)", f)
+	}
+	if d.Transcript.Path != "" && d.Transcript.ContentPath == "" {
+		return fmt.Errorf("transcript: content_path is required")
+	}
+	if f := d.MCP.Format; f != "" && f != "json" && f != "toml" {
+		return fmt.Errorf("mcp: unsupported format %q (supported: json, toml)", f)
+	}
+	return nil
+}
+
+// customToolAdapter implements ToolAdapter from a [tools.<name>] definition
+type customToolAdapter struct {
+	name string
+	def  ToolDef
+}
+
+// customToolAdapterFor returns the adapter for a custom tool, or nil if the
+// tool isn't defined, declares no adapter settings, or declares invalid ones
+func customToolAdapterFor(tool string) ToolAdapter {
+	def := GetToolDef(tool)
+	if def == nil || !def.HasAdapter() || def.Validate() != nil {
+		return nil
+	}
+	return customToolAdapter{name: tool, def: *def}
+}
+
+// ResolveCustomTool maps a command to a [tools.<name>] entry by the tool's
+// name or its exact command. Returns the tool name and the command to run.
+func ResolveCustomTool(command string) (tool, cmd string, ok bool) {
+	command = strings.TrimSpace(command)
+	config, err := LoadUserConfig()
+	if err != nil || config == nil || command == "" {
+		return "", "", false
+	}
+	if def, found := config.Tools[command]; found {
+		if def.Command == "" {
+			return command, command, true
+		}
+		return command, def.Command, true
+	}
+	for name, def := range config.Tools {
+		if def.Command == command {
+			return name, command, true
+		}
+	}
+	return "", "", false
+}
+
+// fillToolTemplate fills {session_id} and {project} in a command template
+func fillToolTemplate(tmpl, sessionID, projectPath string) string {
+	s := strings.ReplaceAll(tmpl, "{session_id}", sessionID)
+	return strings.ReplaceAll(s, "{project}", projectPath)
+}
+
+// toolPath fills a path template and expands ~
+func toolPath(tmpl, sessionID, projectPath string) string {
+	return expandTilde(fillToolTemplate(tmpl, sessionID, projectPath))
+}
+
+func (a customToolAdapter) StartCommand(i *Instance, command string) string {
+	if command == "" {
+		return a.def.Command
+	}
+	return command
+}
+
+func (a customToolAdapter) DiscoverSessionID(i *Instance) string {
+	if a.def.Session.Env != "" {
+		if i.tmuxSession == nil {
+			return ""
+		}
+		id, err := i.tmuxSession.GetEnvironment(a.def.Session.Env)
+		if err != nil {
+			return ""
+		}
+		return id
+	}
+	if a.def.Session.Glob == "" || i.IsRemote() {
+		return ""
+	}
+
+	since := i.CreatedAt
+	if i.lastStartTime.After(since) {
+		since = i.lastStartTime
+	}
+	for _, path := range newestFiles(toolPath(a.def.Session.Glob, "", i.ProjectPath), since) {
+		if id := a.sessionIDFromFile(path, i.ProjectPath); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// sessionIDFromFile reads the ID from a session file, or "" if the file
+// belongs to another directory or has no ID
+func (a customToolAdapter) sessionIDFromFile(path, projectPath string) string {
+	if a.def.Session.IDPath == "" && a.def.Session.CwdPath == "" {
+		return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	doc, err := readJSONDocument(path)
+	if err != nil {
+		return ""
+	}
+	if a.def.Session.CwdPath != "" {
+		cwd, _ := jsonPathLookup(doc, a.def.Session.CwdPath).(string)
+		if cwd == "" || !samePath(cwd, projectPath) {
+			return ""
+		}
+	}
+	if a.def.Session.IDPath == "" {
+		return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return jsonScalarString(jsonPathLookup(doc, a.def.Session.IDPath))
+}
+
+func (a customToolAdapter) ResumeCommand(i *Instance, sessionID string) string {
+	if a.def.ResumeCommand == "" {
+		return ""
+	}
+	cmd := fillToolTemplate(a.def.ResumeCommand, sessionID, i.ProjectPath)
+	if a.def.Session.Env != "" {
+		// Restore the variable so detection keeps working after restart
+		cmd = fmt.Sprintf("tmux set-environment %s %s && %s", a.def.Session.Env, sessionID, cmd)
+	}
+	return cmd
+}
+
+func (a customToolAdapter) ForkCommand(i *Instance, sessionID, workDir string) string {
+	if a.def.ForkCommand == "" {
+		return ""
+	}
+	return fmt.Sprintf("cd %s && %s", workDir, fillToolTemplate(a.def.ForkCommand, sessionID, workDir))
+}
+
+func (a customToolAdapter) LastResponse(i *Instance, sessionID string) (*ResponseOutput, error) {
+	t := a.def.Transcript
+	if t.Path == "" {
+		return nil, fmt.Errorf("no transcript configured for %s", a.name)
+	}
+	if sessionID == "" && strings.Contains(t.Path, "{session_id}") {
+		return nil, fmt.Errorf("no %s session ID available for this instance", a.name)
+	}
+
+	files := newestFiles(toolPath(t.Path, sessionID, i.ProjectPath), time.Time{})
+	if len(files) == 0 {
+		return nil, fmt.Errorf("transcript not found: %s", toolPath(t.Path, sessionID, i.ProjectPath))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	resp, err := parseJSONLLastMessage(data, t.RolePath, t.ContentPath, t.AssistantRole)
+	if err != nil {
+		return nil, err
+	}
+	resp.Tool = a.name
+	resp.SessionID = sessionID
+	return resp, nil
+}
+
+func (a customToolAdapter) MCPConfigPath(projectPath string) string {
+	if a.def.MCP.Path == "" {
+		return ""
+	}
+	return toolPath(a.def.MCP.Path, "", projectPath)
+}
+
+// MCPInfo lists servers from the config file: per-project files
+// ({project} in the path) count as project MCPs, others as global
+func (a customToolAdapter) MCPInfo(projectPath string) *MCPInfo {
+	if a.def.MCP.Path == "" {
+		return nil
+	}
+	names := readMCPServerNames(a.MCPConfigPath(projectPath), a.def.MCP.Format, a.mcpKey())
+	if strings.Contains(a.def.MCP.Path, "{project}") {
+		return &MCPInfo{Project: names}
+	}
+	return &MCPInfo{Global: names}
+}
+
+func (a customToolAdapter) mcpKey() string {
+	if a.def.MCP.Key == "" {
+		return "mcpServers"
+	}
+	return a.def.MCP.Key
+}
+
+// newestFiles returns files matching pattern modified since `since`, newest first
+func newestFiles(pattern string, since time.Time) []string {
+	matches, _ := filepath.Glob(pattern)
+	modTimes := make(map[string]time.Time, len(matches))
+	var files []string
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || info.IsDir() || info.ModTime().Before(since) {
+			continue
+		}
+		modTimes[m] = info.ModTime()
+		files = append(files, m)
+	}
+	sort.Slice(files, func(a, b int) bool { return modTimes[files[a]].After(modTimes[files[b]]) })
+	return files
+}
+
+// readJSONDocument parses a JSON file, or the first line of a .jsonl file
+func readJSONDocument(path string) (interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var data []byte
+	if strings.HasSuffix(path, ".jsonl") {
+		data, err = bufio.NewReader(f).ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+	} else if data, err = io.ReadAll(f); err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// jsonPathLookup follows a dotted path ("message.content.0.text") through
+// decoded JSON; numeric segments index arrays. Returns nil if missing.
+func jsonPathLookup(doc interface{}, path string) interface{} {
+	cur := doc
+	for _, seg := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			cur = v[seg]
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil
+			}
+			cur = v[idx]
+		default:
+			return nil
+		}
+	}
+	return cur
+}
+
+// jsonScalarString formats a string or number found by jsonPathLookup
+func jsonScalarString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// jsonText flattens message content: a string, or an array of strings and
+// {"text": ...} blocks (the shape most agent transcripts use)
+func jsonText(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			if text := jsonText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	case map[string]interface{}:
+		if text, ok := v["text"].(string); ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// parseJSONLLastMessage returns the last message with the assistant role
+func parseJSONLLastMessage(data []byte, rolePath, contentPath, assistantRole string) (*ResponseOutput, error) {
+	if assistantRole == "" {
+		assistantRole = "assistant"
+	}
+
+	var last string
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var doc interface{}
+		if err := json.Unmarshal(line, &doc); err != nil {
+			continue
+		}
+		if rolePath != "" && jsonScalarString(jsonPathLookup(doc, rolePath)) != assistantRole {
+			continue
+		}
+		if text := strings.TrimSpace(jsonText(jsonPathLookup(doc, contentPath))); text != "" {
+			last = text
+		}
+	}
+
+	if last == "" {
+		return nil, fmt.Errorf("no assistant response found in transcript")
+	}
+	return &ResponseOutput{Role: "assistant", Content: last}, nil
+}
+
+// readMCPServerNames lists the keys of the server table at key in a JSON or
+// TOML config file
+func readMCPServerNames(path, format, key string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var doc map[string]interface{}
+	if format == "toml" {
+		err = toml.Unmarshal(data, &doc)
+	} else {
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil
+	}
+
+	servers, ok := jsonPathLookup(doc, key).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CanWriteToolMCPs returns true if the MCP Manager can edit tool's MCP
+// config: Claude and Gemini, or custom tools with a JSON MCP file
+func CanWriteToolMCPs(tool string) bool {
+	switch tool {
+	case "claude", "gemini":
+		return true
+	}
+	a, ok := customToolAdapterFor(tool).(customToolAdapter)
+	return ok && a.def.MCP.Path != "" && a.def.MCP.Format != "toml"
+}
+
+// WriteToolMCPs replaces the server table of a custom tool's JSON MCP config
+// with enabledNames from config.toml. Other fields in the file are preserved.
+func WriteToolMCPs(tool, projectPath string, enabledNames []string) error {
+	a, ok := customToolAdapterFor(tool).(customToolAdapter)
+	if !ok || a.def.MCP.Path == "" {
+		return fmt.Errorf("tool %s has no MCP config", tool)
+	}
+	if a.def.MCP.Format == "toml" {
+		return fmt.Errorf("tool %s: writing TOML MCP config is not supported", tool)
+	}
+	configFile := a.MCPConfigPath(projectPath)
+
+	rawConfig := make(map[string]interface{})
+	if data, err := os.ReadFile(configFile); err == nil {
+		if err := json.Unmarshal(data, &rawConfig); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", configFile, err)
+		}
+	}
+
+	availableMCPs := GetAvailableMCPs()
+	servers := make(map[string]MCPServerConfig)
+	for _, name := range enabledNames {
+		def, ok := availableMCPs[name]
+		if !ok {
+			continue
+		}
+		if def.URL != "" {
+			transport := def.Transport
+			if transport == "" {
+				transport = "http"
+			}
+			servers[name] = MCPServerConfig{Type: transport, URL: def.URL}
+			continue
+		}
+		args := def.Args
+		if args == nil {
+			args = []string{}
+		}
+		servers[name] = MCPServerConfig{Command: def.Command, Args: args, Env: def.Env}
+	}
+
+	// Walk (creating as needed) to the parent table of a dotted key
+	segs := strings.Split(a.mcpKey(), ".")
+	table := rawConfig
+	for _, seg := range segs[:len(segs)-1] {
+		next, ok := table[seg].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			table[seg] = next
+		}
+		table = next
+	}
+	table[segs[len(segs)-1]] = servers
+
+	newData, err := json.MarshalIndent(rawConfig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmpPath := configFile + ".tmp"
+	if err := os.WriteFile(tmpPath, newData, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmpPath, configFile); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// DetectTool determines the tool type from a command line
+func DetectTool(cmd string) string {
+	cmd = strings.ToLower(cmd)
+	switch {
+	case strings.Contains(cmd, "claude"):
+		return "claude"
+	case strings.Contains(cmd, "opencode") || strings.Contains(cmd, "open-code"):
+		return "opencode"
+	case strings.Contains(cmd, "gemini"):
+		return "gemini"
+	case strings.Contains(cmd, "codex"):
+		return "codex"
+	case strings.Contains(cmd, "aider"):
+		return "aider"
+	case strings.Contains(cmd, "cursor"):
+		return "cursor"
+	default:
+		return "shell"
+	}
+}
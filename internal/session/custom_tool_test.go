@@ -0,0 +1,201 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withUserConfig swaps the cached user config for the duration of a test
+func withUserConfig(t *testing.T, config *UserConfig) {
+	t.Helper()
+	userConfigCacheMu.Lock()
+	saved := userConfigCache
+	userConfigCache = config
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = saved
+		userConfigCacheMu.Unlock()
+	})
+}
+
+func TestCustomToolAdapter(t *testing.T) {
+	store := t.TempDir()
+	project := t.TempDir()
+	withUserConfig(t, &UserConfig{
+		Tools: map[string]ToolDef{
+			"my-agent": {
+				Command:       "my-agent --fast",
+				ResumeCommand: "my-agent --resume {session_id}",
+				Session: ToolSessionDef{
+					Glob:    filepath.Join(store, "*.jsonl"),
+					IDPath:  "meta.id",
+					CwdPath: "meta.cwd",
+				},
+				Transcript: ToolTranscriptDef{
+					Path:        filepath.Join(store, "*-{session_id}.jsonl"),
+					Format:      "jsonl",
+					RolePath:    "message.role",
+					ContentPath: "message.content",
+				},
+			},
+		},
+	})
+
+	inst := NewInstanceWithTool("custom", project, "my-agent")
+	inst.CreatedAt = time.Now().Add(-time.Minute)
+
+	writeTestFile(t, filepath.Join(store, "a-sess-1.jsonl"),
+		`{"meta":{"id":"sess-1","cwd":"`+project+`"}}
+{"message":{"role":"user","content":"hi"}}
+{"message":{"role":"assistant","content":[{"type":"text","text":"hello"},{"type":"text","text":"there"}]}}
+{"message":{"role":"user","content":"thanks"}}
+`)
+	// Newer, but for another directory
+	writeTestFile(t, filepath.Join(store, "b-sess-2.jsonl"), `{"meta":{"id":"sess-2","cwd":"/elsewhere"}}`+"\n")
+
+	// No command of its own: the tool's command runs
+	if got := inst.startCommand(); got != "my-agent --fast" {
+		t.Errorf("startCommand() = %q", got)
+	}
+
+	inst.updateAgentSession()
+	if inst.ToolSessionID != "sess-1" {
+		t.Fatalf("ToolSessionID = %q, want sess-1", inst.ToolSessionID)
+	}
+	if got := inst.resumeCommand(); got != "my-agent --resume sess-1" {
+		t.Errorf("resumeCommand() = %q", got)
+	}
+	if !inst.CanRestart() {
+		t.Error("CanRestart() should be true with a resume command and known ID")
+	}
+	if inst.CanFork() {
+		t.Error("CanFork() should be false without fork_command")
+	}
+
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.Content != "hello\nthere" || resp.Tool != "my-agent" || resp.SessionID != "sess-1" {
+		t.Errorf("GetLastResponse = %+v", resp)
+	}
+}
+
+func TestCustomToolAdapter_Env(t *testing.T) {
+	withUserConfig(t, &UserConfig{
+		Tools: map[string]ToolDef{
+			"env-agent": {
+				ResumeCommand: "env-agent resume {session_id}",
+				ForkCommand:   "env-agent fork {session_id}",
+				Session:       ToolSessionDef{Env: "ENV_AGENT_ID"},
+			},
+		},
+	})
+
+	inst := NewInstanceWithTool("env", "/tmp", "env-agent")
+	inst.setAgentSessionID("abc")
+	if got := inst.resumeCommand(); got != "tmux set-environment ENV_AGENT_ID abc && env-agent resume abc" {
+		t.Errorf("resumeCommand() = %q", got)
+	}
+	if !inst.CanFork() {
+		t.Error("CanFork() should be true with fork_command and a fresh ID")
+	}
+	if got := inst.forkCommand("/work"); got != "cd /work && env-agent fork abc" {
+		t.Errorf("forkCommand() = %q", got)
+	}
+}
+
+func TestToolDefValidate(t *testing.T) {
+	bad := []ToolDef{
+		{Session: ToolSessionDef{Env: "X", Glob: "*.json"}},
+		{Transcript: ToolTranscriptDef{Path: "x.jsonl"}},
+		{Transcript: ToolTranscriptDef{Path: "x.md", Format: "markdown", ContentPath: "c"}},
+		{MCP: ToolMCPDef{Path: "x.yaml", Format: "yaml"}},
+	}
+	for _, def := range bad {
+		if def.Validate() == nil {
+			t.Errorf("Validate(%+v) should fail", def)
+		}
+	}
+
+	// Invalid definitions get no adapter, so the session behaves like a shell
+	withUserConfig(t, &UserConfig{Tools: map[string]ToolDef{"broken": bad[0], "plain": {Command: "plain"}}})
+	if GetToolAdapter("broken") != nil {
+		t.Error("invalid tool definition should have no adapter")
+	}
+	if GetToolAdapter("plain") != nil {
+		t.Error("tool without adapter settings should have no adapter")
+	}
+}
+
+func TestCustomToolMCP(t *testing.T) {
+	project := t.TempDir()
+	global := t.TempDir()
+	withUserConfig(t, &UserConfig{
+		Tools: map[string]ToolDef{
+			"json-agent": {MCP: ToolMCPDef{Path: "{project}/.agent/mcp.json", Key: "servers.mcp"}},
+			"toml-agent": {MCP: ToolMCPDef{Path: filepath.Join(global, "config.toml"), Format: "toml", Key: "mcp_servers"}},
+		},
+		MCPs: map[string]MCPDef{
+			"github": {Command: "npx", Args: []string{"-y", "gh-mcp"}},
+			"docs":   {URL: "http://localhost:9000/mcp"},
+		},
+	})
+
+	writeTestFile(t, filepath.Join(global, "config.toml"), "[mcp_servers.one]\ncommand = \"x\"\n")
+	info := NewInstanceWithTool("t", project, "toml-agent").GetMCPInfo()
+	if info == nil || strings.Join(info.Global, ",") != "one" {
+		t.Errorf("toml GetMCPInfo() = %+v", info)
+	}
+	if CanWriteToolMCPs("toml-agent") {
+		t.Error("TOML MCP configs are read-only")
+	}
+
+	configFile := filepath.Join(project, ".agent", "mcp.json")
+	writeTestFile(t, configFile, `{"theme":"dark","servers":{"mcp":{"old":{}}}}`)
+	if !CanWriteToolMCPs("json-agent") {
+		t.Fatal("JSON MCP configs should be writable")
+	}
+	if err := WriteToolMCPs("json-agent", project, []string{"github", "docs", "unknown"}); err != nil {
+		t.Fatalf("WriteToolMCPs: %v", err)
+	}
+
+	inst := NewInstanceWithTool("j", project, "json-agent")
+	if inst.MCPConfigPath() != configFile {
+		t.Errorf("MCPConfigPath() = %q, want %q", inst.MCPConfigPath(), configFile)
+	}
+	info = inst.GetMCPInfo()
+	if info == nil || strings.Join(info.Project, ",") != "docs,github" {
+		t.Errorf("json GetMCPInfo() = %+v, want project docs,github", info)
+	}
+
+	data, _ := os.ReadFile(configFile)
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["theme"] != "dark" {
+		t.Error("other fields should be preserved")
+	}
+}
+
+func TestResolveCustomTool(t *testing.T) {
+	withUserConfig(t, &UserConfig{Tools: map[string]ToolDef{
+		"my-agent": {Command: "my-agent --fast"},
+	}})
+
+	if tool, cmd, ok := ResolveCustomTool("my-agent"); !ok || tool != "my-agent" || cmd != "my-agent --fast" {
+		t.Errorf("by name: %q %q %v", tool, cmd, ok)
+	}
+	if tool, _, ok := ResolveCustomTool("my-agent --fast"); !ok || tool != "my-agent" {
+		t.Errorf("by command: %q %v", tool, ok)
+	}
+	if _, _, ok := ResolveCustomTool("vim"); ok {
+		t.Error("unknown command should not resolve")
+	}
+}
@@ -2,12 +2,15 @@ package session
 
 import (
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/asheshgoplani/agent-deck/internal/tmux"
 )
 
 // DiscoverExistingTmuxSessions finds all tmux sessions and converts them to instances
+// Sessions on configured remote hosts ([hosts.*]) are discovered too; an
+// unreachable host is skipped rather than failing local discovery.
 func DiscoverExistingTmuxSessions(existingInstances []*Instance) ([]*Instance, error) {
 	// Get all tmux sessions
 	tmuxSessions, err := tmux.DiscoverAllTmuxSessions()
@@ -15,20 +18,33 @@ func DiscoverExistingTmuxSessions(existingInstances []*Instance) ([]*Instance, e
 		return nil, err
 	}
 
-	// Build a map of existing sessions by tmux name
+	hostNames := make([]string, 0)
+	for name := range GetHosts() {
+		hostNames = append(hostNames, name)
+	}
+	sort.Strings(hostNames)
+	for _, host := range hostNames {
+		remote, err := tmux.DiscoverTmuxSessionsOnHost(host)
+		if err != nil {
+			continue
+		}
+		tmuxSessions = append(tmuxSessions, remote...)
+	}
+
+	// Build a map of existing sessions by host and tmux name
 	existingMap := make(map[string]bool)
 	for _, inst := range existingInstances {
 		if inst.GetTmuxSession() != nil {
-			existingMap[inst.GetTmuxSession().Name] = true
+			existingMap[inst.Host+"/"+inst.GetTmuxSession().Name] = true
 		}
 		// Also track by title
-		existingMap[inst.Title] = true
+		existingMap[inst.Host+"/"+inst.Title] = true
 	}
 
 	var discovered []*Instance
 	for _, sess := range tmuxSessions {
 		// Skip if already tracked
-		if existingMap[sess.Name] || existingMap[sess.DisplayName] {
+		if existingMap[sess.Host+"/"+sess.Name] || existingMap[sess.Host+"/"+sess.DisplayName] {
 			continue
 		}
 
@@ -54,6 +70,7 @@ func DiscoverExistingTmuxSessions(existingInstances []*Instance) ([]*Instance, e
 			ProjectPath: projectPath,
 			Status:      StatusIdle,
 			Tool:        detectToolFromName(title),
+			Host:        sess.Host,
 			tmuxSession: sess,
 		}
 		_ = inst.UpdateStatus()
@@ -91,6 +108,7 @@ func FilterByQuery(instances []*Instance, query string) []*Instance {
 		"running": StatusRunning,
 		"idle":    StatusIdle,
 		"error":   StatusError,
+		"exited":  StatusExited,
 	}
 
 	// If query matches a status filter exactly, filter by status
@@ -0,0 +1,86 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envRefPattern matches ${VAR} and ${VAR:-default}, the reference syntax
+// .mcp.json env values use
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// envKeyPattern is a valid environment variable name
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ResolveEnvValue expands ${VAR} and ${VAR:-default} references from
+// agent-deck's own environment. Secrets can then live in the shell (or a
+// secrets manager exporting them) instead of sessions.json.
+func ResolveEnvValue(value string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	return envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		m := envRefPattern.FindStringSubmatch(ref)
+		if v, ok := os.LookupEnv(m[1]); ok && v != "" {
+			return v
+		}
+		return m[2]
+	})
+}
+
+// ParseEnvAssignment splits "KEY=VALUE" and validates KEY
+func ParseEnvAssignment(s string) (key, value string, err error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", "", fmt.Errorf("expected KEY=VALUE, got %q", s)
+	}
+	if !envKeyPattern.MatchString(key) {
+		return "", "", fmt.Errorf("invalid environment variable name %q", key)
+	}
+	return key, value, nil
+}
+
+// FormatEnv renders env as KEY=VALUE pairs sorted by key (values unresolved)
+func FormatEnv(env map[string]string) []string {
+	pairs := make([]string, 0, len(env))
+	for k, v := range env {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// SetEnv sets one session environment variable; an empty value removes it.
+// Takes effect on the next start or restart.
+func (i *Instance) SetEnv(key, value string) {
+	if value == "" {
+		delete(i.Env, key)
+		if len(i.Env) == 0 {
+			i.Env = nil
+		}
+		return
+	}
+	if i.Env == nil {
+		i.Env = make(map[string]string)
+	}
+	i.Env[key] = value
+}
+
+// resolvedEnv returns the session's environment with references expanded
+func (i *Instance) resolvedEnv() map[string]string {
+	env := make(map[string]string, len(i.Env)+1)
+	// Claude (and whatever it spawns) runs under the session's account
+	if i.Tool == "claude" && i.hasClaudeAccount() {
+		env["CLAUDE_CONFIG_DIR"] = i.GetClaudeConfigDir()
+	}
+	for k, v := range i.Env {
+		env[k] = ResolveEnvValue(v)
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
@@ -0,0 +1,61 @@
+package session
+
+import "testing"
+
+func TestResolveEnvValue(t *testing.T) {
+	t.Setenv("AGENTDECK_TEST_TOKEN", "secret")
+	t.Setenv("AGENTDECK_TEST_EMPTY", "")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"${AGENTDECK_TEST_TOKEN}", "secret"},
+		{"Bearer ${AGENTDECK_TEST_TOKEN}", "Bearer secret"},
+		{"${AGENTDECK_TEST_UNSET}", ""},
+		{"${AGENTDECK_TEST_UNSET:-fallback}", "fallback"},
+		{"${AGENTDECK_TEST_EMPTY:-fallback}", "fallback"},
+		{"$AGENTDECK_TEST_TOKEN", "$AGENTDECK_TEST_TOKEN"},
+	}
+	for _, tt := range tests {
+		if got := ResolveEnvValue(tt.in); got != tt.want {
+			t.Errorf("ResolveEnvValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseEnvAssignment(t *testing.T) {
+	key, value, err := ParseEnvAssignment("URL=http://x?a=b")
+	if err != nil || key != "URL" || value != "http://x?a=b" {
+		t.Errorf("ParseEnvAssignment() = %q, %q, %v", key, value, err)
+	}
+	if _, value, err := ParseEnvAssignment("DEBUG="); err != nil || value != "" {
+		t.Errorf("empty value should parse, got %q, %v", value, err)
+	}
+	for _, bad := range []string{"NOEQUALS", "=x", "1X=y", "A-B=c"} {
+		if _, _, err := ParseEnvAssignment(bad); err == nil {
+			t.Errorf("ParseEnvAssignment(%q) should fail", bad)
+		}
+	}
+}
+
+func TestInstanceEnv(t *testing.T) {
+	t.Setenv("AGENTDECK_TEST_TOKEN", "secret")
+	inst := NewInstance("env-test", "/tmp")
+
+	inst.SetEnv("TOKEN", "${AGENTDECK_TEST_TOKEN}")
+	inst.SetEnv("DEBUG", "1")
+	if got := inst.resolvedEnv(); got["TOKEN"] != "secret" || got["DEBUG"] != "1" {
+		t.Errorf("resolvedEnv() = %v", got)
+	}
+	if inst.Env["TOKEN"] != "${AGENTDECK_TEST_TOKEN}" {
+		t.Errorf("stored value should stay unresolved, got %q", inst.Env["TOKEN"])
+	}
+
+	inst.SetEnv("TOKEN", "")
+	inst.SetEnv("DEBUG", "")
+	if inst.Env != nil {
+		t.Errorf("removing all keys should clear Env, got %v", inst.Env)
+	}
+}
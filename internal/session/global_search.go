@@ -53,6 +53,15 @@ type MatchRange struct {
 	End   int
 }
 
+// ConfigDir returns the Claude config directory (account) the session file
+// is in: FilePath is <configDir>/projects/<project>/<id>.jsonl
+func (e *SearchEntry) ConfigDir() string {
+	if e.FilePath == "" {
+		return ""
+	}
+	return filepath.Dir(filepath.Dir(filepath.Dir(e.FilePath)))
+}
+
 // Match searches for query in entry content (case-insensitive)
 // Returns match positions for highlighting
 func (e *SearchEntry) Match(query string) []MatchRange {
@@ -155,6 +164,7 @@ type claudeJSONLRecord struct {
 	Timestamp string          `json:"timestamp"`
 	CWD       string          `json:"cwd"`
 	Summary   string          `json:"summary"`
+	IsMeta    bool            `json:"isMeta"` // Injected context, not typed by the user
 }
 
 // claudeMessage represents the message field in a record
@@ -296,8 +306,8 @@ type SearchResult struct {
 // GlobalSearchIndex manages the searchable session index
 type GlobalSearchIndex struct {
 	// Configuration
-	config    GlobalSearchSettings
-	claudeDir string
+	config     GlobalSearchSettings
+	claudeDirs []string // One per Claude account
 
 	// Index data (protected by atomic pointer for lock-free reads)
 	entries atomic.Pointer[[]SearchEntry]
@@ -332,8 +342,9 @@ type FileTracker struct {
 	LastMod    time.Time
 }
 
-// NewGlobalSearchIndex creates a new search index
-func NewGlobalSearchIndex(claudeDir string, config GlobalSearchSettings) (*GlobalSearchIndex, error) {
+// NewGlobalSearchIndex creates a new search index over the conversations of
+// one or more Claude config directories
+func NewGlobalSearchIndex(claudeDirs []string, config GlobalSearchSettings) (*GlobalSearchIndex, error) {
 	if !config.Enabled {
 		return nil, nil
 	}
@@ -347,7 +358,7 @@ func NewGlobalSearchIndex(claudeDir string, config GlobalSearchSettings) (*Globa
 
 	idx := &GlobalSearchIndex{
 		config:       config,
-		claudeDir:    claudeDir,
+		claudeDirs:   claudeDirs,
 		fileTrackers: make(map[string]*FileTracker),
 		limiter:      rate.NewLimiter(rate.Limit(config.IndexRateLimit), 5),
 		ctx:          ctx,
@@ -359,14 +370,17 @@ func NewGlobalSearchIndex(claudeDir string, config GlobalSearchSettings) (*Globa
 	idx.entries.Store(&emptyEntries)
 
 	// Measure data size and determine tier
-	projectsDir := filepath.Join(claudeDir, "projects")
-	totalSize, err := measureDataSize(projectsDir, config.RecentDays)
-	if err != nil {
-		// Don't fail if projects dir doesn't exist, just use empty index
-		if !os.IsNotExist(err) {
-			cancel()
-			return nil, err
+	var totalSize int64
+	for _, claudeDir := range claudeDirs {
+		size, err := measureDataSize(filepath.Join(claudeDir, "projects"), config.RecentDays)
+		if err != nil {
+			// Don't fail if projects dir doesn't exist, just use empty index
+			if !os.IsNotExist(err) {
+				cancel()
+				return nil, err
+			}
 		}
+		totalSize += size
 	}
 
 	// Determine tier (respect config override)
@@ -390,8 +404,12 @@ func NewGlobalSearchIndex(claudeDir string, config GlobalSearchSettings) (*Globa
 	}
 	idx.watcher = watcher
 
-	// Watch the projects directory (create if doesn't exist check)
-	if _, err := os.Stat(projectsDir); err == nil {
+	// Watch each projects directory (create if doesn't exist check)
+	for _, claudeDir := range claudeDirs {
+		projectsDir := filepath.Join(claudeDir, "projects")
+		if _, err := os.Stat(projectsDir); err != nil {
+			continue
+		}
 		if err := watcher.Add(projectsDir); err != nil {
 			log.Printf("GlobalSearch: failed to watch projects dir: %v", err)
 		}
@@ -450,7 +468,6 @@ func measureDataSize(projectsDir string, recentDays int) (int64, error) {
 func (idx *GlobalSearchIndex) initialLoad() {
 	defer idx.wg.Done()
 
-	projectsDir := filepath.Join(idx.claudeDir, "projects")
 	cutoff := time.Time{}
 	if idx.config.RecentDays > 0 {
 		cutoff = time.Now().AddDate(0, 0, -idx.config.RecentDays)
@@ -458,7 +475,7 @@ func (idx *GlobalSearchIndex) initialLoad() {
 
 	var entries []SearchEntry
 
-	_ = filepath.WalkDir(projectsDir, func(path string, d os.DirEntry, err error) error {
+	loadFile := func(path string, d os.DirEntry, err error) error {
 		if err != nil || d.IsDir() {
 			return nil
 		}
@@ -518,7 +535,13 @@ func (idx *GlobalSearchIndex) initialLoad() {
 		idx.trackerMu.Unlock()
 
 		return nil
-	})
+	}
+	for _, claudeDir := range idx.claudeDirs {
+		if idx.ctx.Err() != nil {
+			break
+		}
+		_ = filepath.WalkDir(filepath.Join(claudeDir, "projects"), loadFile)
+	}
 
 	// Store entries and mark loading complete
 	idx.entries.Store(&entries)
@@ -188,7 +188,7 @@ func TestGlobalSearchIndexInstantTier(t *testing.T) {
 		IndexRateLimit: 100,
 	}
 
-	index, err := NewGlobalSearchIndex(tmpDir, config)
+	index, err := NewGlobalSearchIndex([]string{tmpDir}, config)
 	if err != nil {
 		t.Fatalf("Failed to create index: %v", err)
 	}
@@ -227,7 +227,7 @@ func TestGlobalSearchIndexFuzzyMatch(t *testing.T) {
 	_ = os.WriteFile(filepath.Join(projectDir, "c3d4e5f6-a7b8-9012-cdef-345678901234.jsonl"), []byte(jsonl), 0644)
 
 	config := GlobalSearchSettings{Enabled: true, Tier: "auto", MemoryLimitMB: 100, IndexRateLimit: 100}
-	index, err := NewGlobalSearchIndex(tmpDir, config)
+	index, err := NewGlobalSearchIndex([]string{tmpDir}, config)
 	if err != nil {
 		t.Fatalf("Failed to create index: %v", err)
 	}
@@ -243,7 +243,7 @@ func TestGlobalSearchIndexFuzzyMatch(t *testing.T) {
 
 func TestGlobalSearchIndexDisabled(t *testing.T) {
 	config := GlobalSearchSettings{Enabled: false}
-	index, err := NewGlobalSearchIndex("/tmp", config)
+	index, err := NewGlobalSearchIndex([]string{"/tmp"}, config)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -258,7 +258,7 @@ func TestGlobalSearchIndexEmptyQuery(t *testing.T) {
 	_ = os.MkdirAll(projectDir, 0755)
 
 	config := GlobalSearchSettings{Enabled: true, Tier: "auto", MemoryLimitMB: 100, IndexRateLimit: 100}
-	index, _ := NewGlobalSearchIndex(tmpDir, config)
+	index, _ := NewGlobalSearchIndex([]string{tmpDir}, config)
 	if index == nil {
 		t.Fatal("Index should not be nil")
 	}
@@ -291,7 +291,7 @@ func TestGlobalSearchIndexBalancedTier(t *testing.T) {
 		IndexRateLimit: 100,
 	}
 
-	index, err := NewGlobalSearchIndex(tmpDir, config)
+	index, err := NewGlobalSearchIndex([]string{tmpDir}, config)
 	if err != nil {
 		t.Fatalf("Failed to create index: %v", err)
 	}
@@ -328,7 +328,7 @@ func TestGlobalSearchIndexTierAutoDetect(t *testing.T) {
 		IndexRateLimit: 100,
 	}
 
-	index, _ := NewGlobalSearchIndex(tmpDir, config)
+	index, _ := NewGlobalSearchIndex([]string{tmpDir}, config)
 	if index == nil {
 		t.Fatal("Index should not be nil")
 	}
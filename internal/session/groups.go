@@ -41,6 +41,9 @@ type Group struct {
 	Expanded bool
 	Sessions []*Instance
 	Order    int
+
+	// ClaudeConfigDir is the Claude account the group's sessions inherit
+	ClaudeConfigDir string
 }
 
 // GroupTree manages hierarchical session organization
@@ -104,6 +107,8 @@ func NewGroupTreeWithGroups(instances []*Instance, storedGroups []*GroupData) *G
 			Expanded: gd.Expanded,
 			Sessions: []*Instance{},
 			Order:    gd.Order,
+
+			ClaudeConfigDir: gd.ClaudeConfigDir,
 		}
 		tree.Groups[gd.Path] = group
 		tree.Expanded[gd.Path] = gd.Expanded
@@ -142,6 +147,17 @@ func NewGroupTreeWithGroups(instances []*Instance, storedGroups []*GroupData) *G
 	return tree
 }
 
+// ClaudeConfigDirs maps group paths to the Claude account they set
+func (t *GroupTree) ClaudeConfigDirs() map[string]string {
+	dirs := make(map[string]string)
+	for path, g := range t.Groups {
+		if g.ClaudeConfigDir != "" {
+			dirs[path] = g.ClaudeConfigDir
+		}
+	}
+	return dirs
+}
+
 // Note: GroupData is defined in storage.go in the same package
 
 // rebuildGroupList rebuilds the ordered group list
@@ -804,6 +820,8 @@ func (t *GroupTree) ShallowCopyForSave() *GroupTree {
 			Path:     g.Path,
 			Expanded: g.Expanded,
 			Order:    g.Order,
+
+			ClaudeConfigDir: g.ClaudeConfigDir,
 			// Don't copy Sessions - not needed for save, only metadata is saved
 		}
 	}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -28,6 +27,7 @@ const (
 	StatusIdle     Status = "idle"
 	StatusError    Status = "error"
 	StatusStarting Status = "starting" // Session is being created (tmux initializing)
+	StatusExited   Status = "exited"   // The agent process quit; the tmux session is still there
 )
 
 // Instance represents a single agent/shell session
@@ -51,10 +51,61 @@ type Instance struct {
 	GeminiSessionID  string    `json:"gemini_session_id,omitempty"`
 	GeminiDetectedAt time.Time `json:"gemini_detected_at,omitempty"`
 
+	// Conversation ID for other tools with an adapter (Codex, OpenCode, Aider)
+	ToolSessionID  string    `json:"tool_session_id,omitempty"`
+	ToolDetectedAt time.Time `json:"tool_detected_at,omitempty"`
+
 	// MCP tracking - which MCPs were loaded when session started/restarted
 	// Used to detect pending MCPs (added after session start) and stale MCPs (removed but still running)
 	LoadedMCPNames []string `json:"loaded_mcp_names,omitempty"`
 
+	// Layout names a [layouts.<name>] entry in config.toml; LayoutPanes are
+	// per-session extra panes created after the named layout's panes
+	Layout      string       `json:"layout,omitempty"`
+	LayoutPanes []LayoutPane `json:"layout_panes,omitempty"`
+
+	// Host names a [hosts.<name>] entry; the session's tmux runs there over SSH
+	// and ProjectPath is a path on that host. Empty = local.
+	Host string `json:"host,omitempty"`
+
+	// Worktree fields are set for sessions running in their own git worktree:
+	// WorktreePath is the checkout (== ProjectPath), WorktreeRepo the main
+	// repository it was created from, WorktreeBranch the checked-out branch
+	WorktreePath   string `json:"worktree_path,omitempty"`
+	WorktreeRepo   string `json:"worktree_repo,omitempty"`
+	WorktreeBranch string `json:"worktree_branch,omitempty"`
+
+	// ForkedFromID links a fork to the session it was forked from
+	ForkedFromID string `json:"forked_from_id,omitempty"`
+
+	// Env is extra environment for the agent, applied at start and restart.
+	// Values may reference agent-deck's environment: ${VAR}, ${VAR:-default}
+	Env map[string]string `json:"env,omitempty"`
+
+	// InitialMessage is sent once the agent is ready after the next start,
+	// then cleared (e.g. a template's first prompt for a session added via CLI)
+	InitialMessage string `json:"initial_message,omitempty"`
+
+	// ClaudeConfigDir is the Claude account ([claude.accounts] name or config
+	// directory) for this session; "" inherits from the group or profile
+	ClaudeConfigDir string `json:"claude_config_dir,omitempty"`
+
+	// inheritedClaudeConfigDir is the group's or profile's account (see ApplyClaudeConfigDirs)
+	inheritedClaudeConfigDir string
+
+	// RestartPolicy overrides the [restart] default for when the agent exits
+	RestartPolicy *RestartPolicy `json:"restart_policy,omitempty"`
+
+	// Exit tracking and automatic restarts (see restart.go). Not serialized.
+	exitSeen        bool      // The current exit was recorded
+	exitHandled     bool      // The current exit was logged and a restart scheduled
+	exitedAt        time.Time // When the agent was seen exited
+	exitCode        int       // Exit status (when exitCodeKnown)
+	exitCodeKnown   bool      // false if the shell or a signal hid the status
+	restartAttempts int       // Consecutive automatic restarts
+	nextRestartAt   time.Time // When AutoRestart may restart (zero = not scheduled)
+	lastAutoRestart time.Time
+
 	tmuxSession *tmux.Session // Internal tmux session
 
 	// lastErrorCheck tracks when we last confirmed the session doesn't exist
@@ -66,6 +117,9 @@ type Instance struct {
 	// Used to provide grace period for tmux session creation (prevents error flash)
 	// Not serialized - only relevant for current TUI session
 	lastStartTime time.Time
+
+	// lastSessionIDScan rate-limits session ID discovery (see updateAgentSession)
+	lastSessionIDScan time.Time
 }
 
 // MarkAccessed updates the LastAccessedAt timestamp to now
@@ -183,7 +237,7 @@ func (i *Instance) buildClaudeCommandWithMessage(baseCommand, message string) st
 		return baseCommand
 	}
 
-	configDir := GetClaudeConfigDir()
+	configDir := i.GetClaudeConfigDir()
 
 	// Check if dangerous mode is enabled in user config
 	dangerousMode := false
@@ -276,6 +330,106 @@ func (i *Instance) buildGeminiCommand(baseCommand string) string {
 	return baseCommand
 }
 
+// tmuxCustomization resolves the [tmux] config for this session's tool and group.
+// Returns nil when nothing is configured so tmux keeps its built-in defaults.
+func (i *Instance) tmuxCustomization() *tmux.Customization {
+	resolved := GetTmuxSettings().Resolve(i.Tool, i.GroupPath)
+	c := &tmux.Customization{
+		Options:     resolved.Options,
+		ConfigFile:  resolved.ConfigFile,
+		StatusLeft:  resolved.StatusLeft,
+		StatusRight: resolved.StatusRight,
+		Vars: map[string]string{
+			"title":  i.Title,
+			"group":  i.GroupPath,
+			"tool":   i.Tool,
+			"status": string(i.Status),
+			"folder": filepath.Base(i.ProjectPath),
+			"path":   i.ProjectPath,
+		},
+	}
+	if c.IsEmpty() {
+		return nil
+	}
+	return c
+}
+
+// syncTmuxCustomization hands the current [tmux] config and the session's
+// extra environment to the tmux session before it starts
+func (i *Instance) syncTmuxCustomization() {
+	if i.tmuxSession != nil {
+		i.tmuxSession.SetCustomization(i.tmuxCustomization())
+		i.tmuxSession.Env = i.resolvedEnv()
+	}
+}
+
+// layoutPanes resolves the session's layout into tmux panes:
+// the named config layout first, then the per-session panes
+func (i *Instance) layoutPanes() ([]tmux.LayoutPane, error) {
+	var panes []LayoutPane
+	if i.Layout != "" {
+		def, ok := GetLayout(i.Layout)
+		if !ok {
+			return nil, fmt.Errorf("layout '%s' not found in config.toml", i.Layout)
+		}
+		panes = append(panes, def.Panes...)
+	}
+	panes = append(panes, i.LayoutPanes...)
+
+	result := make([]tmux.LayoutPane, len(panes))
+	for idx, p := range panes {
+		result[idx] = tmux.LayoutPane{
+			Command: p.Command,
+			Window:  p.Window,
+			Name:    p.Name,
+			Split:   p.Split,
+			Size:    p.Size,
+		}
+	}
+	return result, nil
+}
+
+// SetHost moves a not-yet-started session to a remote host ("" = local)
+func (i *Instance) SetHost(host string) {
+	i.Host = host
+	if i.tmuxSession != nil {
+		i.tmuxSession.Host = host
+	}
+}
+
+// IsRemote returns true if the session runs on a remote host
+func (i *Instance) IsRemote() bool {
+	return i.Host != ""
+}
+
+// HasLayout returns true if the session has extra panes or windows
+func (i *Instance) HasLayout() bool {
+	return i.Layout != "" || len(i.LayoutPanes) > 0
+}
+
+// applyLayout creates the layout panes around a freshly started agent pane.
+// Failures are logged, not returned: the agent itself is already running.
+func (i *Instance) applyLayout() {
+	if !i.HasLayout() || i.tmuxSession == nil {
+		return
+	}
+	panes, err := i.layoutPanes()
+	if err == nil {
+		err = i.tmuxSession.ApplyLayout(panes)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to apply layout for %s: %v", i.Title, err)
+	}
+}
+
+// startCommand returns the command to run in tmux for a fresh start
+func (i *Instance) startCommand() string {
+	if a := GetToolAdapter(i.Tool); a != nil {
+		return a.StartCommand(i, i.Command)
+	}
+	return i.Command
+}
+
 // Start starts the session in tmux
 func (i *Instance) Start() error {
 	if i.tmuxSession == nil {
@@ -283,20 +437,14 @@ func (i *Instance) Start() error {
 	}
 
 	// Build command (adds config dir for claude, capture-resume for gemini)
-	var command string
-	switch i.Tool {
-	case "claude":
-		command = i.buildClaudeCommand(i.Command)
-	case "gemini":
-		command = i.buildGeminiCommand(i.Command)
-	default:
-		command = i.Command
-	}
+	command := i.startCommand()
 
 	// Start the tmux session
+	i.syncTmuxCustomization()
 	if err := i.tmuxSession.Start(command); err != nil {
 		return fmt.Errorf("failed to start tmux session: %w", err)
 	}
+	i.applyLayout()
 
 	// Capture MCPs that are now loaded (for sync tracking)
 	i.CaptureLoadedMCPs()
@@ -323,20 +471,14 @@ func (i *Instance) StartWithMessage(message string) error {
 	}
 
 	// Start session normally (no embedded message logic)
-	var command string
-	switch i.Tool {
-	case "claude":
-		command = i.buildClaudeCommand(i.Command)
-	case "gemini":
-		command = i.buildGeminiCommand(i.Command)
-	default:
-		command = i.Command
-	}
+	command := i.startCommand()
 
 	// Start the tmux session
+	i.syncTmuxCustomization()
 	if err := i.tmuxSession.Start(command); err != nil {
 		return fmt.Errorf("failed to start tmux session: %w", err)
 	}
+	i.applyLayout()
 
 	// Capture MCPs that are now loaded (for sync tracking)
 	i.CaptureLoadedMCPs()
@@ -355,6 +497,12 @@ func (i *Instance) StartWithMessage(message string) error {
 	return nil
 }
 
+// SendMessageWhenReady sends message once an already started agent is ready.
+// Blocks like StartWithMessage; the TUI runs it in the background.
+func (i *Instance) SendMessageWhenReady(message string) error {
+	return i.sendMessageWhenReady(message)
+}
+
 // sendMessageWhenReady waits for the agent to be ready and sends the message
 // Uses the existing status detection system which is robust and works for all tools
 //
@@ -371,8 +519,6 @@ func (i *Instance) sendMessageWhenReady(message string) error {
 		return fmt.Errorf("tmux session not initialized")
 	}
 
-	sessionName := i.tmuxSession.Name
-
 	// Track state transitions: we need to see "active" before accepting "waiting"
 	// This ensures we don't send the message during initial startup (false "waiting")
 	sawActive := false
@@ -410,19 +556,8 @@ func (i *Instance) sendMessageWhenReady(message string) error {
 			// Small delay to ensure UI is fully rendered
 			time.Sleep(300 * time.Millisecond)
 
-			// Send the message using tmux send-keys
-			// -l flag for literal text, then Enter separately
-			cmd := exec.Command("tmux", "send-keys", "-l", "-t", sessionName, message)
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to send message: %w", err)
-			}
-
-			cmd = exec.Command("tmux", "send-keys", "-t", sessionName, "Enter")
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to send Enter: %w", err)
-			}
-
-			return nil
+			// Paste or type the message and submit it as configured for the tool
+			return i.deliver(i.tmuxSession, message)
 		}
 	}
 
@@ -434,6 +569,10 @@ func (i *Instance) sendMessageWhenReady(message string) error {
 // instead of every 500ms tick, dramatically reducing subprocess spawns
 const errorRecheckInterval = 30 * time.Second
 
+// sessionIDRefreshInterval - how often UpdateStatus re-reads a known agent session ID
+// from the tmux environment (must stay well under CanFork's 5 minute freshness window)
+const sessionIDRefreshInterval = 30 * time.Second
+
 // UpdateStatus updates the session status by checking tmux
 func (i *Instance) UpdateStatus() error {
 	// Grace period FIRST: Skip all checks for recently created sessions
@@ -489,19 +628,23 @@ func (i *Instance) UpdateStatus() error {
 		i.Status = StatusError
 	}
 
+	// A quit agent leaves a dead pane or a bare shell that looks idle
+	if status != "active" && i.agentExited() {
+		i.Status = StatusExited
+	}
+
+	// Keep the {status} status bar variable current (no-op unless a template uses it)
+	i.tmuxSession.SetStatusVar(string(i.Status))
+
 	// Update tool detection dynamically (enables fork when Claude starts)
 	if detectedTool := i.tmuxSession.DetectTool(); detectedTool != "" {
 		i.Tool = detectedTool
 	}
 
-	// Update Claude session tracking (non-blocking, best-effort)
-	// Pass nil for excludeIDs - deduplication happens at manager level
-	i.UpdateClaudeSession(nil)
-
-	// Update Gemini session tracking (non-blocking, best-effort)
-	if i.Tool == "gemini" {
-		i.UpdateGeminiSession(nil)
-	}
+	// Update the agent's conversation ID (non-blocking, best-effort)
+	// Session IDs only change on start/restart, so once known we re-read them
+	// sparingly instead of spawning a subprocess or scanning files every tick
+	i.updateAgentSession()
 
 	return nil
 }
@@ -631,27 +774,30 @@ type ResponseOutput struct {
 }
 
 // GetLastResponse returns the last assistant response from the session
-// For Claude: Parses the JSONL file for the last assistant message
-// For Gemini: Parses the JSON session file for the last assistant message
-// For Codex/Others: Attempts to parse terminal output
+// For tools with an adapter: reads the tool's session store (Claude JSONL,
+// Gemini JSON, Codex rollout files, OpenCode storage, Aider chat history)
+// Claude and Gemini require a known session ID; other tools fall back to
+// parsing terminal output when the store can't be read
 func (i *Instance) GetLastResponse() (*ResponseOutput, error) {
-	if i.Tool == "claude" {
-		return i.getClaudeLastResponse()
+	a := i.adapter()
+	if a == nil {
+		return i.getTerminalLastResponse()
 	}
-	if i.Tool == "gemini" {
-		return i.getGeminiLastResponse()
+	resp, err := a.LastResponse(i, i.AgentSessionID())
+	if err != nil && i.Tool != "claude" && i.Tool != "gemini" {
+		return i.getTerminalLastResponse()
 	}
-	return i.getTerminalLastResponse()
+	return resp, err
 }
 
-// getClaudeLastResponse extracts the last assistant message from Claude's JSONL file
-func (i *Instance) getClaudeLastResponse() (*ResponseOutput, error) {
+// claudeSessionFile returns the JSONL file of the session's Claude conversation
+func (i *Instance) claudeSessionFile() (string, error) {
 	// Require stored session ID - no fallback to file scanning
 	if i.ClaudeSessionID == "" {
-		return nil, fmt.Errorf("no Claude session ID available for this instance")
+		return "", fmt.Errorf("no Claude session ID available for this instance")
 	}
 
-	configDir := GetClaudeConfigDir()
+	configDir := i.GetClaudeConfigDir()
 
 	// Resolve symlinks in project path (macOS: /tmp -> /private/tmp)
 	resolvedPath := i.ProjectPath
@@ -659,17 +805,23 @@ func (i *Instance) getClaudeLastResponse() (*ResponseOutput, error) {
 		resolvedPath = resolved
 	}
 
-	// Convert project path to Claude's directory format
-	// /Users/ashesh/claude-deck -> -Users-ashesh-claude-deck
-	projectDirName := strings.ReplaceAll(resolvedPath, "/", "-")
-	projectDir := filepath.Join(configDir, "projects", projectDirName)
+	projectDir := claudeProjectDir(configDir, resolvedPath)
 
 	// Use stored session ID directly
 	sessionFile := filepath.Join(projectDir, i.ClaudeSessionID+".jsonl")
 
 	// Check file exists
 	if _, err := os.Stat(sessionFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("session file not found: %s", sessionFile)
+		return "", fmt.Errorf("session file not found: %s", sessionFile)
+	}
+	return sessionFile, nil
+}
+
+// getClaudeLastResponse extracts the last assistant message from Claude's JSONL file
+func (i *Instance) getClaudeLastResponse() (*ResponseOutput, error) {
+	sessionFile, err := i.claudeSessionFile()
+	if err != nil {
+		return nil, err
 	}
 
 	// Read and parse the JSONL file
@@ -778,11 +930,11 @@ func parseClaudeLastAssistantMessage(data []byte, sessionID string) (*ResponseOu
 	}, nil
 }
 
-// getGeminiLastResponse extracts the last assistant message from Gemini's JSON file
-func (i *Instance) getGeminiLastResponse() (*ResponseOutput, error) {
+// geminiSessionFile returns the JSON file of the session's Gemini conversation
+func (i *Instance) geminiSessionFile() (string, error) {
 	// Require stored session ID - no fallback to file scanning
 	if i.GeminiSessionID == "" || len(i.GeminiSessionID) < 8 {
-		return nil, fmt.Errorf("no Gemini session ID available for this instance")
+		return "", fmt.Errorf("no Gemini session ID available for this instance")
 	}
 
 	sessionsDir := GetGeminiSessionsDir(i.ProjectPath)
@@ -792,9 +944,17 @@ func (i *Instance) getGeminiLastResponse() (*ResponseOutput, error) {
 	pattern := filepath.Join(sessionsDir, "session-*-"+i.GeminiSessionID[:8]+".json")
 	files, _ := filepath.Glob(pattern)
 	if len(files) == 0 {
-		return nil, fmt.Errorf("session file not found for ID: %s", i.GeminiSessionID)
+		return "", fmt.Errorf("session file not found for ID: %s", i.GeminiSessionID)
+	}
+	return files[0], nil
+}
+
+// getGeminiLastResponse extracts the last assistant message from Gemini's JSON file
+func (i *Instance) getGeminiLastResponse() (*ResponseOutput, error) {
+	sessionFile, err := i.geminiSessionFile()
+	if err != nil {
+		return nil, err
 	}
-	sessionFile := files[0]
 
 	// Read and parse the JSON file
 	data, err := os.ReadFile(sessionFile)
@@ -922,8 +1082,8 @@ func parseGeminiOutput(content string) (*ResponseOutput, error) {
 }
 
 // parseCodexOutput parses OpenAI Codex CLI output
+// Only used when the rollout file can't be read (see codexAdapter.LastResponse)
 func parseCodexOutput(content string) (*ResponseOutput, error) {
-	// Codex has similar structure - adapt as needed
 	return parseGenericOutput(content, "codex")
 }
 
@@ -1005,21 +1165,33 @@ func (i *Instance) Restart() error {
 		i.regenerateMCPConfig()
 	}
 
-	// If Claude session with known ID AND tmux session exists, use respawn-pane
-	if i.Tool == "claude" && i.ClaudeSessionID != "" && i.tmuxSession != nil && i.tmuxSession.Exists() {
-		// Build the resume command with proper config
-		resumeCmd := i.buildClaudeResumeCommand()
+	// Resume command for the tool's conversation, if we know it
+	resumeCmd := i.resumeCommand()
+
+	// If session with known ID (or an exited agent) AND tmux session exists, use respawn-pane
+	if (resumeCmd != "" || i.Status == StatusExited) && i.tmuxSession != nil && i.tmuxSession.Exists() {
+		if resumeCmd == "" {
+			resumeCmd = i.startCommand()
+		}
 		log.Printf("[MCP-DEBUG] Using respawn-pane with command: %s", resumeCmd)
 
+		// Pick up config and env changes made since the session started
+		i.syncTmuxCustomization()
+
 		// Use respawn-pane for atomic restart
 		// This is more reliable than Ctrl+C + wait for shell + send command
 		// respawn-pane -k kills the current process and starts the new command atomically
 		if err := i.tmuxSession.RespawnPane(resumeCmd); err != nil {
 			log.Printf("[MCP-DEBUG] RespawnPane failed: %v", err)
-			return fmt.Errorf("failed to restart Claude session: %w", err)
+			return fmt.Errorf("failed to restart %s session: %w", i.Tool, err)
 		}
 
 		log.Printf("[MCP-DEBUG] RespawnPane succeeded")
+		i.lastStartTime = time.Now()
+
+		// Re-apply tmux settings (config may have changed since the session started)
+		i.tmuxSession.ApplyCustomization()
+		i.tmuxSession.SourceConfigFile()
 
 		// Re-capture MCPs after restart (they may have changed since session started)
 		i.CaptureLoadedMCPs()
@@ -1033,27 +1205,15 @@ func (i *Instance) Restart() error {
 
 	// Fallback: recreate tmux session (for dead sessions or unknown ID)
 	i.tmuxSession = tmux.NewSession(i.Title, i.ProjectPath)
+	i.tmuxSession.Host = i.Host
 
-	var command string
-	if i.Tool == "claude" && i.ClaudeSessionID != "" {
-		command = i.buildClaudeResumeCommand()
-	} else if i.Tool == "gemini" && i.GeminiSessionID != "" {
-		// Set GEMINI_SESSION_ID in tmux env so detection works after restart
-		command = fmt.Sprintf("tmux set-environment GEMINI_SESSION_ID %s && gemini --resume %s",
-			i.GeminiSessionID, i.GeminiSessionID)
-	} else {
-		// Route to appropriate command builder based on tool
-		switch i.Tool {
-		case "claude":
-			command = i.buildClaudeCommand(i.Command)
-		case "gemini":
-			command = i.buildGeminiCommand(i.Command)
-		default:
-			command = i.Command
-		}
+	command := resumeCmd
+	if command == "" {
+		command = i.startCommand()
 	}
 	log.Printf("[MCP-DEBUG] Starting new tmux session with command: %s", command)
 
+	i.syncTmuxCustomization()
 	if err := i.tmuxSession.Start(command); err != nil {
 		log.Printf("[MCP-DEBUG] tmuxSession.Start() failed: %v", err)
 		i.Status = StatusError
@@ -1061,6 +1221,10 @@ func (i *Instance) Restart() error {
 	}
 
 	log.Printf("[MCP-DEBUG] tmuxSession.Start() succeeded")
+	i.lastStartTime = time.Now()
+
+	// The old panes died with the session - rebuild the layout
+	i.applyLayout()
 
 	// Re-capture MCPs after restart
 	i.CaptureLoadedMCPs()
@@ -1075,12 +1239,21 @@ func (i *Instance) Restart() error {
 	return nil
 }
 
+// resumeCommand returns the command that continues this session's
+// conversation, or "" if the tool can't resume or the ID isn't known
+func (i *Instance) resumeCommand() string {
+	a := i.adapter()
+	sessionID := i.AgentSessionID()
+	if a == nil || sessionID == "" {
+		return ""
+	}
+	return a.ResumeCommand(i, sessionID)
+}
+
 // buildClaudeResumeCommand builds the claude resume command with proper config options
-// Respects: CLAUDE_CONFIG_DIR, dangerous_mode from user config
+// Respects: the session's Claude config dir, dangerous_mode from user config
 // IMPORTANT: Also sets CLAUDE_SESSION_ID in tmux environment so detection works after restart
-func (i *Instance) buildClaudeResumeCommand() string {
-	configDir := GetClaudeConfigDir()
-
+func buildClaudeResumeCommand(configDir, sessionID string) string {
 	// Check if dangerous mode is enabled in user config
 	dangerousMode := false
 	if userConfig, err := LoadUserConfig(); err == nil && userConfig != nil {
@@ -1092,56 +1265,59 @@ func (i *Instance) buildClaudeResumeCommand() string {
 	// so GetSessionIDFromTmux() works correctly and detects the session
 	if dangerousMode {
 		return fmt.Sprintf("tmux set-environment CLAUDE_SESSION_ID %s && CLAUDE_CONFIG_DIR=%s claude --resume %s --dangerously-skip-permissions",
-			i.ClaudeSessionID, configDir, i.ClaudeSessionID)
+			sessionID, configDir, sessionID)
 	}
 	return fmt.Sprintf("tmux set-environment CLAUDE_SESSION_ID %s && CLAUDE_CONFIG_DIR=%s claude --resume %s",
-		i.ClaudeSessionID, configDir, i.ClaudeSessionID)
+		sessionID, configDir, sessionID)
 }
 
 // CanRestart returns true if the session can be restarted
-// For Claude sessions with known ID: can always restart (interrupt and resume)
-// For Gemini sessions with known ID: can always restart (interrupt and resume)
+// For sessions whose tool can resume a known conversation ID: can always
+// restart (interrupt and resume)
 // For other sessions: only if dead/error state
 func (i *Instance) CanRestart() bool {
-	// Gemini sessions with known session ID can always be restarted
-	if i.Tool == "gemini" && i.GeminiSessionID != "" {
+	if i.resumeCommand() != "" {
 		return true
 	}
 
-	// Claude sessions with known session ID can always be restarted
-	if i.Tool == "claude" && i.ClaudeSessionID != "" {
-		return true
-	}
-
-	// Other sessions: only if dead or error
-	return i.Status == StatusError || i.tmuxSession == nil || !i.tmuxSession.Exists()
+	// Other sessions: only if dead, error or the agent exited
+	return i.Status == StatusError || i.Status == StatusExited || i.tmuxSession == nil || !i.tmuxSession.Exists()
 }
 
-// CanFork returns true if this session can be forked
+// CanFork returns true if this session can be forked: the tool supports
+// forking (Gemini CLI doesn't) and the session ID is recent
 func (i *Instance) CanFork() bool {
-	// Gemini CLI doesn't support forking
-	if i.Tool == "gemini" {
+	if i.forkCommand(i.ProjectPath) == "" {
 		return false
 	}
-
-	// Claude sessions can fork if session ID is recent
-	if i.ClaudeSessionID == "" {
-		return false
-	}
-	return time.Since(i.ClaudeDetectedAt) < 5*time.Minute
+	return time.Since(i.agentSessionDetectedAt()) < 5*time.Minute
 }
 
-// Fork returns the command to create a forked Claude session
-// Uses capture-resume pattern: starts fork in print mode to get new session ID,
-// stores in tmux environment, then resumes interactively
+// Fork returns the command to create a forked session
+// For Claude uses capture-resume pattern: starts fork in print mode to get
+// new session ID, stores in tmux environment, then resumes interactively
 func (i *Instance) Fork(newTitle, newGroupPath string) (string, error) {
 	if !i.CanFork() {
-		return "", fmt.Errorf("cannot fork: no active Claude session")
+		return "", fmt.Errorf("cannot fork: no active %s session", i.Tool)
 	}
 
-	workDir := i.ProjectPath
-	configDir := GetClaudeConfigDir()
+	return i.forkCommand(i.ProjectPath), nil
+}
+
+// forkCommand builds the fork command running in workDir ("" if the tool
+// can't fork or the session ID isn't known)
+func (i *Instance) forkCommand(workDir string) string {
+	a := i.adapter()
+	sessionID := i.AgentSessionID()
+	if a == nil || sessionID == "" {
+		return ""
+	}
+	return a.ForkCommand(i, sessionID, workDir)
+}
 
+// buildClaudeForkCommand builds the claude fork command running in workDir
+// under the Claude account in configDir
+func buildClaudeForkCommand(configDir, sessionID, workDir string) string {
 	// Capture-resume pattern for fork:
 	// 1. Fork in print mode to get new session ID
 	// 2. Store in tmux environment
@@ -1150,9 +1326,9 @@ func (i *Instance) Fork(newTitle, newGroupPath string) (string, error) {
 		`cd %s && session_id=$(CLAUDE_CONFIG_DIR=%s claude -p "." --output-format json --resume %s --fork-session 2>/dev/null | jq -r '.session_id') && `+
 			`tmux set-environment CLAUDE_SESSION_ID "$session_id" && `+
 			`CLAUDE_CONFIG_DIR=%s claude --resume "$session_id" --dangerously-skip-permissions`,
-		workDir, configDir, i.ClaudeSessionID, configDir)
+		workDir, configDir, sessionID, configDir)
 
-	return cmd, nil
+	return cmd
 }
 
 // GetActualWorkDir returns the actual working directory from tmux, or falls back to ProjectPath
@@ -1174,16 +1350,39 @@ func (i *Instance) CreateForkedInstance(newTitle, newGroupPath string) (*Instanc
 
 	// Create new instance with the PARENT's project path
 	// This ensures the forked session is in the same Claude project directory as parent
+	forked := i.newForkedInstance(newTitle, newGroupPath)
+	forked.Command = cmd
+	return forked, cmd, nil
+}
+
+// newForkedInstance creates the child instance of a fork in the parent's
+// project path, group, host and layout. The caller sets Command.
+func (i *Instance) newForkedInstance(newTitle, newGroupPath string) *Instance {
 	forked := NewInstance(newTitle, i.ProjectPath)
 	if newGroupPath != "" {
 		forked.GroupPath = newGroupPath
 	} else {
 		forked.GroupPath = i.GroupPath
 	}
-	forked.Command = cmd
-	forked.Tool = "claude"
+	// Sessions recognised as Claude only by their conversation ID fork as Claude
+	forked.Tool = i.Tool
+	if GetToolAdapter(i.Tool) == nil {
+		forked.Tool = "claude"
+	}
+	forked.ForkedFromID = i.ID
 
-	return forked, cmd, nil
+	// Forks run on the parent's host with the same side panes
+	forked.SetHost(i.Host)
+	forked.Layout = i.Layout
+	forked.LayoutPanes = append([]LayoutPane(nil), i.LayoutPanes...)
+
+	// The conversation lives in the parent's Claude account, wherever the fork is grouped
+	forked.ClaudeConfigDir = i.ClaudeConfigDir
+	if forked.ClaudeConfigDir == "" {
+		forked.ClaudeConfigDir = i.inheritedClaudeConfigDir
+	}
+
+	return forked
 }
 
 // Exists checks if the tmux session still exists
@@ -1213,16 +1412,16 @@ func (i *Instance) GetSessionIDFromTmux() string {
 }
 
 // GetMCPInfo returns MCP server information for this session
-// Returns nil if not a Claude or Gemini session
+// Returns nil for tools without MCP support
 func (i *Instance) GetMCPInfo() *MCPInfo {
-	switch i.Tool {
-	case "claude":
-		return GetMCPInfo(i.ProjectPath)
-	case "gemini":
-		return GetGeminiMCPInfo(i.ProjectPath)
-	default:
-		return nil
+	if i.Tool == "claude" {
+		// Global and project MCPs come from the session's Claude account
+		return GetMCPInfoForConfigDir(i.GetClaudeConfigDir(), i.ProjectPath)
+	}
+	if a := GetToolAdapter(i.Tool); a != nil {
+		return a.MCPInfo(i.ProjectPath)
 	}
+	return nil
 }
 
 // CaptureLoadedMCPs captures the current MCP names as the "loaded" state
@@ -1234,7 +1433,7 @@ func (i *Instance) CaptureLoadedMCPs() {
 		return
 	}
 
-	mcpInfo := GetMCPInfo(i.ProjectPath)
+	mcpInfo := i.GetMCPInfo()
 	if mcpInfo == nil {
 		i.LoadedMCPNames = nil
 		return
@@ -1247,7 +1446,7 @@ func (i *Instance) CaptureLoadedMCPs() {
 // If socket pool is running, MCPs will use socket configs (nc -U /tmp/...)
 // Otherwise, MCPs will use stdio configs (npx ...)
 func (i *Instance) regenerateMCPConfig() {
-	mcpInfo := GetMCPInfo(i.ProjectPath)
+	mcpInfo := GetMCPInfoForConfigDir(i.GetClaudeConfigDir(), i.ProjectPath)
 	if mcpInfo == nil {
 		return
 	}
@@ -776,3 +776,37 @@ func TestInstance_CanRestart_Gemini(t *testing.T) {
 		t.Error("CanRestart() should work with stale session ID")
 	}
 }
+
+func TestInstance_LayoutPanes(t *testing.T) {
+	userConfigCacheMu.Lock()
+	saved := userConfigCache
+	userConfigCache = &UserConfig{Layouts: map[string]LayoutDef{
+		"dev": {Panes: []LayoutPane{{Command: "npm test", Split: "vertical", Size: "30%"}}},
+	}}
+	userConfigCacheMu.Unlock()
+	defer func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = saved
+		userConfigCacheMu.Unlock()
+	}()
+
+	inst := NewInstance("layout", "/tmp")
+	if inst.HasLayout() {
+		t.Error("new instance should have no layout")
+	}
+
+	inst.Layout = "dev"
+	inst.LayoutPanes = []LayoutPane{{Command: "npm run dev", Window: true}}
+	panes, err := inst.layoutPanes()
+	if err != nil {
+		t.Fatalf("layoutPanes: %v", err)
+	}
+	if len(panes) != 2 || panes[0].Command != "npm test" || panes[0].Split != "vertical" || !panes[1].Window {
+		t.Errorf("unexpected panes: %+v", panes)
+	}
+
+	inst.Layout = "missing"
+	if _, err := inst.layoutPanes(); err == nil {
+		t.Error("expected error for unknown layout")
+	}
+}
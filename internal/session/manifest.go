@@ -0,0 +1,296 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ManifestFileName is the project-local manifest read by `agent-deck up`
+const ManifestFileName = "agentdeck.toml"
+
+// Manifest describes the set of sessions a project runs (agentdeck.toml).
+// `agent-deck up` creates the missing ones and starts them, `down` stops them.
+type Manifest struct {
+	// Group is the default group for sessions that don't set one
+	Group string `toml:"group,omitempty"`
+
+	Sessions []ManifestSession `toml:"sessions"`
+
+	// dir is the manifest's directory; relative paths resolve against it
+	dir string
+}
+
+// ManifestSession is one [[sessions]] entry. Sessions are matched to existing
+// ones by title and path, so renaming either creates a new session.
+type ManifestSession struct {
+	Title string `toml:"title"`
+
+	// Path is the project directory, relative to the manifest (default ".")
+	Path string `toml:"path,omitempty"`
+
+	// Template from config.toml [templates.<name>] supplying unset fields
+	Template string `toml:"template,omitempty"`
+
+	Tool    string `toml:"tool,omitempty"`
+	Command string `toml:"command,omitempty"`
+	Group   string `toml:"group,omitempty"`
+
+	// Parent is the title of another session in the manifest
+	Parent string `toml:"parent,omitempty"`
+
+	MCPs []string          `toml:"mcps,omitempty"`
+	Env  map[string]string `toml:"env,omitempty"`
+
+	// Message is sent to the agent once it is ready after creation
+	Message string `toml:"message,omitempty"`
+
+	// Worktree runs the session in a new git worktree of Path on this branch
+	Worktree string `toml:"worktree,omitempty"`
+}
+
+// LoadManifest reads and validates a manifest file. Template settings are
+// merged into each session so callers see the effective values.
+func LoadManifest(file string) (*Manifest, error) {
+	var m Manifest
+	if _, err := toml.DecodeFile(file, &m); err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", file, err)
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	m.dir = filepath.Dir(abs)
+
+	for idx := range m.Sessions {
+		s := &m.Sessions[idx]
+		if s.Template == "" {
+			continue
+		}
+		tmpl, ok := GetTemplate(s.Template)
+		if !ok {
+			return nil, fmt.Errorf("session '%s': template '%s' not found in config.toml", s.Title, s.Template)
+		}
+		s.applyTemplate(tmpl)
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// applyTemplate fills the fields the session leaves empty from tmpl
+func (s *ManifestSession) applyTemplate(tmpl TemplateDef) {
+	if s.Tool == "" {
+		s.Tool = tmpl.Tool
+	}
+	if s.Command == "" {
+		s.Command = tmpl.Command
+	}
+	if s.Group == "" {
+		s.Group = tmpl.Group
+	}
+	if s.Parent == "" {
+		s.Parent = tmpl.Parent
+	}
+	if len(s.MCPs) == 0 {
+		s.MCPs = tmpl.MCPs
+	}
+	for k, v := range tmpl.Env {
+		if _, ok := s.Env[k]; !ok {
+			if s.Env == nil {
+				s.Env = make(map[string]string)
+			}
+			s.Env[k] = v
+		}
+	}
+	if s.Message == "" {
+		s.Message = tmpl.Message
+	}
+	if s.Worktree == "" {
+		s.Worktree = tmpl.Worktree
+	}
+}
+
+// Validate checks titles are unique and parents refer to top-level manifest sessions
+func (m *Manifest) Validate() error {
+	byTitle := make(map[string]ManifestSession, len(m.Sessions))
+	for _, s := range m.Sessions {
+		if s.Title == "" {
+			return fmt.Errorf("manifest session without a title")
+		}
+		if _, dup := byTitle[s.Title]; dup {
+			return fmt.Errorf("duplicate session title '%s' in manifest", s.Title)
+		}
+		byTitle[s.Title] = s
+	}
+	for _, s := range m.Sessions {
+		if s.Parent == "" {
+			continue
+		}
+		parent, ok := byTitle[s.Parent]
+		if !ok {
+			return fmt.Errorf("session '%s': parent '%s' is not in the manifest", s.Title, s.Parent)
+		}
+		if parent.Parent != "" {
+			return fmt.Errorf("session '%s': parent '%s' is itself a sub-session (single level only)", s.Title, s.Parent)
+		}
+	}
+	return nil
+}
+
+// SessionPath returns the absolute project path of a manifest session
+func (m *Manifest) SessionPath(s ManifestSession) string {
+	path := expandTilde(s.Path)
+	if path == "" {
+		path = "."
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.dir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// SessionGroup returns the group of a manifest session (the manifest default if unset)
+func (m *Manifest) SessionGroup(s ManifestSession) string {
+	if s.Group != "" {
+		return s.Group
+	}
+	return m.Group
+}
+
+// Ordered returns the sessions with parents before their sub-sessions
+func (m *Manifest) Ordered() []ManifestSession {
+	ordered := make([]ManifestSession, 0, len(m.Sessions))
+	for _, s := range m.Sessions {
+		if s.Parent == "" {
+			ordered = append(ordered, s)
+		}
+	}
+	for _, s := range m.Sessions {
+		if s.Parent != "" {
+			ordered = append(ordered, s)
+		}
+	}
+	return ordered
+}
+
+// FindInstance returns the existing session for s: same title and the same
+// path (or, for worktree sessions, created from that repository)
+func (m *Manifest) FindInstance(s ManifestSession, instances []*Instance) *Instance {
+	path := m.SessionPath(s)
+	for _, inst := range instances {
+		if inst.Title != s.Title {
+			continue
+		}
+		if inst.ProjectPath == path || (inst.IsWorktree() && inst.WorktreeRepo == path) {
+			return inst
+		}
+	}
+	return nil
+}
+
+// ExportManifest builds a manifest for dir from existing sessions. Paths
+// inside dir become relative; a group shared by all sessions becomes the default.
+func ExportManifest(instances []*Instance, dir string) *Manifest {
+	m := &Manifest{dir: dir}
+	byID := make(map[string]*Instance, len(instances))
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+	available := GetAvailableMCPs()
+
+	for _, inst := range instances {
+		path := inst.ProjectPath
+		s := ManifestSession{
+			Title: inst.Title,
+			Group: inst.GroupPath,
+			Env:   inst.Env,
+		}
+		if inst.IsWorktree() {
+			path = inst.WorktreeRepo
+			s.Worktree = inst.WorktreeBranch
+		}
+		s.Path = manifestRelPath(dir, path)
+		if inst.Tool != "" && inst.Tool != "shell" {
+			s.Tool = inst.Tool
+		}
+		if inst.Command != s.Tool {
+			s.Command = inst.Command
+		}
+		if parent, ok := byID[inst.ParentSessionID]; ok {
+			s.Parent = parent.Title
+		}
+		// Project MCPs that `up` can write back from config.toml
+		if inst.Tool == "claude" {
+			if info := GetMCPInfo(inst.ProjectPath); info != nil {
+				for _, local := range info.LocalMCPs {
+					if _, ok := available[local.Name]; ok && samePath(local.SourcePath, inst.ProjectPath) {
+						s.MCPs = append(s.MCPs, local.Name)
+					}
+				}
+				sort.Strings(s.MCPs)
+			}
+		}
+		m.Sessions = append(m.Sessions, s)
+	}
+
+	// Hoist a group every session shares
+	if len(m.Sessions) > 0 {
+		shared := m.Sessions[0].Group
+		for _, s := range m.Sessions[1:] {
+			if s.Group != shared {
+				shared = ""
+				break
+			}
+		}
+		if shared != "" {
+			m.Group = shared
+			for idx := range m.Sessions {
+				m.Sessions[idx].Group = ""
+			}
+		}
+	}
+	return m
+}
+
+// manifestRelPath returns path relative to dir when it lies inside dir
+func manifestRelPath(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	if rel == "." {
+		return ""
+	}
+	return rel
+}
+
+// Encode renders the manifest as TOML
+func (m *Manifest) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# Agent Deck workspace manifest\n")
+	buf.WriteString("# agent-deck up    creates missing sessions and starts them\n")
+	buf.WriteString("# agent-deck down  stops them\n\n")
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the manifest to file
+func (m *Manifest) WriteFile(file string) error {
+	data, err := m.Encode()
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return os.WriteFile(file, data, 0644)
+}
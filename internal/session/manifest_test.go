@@ -0,0 +1,148 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	withUserConfig(t, &UserConfig{Templates: map[string]TemplateDef{
+		"reviewer": {Tool: "claude", Group: "reviews", Message: "Review {project}", Env: map[string]string{"A": "tmpl", "B": "tmpl"}},
+	}})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, ManifestFileName)
+	writeTestFile(t, file, `
+group = "clients/acme"
+
+[[sessions]]
+title = "planner"
+tool = "claude"
+
+[[sessions]]
+title = "reviewer"
+path = "api"
+template = "reviewer"
+parent = "planner"
+env = { A = "own" }
+
+[[sessions]]
+title = "shell"
+path = "/abs/elsewhere"
+group = "misc"
+`)
+
+	m, err := LoadManifest(file)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	planner, reviewer, shell := m.Sessions[0], m.Sessions[1], m.Sessions[2]
+
+	if m.SessionPath(planner) != dir || m.SessionPath(reviewer) != filepath.Join(dir, "api") {
+		t.Errorf("relative paths should resolve against the manifest: %q %q", m.SessionPath(planner), m.SessionPath(reviewer))
+	}
+	if m.SessionPath(shell) != "/abs/elsewhere" {
+		t.Errorf("SessionPath(abs) = %q", m.SessionPath(shell))
+	}
+	if m.SessionGroup(planner) != "clients/acme" || m.SessionGroup(shell) != "misc" {
+		t.Errorf("SessionGroup: %q %q", m.SessionGroup(planner), m.SessionGroup(shell))
+	}
+
+	// Template fills only what the session leaves unset
+	if reviewer.Tool != "claude" || reviewer.Message != "Review {project}" || reviewer.Group != "reviews" {
+		t.Errorf("template not merged: %+v", reviewer)
+	}
+	if reviewer.Env["A"] != "own" || reviewer.Env["B"] != "tmpl" {
+		t.Errorf("Env = %v", reviewer.Env)
+	}
+
+	if ordered := m.Ordered(); ordered[len(ordered)-1].Title != "reviewer" {
+		t.Errorf("sub-sessions should come after parents: %v", ordered)
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	bad := map[string]string{
+		"no title":  "[[sessions]]\npath = \".\"\n",
+		"duplicate": "[[sessions]]\ntitle = \"a\"\n[[sessions]]\ntitle = \"a\"\n",
+		"no parent": "[[sessions]]\ntitle = \"a\"\nparent = \"missing\"\n",
+		"nested":    "[[sessions]]\ntitle = \"a\"\n[[sessions]]\ntitle = \"b\"\nparent = \"a\"\n[[sessions]]\ntitle = \"c\"\nparent = \"b\"\n",
+		"template":  "[[sessions]]\ntitle = \"a\"\ntemplate = \"missing\"\n",
+	}
+	withUserConfig(t, &UserConfig{})
+	for name, content := range bad {
+		file := filepath.Join(t.TempDir(), ManifestFileName)
+		writeTestFile(t, file, content)
+		if _, err := LoadManifest(file); err == nil {
+			t.Errorf("%s: LoadManifest should fail", name)
+		}
+	}
+}
+
+func TestManifestFindInstance(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manifest{dir: dir}
+	s := ManifestSession{Title: "api", Path: "api"}
+
+	other := NewInstance("api", filepath.Join(dir, "other"))
+	match := NewInstance("api", filepath.Join(dir, "api"))
+	if got := m.FindInstance(s, []*Instance{other}); got != nil {
+		t.Error("same title at another path should not match")
+	}
+	if got := m.FindInstance(s, []*Instance{other, match}); got != match {
+		t.Error("title + path should match")
+	}
+
+	wt := NewInstance("api", "/worktrees/api/feature")
+	wt.WorktreePath, wt.WorktreeRepo, wt.WorktreeBranch = wt.ProjectPath, filepath.Join(dir, "api"), "feature"
+	if got := m.FindInstance(s, []*Instance{wt}); got != wt {
+		t.Error("worktree sessions should match by repository")
+	}
+}
+
+func TestExportManifest(t *testing.T) {
+	withUserConfig(t, &UserConfig{})
+	dir := t.TempDir()
+
+	parent := NewInstanceWithGroupAndTool("planner", dir, "acme", "claude")
+	parent.Command = "claude"
+	child := NewInstanceWithGroupAndTool("server", filepath.Join(dir, "web"), "acme", "shell")
+	child.Command = "npm run dev"
+	child.SetParent(parent.ID)
+	child.Env = map[string]string{"PORT": "3000"}
+
+	m := ExportManifest([]*Instance{parent, child}, dir)
+	if m.Group != "acme" {
+		t.Errorf("shared group should be hoisted, got %q", m.Group)
+	}
+
+	file := filepath.Join(dir, ManifestFileName)
+	if err := m.WriteFile(file); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(file)
+	if strings.Contains(string(data), dir) {
+		t.Errorf("paths inside the manifest dir should be relative:\n%s", data)
+	}
+
+	// Round trip: the written manifest finds the sessions it came from
+	loaded, err := LoadManifest(file)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v\n%s", err, data)
+	}
+	if len(loaded.Sessions) != 2 {
+		t.Fatalf("got %d sessions", len(loaded.Sessions))
+	}
+	planner, server := loaded.Sessions[0], loaded.Sessions[1]
+	if planner.Tool != "claude" || planner.Command != "" {
+		t.Errorf("planner = %+v", planner)
+	}
+	if server.Parent != "planner" || server.Command != "npm run dev" || server.Env["PORT"] != "3000" {
+		t.Errorf("server = %+v", server)
+	}
+	if loaded.FindInstance(server, []*Instance{parent, child}) != child {
+		t.Error("exported session should match its instance")
+	}
+}
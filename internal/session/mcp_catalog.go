@@ -183,10 +183,9 @@ func WriteMCPJsonFromConfig(projectPath string, enabledNames []string) error {
 	return nil
 }
 
-// WriteGlobalMCP adds or removes MCPs from Claude's global config
-// This modifies ~/.claude-work/.claude.json → mcpServers
-func WriteGlobalMCP(enabledNames []string) error {
-	configDir := GetClaudeConfigDir()
+// WriteGlobalMCP adds or removes MCPs from Claude's global config in configDir
+// This modifies e.g. ~/.claude-work/.claude.json → mcpServers
+func WriteGlobalMCP(configDir string, enabledNames []string) error {
 	configFile := filepath.Join(configDir, ".claude.json")
 
 	// Read existing config (preserve other fields like projects, settings, etc.)
@@ -314,9 +313,8 @@ func WriteGlobalMCP(enabledNames []string) error {
 	return nil
 }
 
-// GetGlobalMCPNames returns the names of MCPs currently in Claude's global config
-func GetGlobalMCPNames() []string {
-	configDir := GetClaudeConfigDir()
+// GetGlobalMCPNames returns the names of MCPs currently in Claude's global config in configDir
+func GetGlobalMCPNames(configDir string) []string {
 	configFile := filepath.Join(configDir, ".claude.json")
 
 	data, err := os.ReadFile(configFile)
@@ -339,9 +337,8 @@ func GetGlobalMCPNames() []string {
 	return names
 }
 
-// GetProjectMCPNames returns MCPs from projects[path].mcpServers in Claude's config
-func GetProjectMCPNames(projectPath string) []string {
-	configDir := GetClaudeConfigDir()
+// GetProjectMCPNames returns MCPs from projects[path].mcpServers in Claude's config in configDir
+func GetProjectMCPNames(configDir, projectPath string) []string {
 	configFile := filepath.Join(configDir, ".claude.json")
 
 	data, err := os.ReadFile(configFile)
@@ -371,9 +368,8 @@ func GetProjectMCPNames(projectPath string) []string {
 	return names
 }
 
-// ClearProjectMCPs removes all MCPs from projects[path].mcpServers in Claude's config
-func ClearProjectMCPs(projectPath string) error {
-	configDir := GetClaudeConfigDir()
+// ClearProjectMCPs removes all MCPs from projects[path].mcpServers in Claude's config in configDir
+func ClearProjectMCPs(configDir, projectPath string) error {
 	configFile := filepath.Join(configDir, ".claude.json")
 
 	// Read existing config
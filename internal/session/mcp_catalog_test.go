@@ -162,7 +162,7 @@ func TestGetGlobalMCPNames(t *testing.T) {
 	}
 
 	// Test GetGlobalMCPNames
-	names := GetGlobalMCPNames()
+	names := GetGlobalMCPNames(GetClaudeConfigDir())
 	if len(names) != 2 {
 		t.Errorf("Expected 2 MCPs, got %d", len(names))
 	}
@@ -214,7 +214,7 @@ func TestGetProjectMCPNames(t *testing.T) {
 	}
 
 	// Test GetProjectMCPNames
-	names := GetProjectMCPNames(projectPath)
+	names := GetProjectMCPNames(GetClaudeConfigDir(), projectPath)
 	if len(names) != 1 {
 		t.Errorf("Expected 1 MCP, got %d", len(names))
 	}
@@ -223,7 +223,7 @@ func TestGetProjectMCPNames(t *testing.T) {
 	}
 
 	// Non-existent project should return nil
-	names2 := GetProjectMCPNames("/other/path")
+	names2 := GetProjectMCPNames(GetClaudeConfigDir(), "/other/path")
 	if names2 != nil {
 		t.Errorf("Expected nil for non-existent project, got %v", names2)
 	}
@@ -0,0 +1,223 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GetOpenCodeDataDir returns $XDG_DATA_HOME/opencode, or ~/.local/share/opencode
+func GetOpenCodeDataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "opencode")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "opencode")
+}
+
+// GetOpenCodeConfigDir returns $XDG_CONFIG_HOME/opencode, or ~/.config/opencode
+func GetOpenCodeConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "opencode")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "opencode")
+}
+
+// openCodeStorageDir holds OpenCode's JSON store:
+//
+//	session/<project id>/<session id>.json
+//	message/<session id>/<message id>.json
+//	part/<message id>/<part id>.json
+func openCodeStorageDir() string {
+	return filepath.Join(GetOpenCodeDataDir(), "storage")
+}
+
+// openCodeTime is the {created, updated, completed} block, in Unix milliseconds
+type openCodeTime struct {
+	Created   int64 `json:"created"`
+	Updated   int64 `json:"updated"`
+	Completed int64 `json:"completed"`
+}
+
+type openCodeSession struct {
+	ID        string       `json:"id"`
+	Directory string       `json:"directory"`
+	ParentID  string       `json:"parentID"`
+	Time      openCodeTime `json:"time"`
+}
+
+type openCodeMessage struct {
+	ID   string       `json:"id"`
+	Role string       `json:"role"`
+	Time openCodeTime `json:"time"`
+}
+
+type openCodePart struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// readJSONFiles unmarshals every *.json file in dir into a new T
+func readJSONFiles[T any](dir string) []T {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var out []T
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			continue
+		}
+		var v T
+		if json.Unmarshal(data, &v) == nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// findOpenCodeSession returns the most recently updated top-level session in
+// projectPath that was active since `since`
+func findOpenCodeSession(projectPath string, since time.Time) (openCodeSession, bool) {
+	var best openCodeSession
+	found := false
+	projects, _ := os.ReadDir(filepath.Join(openCodeStorageDir(), "session"))
+	for _, p := range projects {
+		if !p.IsDir() {
+			continue
+		}
+		for _, s := range readJSONFiles[openCodeSession](filepath.Join(openCodeStorageDir(), "session", p.Name())) {
+			// Child sessions belong to subagents, not to the conversation in the pane
+			if s.ID == "" || s.ParentID != "" || !samePath(s.Directory, projectPath) {
+				continue
+			}
+			if time.UnixMilli(s.Time.Updated).Before(since) {
+				continue
+			}
+			if !found || s.Time.Updated > best.Time.Updated {
+				best, found = s, true
+			}
+		}
+	}
+	return best, found
+}
+
+// openCodeLastAssistantMessage reads the text parts of the last assistant
+// message of sessionID
+func openCodeLastAssistantMessage(sessionID string) (*ResponseOutput, error) {
+	messages := readJSONFiles[openCodeMessage](filepath.Join(openCodeStorageDir(), "message", sessionID))
+	sort.Slice(messages, func(a, b int) bool { return messages[a].Time.Created < messages[b].Time.Created })
+
+	for idx := len(messages) - 1; idx >= 0; idx-- {
+		msg := messages[idx]
+		if msg.Role != "assistant" {
+			continue
+		}
+		parts := readJSONFiles[openCodePart](filepath.Join(openCodeStorageDir(), "part", msg.ID))
+		// Part IDs sort in creation order
+		sort.Slice(parts, func(a, b int) bool { return parts[a].ID < parts[b].ID })
+
+		var text []string
+		for _, part := range parts {
+			if part.Type == "text" && strings.TrimSpace(part.Text) != "" {
+				text = append(text, part.Text)
+			}
+		}
+		if len(text) == 0 {
+			continue // tool-call-only message
+		}
+
+		var timestamp string
+		if msg.Time.Created > 0 {
+			timestamp = time.UnixMilli(msg.Time.Created).UTC().Format(time.RFC3339)
+		}
+		return &ResponseOutput{
+			Tool:      "opencode",
+			Role:      "assistant",
+			Content:   strings.Join(text, "\n"),
+			Timestamp: timestamp,
+			SessionID: sessionID,
+		}, nil
+	}
+	return nil, fmt.Errorf("no assistant response found in session")
+}
+
+// openCodeMCPServers returns the names under "mcp" in an opencode.json
+func openCodeMCPServers(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var config struct {
+		MCP map[string]json.RawMessage `json:"mcp"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	var names []string
+	for name := range config.MCP {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetOpenCodeMCPInfo lists MCP servers from the global opencode.json and
+// the project's opencode.json
+func GetOpenCodeMCPInfo(projectPath string) *MCPInfo {
+	return &MCPInfo{
+		Global:  openCodeMCPServers(filepath.Join(GetOpenCodeConfigDir(), "opencode.json")),
+		Project: openCodeMCPServers(filepath.Join(projectPath, "opencode.json")),
+	}
+}
+
+// opencodeAdapter: OpenCode keeps sessions, messages and parts as JSON files
+// under its data directory and resumes with `opencode --session <id>`
+type opencodeAdapter struct{}
+
+func (opencodeAdapter) StartCommand(i *Instance, command string) string {
+	return command
+}
+
+func (opencodeAdapter) DiscoverSessionID(i *Instance) string {
+	if i.IsRemote() {
+		return ""
+	}
+	since := i.CreatedAt
+	if i.lastStartTime.After(since) {
+		since = i.lastStartTime
+	}
+	if s, ok := findOpenCodeSession(i.ProjectPath, since); ok {
+		return s.ID
+	}
+	return ""
+}
+
+func (opencodeAdapter) ResumeCommand(i *Instance, sessionID string) string {
+	return "opencode --session " + sessionID
+}
+
+// ForkCommand: OpenCode can't fork a session from the command line
+func (opencodeAdapter) ForkCommand(i *Instance, sessionID, workDir string) string {
+	return ""
+}
+
+func (opencodeAdapter) LastResponse(i *Instance, sessionID string) (*ResponseOutput, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("no OpenCode session ID available for this instance")
+	}
+	return openCodeLastAssistantMessage(sessionID)
+}
+
+// MCPConfigPath is the project's opencode.json (OpenCode also reads the global one)
+func (opencodeAdapter) MCPConfigPath(projectPath string) string {
+	return filepath.Join(projectPath, "opencode.json")
+}
+
+func (opencodeAdapter) MCPInfo(projectPath string) *MCPInfo {
+	return GetOpenCodeMCPInfo(projectPath)
+}
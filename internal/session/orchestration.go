@@ -0,0 +1,147 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Cleanup policies for orchestrated child sessions
+const (
+	CleanupKeep            = "keep"              // Leave children running (default)
+	CleanupStop            = "stop"              // Stop children, keep them in the list
+	CleanupRemove          = "remove"            // Stop and remove children (and their worktrees)
+	CleanupRemoveOnSuccess = "remove-on-success" // Remove children that finished; keep failed ones to inspect
+)
+
+const defaultOrchestrationTimeout = time.Hour
+
+// Orchestration is a task list for `agent-deck orchestrate`: child sessions a
+// parent session spawns, waits for, and collects the final responses of.
+// Each task is a manifest session whose message is the task's prompt.
+type Orchestration struct {
+	// Template, Tool and MCPs are defaults for tasks that don't set them
+	Template string   `toml:"template,omitempty"`
+	Tool     string   `toml:"tool,omitempty"`
+	MCPs     []string `toml:"mcps,omitempty"`
+
+	// Timeout bounds the whole run (default "1h")
+	Timeout string `toml:"timeout,omitempty"`
+
+	// Cleanup is what happens to the children once the report is written
+	Cleanup string `toml:"cleanup,omitempty"`
+
+	// Report is a file for the aggregated report ("" = send it to the parent)
+	Report string `toml:"report,omitempty"`
+
+	Tasks []ManifestSession `toml:"tasks"`
+
+	// dir is the task file's directory; relative paths resolve against it
+	dir string
+}
+
+// LoadOrchestration reads a task list file (TOML)
+func LoadOrchestration(file string) (*Orchestration, error) {
+	var o Orchestration
+	if _, err := toml.DecodeFile(file, &o); err != nil {
+		return nil, fmt.Errorf("failed to read task list %s: %w", file, err)
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	o.dir = filepath.Dir(abs)
+	if o.Report != "" && !filepath.IsAbs(expandTilde(o.Report)) {
+		o.Report = filepath.Join(o.dir, o.Report)
+	}
+	return &o, nil
+}
+
+// Prepare fills in task defaults for children of parent: titles
+// ("<parent>-task-N"), the parent's project path, the task's template, then
+// the list's defaults, then the parent's tool. Relative task paths resolve against the task file's
+// directory (or dir, for task lists built in code).
+func (o *Orchestration) Prepare(dir string, parent *Instance) error {
+	if o.dir == "" {
+		o.dir = dir
+	}
+	for idx := range o.Tasks {
+		t := &o.Tasks[idx]
+		if t.Title == "" {
+			t.Title = fmt.Sprintf("%s-task-%d", parent.Title, idx+1)
+		}
+		if t.Path == "" {
+			t.Path = parent.ProjectPath
+		}
+		if t.Template == "" {
+			t.Template = o.Template
+		}
+		if t.Template != "" {
+			tmpl, ok := GetTemplate(t.Template)
+			if !ok {
+				return fmt.Errorf("task '%s': template '%s' not found in config.toml", t.Title, t.Template)
+			}
+			t.applyTemplate(tmpl)
+		}
+		if t.Tool == "" && t.Command == "" {
+			t.Tool = o.Tool
+		}
+		// Agents spawn agents like themselves unless told otherwise
+		if t.Tool == "" && t.Command == "" && parent.Tool != "shell" {
+			t.Tool = parent.Tool
+		}
+		if len(t.MCPs) == 0 {
+			t.MCPs = o.MCPs
+		}
+		// Children are sub-sessions of the parent, in its group
+		t.Parent = parent.Title
+		t.Group = ""
+	}
+	return o.Validate()
+}
+
+// Validate checks the task list
+func (o *Orchestration) Validate() error {
+	if len(o.Tasks) == 0 {
+		return fmt.Errorf("no tasks")
+	}
+	seen := make(map[string]bool, len(o.Tasks))
+	for _, t := range o.Tasks {
+		if t.Title == "" {
+			return fmt.Errorf("task without a title")
+		}
+		if seen[t.Title] {
+			return fmt.Errorf("duplicate task title '%s'", t.Title)
+		}
+		seen[t.Title] = true
+		if t.Message == "" {
+			return fmt.Errorf("task '%s' has no message (its prompt)", t.Title)
+		}
+	}
+	switch o.Cleanup {
+	case "", CleanupKeep, CleanupStop, CleanupRemove, CleanupRemoveOnSuccess:
+	default:
+		return fmt.Errorf("invalid cleanup policy '%s' (use keep, stop, remove or remove-on-success)", o.Cleanup)
+	}
+	if o.Timeout != "" {
+		if d, err := time.ParseDuration(o.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s' (use a duration like 30m)", o.Timeout)
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns Timeout or its default
+func (o *Orchestration) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(o.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultOrchestrationTimeout
+}
+
+// Manifest returns the tasks as a manifest, for creating the child sessions
+func (o *Orchestration) Manifest() *Manifest {
+	return &Manifest{Sessions: o.Tasks, dir: o.dir}
+}
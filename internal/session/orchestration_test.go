@@ -0,0 +1,90 @@
+package session
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadOrchestration(t *testing.T) {
+	withUserConfig(t, &UserConfig{Templates: map[string]TemplateDef{
+		"tester": {Tool: "claude", MCPs: []string{"github"}, Message: "Run the tests in {project}"},
+	}})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tasks.toml")
+	writeTestFile(t, file, `
+tool = "codex"
+timeout = "20m"
+cleanup = "remove-on-success"
+report = "report.md"
+
+[[tasks]]
+message = "Fix the flaky login test"
+
+[[tasks]]
+title = "api-tests"
+template = "tester"
+path = "api"
+worktree = "orch/api"
+`)
+
+	o, err := LoadOrchestration(file)
+	if err != nil {
+		t.Fatalf("LoadOrchestration: %v", err)
+	}
+	if o.Report != filepath.Join(dir, "report.md") {
+		t.Errorf("Report = %q, want it relative to the task file", o.Report)
+	}
+
+	parent := NewInstanceWithGroup("lead", "/work/repo", "team")
+	if err := o.Prepare("", parent); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	first, second := o.Tasks[0], o.Tasks[1]
+	if first.Title != "lead-task-1" || first.Tool != "codex" || first.Parent != "lead" {
+		t.Errorf("first task defaults = %+v", first)
+	}
+	m := o.Manifest()
+	if m.SessionPath(first) != "/work/repo" || m.SessionPath(second) != filepath.Join(dir, "api") {
+		t.Errorf("paths = %q, %q", m.SessionPath(first), m.SessionPath(second))
+	}
+	if second.Tool != "claude" || second.Message != "Run the tests in {project}" || len(second.MCPs) != 1 {
+		t.Errorf("template not merged: %+v", second)
+	}
+
+	adhoc := &Orchestration{Tasks: []ManifestSession{{Message: "Research X"}}}
+	parent.Tool = "gemini"
+	if err := adhoc.Prepare(dir, parent); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if adhoc.Tasks[0].Tool != "gemini" {
+		t.Errorf("task without a tool = %q, want the parent's tool", adhoc.Tasks[0].Tool)
+	}
+	if o.TimeoutDuration() != 20*time.Minute {
+		t.Errorf("TimeoutDuration() = %s", o.TimeoutDuration())
+	}
+}
+
+func TestOrchestrationValidate(t *testing.T) {
+	parent := NewInstance("lead", "/work/repo")
+	tests := []struct {
+		o    Orchestration
+		want string
+	}{
+		{Orchestration{}, "no tasks"},
+		{Orchestration{Tasks: []ManifestSession{{Title: "a"}}}, "no message"},
+		{Orchestration{Tasks: []ManifestSession{{Title: "a", Message: "x"}, {Title: "a", Message: "y"}}}, "duplicate"},
+		{Orchestration{Cleanup: "sometimes", Tasks: []ManifestSession{{Message: "x"}}}, "cleanup"},
+		{Orchestration{Timeout: "soon", Tasks: []ManifestSession{{Message: "x"}}}, "timeout"},
+		{Orchestration{Template: "missing", Tasks: []ManifestSession{{Message: "x"}}}, "template"},
+	}
+	for _, tt := range tests {
+		o := tt.o
+		err := o.Prepare(t.TempDir(), parent)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Prepare(%+v) = %v, want error containing %q", tt.o, err, tt.want)
+		}
+	}
+}
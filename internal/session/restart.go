@@ -0,0 +1,272 @@
+package session
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// Restart policies: what happens when a session's agent exits
+const (
+	RestartNever     = "never" // Default: leave the session exited
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+const (
+	defaultMaxRestarts    = 3
+	defaultRestartBackoff = 5 * time.Second
+	maxRestartBackoff     = 5 * time.Minute
+
+	// exitGracePeriod ignores the shell a pane shows while an agent launches
+	exitGracePeriod = 10 * time.Second
+
+	// restartResetAfter forgets past restarts once the agent stays up this long
+	restartResetAfter = 10 * time.Minute
+
+	exitLogFileName = "exits.log"
+)
+
+// RestartPolicy says whether an agent that exits (crash, OOM, /exit) is
+// restarted, with resume, automatically. [restart] in config.toml is the
+// default; sessions and templates can set their own.
+type RestartPolicy struct {
+	// When is never, on-failure or always. An agent that falls back to the
+	// shell or is killed by a signal has no known exit status and counts as
+	// a failure.
+	When string `json:"when,omitempty" toml:"when"`
+
+	// MaxRetries is the number of consecutive restarts before giving up (default 3)
+	MaxRetries int `json:"max_retries,omitempty" toml:"max_retries"`
+
+	// Backoff is the delay before the first restart, doubled for each
+	// following one (default "5s", at most 5m)
+	Backoff string `json:"backoff,omitempty" toml:"backoff"`
+}
+
+// maxRetries returns MaxRetries or its default
+func (p RestartPolicy) maxRetries() int {
+	if p.MaxRetries > 0 {
+		return p.MaxRetries
+	}
+	return defaultMaxRestarts
+}
+
+// delay returns the wait before restart number attempt (0-based)
+func (p RestartPolicy) delay(attempt int) time.Duration {
+	d := defaultRestartBackoff
+	if parsed, err := time.ParseDuration(p.Backoff); err == nil && parsed > 0 {
+		d = parsed
+	}
+	for n := 0; n < attempt && d < maxRestartBackoff; n++ {
+		d *= 2
+	}
+	if d > maxRestartBackoff {
+		d = maxRestartBackoff
+	}
+	return d
+}
+
+// String describes the policy, e.g. "on-failure (max 3, backoff 5s)"
+func (p RestartPolicy) String() string {
+	if p.When == "" || p.When == RestartNever {
+		return RestartNever
+	}
+	return fmt.Sprintf("%s (max %d, backoff %s)", p.When, p.maxRetries(), p.delay(0))
+}
+
+// Validate checks When and Backoff
+func (p RestartPolicy) Validate() error {
+	switch p.When {
+	case "", RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		return fmt.Errorf("invalid restart policy '%s' (use never, on-failure or always)", p.When)
+	}
+	if p.Backoff != "" {
+		if d, err := time.ParseDuration(p.Backoff); err != nil || d <= 0 {
+			return fmt.Errorf("invalid restart backoff '%s' (use a duration like 5s or 1m)", p.Backoff)
+		}
+	}
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("max retries can't be negative")
+	}
+	return nil
+}
+
+// GetRestartPolicy returns the session's restart policy: its own, else the
+// [restart] default from config.toml
+func (i *Instance) GetRestartPolicy() RestartPolicy {
+	if i.RestartPolicy != nil {
+		return *i.RestartPolicy
+	}
+	if userConfig, _ := LoadUserConfig(); userConfig != nil {
+		return userConfig.Restart
+	}
+	return RestartPolicy{}
+}
+
+// LastExit returns the exit status of the agent while the session is exited.
+// known is false when the agent fell back to the shell or was killed by a signal.
+func (i *Instance) LastExit() (code int, known bool, at time.Time) {
+	return i.exitCode, i.exitCodeKnown, i.exitedAt
+}
+
+// NextAutoRestart returns when AutoRestart will restart the exited session
+// (zero if it won't)
+func (i *Instance) NextAutoRestart() time.Time {
+	return i.nextRestartAt
+}
+
+// CarryRuntimeState copies the exit tracking, pending restart, start grace
+// period and tmux status tracking of old, the same session before
+// sessions.json was reloaded, so a reload doesn't log an exit twice, forget
+// restarts already made or turn a session the user has seen back to waiting
+func (i *Instance) CarryRuntimeState(old *Instance) {
+	i.exitSeen, i.exitHandled = old.exitSeen, old.exitHandled
+	i.exitedAt, i.exitCode, i.exitCodeKnown = old.exitedAt, old.exitCode, old.exitCodeKnown
+	i.restartAttempts, i.nextRestartAt, i.lastAutoRestart = old.restartAttempts, old.nextRestartAt, old.lastAutoRestart
+	i.lastStartTime = old.lastStartTime
+	if i.tmuxSession != nil {
+		i.tmuxSession.CarryStatusState(old.tmuxSession)
+	}
+}
+
+// watchesExit returns true if the session runs an agent whose exit can be told
+// apart from normal operation (not a plain shell, not still launching)
+func (i *Instance) watchesExit() bool {
+	if i.tmuxSession == nil || strings.TrimSpace(i.Command) == "" {
+		return false
+	}
+	if tmux.IsShell(strings.Fields(i.Command)[0]) {
+		return false
+	}
+	return time.Since(i.lastStartTime) > exitGracePeriod
+}
+
+// agentExited checks whether the agent process is gone: the pane is dead
+// (the agent was the pane's process) or back at the shell it was typed into.
+// Records the exit status the first time an exit is seen.
+func (i *Instance) agentExited() bool {
+	if !i.watchesExit() {
+		return false
+	}
+	state, err := i.tmuxSession.GetPaneState()
+	if err != nil {
+		return false
+	}
+	if !state.Exited() {
+		i.exitSeen = false
+		if i.restartAttempts > 0 && time.Since(i.lastAutoRestart) > restartResetAfter {
+			i.restartAttempts = 0
+		}
+		return false
+	}
+
+	if !i.exitSeen {
+		i.exitSeen = true
+		i.exitHandled = false
+		i.exitedAt = time.Now()
+		i.exitCode, i.exitCodeKnown = state.DeadStatus, state.Dead && state.DeadStatus >= 0
+	}
+	return true
+}
+
+// scheduleRestart logs an exit and, if the policy allows, when the session
+// will be restarted (see AutoRestart)
+func (i *Instance) scheduleRestart() {
+	exit := "exited (status unknown)"
+	if i.exitCodeKnown {
+		exit = fmt.Sprintf("exited with status %d", i.exitCode)
+	}
+
+	policy := i.GetRestartPolicy()
+	i.nextRestartAt = time.Time{}
+	switch {
+	case policy.When == "" || policy.When == RestartNever:
+		i.logExitEvent("%s; restart policy is never", exit)
+	case policy.When == RestartOnFailure && i.exitCodeKnown && i.exitCode == 0:
+		i.logExitEvent("%s; not restarting a clean exit (on-failure)", exit)
+	case i.restartAttempts >= policy.maxRetries():
+		i.logExitEvent("%s; giving up after %d restarts", exit, i.restartAttempts)
+	default:
+		delay := policy.delay(i.restartAttempts)
+		i.nextRestartAt = time.Now().Add(delay)
+		i.logExitEvent("%s; restarting in %s (%d/%d)", exit, delay, i.restartAttempts+1, policy.maxRetries())
+	}
+}
+
+// AutoRestart applies the restart policy of an exited session: it logs a new
+// exit and restarts the session (with resume) once the backoff has passed.
+// Call periodically after UpdateStatus from the process that owns restarts
+// (one-shot CLI commands only report status); returns true if the session
+// was restarted.
+func (i *Instance) AutoRestart() (bool, error) {
+	if !i.DueRestart() {
+		return false, nil
+	}
+	err := i.Restart()
+	i.RestartDone(err)
+	return err == nil, err
+}
+
+// DueRestart is AutoRestart without the restart, for callers that restart
+// elsewhere (the TUI, off its status worker): it logs a new exit and returns
+// true once the backoff has passed. The caller then calls Restart and
+// RestartDone with its result.
+func (i *Instance) DueRestart() bool {
+	if i.Status != StatusExited {
+		return false
+	}
+	if !i.exitHandled {
+		i.exitHandled = true
+		i.scheduleRestart()
+	}
+	if i.nextRestartAt.IsZero() || time.Now().Before(i.nextRestartAt) {
+		return false
+	}
+	i.nextRestartAt = time.Time{}
+	i.restartAttempts++
+	i.lastAutoRestart = time.Now()
+	return true
+}
+
+// RestartDone logs the result of a restart DueRestart asked for
+func (i *Instance) RestartDone(err error) {
+	if err != nil {
+		i.logExitEvent("restart %d failed: %v", i.restartAttempts, err)
+		return
+	}
+	i.logExitEvent("restarted (%d/%d)", i.restartAttempts, i.GetRestartPolicy().maxRetries())
+}
+
+// ExitLogPath returns the file exits and automatic restarts are logged to
+func ExitLogPath() (string, error) {
+	dir, err := GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, exitLogFileName), nil
+}
+
+// logExitEvent appends a line about this session to the exit log
+func (i *Instance) logExitEvent(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	log.Printf("[RESTART] %s (%s): %s", i.Title, i.ID, msg)
+
+	path, err := ExitLogPath()
+	if err != nil {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0700)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s (%s): %s\n", time.Now().Format(time.RFC3339), i.Title, i.ID, msg)
+}
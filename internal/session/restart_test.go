@@ -0,0 +1,156 @@
+package session
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestartPolicyDelay(t *testing.T) {
+	tests := []struct {
+		policy  RestartPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{RestartPolicy{}, 0, 5 * time.Second},
+		{RestartPolicy{}, 2, 20 * time.Second},
+		{RestartPolicy{Backoff: "1m"}, 1, 2 * time.Minute},
+		{RestartPolicy{Backoff: "1m"}, 10, 5 * time.Minute},
+		{RestartPolicy{Backoff: "bogus"}, 0, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.delay(tt.attempt); got != tt.want {
+			t.Errorf("%+v.delay(%d) = %s, want %s", tt.policy, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRestartPolicyValidate(t *testing.T) {
+	valid := []RestartPolicy{
+		{},
+		{When: RestartNever},
+		{When: RestartOnFailure, MaxRetries: 5},
+		{When: RestartAlways, Backoff: "30s"},
+	}
+	for _, p := range valid {
+		if err := p.Validate(); err != nil {
+			t.Errorf("%+v.Validate() = %v, want nil", p, err)
+		}
+	}
+	invalid := []RestartPolicy{
+		{When: "sometimes"},
+		{When: RestartAlways, Backoff: "soon"},
+		{When: RestartAlways, MaxRetries: -1},
+	}
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%+v.Validate() = nil, want error", p)
+		}
+	}
+}
+
+// exitedInstance returns a session whose agent was just seen exiting
+func exitedInstance(policy *RestartPolicy, code int, known bool) *Instance {
+	inst := NewInstanceWithTool("crashy", "/tmp", "claude")
+	inst.RestartPolicy = policy
+	inst.Status = StatusExited
+	inst.exitSeen = true
+	inst.exitCode, inst.exitCodeKnown = code, known
+	return inst
+}
+
+func TestAutoRestartSchedule(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	withUserConfig(t, &UserConfig{})
+
+	tests := []struct {
+		name      string
+		policy    *RestartPolicy
+		code      int
+		known     bool
+		attempts  int
+		scheduled bool
+	}{
+		{"default is never", nil, 1, true, 0, false},
+		{"on-failure skips clean exit", &RestartPolicy{When: RestartOnFailure}, 0, true, 0, false},
+		{"on-failure restarts crash", &RestartPolicy{When: RestartOnFailure}, 137, true, 0, true},
+		{"on-failure restarts unknown status", &RestartPolicy{When: RestartOnFailure}, 0, false, 0, true},
+		{"always restarts clean exit", &RestartPolicy{When: RestartAlways}, 0, true, 0, true},
+		{"gives up after max retries", &RestartPolicy{When: RestartAlways, MaxRetries: 2}, 1, true, 2, false},
+	}
+	for _, tt := range tests {
+		inst := exitedInstance(tt.policy, tt.code, tt.known)
+		inst.restartAttempts = tt.attempts
+
+		restarted, err := inst.AutoRestart()
+		if restarted || err != nil {
+			t.Errorf("%s: AutoRestart() = %v, %v before the backoff passed", tt.name, restarted, err)
+		}
+		if got := !inst.NextAutoRestart().IsZero(); got != tt.scheduled {
+			t.Errorf("%s: restart scheduled = %v, want %v", tt.name, got, tt.scheduled)
+		}
+	}
+
+	path, err := ExitLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("exit log not written: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != len(tests) {
+		t.Errorf("exit log has %d lines, want %d:\n%s", lines, len(tests), data)
+	}
+	if !strings.Contains(string(data), "exited with status 137; restarting in 5s (1/3)") {
+		t.Errorf("exit log missing restart line:\n%s", data)
+	}
+}
+
+func TestTemplateRestartPolicy(t *testing.T) {
+	tmpl := TemplateDef{Restart: &RestartPolicy{When: RestartAlways}}
+	a := NewInstance("a", "/tmp")
+	b := NewInstance("b", "/tmp")
+	a.ApplyTemplate(tmpl)
+	b.ApplyTemplate(tmpl)
+
+	a.RestartPolicy.When = RestartNever
+	if b.GetRestartPolicy().When != RestartAlways {
+		t.Error("sessions from one template must not share a restart policy")
+	}
+}
+
+func TestCarryRuntimeState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	withUserConfig(t, &UserConfig{})
+
+	old := exitedInstance(&RestartPolicy{When: RestartAlways}, 1, true)
+	old.restartAttempts = 1
+	if _, err := old.AutoRestart(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same session as loaded again from sessions.json
+	reloaded := exitedInstance(&RestartPolicy{When: RestartAlways}, 0, false)
+	reloaded.exitSeen, reloaded.exitHandled = false, false
+	reloaded.CarryRuntimeState(old)
+
+	if !reloaded.NextAutoRestart().Equal(old.NextAutoRestart()) || reloaded.restartAttempts != 1 {
+		t.Errorf("carried restart = %v after %d attempts, want %v after 1",
+			reloaded.NextAutoRestart(), reloaded.restartAttempts, old.NextAutoRestart())
+	}
+	if code, known, _ := reloaded.LastExit(); code != 1 || !known {
+		t.Errorf("carried exit = %d (known %v), want 1", code, known)
+	}
+
+	// The exit was handled before the reload, so it isn't logged again
+	if _, err := reloaded.AutoRestart(); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := ExitLogPath()
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("exit log has %d lines, want 1:\n%s", lines, data)
+	}
+}
@@ -0,0 +1,216 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// WaitForAgentReady waits for Claude/Gemini/other agents to be ready for input
+// Uses status detection: waits for "active" → "waiting" transition
+func WaitForAgentReady(tmuxSess *tmux.Session) error {
+	sawActive := false
+	waitingCount := 0
+	maxAttempts := 300 // 60 seconds max (300 * 200ms)
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		time.Sleep(200 * time.Millisecond)
+
+		status, err := tmuxSess.GetStatus()
+		if err != nil {
+			waitingCount = 0
+			continue
+		}
+
+		if status == "active" {
+			sawActive = true
+			waitingCount = 0
+			continue
+		}
+
+		if status == "waiting" {
+			waitingCount++
+		} else {
+			waitingCount = 0
+		}
+
+		// Agent is ready when:
+		// 1. We've seen "active" (loading) and now see "waiting" (ready)
+		// 2. We've seen "waiting" 10+ times (already ready)
+		alreadyReady := waitingCount >= 10 && attempt >= 15 // At least 3s elapsed
+		if (sawActive && status == "waiting") || alreadyReady {
+			time.Sleep(300 * time.Millisecond) // Small delay for UI to render
+			return nil
+		}
+	}
+
+	return fmt.Errorf("agent not ready after 60 seconds")
+}
+
+// Send types message into the session's agent pane and submits it, first
+// waiting for the agent to be ready unless noWait. The session must be running.
+func (i *Instance) Send(message string, noWait bool) error {
+	if !i.Exists() {
+		return fmt.Errorf("session '%s' is not running", i.Title)
+	}
+	tmuxSess := i.GetTmuxSession()
+	if tmuxSess == nil {
+		return fmt.Errorf("could not determine tmux session")
+	}
+
+	if !noWait {
+		if err := WaitForAgentReady(tmuxSess); err != nil {
+			return fmt.Errorf("timeout waiting for agent: %w", err)
+		}
+	}
+
+	return i.deliver(tmuxSess, message)
+}
+
+// Finder looks up a session for background work that sends to it (scheduled
+// prompts, session messages). release, never nil, ends that work; the daemon
+// uses it to hold the session's lock for the whole delivery.
+type Finder func(id string) (inst *Instance, release func())
+
+// Unlocked adapts a plain lookup to a Finder whose release does nothing
+func Unlocked(find func(id string) *Instance) Finder {
+	return func(id string) (*Instance, func()) {
+		return find(id), func() {}
+	}
+}
+
+// SendKeys presses tmux keys by name (e.g. "Escape", "C-c") in the session's
+// agent pane, without waiting for the agent. The session must be running.
+func (i *Instance) SendKeys(keys []string) error {
+	if !i.Exists() {
+		return fmt.Errorf("session '%s' is not running", i.Title)
+	}
+	tmuxSess := i.GetTmuxSession()
+	if tmuxSess == nil {
+		return fmt.Errorf("could not determine tmux session")
+	}
+	if err := tmuxSess.SendKeyNames(keys...); err != nil {
+		return fmt.Errorf("failed to send keys: %w", err)
+	}
+	return nil
+}
+
+// deliver enters message into the agent pane (locally or over SSH) and
+// submits it as configured for the session's tool in [send.<tool>]
+func (i *Instance) deliver(tmuxSess *tmux.Session, message string) error {
+	settings := GetSendSettings(i.Tool)
+	if settings.usePaste(message) {
+		if err := tmuxSess.PasteText(message); err != nil {
+			return fmt.Errorf("failed to paste message: %w", err)
+		}
+	} else if err := tmuxSess.SendKeys(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	keys := settings.submitKeys()
+	if len(keys) == 0 {
+		return nil
+	}
+	time.Sleep(settings.submitDelay())
+	if err := tmuxSess.SendKeyNames(keys...); err != nil {
+		return fmt.Errorf("failed to submit message: %w", err)
+	}
+	return nil
+}
+
+// Paste modes for [send.<tool>] paste
+const (
+	PasteMultiline = "multiline" // Bracketed paste for text with newlines (default)
+	PasteAlways    = "always"
+	PasteNever     = "never" // Type the text; each newline presses Enter
+)
+
+const defaultSubmitDelay = 150 * time.Millisecond
+
+// SendSettings controls how messages are entered into one tool's sessions
+// by `session send`, schedules and the initial message: [send.codex]
+type SendSettings struct {
+	// Paste is when text goes in as one bracketed paste: multiline (default),
+	// always or never
+	Paste string `toml:"paste"`
+
+	// Submit is the tmux keys pressed after the text, separated by spaces
+	// (default "Enter"; "none" leaves the text unsubmitted)
+	Submit string `toml:"submit"`
+
+	// SubmitDelay is the pause between the text and the submit keys, so the
+	// tool has taken in a paste before it is submitted (default "150ms")
+	SubmitDelay string `toml:"submit_delay"`
+}
+
+// GetSendSettings returns the [send.<tool>] settings for tool
+func GetSendSettings(tool string) SendSettings {
+	if config, err := LoadUserConfig(); err == nil && config != nil {
+		return config.Send[tool]
+	}
+	return SendSettings{}
+}
+
+// Validate checks Paste and SubmitDelay
+func (s SendSettings) Validate() error {
+	switch s.Paste {
+	case "", PasteMultiline, PasteAlways, PasteNever:
+	default:
+		return fmt.Errorf("invalid paste mode '%s' (use multiline, always or never)", s.Paste)
+	}
+	if s.SubmitDelay != "" {
+		if d, err := time.ParseDuration(s.SubmitDelay); err != nil || d < 0 {
+			return fmt.Errorf("invalid submit_delay '%s' (use a duration like 150ms)", s.SubmitDelay)
+		}
+	}
+	return nil
+}
+
+// usePaste reports whether message should be pasted rather than typed
+func (s SendSettings) usePaste(message string) bool {
+	switch s.Paste {
+	case PasteAlways:
+		return true
+	case PasteNever:
+		return false
+	default:
+		return strings.ContainsAny(message, "\r\n")
+	}
+}
+
+// submitKeys returns the keys that submit a message
+func (s SendSettings) submitKeys() []string {
+	switch strings.TrimSpace(s.Submit) {
+	case "":
+		return []string{"Enter"}
+	case "none":
+		return nil
+	default:
+		return strings.Fields(s.Submit)
+	}
+}
+
+// submitDelay returns SubmitDelay or its default
+func (s SendSettings) submitDelay() time.Duration {
+	if d, err := time.ParseDuration(s.SubmitDelay); err == nil && d >= 0 {
+		return d
+	}
+	return defaultSubmitDelay
+}
+
+// StartPending starts the session and sends message once the agent is ready.
+// Without a message, the pending InitialMessage from the session's template
+// is sent (first start only). Returns the message that will be sent, if any.
+func (i *Instance) StartPending(message string) (string, error) {
+	if message == "" {
+		message = i.InitialMessage
+	}
+	i.InitialMessage = ""
+
+	if message != "" {
+		return message, i.StartWithMessage(message)
+	}
+	return "", i.Start()
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSendSettings(t *testing.T) {
+	defaults := SendSettings{}
+	if defaults.usePaste("one line") {
+		t.Error("single-line text should be typed by default")
+	}
+	if !defaults.usePaste("line one\nline two") {
+		t.Error("multi-line text should be pasted by default")
+	}
+	if got := defaults.submitKeys(); !reflect.DeepEqual(got, []string{"Enter"}) {
+		t.Errorf("default submitKeys() = %v, want [Enter]", got)
+	}
+	if got := defaults.submitDelay(); got != 150*time.Millisecond {
+		t.Errorf("default submitDelay() = %s, want 150ms", got)
+	}
+
+	custom := SendSettings{Paste: PasteAlways, Submit: "Escape Enter", SubmitDelay: "0s"}
+	if !custom.usePaste("one line") {
+		t.Error("paste = always should paste single-line text")
+	}
+	if got := custom.submitKeys(); !reflect.DeepEqual(got, []string{"Escape", "Enter"}) {
+		t.Errorf("submitKeys() = %v, want [Escape Enter]", got)
+	}
+	if got := custom.submitDelay(); got != 0 {
+		t.Errorf("submitDelay() = %s, want 0", got)
+	}
+	if (SendSettings{Paste: PasteNever}).usePaste("a\nb") {
+		t.Error("paste = never should type multi-line text")
+	}
+	if got := (SendSettings{Submit: "none"}).submitKeys(); got != nil {
+		t.Errorf("submit = none: submitKeys() = %v, want none", got)
+	}
+}
+
+func TestSendSettingsValidate(t *testing.T) {
+	valid := []SendSettings{
+		{},
+		{Paste: PasteMultiline, Submit: "C-m", SubmitDelay: "300ms"},
+		{Paste: PasteNever, Submit: "none"},
+	}
+	for _, s := range valid {
+		if err := s.Validate(); err != nil {
+			t.Errorf("%+v.Validate() = %v, want nil", s, err)
+		}
+	}
+	invalid := []SendSettings{
+		{Paste: "sometimes"},
+		{SubmitDelay: "soon"},
+		{SubmitDelay: "-1s"},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v.Validate() = nil, want error", s)
+		}
+	}
+}
+
+func TestGetSendSettings(t *testing.T) {
+	withUserConfig(t, &UserConfig{
+		Send: map[string]SendSettings{"codex": {Submit: "C-j"}},
+	})
+	if got := GetSendSettings("codex").submitKeys(); !reflect.DeepEqual(got, []string{"C-j"}) {
+		t.Errorf("codex submitKeys() = %v, want [C-j]", got)
+	}
+	if got := GetSendSettings("claude"); got != (SendSettings{}) {
+		t.Errorf("claude settings = %+v, want defaults", got)
+	}
+}
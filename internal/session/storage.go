@@ -78,8 +78,37 @@ type InstanceData struct {
 	GeminiSessionID  string    `json:"gemini_session_id,omitempty"`
 	GeminiDetectedAt time.Time `json:"gemini_detected_at,omitempty"`
 
+	// Other tools' conversation (Codex, OpenCode, Aider)
+	ToolSessionID  string    `json:"tool_session_id,omitempty"`
+	ToolDetectedAt time.Time `json:"tool_detected_at,omitempty"`
+
 	// MCP tracking (persisted for sync status display)
 	LoadedMCPNames []string `json:"loaded_mcp_names,omitempty"`
+
+	// Multi-pane layout (named config layout and/or per-session panes)
+	Layout      string       `json:"layout,omitempty"`
+	LayoutPanes []LayoutPane `json:"layout_panes,omitempty"`
+
+	// Remote host running the tmux session (empty = local)
+	Host string `json:"host,omitempty"`
+
+	// Git worktree backing the session (ProjectPath points into it)
+	WorktreePath   string `json:"worktree_path,omitempty"`
+	WorktreeRepo   string `json:"worktree_repo,omitempty"`
+	WorktreeBranch string `json:"worktree_branch,omitempty"`
+
+	// Session this one was forked from
+	ForkedFromID string `json:"forked_from_id,omitempty"`
+
+	// Extra tmux environment and a first prompt still waiting to be sent
+	Env            map[string]string `json:"env,omitempty"`
+	InitialMessage string            `json:"initial_message,omitempty"`
+
+	// Claude account set on the session itself
+	ClaudeConfigDir string `json:"claude_config_dir,omitempty"`
+
+	// Restart policy set on the session itself
+	RestartPolicy *RestartPolicy `json:"restart_policy,omitempty"`
 }
 
 // GroupData represents serializable group data
@@ -88,6 +117,9 @@ type GroupData struct {
 	Path     string `json:"path"`
 	Expanded bool   `json:"expanded"`
 	Order    int    `json:"order"`
+
+	// ClaudeConfigDir is the Claude account the group's sessions inherit
+	ClaudeConfigDir string `json:"claude_config_dir,omitempty"`
 }
 
 // Storage handles persistence of session data
@@ -214,7 +246,20 @@ func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) er
 			ClaudeDetectedAt: inst.ClaudeDetectedAt,
 			GeminiSessionID:  inst.GeminiSessionID,
 			GeminiDetectedAt: inst.GeminiDetectedAt,
+			ToolSessionID:    inst.ToolSessionID,
+			ToolDetectedAt:   inst.ToolDetectedAt,
 			LoadedMCPNames:   inst.LoadedMCPNames,
+			Layout:           inst.Layout,
+			LayoutPanes:      inst.LayoutPanes,
+			Host:             inst.Host,
+			WorktreePath:     inst.WorktreePath,
+			WorktreeRepo:     inst.WorktreeRepo,
+			WorktreeBranch:   inst.WorktreeBranch,
+			ForkedFromID:     inst.ForkedFromID,
+			Env:              inst.Env,
+			InitialMessage:   inst.InitialMessage,
+			ClaudeConfigDir:  inst.ClaudeConfigDir,
+			RestartPolicy:    inst.RestartPolicy,
 		}
 	}
 
@@ -223,10 +268,11 @@ func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) er
 		data.Groups = make([]*GroupData, 0, len(groupTree.GroupList))
 		for _, g := range groupTree.GroupList {
 			data.Groups = append(data.Groups, &GroupData{
-				Name:     g.Name,
-				Path:     g.Path,
-				Expanded: g.Expanded,
-				Order:    g.Order,
+				Name:            g.Name,
+				Path:            g.Path,
+				Expanded:        g.Expanded,
+				Order:           g.Order,
+				ClaudeConfigDir: g.ClaudeConfigDir,
 			})
 		}
 	}
@@ -478,19 +524,14 @@ func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupDa
 			// Convert Status enum to string for tmux package
 			// This restores the exact status across app restarts
 			previousStatus := statusToString(instData.Status)
-			tmuxSess = tmux.ReconnectSessionWithStatus(
+			tmuxSess = tmux.ReconnectSessionWithStatusOnHost(
+				instData.Host,
 				instData.TmuxSession,
 				instData.Title,
 				instData.ProjectPath,
 				instData.Command,
 				previousStatus,
 			)
-			// Enable mouse mode for proper scrolling (only if session still exists)
-			// Sessions may no longer exist after tmux server restart
-			if tmuxSess.Exists() {
-				// Ignore errors - non-fatal, older tmux versions may not support all options
-				_ = tmuxSess.EnableMouseMode()
-			}
 		}
 
 		// Migrate old sessions without GroupPath
@@ -500,7 +541,11 @@ func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupDa
 		}
 
 		// Expand tilde in project path (handles paths like ~/project saved from UI)
-		projectPath := expandTilde(instData.ProjectPath)
+		// Remote paths are left alone: ~ means the remote user's home
+		projectPath := instData.ProjectPath
+		if instData.Host == "" {
+			projectPath = expandTilde(projectPath)
+		}
 
 		inst := &Instance{
 			ID:               instData.ID,
@@ -517,10 +562,34 @@ func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupDa
 			ClaudeDetectedAt: instData.ClaudeDetectedAt,
 			GeminiSessionID:  instData.GeminiSessionID,
 			GeminiDetectedAt: instData.GeminiDetectedAt,
+			ToolSessionID:    instData.ToolSessionID,
+			ToolDetectedAt:   instData.ToolDetectedAt,
 			LoadedMCPNames:   instData.LoadedMCPNames,
+			Layout:           instData.Layout,
+			LayoutPanes:      instData.LayoutPanes,
+			Host:             instData.Host,
+			WorktreePath:     instData.WorktreePath,
+			WorktreeRepo:     instData.WorktreeRepo,
+			WorktreeBranch:   instData.WorktreeBranch,
+			ForkedFromID:     instData.ForkedFromID,
+			Env:              instData.Env,
+			InitialMessage:   instData.InitialMessage,
+			ClaudeConfigDir:  instData.ClaudeConfigDir,
+			RestartPolicy:    instData.RestartPolicy,
 			tmuxSession:      tmuxSess,
 		}
 
+		// Enable mouse mode for proper scrolling (only if session still exists)
+		// Sessions may no longer exist after tmux server restart
+		if tmuxSess != nil && tmuxSess.Exists() {
+			// Ignore errors - non-fatal, older tmux versions may not support all options
+			_ = tmuxSess.EnableMouseMode()
+
+			// Re-apply user [tmux] settings on top of the defaults just restored
+			inst.syncTmuxCustomization()
+			tmuxSess.ApplyCustomization()
+		}
+
 		// Update status immediately to prevent flickering on startup
 		// Without this, UI renders saved status, then first tick changes it
 		if tmuxSess != nil {
@@ -530,6 +599,8 @@ func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupDa
 		instances[i] = inst
 	}
 
+	ApplyClaudeConfigDirs(instances, GroupClaudeConfigDirs(data.Groups), s.profile)
+
 	return instances, data.Groups, nil
 }
 
@@ -583,7 +654,7 @@ func statusToString(s Status) string {
 		return "waiting"
 	case StatusIdle:
 		return "idle"
-	case StatusError:
+	case StatusError, StatusExited:
 		return "waiting" // Treat errors as needing attention
 	default:
 		return "waiting"
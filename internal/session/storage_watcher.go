@@ -1,4 +1,4 @@
-package ui
+package session
 
 import (
 	"fmt"
@@ -190,7 +190,7 @@ func (sw *StorageWatcher) ReloadChannel() <-chan struct{} {
 	return sw.reloadCh
 }
 
-// NotifySave should be called by the TUI right before it saves to storage.
+// NotifySave should be called by the watcher's owner (TUI or daemon) right before it saves.
 // This marks the current time so the watcher can ignore the resulting file change.
 func (sw *StorageWatcher) NotifySave() {
 	sw.saveMu.Lock()
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// TemplateDef bundles everything needed to launch a kind of session that
+// gets created over and over: [templates.review] in config.toml.
+// Title, Message and Worktree may use {project}, {branch} and {date}.
+type TemplateDef struct {
+	// Tool is the agent (claude, gemini, codex, a [tools] name...)
+	Tool string `toml:"tool"`
+
+	// Command is the full command line, flags included (default: the tool)
+	Command string `toml:"command"`
+
+	// Title of the new session (default: folder name)
+	Title string `toml:"title"`
+
+	// Group path the session is created in
+	Group string `toml:"group"`
+
+	// MCPs from [mcps] to attach to the project
+	MCPs []string `toml:"mcps"`
+
+	// Env is extra environment for the agent (${VAR} references allowed)
+	Env map[string]string `toml:"env"`
+
+	// Message is sent to the agent once it is ready after the first start
+	Message string `toml:"message"`
+
+	// Worktree runs the session in a new git worktree on this branch
+	Worktree string `toml:"worktree"`
+
+	// Parent creates the session as a sub-session of this session (title or ID)
+	Parent string `toml:"parent"`
+
+	// Restart is the restart policy of the session (default: [restart])
+	Restart *RestartPolicy `toml:"restart"`
+}
+
+// CommandLine returns the command the template runs
+func (t TemplateDef) CommandLine() string {
+	if t.Command != "" {
+		return t.Command
+	}
+	return t.Tool
+}
+
+// GetTemplate returns a named session template from config.toml
+func GetTemplate(name string) (TemplateDef, bool) {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return TemplateDef{}, false
+	}
+	tmpl, ok := config.Templates[name]
+	return tmpl, ok
+}
+
+// GetTemplateNames returns the names of all session templates, sorted
+func GetTemplateNames() []string {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return nil
+	}
+	names := make([]string, 0, len(config.Templates))
+	for name := range config.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandTemplateVars fills {project} (folder name), {branch} (current git
+// branch, empty outside a repo) and {date} (YYYY-MM-DD) in s
+func ExpandTemplateVars(s, projectPath string) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+	branch := ""
+	if strings.Contains(s, "{branch}") {
+		branch, _ = git.CurrentBranch(projectPath)
+	}
+	return strings.NewReplacer(
+		"{project}", filepath.Base(projectPath),
+		"{branch}", branch,
+		"{date}", time.Now().Format("2006-01-02"),
+	).Replace(s)
+}
+
+// ApplyTemplate copies the template's environment, initial message and restart
+// policy onto a new session. Call before CreateWorktree so variables see the
+// original project.
+func (i *Instance) ApplyTemplate(t TemplateDef) {
+	if len(t.Env) > 0 {
+		if i.Env == nil {
+			i.Env = make(map[string]string, len(t.Env))
+		}
+		for k, v := range t.Env {
+			i.Env[k] = v
+		}
+	}
+	if t.Message != "" {
+		i.InitialMessage = ExpandTemplateVars(t.Message, i.ProjectPath)
+	}
+	if t.Restart != nil {
+		policy := *t.Restart
+		i.RestartPolicy = &policy
+	}
+}
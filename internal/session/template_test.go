@@ -0,0 +1,60 @@
+package session
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplateVars(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "api")
+	got := ExpandTemplateVars("review-{project}-{date}", project)
+	want := "review-api-" + time.Now().Format("2006-01-02")
+	if got != want {
+		t.Errorf("ExpandTemplateVars() = %q, want %q", got, want)
+	}
+
+	// Outside a git repo {branch} is empty
+	if got := ExpandTemplateVars("b={branch}", project); got != "b=" {
+		t.Errorf("{branch} outside repo = %q", got)
+	}
+	if got := ExpandTemplateVars("plain", project); got != "plain" {
+		t.Errorf("no vars = %q", got)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	withUserConfig(t, &UserConfig{Templates: map[string]TemplateDef{
+		"review": {
+			Tool:    "claude",
+			Message: "Review {project}",
+			Env:     map[string]string{"MODE": "strict"},
+		},
+		"bare": {Command: "aider --model x"},
+	}})
+
+	if names := GetTemplateNames(); strings.Join(names, ",") != "bare,review" {
+		t.Errorf("GetTemplateNames() = %v", names)
+	}
+	review, ok := GetTemplate("review")
+	if !ok {
+		t.Fatal("review template not found")
+	}
+	if review.CommandLine() != "claude" {
+		t.Errorf("CommandLine() = %q, want the tool", review.CommandLine())
+	}
+	if bare, _ := GetTemplate("bare"); bare.CommandLine() != "aider --model x" {
+		t.Errorf("CommandLine() = %q", bare.CommandLine())
+	}
+
+	inst := NewInstanceWithTool("r", "/src/api", "claude")
+	inst.Env = map[string]string{"KEEP": "1"}
+	inst.ApplyTemplate(review)
+	if inst.InitialMessage != "Review api" {
+		t.Errorf("InitialMessage = %q", inst.InitialMessage)
+	}
+	if inst.Env["MODE"] != "strict" || inst.Env["KEEP"] != "1" {
+		t.Errorf("Env = %v", inst.Env)
+	}
+}
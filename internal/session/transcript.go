@@ -0,0 +1,392 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transcript entry types
+const (
+	EntryUser       = "user"
+	EntryAssistant  = "assistant"
+	EntryToolUse    = "tool_use"
+	EntryToolResult = "tool_result"
+)
+
+// TranscriptEntry is one message or tool call of a conversation
+type TranscriptEntry struct {
+	Type      string          `json:"type"`
+	Timestamp string          `json:"timestamp,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	ToolName  string          `json:"tool_name,omitempty"`   // tool_use, and tool_result when known
+	ToolUseID string          `json:"tool_use_id,omitempty"` // Pairs a tool_result with its tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use arguments
+	IsError   bool            `json:"is_error,omitempty"`    // The tool call failed
+}
+
+// Time returns the entry's timestamp (zero if missing or unparseable)
+func (e TranscriptEntry) Time() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Transcript is the full conversation of a session's agent
+type Transcript struct {
+	Tool      string            `json:"tool"`
+	SessionID string            `json:"session_id,omitempty"` // The tool's conversation ID
+	File      string            `json:"file"`
+	Entries   []TranscriptEntry `json:"entries"`
+}
+
+// Since returns the entries at or after t (entries without a timestamp are kept)
+func (t *Transcript) Since(since time.Time) []TranscriptEntry {
+	var entries []TranscriptEntry
+	for _, e := range t.Entries {
+		if ts := e.Time(); ts.IsZero() || !ts.Before(since) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// transcriptSource returns the conversation file and its format ("claude" or
+// "gemini"). Transcripts are read from Claude and Gemini session stores.
+func (i *Instance) transcriptSource() (string, string, error) {
+	switch {
+	case i.Tool == "gemini":
+		file, err := i.geminiSessionFile()
+		return file, "gemini", err
+	case i.Tool == "claude" || (GetToolAdapter(i.Tool) == nil && i.ClaudeSessionID != ""):
+		file, err := i.claudeSessionFile()
+		return file, "claude", err
+	default:
+		return "", "", fmt.Errorf("transcripts are only available for Claude and Gemini sessions (tool: %s)", i.Tool)
+	}
+}
+
+// GetTranscript reads the session's full conversation, including tool calls
+// and their results
+func (i *Instance) GetTranscript() (*Transcript, error) {
+	file, format, err := i.transcriptSource()
+	if err != nil {
+		return nil, err
+	}
+	return readTranscript(file, format)
+}
+
+// readTranscript parses a conversation file
+func readTranscript(file, format string) (*Transcript, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	t := &Transcript{Tool: format, File: file}
+	if format == "gemini" {
+		t.SessionID, t.Entries, err = parseGeminiTranscript(data)
+	} else {
+		t.SessionID, t.Entries = parseClaudeTranscript(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FollowTranscript calls fn for each entry added to the conversation after
+// the call, checking every interval until ctx is done. The conversation is
+// looked up again on each check, so a restart that starts a new conversation
+// is followed too.
+func (i *Instance) FollowTranscript(ctx context.Context, interval time.Duration, fn func(TranscriptEntry)) error {
+	type fileStamp struct {
+		modTime time.Time
+		size    int64
+	}
+	stampOf := func(file string) fileStamp {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fileStamp{}
+		}
+		return fileStamp{info.ModTime(), info.Size()}
+	}
+
+	// Entries already written are not repeated
+	var file string
+	var stamp fileStamp
+	var seen int
+	var lastTime time.Time
+	if f, format, err := i.transcriptSource(); err == nil {
+		if t, err := readTranscript(f, format); err == nil {
+			file, stamp, seen = f, stampOf(f), len(t.Entries)
+			lastTime = lastEntryTime(t.Entries)
+		}
+	} else if format == "" {
+		return err // Not a Claude or Gemini session
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		i.updateAgentSession()
+		f, format, err := i.transcriptSource()
+		if err != nil {
+			continue // No conversation yet
+		}
+		st := stampOf(f)
+		if f == file && st == stamp {
+			continue
+		}
+		t, err := readTranscript(f, format)
+		if err != nil {
+			continue
+		}
+
+		var fresh []TranscriptEntry
+		if f == file && len(t.Entries) >= seen {
+			fresh = t.Entries[seen:]
+		} else {
+			// A new conversation (or a rewritten file): skip what was already shown
+			for _, e := range t.Entries {
+				if lastTime.IsZero() || e.Time().After(lastTime) {
+					fresh = append(fresh, e)
+				}
+			}
+		}
+		file, stamp, seen = f, st, len(t.Entries)
+
+		for _, e := range fresh {
+			fn(e)
+		}
+		if ts := lastEntryTime(fresh); ts.After(lastTime) {
+			lastTime = ts
+		}
+	}
+}
+
+// lastEntryTime returns the latest timestamp among entries
+func lastEntryTime(entries []TranscriptEntry) time.Time {
+	var last time.Time
+	for _, e := range entries {
+		if ts := e.Time(); ts.After(last) {
+			last = ts
+		}
+	}
+	return last
+}
+
+// claudeBlock is a content block of a Claude message
+type claudeBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// parseClaudeTranscript parses a Claude JSONL file into entries
+func parseClaudeTranscript(data []byte) (string, []TranscriptEntry) {
+	var sessionID string
+	var entries []TranscriptEntry
+	toolNames := make(map[string]string) // tool_use ID -> tool name
+
+	// Tool results can be large, so lines aren't length-limited like bufio.Scanner
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var record claudeJSONLRecord
+			if json.Unmarshal(line, &record) == nil {
+				if sessionID == "" {
+					sessionID = record.SessionID
+				}
+				entries = append(entries, claudeRecordEntries(record, toolNames)...)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return sessionID, entries
+}
+
+// claudeRecordEntries converts one JSONL record into entries
+func claudeRecordEntries(record claudeJSONLRecord, toolNames map[string]string) []TranscriptEntry {
+	if record.Type != EntryUser && record.Type != EntryAssistant || record.IsMeta || len(record.Message) == 0 {
+		return nil
+	}
+	var msg claudeMessage
+	if json.Unmarshal(record.Message, &msg) != nil {
+		return nil
+	}
+	role := msg.Role
+	if role == "" {
+		role = record.Type
+	}
+
+	var entries []TranscriptEntry
+	add := func(e TranscriptEntry) {
+		e.Timestamp = record.Timestamp
+		entries = append(entries, e)
+	}
+
+	// Content can be string or array of blocks
+	var text string
+	if json.Unmarshal(msg.Content, &text) == nil {
+		if strings.TrimSpace(text) != "" {
+			add(TranscriptEntry{Type: role, Content: text})
+		}
+		return entries
+	}
+	var blocks []claudeBlock
+	if json.Unmarshal(msg.Content, &blocks) != nil {
+		return nil
+	}
+
+	// Consecutive text blocks form one message, tool blocks split it
+	var sb strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(sb.String()); s != "" {
+			add(TranscriptEntry{Type: role, Content: s})
+		}
+		sb.Reset()
+	}
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			sb.WriteString(b.Text)
+			sb.WriteString("\n")
+		case "tool_use":
+			flush()
+			toolNames[b.ID] = b.Name
+			add(TranscriptEntry{Type: EntryToolUse, ToolName: b.Name, ToolUseID: b.ID, Input: b.Input})
+		case "tool_result":
+			flush()
+			add(TranscriptEntry{
+				Type:      EntryToolResult,
+				Content:   contentText(b.Content),
+				ToolName:  toolNames[b.ToolUseID],
+				ToolUseID: b.ToolUseID,
+				IsError:   b.IsError,
+			})
+		}
+	}
+	flush()
+	return entries
+}
+
+// parseGeminiTranscript parses a Gemini session JSON file into entries
+// VERIFIED: Message type is "user" or "gemini"; tool calls hang off the
+// message that made them
+func parseGeminiTranscript(data []byte) (string, []TranscriptEntry, error) {
+	var session struct {
+		SessionID string `json:"sessionId"`
+		Messages  []struct {
+			Timestamp string          `json:"timestamp"`
+			Type      string          `json:"type"`
+			Content   json.RawMessage `json:"content"`
+			ToolCalls []struct {
+				ID        string          `json:"id"`
+				Name      string          `json:"name"`
+				Args      json.RawMessage `json:"args"`
+				Result    json.RawMessage `json:"result"`
+				Status    string          `json:"status"`
+				Timestamp string          `json:"timestamp"`
+			} `json:"toolCalls,omitempty"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &session); err != nil {
+		return "", nil, fmt.Errorf("failed to parse session file: %w", err)
+	}
+
+	var entries []TranscriptEntry
+	for _, msg := range session.Messages {
+		var role string
+		switch msg.Type {
+		case "user":
+			role = EntryUser
+		case "gemini":
+			role = EntryAssistant
+		default:
+			continue // info and error notices
+		}
+		if text := strings.TrimSpace(contentText(msg.Content)); text != "" {
+			entries = append(entries, TranscriptEntry{Type: role, Timestamp: msg.Timestamp, Content: text})
+		}
+		for _, call := range msg.ToolCalls {
+			ts := call.Timestamp
+			if ts == "" {
+				ts = msg.Timestamp
+			}
+			entries = append(entries, TranscriptEntry{
+				Type:      EntryToolUse,
+				Timestamp: ts,
+				ToolName:  call.Name,
+				ToolUseID: call.ID,
+				Input:     call.Args,
+			})
+			if len(call.Result) > 0 && string(call.Result) != "null" {
+				entries = append(entries, TranscriptEntry{
+					Type:      EntryToolResult,
+					Timestamp: ts,
+					Content:   contentText(call.Result),
+					ToolName:  call.Name,
+					ToolUseID: call.ID,
+					IsError:   call.Status == "error",
+				})
+			}
+		}
+	}
+	return session.SessionID, entries, nil
+}
+
+// contentText flattens message or tool result content: a string, a list of
+// text blocks, or (for anything else) its JSON
+func contentText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &blocks) == nil {
+		var parts []string
+		for _, b := range blocks {
+			switch {
+			case b.Text != "":
+				parts = append(parts, b.Text)
+			case b.Type == "image":
+				parts = append(parts, "[image]")
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, "\n")
+		}
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, raw) == nil {
+		return compact.String()
+	}
+	return string(raw)
+}
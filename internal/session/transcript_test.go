@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const claudeTranscriptFixture = `{"type":"user","sessionId":"conv-1","timestamp":"2025-01-15T10:00:00.000Z","message":{"role":"user","content":"List the files"}}
+{"type":"user","sessionId":"conv-1","isMeta":true,"timestamp":"2025-01-15T10:00:00.500Z","message":{"role":"user","content":"<local-command-caveat>"}}
+{"type":"assistant","sessionId":"conv-1","timestamp":"2025-01-15T10:00:01.000Z","message":{"role":"assistant","content":[{"type":"thinking","thinking":"..."},{"type":"text","text":"Let me look."},{"type":"tool_use","id":"tu-1","name":"Bash","input":{"command":"ls"}}]}}
+{"type":"user","sessionId":"conv-1","timestamp":"2025-01-15T10:00:02.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu-1","content":[{"type":"text","text":"main.go\ngo.mod"}]}]}}
+{"type":"summary","summary":"Listing files"}
+{"type":"assistant","sessionId":"conv-1","timestamp":"2025-01-15T10:00:03.000Z","message":{"role":"assistant","content":[{"type":"text","text":"There are two files."}]}}
+`
+
+func TestParseClaudeTranscript(t *testing.T) {
+	sessionID, entries := parseClaudeTranscript([]byte(claudeTranscriptFixture))
+	if sessionID != "conv-1" {
+		t.Errorf("sessionID = %q, want conv-1", sessionID)
+	}
+
+	want := []TranscriptEntry{
+		{Type: EntryUser, Content: "List the files"},
+		{Type: EntryAssistant, Content: "Let me look."},
+		{Type: EntryToolUse, ToolName: "Bash", ToolUseID: "tu-1"},
+		{Type: EntryToolResult, ToolName: "Bash", ToolUseID: "tu-1", Content: "main.go\ngo.mod"},
+		{Type: EntryAssistant, Content: "There are two files."},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Type != w.Type || e.Content != w.Content || e.ToolName != w.ToolName || e.ToolUseID != w.ToolUseID {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+	}
+	if string(entries[2].Input) != `{"command":"ls"}` {
+		t.Errorf("tool_use input = %s", entries[2].Input)
+	}
+	if got := entries[4].Time(); !got.Equal(time.Date(2025, 1, 15, 10, 0, 3, 0, time.UTC)) {
+		t.Errorf("Time() = %s", got)
+	}
+
+	transcript := &Transcript{Entries: entries}
+	if since := transcript.Since(time.Date(2025, 1, 15, 10, 0, 2, 0, time.UTC)); len(since) != 2 {
+		t.Errorf("Since() = %d entries, want 2", len(since))
+	}
+}
+
+func TestParseGeminiTranscript(t *testing.T) {
+	data := `{"sessionId":"gem-1","messages":[
+		{"id":"1","timestamp":"2025-01-15T10:00:00.000Z","type":"user","content":"Read main.go"},
+		{"id":"2","timestamp":"2025-01-15T10:00:01.000Z","type":"gemini","content":"Reading it.","toolCalls":[
+			{"id":"call-1","name":"read_file","args":{"path":"main.go"},"result":[{"functionResponse":{"response":{"output":"package main"}}}],"status":"error"}
+		]},
+		{"id":"3","timestamp":"2025-01-15T10:00:02.000Z","type":"info","content":"Request cancelled."}
+	]}`
+	sessionID, entries, err := parseGeminiTranscript([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sessionID != "gem-1" {
+		t.Errorf("sessionID = %q, want gem-1", sessionID)
+	}
+	types := make([]string, len(entries))
+	for i, e := range entries {
+		types[i] = e.Type
+	}
+	if got := strings.Join(types, ","); got != "user,assistant,tool_use,tool_result" {
+		t.Fatalf("entry types = %s", got)
+	}
+	if r := entries[3]; r.ToolName != "read_file" || !r.IsError || !strings.Contains(r.Content, "package main") {
+		t.Errorf("tool_result = %+v", r)
+	}
+}
+
+func TestFollowTranscript(t *testing.T) {
+	configDir := t.TempDir()
+	projectPath := t.TempDir()
+	inst := NewInstance("follow", projectPath)
+	inst.Tool = "claude"
+	inst.ClaudeConfigDir = configDir
+	inst.ClaudeSessionID = "conv-1"
+	inst.ClaudeDetectedAt = time.Now()
+
+	file := filepath.Join(claudeProjectDir(configDir, resolvedPath(projectPath)), "conv-1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(claudeTranscriptFixture, "\n")
+	if err := os.WriteFile(file, []byte(lines[0]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan TranscriptEntry, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- inst.FollowTranscript(ctx, 20*time.Millisecond, func(e TranscriptEntry) { got <- e })
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Only entries written after following starts are delivered
+	if err := os.WriteFile(file, []byte(strings.Join(lines, "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	timeout := time.After(5 * time.Second)
+	for len(types) < 4 {
+		select {
+		case e := <-got:
+			types = append(types, e.Type)
+		case <-timeout:
+			t.Fatalf("got entries %v, want 4", types)
+		}
+	}
+	if strings.Join(types, ",") != "assistant,tool_use,tool_result,assistant" {
+		t.Errorf("followed entries = %v", types)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("FollowTranscript() error: %v", err)
+	}
+}
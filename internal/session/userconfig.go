@@ -1,12 +1,16 @@
 package session
 
 import (
+	"log"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
 )
 
 // UserConfigFileName is the TOML config file for user preferences
@@ -40,6 +44,215 @@ type UserConfig struct {
 
 	// Updates defines auto-update settings
 	Updates UpdateSettings `toml:"updates"`
+
+	// Tmux defines how agent-deck talks to tmux
+	Tmux TmuxSettings `toml:"tmux"`
+
+	// Layouts defines named multi-pane layouts: [layouts.dev]
+	// Used with `agent-deck add --layout dev`
+	Layouts map[string]LayoutDef `toml:"layouts"`
+
+	// Hosts defines remote machines that run sessions over SSH: [hosts.devbox]
+	// Used with `agent-deck add --host devbox`
+	Hosts map[string]HostDef `toml:"hosts"`
+
+	// Worktree defines where git worktrees for worktree-backed sessions live
+	Worktree WorktreeSettings `toml:"worktree"`
+
+	// Restart is the default restart policy for agents that exit: [restart]
+	Restart RestartPolicy `toml:"restart"`
+
+	// Templates defines reusable session setups: [templates.review]
+	// Used with `agent-deck add --template review` and the new session dialog
+	Templates map[string]TemplateDef `toml:"templates"`
+
+	// Profiles defines per-profile settings: [profiles.work]
+	Profiles map[string]ProfileSettings `toml:"profiles"`
+
+	// Send defines how messages are entered and submitted per tool: [send.codex]
+	Send map[string]SendSettings `toml:"send"`
+}
+
+// ProfileSettings are defaults for every session of one agent-deck profile
+type ProfileSettings struct {
+	// ClaudeConfigDir is the Claude account (a [claude.accounts] name or a
+	// config directory) used by the profile's sessions unless their group
+	// or the session sets one
+	ClaudeConfigDir string `toml:"claude_config_dir"`
+}
+
+// WorktreeSettings configures git worktree-backed sessions
+type WorktreeSettings struct {
+	// Root is the directory worktrees are created under, as <root>/<repo>/<branch>
+	// Default: ~/.agent-deck/worktrees
+	Root string `toml:"root"`
+}
+
+// HostDef defines a remote tmux host reached over SSH.
+// Requires key/agent-based auth: background commands never prompt.
+type HostDef struct {
+	// SSH is the ssh target: "user@devbox" or an ~/.ssh/config alias
+	// (defaults to the host name)
+	SSH string `toml:"ssh"`
+
+	// Port overrides the ssh port
+	Port int `toml:"port"`
+
+	// IdentityFile is the private key to use
+	IdentityFile string `toml:"identity_file"`
+
+	// SSHOptions are extra ssh -o options, e.g. ["ServerAliveInterval=30"]
+	SSHOptions []string `toml:"ssh_options"`
+
+	// Tmux is the tmux binary on the remote host (default: "tmux")
+	Tmux string `toml:"tmux"`
+
+	// ControlPersist keeps the shared connection open when idle (default: "10m")
+	ControlPersist string `toml:"control_persist"`
+}
+
+// toTmuxHost converts a config entry into a tmux.Host
+func (h HostDef) toTmuxHost(name string) tmux.Host {
+	target := h.SSH
+	if target == "" {
+		target = name
+	}
+	return tmux.Host{
+		Name:           name,
+		Target:         target,
+		Port:           h.Port,
+		IdentityFile:   h.IdentityFile,
+		Options:        h.SSHOptions,
+		TmuxPath:       h.Tmux,
+		ControlPersist: h.ControlPersist,
+	}
+}
+
+func init() {
+	// Remote tmux sessions look up their host definitions in config.toml
+	tmux.SetHostResolver(func(name string) (tmux.Host, bool) {
+		def, ok := GetHosts()[name]
+		if !ok {
+			return tmux.Host{}, false
+		}
+		return def.toTmuxHost(name), true
+	})
+}
+
+// GetHosts returns remote hosts from config.toml
+func GetHosts() map[string]HostDef {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return nil
+	}
+	return config.Hosts
+}
+
+// LayoutDef is a named set of extra panes/windows created next to the agent pane
+type LayoutDef struct {
+	Panes []LayoutPane `toml:"panes"`
+}
+
+// LayoutPane describes one extra pane or window in a session layout.
+// The agent always keeps the session's first pane.
+type LayoutPane struct {
+	// Command runs in the new pane's shell (empty = plain shell)
+	Command string `toml:"command" json:"command,omitempty"`
+
+	// Window opens a new window instead of splitting the agent's window
+	Window bool `toml:"window" json:"window,omitempty"`
+
+	// Name is the window name (window panes only)
+	Name string `toml:"name" json:"name,omitempty"`
+
+	// Split is "horizontal" (side by side, default) or "vertical" (stacked)
+	Split string `toml:"split" json:"split,omitempty"`
+
+	// Size is lines/columns ("20") or a percentage ("30%")
+	Size string `toml:"size" json:"size,omitempty"`
+}
+
+// TmuxSettings defines tmux backend configuration
+type TmuxSettings struct {
+	// ControlMode routes tmux commands from the TUI through one persistent
+	// `tmux -C` connection instead of spawning a subprocess per command.
+	// Falls back to exec mode automatically if the connection drops.
+	// Default: true (nil); see UseControlMode
+	ControlMode *bool `toml:"control_mode"`
+
+	// Options are set-option key/values applied to every session,
+	// after agent-deck's defaults (e.g. mouse = "off", history-limit = "50000")
+	Options map[string]string `toml:"options"`
+
+	// ConfigFile is an extra tmux config file sourced when a session starts.
+	// tmux has no per-session source-file, so its commands apply server-wide
+	// unless they target a session themselves.
+	ConfigFile string `toml:"config_file"`
+
+	// StatusLeft/StatusRight replace the default status bar content.
+	// Variables: {title}, {group}, {tool}, {status}, {folder}, {path}
+	StatusLeft  string `toml:"status_left"`
+	StatusRight string `toml:"status_right"`
+
+	// Tools overrides settings per tool: [tmux.tools.claude]
+	Tools map[string]TmuxOverride `toml:"tools"`
+
+	// Groups overrides settings per group path: [tmux.groups."work/api"]
+	// Parent group settings also apply to subgroups.
+	Groups map[string]TmuxOverride `toml:"groups"`
+}
+
+// TmuxOverride is a per-tool or per-group layer of tmux settings.
+// Options are merged key by key; non-empty strings replace the outer value.
+type TmuxOverride struct {
+	Options     map[string]string `toml:"options"`
+	ConfigFile  string            `toml:"config_file"`
+	StatusLeft  string            `toml:"status_left"`
+	StatusRight string            `toml:"status_right"`
+}
+
+// merge layers o on top of the receiver
+func (t *TmuxOverride) merge(o TmuxOverride) {
+	if len(o.Options) > 0 && t.Options == nil {
+		t.Options = make(map[string]string, len(o.Options))
+	}
+	for k, v := range o.Options {
+		t.Options[k] = v
+	}
+	if o.ConfigFile != "" {
+		t.ConfigFile = o.ConfigFile
+	}
+	if o.StatusLeft != "" {
+		t.StatusLeft = o.StatusLeft
+	}
+	if o.StatusRight != "" {
+		t.StatusRight = o.StatusRight
+	}
+}
+
+// Resolve returns the effective tmux settings for a session with the given
+// tool and group path. Precedence (last wins): global, tool, then each group
+// from the root down to the session's own group.
+func (t TmuxSettings) Resolve(tool, groupPath string) TmuxOverride {
+	result := TmuxOverride{}
+	result.merge(TmuxOverride{
+		Options:     t.Options,
+		ConfigFile:  t.ConfigFile,
+		StatusLeft:  t.StatusLeft,
+		StatusRight: t.StatusRight,
+	})
+	if o, ok := t.Tools[tool]; ok {
+		result.merge(o)
+	}
+	if groupPath != "" {
+		parts := strings.Split(groupPath, "/")
+		for n := 1; n <= len(parts); n++ {
+			if o, ok := t.Groups[strings.Join(parts[:n], "/")]; ok {
+				result.merge(o)
+			}
+		}
+	}
+	return result
 }
 
 // MCPPoolSettings defines HTTP MCP pool configuration
@@ -123,6 +336,10 @@ type ClaudeSettings struct {
 	// DangerousMode enables --dangerously-skip-permissions flag for Claude sessions
 	// Default: false
 	DangerousMode bool `toml:"dangerous_mode"`
+
+	// Accounts names Claude config directories for per-session switching:
+	// [claude.accounts] work = "~/.claude-work"
+	Accounts map[string]string `toml:"accounts"`
 }
 
 // GlobalSearchSettings defines global conversation search configuration
@@ -160,6 +377,75 @@ type ToolDef struct {
 
 	// BusyPatterns are strings that indicate the tool is busy
 	BusyPatterns []string `toml:"busy_patterns"`
+
+	// The settings below give a custom tool the same resume, response and
+	// MCP support as the built-in agents. Built-in tools ignore them.
+	// Templates may use {session_id} and {project} (the session's path).
+
+	// ResumeCommand continues a conversation, e.g. "my-ai --resume {session_id}"
+	ResumeCommand string `toml:"resume_command"`
+
+	// ForkCommand starts a copy of a conversation (optional)
+	ForkCommand string `toml:"fork_command"`
+
+	// Session tells agent-deck how to find the conversation ID
+	Session ToolSessionDef `toml:"session"`
+
+	// Transcript tells agent-deck where and how to read responses
+	Transcript ToolTranscriptDef `toml:"transcript"`
+
+	// MCP locates the tool's MCP server config file
+	MCP ToolMCPDef `toml:"mcp"`
+}
+
+// ToolSessionDef is a session-ID discovery strategy: either an environment
+// variable the tool (or its wrapper) sets in tmux, or the newest file matching
+// a glob, with the ID read from a JSON path inside it
+type ToolSessionDef struct {
+	// Env names a tmux environment variable holding the ID
+	Env string `toml:"env"`
+
+	// Glob matches the tool's session files, e.g. "~/.my-ai/sessions/*.json"
+	Glob string `toml:"glob"`
+
+	// IDPath is a dotted JSON path to the ID ("meta.id"); empty uses the
+	// file name without extension. For .jsonl files the first line is read.
+	IDPath string `toml:"id_path"`
+
+	// CwdPath is a dotted JSON path to the session's directory; when set,
+	// only files whose directory is the session's project path match
+	CwdPath string `toml:"cwd_path"`
+}
+
+// ToolTranscriptDef describes a conversation transcript
+type ToolTranscriptDef struct {
+	// Path of the transcript (globs allowed), e.g. "~/.my-ai/sessions/{session_id}.jsonl"
+	Path string `toml:"path"`
+
+	// Format of the file; only "jsonl" (one message per line) is supported
+	Format string `toml:"format"`
+
+	// RolePath and ContentPath are dotted JSON paths in each line, e.g.
+	// "message.role" and "message.content". Content may be a string or an
+	// array of strings / {"text": ...} blocks.
+	RolePath    string `toml:"role_path"`
+	ContentPath string `toml:"content_path"`
+
+	// AssistantRole is the role value of assistant messages (default: "assistant")
+	AssistantRole string `toml:"assistant_role"`
+}
+
+// ToolMCPDef locates a tool's MCP config file
+type ToolMCPDef struct {
+	// Path of the config file; "{project}/.my-ai/mcp.json" for per-project
+	// config, "~/.my-ai/config.json" for global config
+	Path string `toml:"path"`
+
+	// Format is "json" (default) or "toml"
+	Format string `toml:"format"`
+
+	// Key is the dotted path of the server table (default: "mcpServers")
+	Key string `toml:"key"`
 }
 
 // MCPDef defines an MCP server configuration for the MCP Manager
@@ -253,6 +539,16 @@ func LoadUserConfig() (*UserConfig, error) {
 	if config.MCPs == nil {
 		config.MCPs = make(map[string]MCPDef)
 	}
+	for name, def := range config.Tools {
+		if err := def.Validate(); err != nil {
+			log.Printf("[CONFIG] tools.%s: %v (resume/transcript/MCP settings ignored)", name, err)
+		}
+	}
+	for name, settings := range config.Send {
+		if err := settings.Validate(); err != nil {
+			log.Printf("[CONFIG] send.%s: %v (default used)", name, err)
+		}
+	}
 
 	userConfigCache = &config
 	return userConfigCache, nil
@@ -387,6 +683,35 @@ func GetUpdateSettings() UpdateSettings {
 	return settings
 }
 
+// GetTmuxSettings returns tmux backend settings
+func GetTmuxSettings() TmuxSettings {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return TmuxSettings{}
+	}
+	return config.Tmux
+}
+
+// UseControlMode returns true unless control mode was turned off with
+// control_mode = false
+func (t TmuxSettings) UseControlMode() bool {
+	return t.ControlMode == nil || *t.ControlMode
+}
+
+// GetWorktreeSettings returns worktree settings with defaults applied
+func GetWorktreeSettings() WorktreeSettings {
+	var settings WorktreeSettings
+	if config, err := LoadUserConfig(); err == nil && config != nil {
+		settings = config.Worktree
+	}
+	if settings.Root == "" {
+		home, _ := os.UserHomeDir()
+		settings.Root = filepath.Join(home, ".agent-deck", "worktrees")
+	}
+	settings.Root = expandTilde(settings.Root)
+	return settings
+}
+
 // CreateExampleConfig creates an example config file if none exists
 func CreateExampleConfig() error {
 	configPath, err := GetUserConfigPath()
@@ -413,6 +738,16 @@ func CreateExampleConfig() error {
 # Default: ~/.claude (or CLAUDE_CONFIG_DIR env var takes priority)
 # [claude]
 # config_dir = "~/.claude-work"
+#
+# Several Claude accounts side by side: name their config directories, then
+# pick one per session or group (C in the TUI, or
+# agent-deck session set <id> claude-config-dir work) or per profile
+# [claude.accounts]
+# personal = "~/.claude"
+# work = "~/.claude-work"
+#
+# [profiles.work]
+# claude_config_dir = "work"
 
 # Log file management
 # Agent-deck logs session output to ~/.agent-deck/logs/ for status detection
@@ -437,6 +772,112 @@ check_interval_hours = 24
 # Show update notification in CLI commands, not just TUI (default: true)
 notify_in_cli = true
 
+# tmux backend
+# [tmux]
+# The TUI sends tmux commands over one persistent control-mode connection
+# (tmux -C) instead of spawning a tmux process per command, falling back to
+# exec mode on errors (default: true). Set false to always spawn processes.
+# control_mode = false
+#
+# Status bar templates: {title}, {group}, {tool}, {status}, {folder}, {path}
+# plus any tmux format (#{...}, #[fg=...])
+# status_left = " #[fg=#7aa2f7]{title}#[default] ({tool}) "
+# status_right = " {status} | {group} "
+#
+# Extra tmux config to source when a session starts (applies server-wide)
+# config_file = "~/.agent-deck/tmux.conf"
+#
+# Extra set-option values for every session (applied after agent-deck defaults)
+# [tmux.options]
+# mouse = "off"
+# history-limit = "50000"
+#
+# Per-tool and per-group overrides (parent groups apply to subgroups)
+# [tmux.tools.claude.options]
+# history-limit = "100000"
+# [tmux.groups."work/api"]
+# status_right = " API | {status} "
+
+# ============================================================================
+# Session Layouts
+# ============================================================================
+# Extra panes/windows next to the agent (agent-deck add --layout dev).
+# Status, preview and restart always target the agent pane.
+#
+# [layouts.dev]
+# panes = [
+#   { command = "npm test -- --watch", split = "horizontal", size = "35%" },
+#   { command = "npm run dev", window = true, name = "server" },
+# ]
+
+# ============================================================================
+# Remote Hosts
+# ============================================================================
+# Run sessions in tmux on another machine (agent-deck add --host devbox).
+# Commands share one SSH ControlMaster connection; key-based auth required.
+#
+# [hosts.devbox]
+# ssh = "me@devbox.local"
+# port = 22
+# identity_file = "~/.ssh/id_ed25519"
+# ssh_options = ["ServerAliveInterval=30"]
+# tmux = "/usr/local/bin/tmux"
+
+# ============================================================================
+# Git Worktrees
+# ============================================================================
+# Sessions created with --worktree <branch> (or the worktree field in the
+# new/fork dialogs) get their own checkout under <root>/<repo>/<branch>,
+# so parallel agents never edit the same files.
+#
+# [worktree]
+# root = "~/.agent-deck/worktrees"
+
+# ============================================================================
+# Restart Policy
+# ============================================================================
+# When an agent exits (crash, OOM, /exit) its session shows as "exited".
+# Restart it automatically, resuming the conversation. An agent that drops
+# back to the shell has no known exit status and counts as a failure.
+# Sessions override this with: agent-deck session set <id> restart-policy ...
+# Exits and restarts are logged to ~/.agent-deck/exits.log
+#
+# [restart]
+# when = "on-failure"            # never (default), on-failure or always
+# max_retries = 3                # consecutive restarts before giving up
+# backoff = "5s"                 # first delay, doubled per retry (max 5m)
+
+# ============================================================================
+# Sending Messages
+# ============================================================================
+# How agent-deck session send, schedules and initial messages enter text into
+# a tool, per tool. Multi-line text goes in as one bracketed paste, so a
+# newline doesn't submit early; the submit keys are pressed after it.
+#
+# [send.codex]
+# paste = "multiline"            # multiline (default), always or never (type it)
+# submit = "Enter"               # tmux keys, space separated; "none" to not submit
+# submit_delay = "300ms"         # pause before submitting (default 150ms)
+
+# ============================================================================
+# Session Templates
+# ============================================================================
+# One-keystroke launches (agent-deck add --template review, or the template
+# picker in the new session dialog). title, message and worktree accept
+# {project} (folder name), {branch} (current git branch) and {date}.
+#
+# [templates.review]
+# tool = "claude"
+# command = "claude --model opus"
+# title = "review-{branch}"
+# group = "reviews"
+# mcps = ["github"]
+# env = { REVIEW_MODE = "strict", GITHUB_TOKEN = "${GITHUB_TOKEN}" }
+# message = "Review the changes on {branch} against main"
+# worktree = "review/{date}"     # run in a new worktree on this branch
+# parent = "main-project"        # create as a sub-session of this session
+# restart = { when = "always" }  # restart policy for sessions from this template
+
 # ============================================================================
 # MCP Server Definitions
 # ============================================================================
@@ -516,6 +957,28 @@ notify_in_cli = true
 # command = "gh copilot"
 # icon = "🤖"
 # busy_patterns = ["Generating..."]
+
+# Example: a custom agent with resume, responses and MCPs from config alone
+# [tools.my-agent]
+# command = "my-agent"
+# resume_command = "my-agent --resume {session_id}"
+#
+# [tools.my-agent.session]
+# glob = "~/.my-agent/sessions/*.jsonl"   # newest file for this project wins
+# id_path = "session_id"                  # JSON path (first line of .jsonl)
+# cwd_path = "cwd"
+# # or: env = "MY_AGENT_SESSION_ID"       # read from the tmux environment
+#
+# [tools.my-agent.transcript]
+# path = "~/.my-agent/sessions/{session_id}.jsonl"
+# format = "jsonl"
+# role_path = "message.role"
+# content_path = "message.content"
+#
+# [tools.my-agent.mcp]
+# path = "{project}/.my-agent/mcp.json"
+# format = "json"
+# key = "mcpServers"
 `
 
 	// Ensure directory exists
@@ -527,6 +990,16 @@ notify_in_cli = true
 	return os.WriteFile(configPath, []byte(exampleConfig), 0600)
 }
 
+// GetLayout returns a named layout from config.toml
+func GetLayout(name string) (LayoutDef, bool) {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return LayoutDef{}, false
+	}
+	layout, ok := config.Layouts[name]
+	return layout, ok
+}
+
 // GetAvailableMCPs returns MCPs from config.toml as a map
 // This replaces the old catalog-based approach with explicit user configuration
 func GetAvailableMCPs() map[string]MCPDef {
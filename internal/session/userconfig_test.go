@@ -149,3 +149,104 @@ tier = "disabled"
 		t.Errorf("Expected tier 'disabled', got %q", config.GlobalSearch.Tier)
 	}
 }
+
+func TestTmuxSettingsResolve(t *testing.T) {
+	configContent := `
+[tmux]
+status_left = " {title} "
+config_file = "~/.agent-deck/tmux.conf"
+
+[tmux.options]
+mouse = "off"
+history-limit = "50000"
+
+[tmux.tools.claude.options]
+history-limit = "100000"
+
+[tmux.groups.work]
+status_right = " work | {status} "
+
+[tmux.groups."work/api"]
+status_left = " API {title} "
+
+[tmux.groups."work/api".options]
+status-style = "bg=red"
+`
+	var config UserConfig
+	if _, err := toml.Decode(configContent, &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	got := config.Tmux.Resolve("claude", "work/api/v2")
+	if got.Options["mouse"] != "off" {
+		t.Errorf("mouse = %q, want off (global)", got.Options["mouse"])
+	}
+	if got.Options["history-limit"] != "100000" {
+		t.Errorf("history-limit = %q, want 100000 (tool override)", got.Options["history-limit"])
+	}
+	if got.Options["status-style"] != "bg=red" {
+		t.Errorf("status-style = %q, want bg=red (group override)", got.Options["status-style"])
+	}
+	if got.StatusLeft != " API {title} " {
+		t.Errorf("StatusLeft = %q, want subgroup template", got.StatusLeft)
+	}
+	if got.StatusRight != " work | {status} " {
+		t.Errorf("StatusRight = %q, want parent group template", got.StatusRight)
+	}
+	if got.ConfigFile != "~/.agent-deck/tmux.conf" {
+		t.Errorf("ConfigFile = %q, want global value", got.ConfigFile)
+	}
+
+	// Other tools/groups only see the global layer
+	other := config.Tmux.Resolve("gemini", "personal")
+	if other.Options["history-limit"] != "50000" || other.StatusLeft != " {title} " || other.StatusRight != "" {
+		t.Errorf("unexpected resolution for gemini/personal: %+v", other)
+	}
+
+	// Resolving must not mutate the global options map
+	if config.Tmux.Options["history-limit"] != "50000" {
+		t.Errorf("global options mutated: %v", config.Tmux.Options)
+	}
+}
+
+func TestTmuxControlModeDefault(t *testing.T) {
+	var config UserConfig
+	if _, err := toml.Decode("[tmux]\nstatus_left = \" {title} \"\n", &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if !config.Tmux.UseControlMode() {
+		t.Error("control mode should be on when control_mode is not set")
+	}
+
+	if _, err := toml.Decode("[tmux]\ncontrol_mode = false\n", &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if config.Tmux.UseControlMode() {
+		t.Error("control_mode = false should turn control mode off")
+	}
+}
+
+func TestHostDefToTmuxHost(t *testing.T) {
+	configContent := `
+[hosts.devbox]
+ssh = "me@devbox.local"
+port = 2222
+ssh_options = ["ServerAliveInterval=30"]
+
+[hosts.gpu]
+`
+	var config UserConfig
+	if _, err := toml.Decode(configContent, &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	h := config.Hosts["devbox"].toTmuxHost("devbox")
+	if h.Target != "me@devbox.local" || h.Port != 2222 || len(h.Options) != 1 {
+		t.Errorf("unexpected host: %+v", h)
+	}
+
+	// Without ssh = "...", the host name doubles as the ssh target (~/.ssh/config alias)
+	if gpu := config.Hosts["gpu"].toTmuxHost("gpu"); gpu.Target != "gpu" {
+		t.Errorf("gpu target = %q, want gpu", gpu.Target)
+	}
+}
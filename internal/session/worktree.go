@@ -0,0 +1,220 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// worktreePathFor returns where the worktree for branch of repo lives:
+// <root>/<repo name>/<sanitized branch>
+func worktreePathFor(repo, branch string) string {
+	root := GetWorktreeSettings().Root
+	return filepath.Join(root, filepath.Base(repo), git.SanitizeBranchName(branch))
+}
+
+// CreateWorktree creates a git worktree for branch from the repository at
+// ProjectPath and moves the session into it (into the same subdirectory when
+// ProjectPath is below the repository root). An existing branch is checked
+// out; a new one starts at ProjectPath's HEAD. Call before Start.
+func (i *Instance) CreateWorktree(branch string) error {
+	if i.IsRemote() {
+		return fmt.Errorf("worktrees are not supported for remote sessions")
+	}
+	if i.IsWorktree() {
+		return fmt.Errorf("session already runs in worktree %s", i.WorktreePath)
+	}
+	if err := git.ValidateBranchName(branch); err != nil {
+		return err
+	}
+	if !git.IsRepo(i.ProjectPath) {
+		return fmt.Errorf("%s is not a git repository", i.ProjectPath)
+	}
+
+	repo, err := git.MainRepoRoot(i.ProjectPath)
+	if err != nil {
+		return err
+	}
+	base, err := git.HeadCommit(i.ProjectPath)
+	if err != nil {
+		return err
+	}
+	prefix, err := git.PathPrefix(i.ProjectPath)
+	if err != nil {
+		return err
+	}
+
+	path := worktreePathFor(repo, branch)
+	if err := git.CreateWorktree(repo, path, branch, base); err != nil {
+		return err
+	}
+
+	i.setProjectPath(filepath.Join(path, prefix))
+	i.WorktreePath = path
+	i.WorktreeRepo = repo
+	i.WorktreeBranch = branch
+	return nil
+}
+
+// setProjectPath points the session (and its not-yet-started tmux session) at path
+func (i *Instance) setProjectPath(path string) {
+	i.ProjectPath = path
+	if i.tmuxSession != nil {
+		i.tmuxSession.WorkDir = path
+	}
+}
+
+// IsWorktree returns true if the session runs in a worktree agent-deck created
+func (i *Instance) IsWorktree() bool {
+	return i.WorktreePath != ""
+}
+
+// WorktreeStatus returns the branch, dirty state and unmerged commit count
+// of the session's worktree
+func (i *Instance) WorktreeStatus() (git.Status, error) {
+	if !i.IsWorktree() {
+		return git.Status{}, fmt.Errorf("session does not run in a worktree")
+	}
+	return git.GetStatus(i.WorktreePath)
+}
+
+// CheckWorktreeRemovable returns an error if deleting the worktree would lose
+// work: uncommitted changes, or commits no other branch contains
+func (i *Instance) CheckWorktreeRemovable() error {
+	if !i.IsWorktree() {
+		return fmt.Errorf("session does not run in a worktree")
+	}
+	if _, err := os.Stat(i.WorktreePath); os.IsNotExist(err) {
+		return nil
+	}
+
+	st, err := i.WorktreeStatus()
+	if err != nil {
+		return err
+	}
+	if st.Dirty {
+		return fmt.Errorf("worktree %s has uncommitted changes", i.WorktreePath)
+	}
+	if st.Unmerged > 0 {
+		return fmt.Errorf("branch %s has %d commit(s) not merged into any other branch", st.Branch, st.Unmerged)
+	}
+	return nil
+}
+
+// RemoveWorktree deletes the session's worktree (the branch is kept).
+// Without force it refuses when CheckWorktreeRemovable fails.
+func (i *Instance) RemoveWorktree(force bool) error {
+	if !i.IsWorktree() {
+		return nil
+	}
+	if !force {
+		if err := i.CheckWorktreeRemovable(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(i.WorktreePath); os.IsNotExist(err) {
+		// Already deleted by hand: just drop git's record of it
+		return git.PruneWorktrees(i.WorktreeRepo)
+	}
+	return git.RemoveWorktree(i.WorktreeRepo, i.WorktreePath, force)
+}
+
+// CreateForkedInstanceInWorktree forks the Claude conversation into a new
+// worktree at the parent's HEAD, so parent and fork stop editing the same
+// files. branch defaults to fork/<title>. carryChanges copies the parent's
+// uncommitted (and untracked) changes into the new worktree.
+func (i *Instance) CreateForkedInstanceInWorktree(newTitle, newGroupPath, branch string, carryChanges bool) (*Instance, string, error) {
+	if !i.CanFork() {
+		return nil, "", fmt.Errorf("cannot fork: no active %s session", i.Tool)
+	}
+
+	forked := i.newForkedInstance(newTitle, newGroupPath)
+	if branch == "" {
+		branch = forkBranchName(i.ProjectPath, newTitle, forked.ID)
+	}
+
+	// Take the patch before creating the worktree so a failure leaves nothing behind
+	var patch []byte
+	if carryChanges {
+		var err error
+		if patch, err = git.UncommittedPatch(i.ProjectPath); err != nil {
+			return nil, "", fmt.Errorf("failed to snapshot uncommitted changes: %w", err)
+		}
+	}
+
+	if err := forked.CreateWorktree(branch); err != nil {
+		return nil, "", err
+	}
+	if err := git.ApplyPatch(forked.WorktreePath, patch); err != nil {
+		_ = forked.RemoveWorktree(true)
+		return nil, "", fmt.Errorf("failed to apply uncommitted changes: %w", err)
+	}
+
+	// Claude looks up --resume IDs in the project directory of the cwd,
+	// so the transcript must exist under the worktree's path as well
+	if forked.Tool == "claude" {
+		if err := copyClaudeTranscript(i.GetClaudeConfigDir(), i.ClaudeSessionID, i.ProjectPath, forked.ProjectPath); err != nil {
+			_ = forked.RemoveWorktree(true)
+			return nil, "", err
+		}
+	}
+
+	cmd := i.forkCommand(forked.ProjectPath)
+	forked.Command = cmd
+	return forked, cmd, nil
+}
+
+// forkBranchName picks a branch for a worktree fork: fork/<title>, with the
+// fork's ID appended if that branch already exists
+func forkBranchName(repoPath, title, id string) string {
+	name := git.SanitizeBranchName(title)
+	if name == "" {
+		name = "session"
+	}
+	branch := "fork/" + name
+	if git.BranchExists(repoPath, branch) {
+		short := id
+		if len(short) > 8 {
+			short = short[:8]
+		}
+		branch += "-" + short
+	}
+	return branch
+}
+
+// copyClaudeTranscript copies a Claude session file from one project's
+// directory to another's within the Claude config dir configDir
+func copyClaudeTranscript(configDir, sessionID, fromPath, toPath string) error {
+	if resolved, err := filepath.EvalSymlinks(fromPath); err == nil {
+		fromPath = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(toPath); err == nil {
+		toPath = resolved
+	}
+
+	src := filepath.Join(claudeProjectDir(configDir, fromPath), sessionID+".jsonl")
+	dstDir := claudeProjectDir(configDir, toPath)
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return fmt.Errorf("failed to create Claude project directory: %w", err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to read Claude session: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(filepath.Join(dstDir, sessionID+".jsonl"))
+	if err != nil {
+		return fmt.Errorf("failed to copy Claude session: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy Claude session: %w", err)
+	}
+	return out.Close()
+}
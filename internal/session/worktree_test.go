@@ -0,0 +1,168 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstance_CreateWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	userConfigCacheMu.Lock()
+	saved := userConfigCache
+	userConfigCache = &UserConfig{Worktree: WorktreeSettings{Root: root}}
+	userConfigCacheMu.Unlock()
+	defer func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = saved
+		userConfigCacheMu.Unlock()
+	}()
+
+	repo := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+
+	inst := NewInstance("wt", repo)
+	if err := inst.CreateWorktree("feature/login"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+
+	wantPath := filepath.Join(root, "app", "feature-login")
+	if inst.ProjectPath != wantPath || inst.WorktreePath != wantPath {
+		t.Errorf("ProjectPath = %s, WorktreePath = %s; want %s", inst.ProjectPath, inst.WorktreePath, wantPath)
+	}
+	if inst.GetTmuxSession().WorkDir != wantPath {
+		t.Errorf("tmux WorkDir = %s, want %s", inst.GetTmuxSession().WorkDir, wantPath)
+	}
+	if inst.WorktreeBranch != "feature/login" || inst.WorktreeRepo == "" {
+		t.Errorf("worktree fields not recorded: branch=%q repo=%q", inst.WorktreeBranch, inst.WorktreeRepo)
+	}
+
+	// Uncommitted work blocks removal
+	if err := os.WriteFile(filepath.Join(wantPath, "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.CheckWorktreeRemovable(); err == nil {
+		t.Error("expected dirty worktree to be refused")
+	}
+	if err := inst.RemoveWorktree(false); err == nil {
+		t.Error("RemoveWorktree(false) should refuse a dirty worktree")
+	}
+	if _, err := os.Stat(wantPath); err != nil {
+		t.Error("refused removal must keep the worktree")
+	}
+
+	if err := inst.RemoveWorktree(true); err != nil {
+		t.Fatalf("RemoveWorktree(true): %v", err)
+	}
+	if _, err := os.Stat(wantPath); !os.IsNotExist(err) {
+		t.Error("forced removal should delete the worktree")
+	}
+}
+
+func TestInstance_CreateWorktreeRejectsRemote(t *testing.T) {
+	inst := NewInstance("remote", "/srv/app")
+	inst.SetHost("devbox")
+	if err := inst.CreateWorktree("feature"); err == nil {
+		t.Error("expected error for remote session")
+	}
+}
+
+func TestInstance_ForkIntoWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	userConfigCacheMu.Lock()
+	saved := userConfigCache
+	userConfigCache = &UserConfig{Worktree: WorktreeSettings{Root: root}}
+	userConfigCacheMu.Unlock()
+	defer func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = saved
+		userConfigCacheMu.Unlock()
+	}()
+	claudeDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+
+	repo := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := NewInstanceWithTool("parent", repo, "claude")
+	parent.ClaudeSessionID = "11111111-2222-3333-4444-555555555555"
+	parent.ClaudeDetectedAt = time.Now()
+	resolvedRepo, _ := filepath.EvalSymlinks(repo)
+	transcriptDir := claudeProjectDir(claudeDir, resolvedRepo)
+	if err := os.MkdirAll(transcriptDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(transcriptDir, parent.ClaudeSessionID+".jsonl"), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	forked, cmd, err := parent.CreateForkedInstanceInWorktree("try two", "", "", true)
+	if err != nil {
+		t.Fatalf("CreateForkedInstanceInWorktree: %v", err)
+	}
+
+	if forked.ForkedFromID != parent.ID {
+		t.Errorf("ForkedFromID = %q, want %q", forked.ForkedFromID, parent.ID)
+	}
+	if forked.WorktreeBranch != "fork/try-two" {
+		t.Errorf("WorktreeBranch = %q, want fork/try-two", forked.WorktreeBranch)
+	}
+	if !strings.Contains(cmd, "cd "+forked.ProjectPath+" ") {
+		t.Errorf("fork command should run in the worktree: %s", cmd)
+	}
+	if data, err := os.ReadFile(filepath.Join(forked.ProjectPath, "wip.txt")); err != nil || string(data) != "wip" {
+		t.Errorf("uncommitted changes not carried: %q, %v", data, err)
+	}
+	copied := filepath.Join(claudeProjectDir(claudeDir, forked.ProjectPath), parent.ClaudeSessionID+".jsonl")
+	if _, err := os.Stat(copied); err != nil {
+		t.Errorf("transcript not copied to the worktree's project dir: %v", err)
+	}
+
+	_ = forked.RemoveWorktree(true)
+}
+
+func TestClaudeProjectDir(t *testing.T) {
+	got := claudeProjectDir("/cfg", "/home/me/.agent-deck/worktrees/app_x")
+	want := filepath.Join("/cfg", "projects", "-home-me--agent-deck-worktrees-app-x")
+	if got != want {
+		t.Errorf("claudeProjectDir = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,445 @@
+package tmux
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Control mode backend (tmux -C)
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Instead of spawning `tmux ...` for every send-keys/capture-pane/set-option,
+// a single persistent `tmux -C` client multiplexes commands over its stdin and
+// reads framed replies from stdout:
+//
+//	%begin <time> <cmd-number> <flags>
+//	...output lines...
+//	%end <time> <cmd-number> <flags>      (or %error on failure)
+//
+// Replies arrive in the order commands were written, so pending commands are
+// kept in a FIFO. Blocks with flags=0 were not issued by us (e.g. the implicit
+// attach command) and are ignored. Lines starting with % outside a block are
+// notifications (%output, %window-add, %session-changed, %sessions-changed, ...).
+//
+// tmux only streams %output for panes in the client's attached session, which
+// is our private control session. Per-session output events still come from
+// pipe-pane logs (see events.go); control notifications are used for server-wide
+// changes such as sessions being created or killed.
+//
+// The client attaches to a private session (ControlSessionName) so it never
+// interferes with user sessions. If the connection drops, commands fall back
+// to exec mode and the connection is re-established lazily with backoff.
+
+// ControlSessionName is the hidden tmux session the control client attaches to.
+// It is excluded from session discovery.
+const ControlSessionName = "__agentdeck_control"
+
+// controlCommandTimeout bounds how long a single control-mode command may take.
+// A timeout desynchronizes the reply FIFO, so the connection is dropped.
+const controlCommandTimeout = 10 * time.Second
+
+// controlReconnectBackoff is the minimum delay between reconnect attempts
+const controlReconnectBackoff = 5 * time.Second
+
+// ErrControlClosed is returned when the control-mode connection is gone before
+// a command was sent. Callers (tmuxCommand) fall back to exec mode on this error.
+var ErrControlClosed = errors.New("tmux control connection closed")
+
+// errControlLost is returned when the connection died after a command was sent.
+// The command may or may not have run, so it is NOT retried in exec mode
+// (re-running send-keys would type the text twice).
+var errControlLost = errors.New("tmux control connection lost while waiting for reply")
+
+// ControlNotification is an asynchronous event emitted by a control-mode client
+type ControlNotification struct {
+	Type string // e.g. "output", "window-add", "session-changed", "sessions-changed", "exit"
+	Args string // Raw arguments after the notification name
+
+	// For "output" notifications only
+	PaneID string // e.g. "%12"
+	Data   []byte // Decoded pane output
+}
+
+// controlReply is the result of one framed command block
+type controlReply struct {
+	output string
+	err    error
+}
+
+// ControlClient is a persistent tmux control-mode connection
+type ControlClient struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	notify func(ControlNotification)
+
+	// mu serializes writes and keeps the pending FIFO in write order
+	mu      sync.Mutex
+	pending []chan controlReply
+	closed  bool
+
+	done chan struct{}
+}
+
+// NewControlClient starts `tmux -C` attached to the private control session
+// (creating it if needed). notify may be nil.
+func NewControlClient(notify func(ControlNotification)) (*ControlClient, error) {
+	// "cat" keeps the control session's pane alive without a login shell
+	cmd := exec.Command("tmux", "-C", "new-session", "-A", "-s", ControlSessionName, "cat")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("control mode stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("control mode stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start tmux control mode: %w", err)
+	}
+	execCount.Add(1)
+
+	c := &ControlClient{
+		cmd:    cmd,
+		stdin:  stdin,
+		notify: notify,
+		done:   make(chan struct{}),
+	}
+	go c.readLoop(stdout)
+
+	// Round-trip a no-op to confirm the connection is usable
+	if _, err := c.Run("display-message", "-p", ""); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("tmux control mode handshake failed: %w", err)
+	}
+	return c, nil
+}
+
+// Run sends one tmux command over the control connection and waits for its reply.
+// Output matches what `tmux <args>` would print on stdout (trailing newline included).
+func (c *ControlClient) Run(args ...string) (string, error) {
+	reply := make(chan controlReply, 1)
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return "", ErrControlClosed
+	}
+	c.pending = append(c.pending, reply)
+	_, err := io.WriteString(c.stdin, buildControlCommand(args)+"\n")
+	c.mu.Unlock()
+	if err != nil {
+		c.shutdown()
+		return "", ErrControlClosed
+	}
+
+	select {
+	case r := <-reply:
+		return r.output, r.err
+	case <-time.After(controlCommandTimeout):
+		log.Printf("[TMUX-CONTROL] command timed out, dropping connection: %v", args)
+		c.shutdown()
+		return "", errControlLost
+	}
+}
+
+// Done is closed when the connection has terminated
+func (c *ControlClient) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close terminates the control connection. The control session is left
+// running so the tmux server stays up for other clients.
+func (c *ControlClient) Close() error {
+	c.shutdown()
+	return nil
+}
+
+// shutdown marks the client closed, fails pending commands and stops tmux -C
+func (c *ControlClient) shutdown() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, ch := range pending {
+		ch <- controlReply{err: errControlLost}
+	}
+	_ = c.stdin.Close()
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	go func() { _ = c.cmd.Wait() }()
+}
+
+// readLoop parses framed replies and notifications until tmux exits
+func (c *ControlClient) readLoop(r io.Reader) {
+	defer close(c.done)
+	defer c.shutdown()
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var (
+		inBlock  bool
+		ours     bool
+		blockID  string // "<time> <number>" from %begin, matched against %end/%error
+		blockOut strings.Builder
+	)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		if inBlock {
+			if kind, id, _, ok := parseControlGuard(line); ok && id == blockID && kind != "begin" {
+				inBlock = false
+				if ours {
+					reply := controlReply{output: blockOut.String()}
+					if kind == "error" {
+						reply.err = errors.New(strings.TrimSpace(reply.output))
+						reply.output = ""
+					}
+					c.deliver(reply)
+				}
+				continue
+			}
+			blockOut.WriteString(line)
+			blockOut.WriteString("\n")
+			continue
+		}
+
+		if kind, id, flags, ok := parseControlGuard(line); ok && kind == "begin" {
+			inBlock = true
+			ours = flags&1 != 0
+			blockID = id
+			blockOut.Reset()
+			continue
+		}
+
+		if strings.HasPrefix(line, "%") {
+			c.handleNotification(line)
+		}
+	}
+}
+
+// deliver hands a reply to the oldest pending command
+func (c *ControlClient) deliver(reply controlReply) {
+	c.mu.Lock()
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	ch := c.pending[0]
+	c.pending = c.pending[1:]
+	c.mu.Unlock()
+	ch <- reply
+}
+
+func (c *ControlClient) handleNotification(line string) {
+	name, args, _ := strings.Cut(strings.TrimPrefix(line, "%"), " ")
+	n := ControlNotification{Type: name, Args: args}
+	if name == "output" {
+		paneID, data, _ := strings.Cut(args, " ")
+		n.PaneID = paneID
+		n.Data = decodeControlOutput(data)
+	}
+	if c.notify != nil {
+		c.notify(n)
+	}
+}
+
+// parseControlGuard parses "%begin|%end|%error <time> <number> <flags>"
+func parseControlGuard(line string) (kind, id string, flags int, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 4 {
+		return "", "", 0, false
+	}
+	switch fields[0] {
+	case "%begin":
+		kind = "begin"
+	case "%end":
+		kind = "end"
+	case "%error":
+		kind = "error"
+	default:
+		return "", "", 0, false
+	}
+	flags, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return "", "", 0, false
+	}
+	return kind, fields[1] + " " + fields[2], flags, true
+}
+
+// buildControlCommand quotes args for tmux's command parser.
+// Double quotes are used so escapes like \n survive; $ and ~ are escaped to
+// prevent environment/home expansion, matching exec.Command semantics.
+func buildControlCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteControlArg(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteControlArg(arg string) string {
+	var b strings.Builder
+	b.Grow(len(arg) + 2)
+	b.WriteByte('"')
+	for _, r := range arg {
+		switch r {
+		case '\\', '"', '$', '~':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\%03o`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// decodeControlOutput reverses tmux's %output escaping (octal \ooo for
+// control characters and backslash)
+func decodeControlOutput(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				out = append(out, byte(v))
+				i += 3
+				continue
+			}
+		}
+		out = append(out, s[i])
+	}
+	return out
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Process-wide control connection
+// ═══════════════════════════════════════════════════════════════════════════
+
+var (
+	controlMu          sync.Mutex
+	controlEnabled     bool
+	controlClient      *ControlClient
+	controlLastAttempt time.Time
+
+	// Separate lock: handlers are read on the reader goroutine, which must
+	// never wait on controlMu (held during the connect handshake)
+	controlHandlersMu sync.RWMutex
+	controlHandlers   []func(ControlNotification)
+)
+
+// EnableControlMode routes this package's tmux commands through a persistent
+// control-mode connection. Intended for long-lived processes (TUI, daemon);
+// one-shot CLI commands are cheaper in exec mode. Returns the connect error,
+// if any - commands still work via exec fallback and reconnect is retried.
+func EnableControlMode() error {
+	controlMu.Lock()
+	defer controlMu.Unlock()
+	controlEnabled = true
+	_, err := connectControlLocked()
+	return err
+}
+
+// DisableControlMode closes the control connection and returns to exec mode
+func DisableControlMode() {
+	controlMu.Lock()
+	defer controlMu.Unlock()
+	controlEnabled = false
+	if controlClient != nil {
+		_ = controlClient.Close()
+		controlClient = nil
+	}
+}
+
+// ControlModeActive reports whether commands are currently using control mode
+func ControlModeActive() bool {
+	return activeControlClient() != nil
+}
+
+// OnControlNotification registers a handler for control-mode notifications.
+// Handlers run on the connection's reader goroutine and must not block.
+func OnControlNotification(handler func(ControlNotification)) {
+	controlHandlersMu.Lock()
+	defer controlHandlersMu.Unlock()
+	controlHandlers = append(controlHandlers, handler)
+}
+
+// activeControlClient returns the live connection, reconnecting with backoff
+// if it dropped. Returns nil when control mode is disabled or unavailable.
+func activeControlClient() *ControlClient {
+	controlMu.Lock()
+	defer controlMu.Unlock()
+	if !controlEnabled {
+		return nil
+	}
+	if controlClient != nil {
+		select {
+		case <-controlClient.Done():
+			controlClient = nil
+		default:
+			return controlClient
+		}
+	}
+	if time.Since(controlLastAttempt) < controlReconnectBackoff {
+		return nil
+	}
+	c, _ := connectControlLocked()
+	return c
+}
+
+// connectControlLocked dials a new control client. MUST be called with controlMu held.
+func connectControlLocked() (*ControlClient, error) {
+	controlLastAttempt = time.Now()
+	c, err := NewControlClient(dispatchControlNotification)
+	if err != nil {
+		debugLog("control mode unavailable, using exec: %v", err)
+		return nil, err
+	}
+	controlClient = c
+	return c, nil
+}
+
+func dispatchControlNotification(n ControlNotification) {
+	// Session list changed - invalidate the cache so the next tick refetches
+	if n.Type == "sessions-changed" {
+		sessionCacheMu.Lock()
+		sessionCacheTime = time.Time{}
+		sessionCacheMu.Unlock()
+	}
+
+	controlHandlersMu.RLock()
+	handlers := controlHandlers
+	controlHandlersMu.RUnlock()
+	for _, h := range handlers {
+		h(n)
+	}
+}
@@ -0,0 +1,113 @@
+package tmux
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteControlArg(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{"", `""`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`$HOME ~/x`, `"\$HOME \~/x"`},
+		{`back\slash`, `"back\\slash"`},
+		{"two\nlines\ttab", `"two\nlines\ttab"`},
+		{"esc\x1b", `"esc\033"`},
+		{"#{session_name};", `"#{session_name};"`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, quoteControlArg(tt.in), "input %q", tt.in)
+	}
+}
+
+func TestParseControlGuard(t *testing.T) {
+	kind, id, flags, ok := parseControlGuard("%begin 1792332516 10965 1")
+	assert.True(t, ok)
+	assert.Equal(t, "begin", kind)
+	assert.Equal(t, "1792332516 10965", id)
+	assert.Equal(t, 1, flags)
+
+	kind, _, flags, ok = parseControlGuard("%error 1 2 0")
+	assert.True(t, ok)
+	assert.Equal(t, "error", kind)
+	assert.Equal(t, 0, flags)
+
+	_, _, _, ok = parseControlGuard("%end of the world")
+	assert.False(t, ok)
+	_, _, _, ok = parseControlGuard("%output %1 hello")
+	assert.False(t, ok)
+}
+
+func TestDecodeControlOutput(t *testing.T) {
+	assert.Equal(t, []byte("hi\r\n"), decodeControlOutput(`hi\015\012`))
+	assert.Equal(t, []byte(`a\b`), decodeControlOutput(`a\134b`))
+	assert.Equal(t, []byte(`trailing\0`), decodeControlOutput(`trailing\0`))
+}
+
+// TestControlModeSessionOperations runs the Session API over a live control
+// connection and verifies it spawns no per-command subprocesses and falls
+// back to exec mode when the connection drops
+func TestControlModeSessionOperations(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	require.NoError(t, EnableControlMode())
+	t.Cleanup(func() {
+		DisableControlMode()
+		_ = exec.Command("tmux", "kill-session", "-t", ControlSessionName).Run()
+	})
+	require.True(t, ControlModeActive())
+
+	sess := NewSession("control-test", t.TempDir())
+	require.NoError(t, sess.Start("cat"))
+	defer func() { _ = sess.Kill() }()
+
+	execBefore := ExecCount()
+
+	// Quoting must survive the tmux command parser unchanged
+	literal := `echo "$HOME" ~ \ ; #{x}`
+	require.NoError(t, sess.SendKeys(literal))
+
+	var content string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		content, _ = sess.CapturePane()
+		if strings.Contains(content, literal) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Contains(t, content, literal)
+
+	_, err := sess.GetEnvironment("NOT_SET_ANYWHERE")
+	assert.Error(t, err, "%error replies must surface as errors")
+
+	assert.Equal(t, execBefore, ExecCount(), "control mode should not spawn tmux subprocesses")
+
+	// Drop the connection: commands fall back to exec until reconnect
+	controlMu.Lock()
+	client := controlClient
+	controlMu.Unlock()
+	require.NotNil(t, client)
+	_ = client.cmd.Process.Kill()
+	<-client.Done()
+
+	_, err = sess.CapturePane()
+	assert.NoError(t, err, "exec fallback should serve commands while disconnected")
+	assert.Greater(t, ExecCount(), execBefore)
+
+	// Allow an immediate reconnect
+	controlMu.Lock()
+	controlLastAttempt = time.Time{}
+	controlMu.Unlock()
+	assert.True(t, ControlModeActive(), "control mode should reconnect")
+}
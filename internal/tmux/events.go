@@ -0,0 +1,159 @@
+package tmux
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Event-driven status detection
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// When a LogWatcher is running, pipe-pane log writes tell us exactly when a
+// session produces output. GetStatus then no longer needs window_activity or
+// a CapturePane on every tick. Per-session state machine:
+//
+//	quiet    --write-->              pending  (spike window opens)
+//	pending  --2nd write within 1s--> active   (sustained output → GREEN)
+//	pending  --window expires-->      quiet    (spike filtered)
+//	active   --cooldown expires-->    settling (ONE CapturePane for busy indicators)
+//	settling --busy-->                active   (re-check after next cooldown)
+//	settling --not busy-->            quiet    (YELLOW or GRAY by acknowledged)
+//
+// Every eventResyncInterval the polling path runs once anyway, so a broken
+// pipe or a missed fsnotify event can't leave a session stuck.
+
+// eventSpikeWindow is how long we wait for a second log write before treating
+// a single write as a spike (cursor blink, title update, log truncation)
+const eventSpikeWindow = 1 * time.Second
+
+// eventResyncInterval is how often an event-driven session still goes through
+// the polling path as a safety net
+const eventResyncInterval = 15 * time.Second
+
+// activeLogWatchers counts running LogWatchers in this process.
+// Event-driven status is only trusted while at least one is running.
+var activeLogWatchers atomic.Int32
+
+// EventDrivenStatusActive reports whether a LogWatcher is currently feeding
+// output events into SignalFileActivity
+func EventDrivenStatusActive() bool {
+	return activeLogWatchers.Load() > 0
+}
+
+// usesEventStatusLocked reports whether GetStatus can rely on log events
+// for this session. MUST be called with mu held.
+func (s *Session) usesEventStatusLocked() bool {
+	return s.pipeEnabled && EventDrivenStatusActive()
+}
+
+// SignalFileActivity records a pipe-pane log write for this session (from LogWatcher).
+// Two writes within eventSpikeWindow (or any write while already active) mark the
+// session GREEN; a lone write only schedules a settle check.
+func (s *Session) SignalFileActivity() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensureStateTrackerLocked()
+	st := s.stateTracker
+	now := time.Now()
+
+	st.lastOutputAt = now
+	st.needsSettleCheck = true
+
+	if st.outputWindowStart.IsZero() || now.Sub(st.outputWindowStart) > eventSpikeWindow {
+		st.outputWindowStart = now
+		st.outputEventCount = 1
+	} else {
+		st.outputEventCount++
+	}
+
+	alreadyActive := now.Sub(st.lastChangeTime) < activityCooldown
+	if st.outputEventCount >= 2 || alreadyActive {
+		st.lastChangeTime = now
+		// Same grace period as the polling path: output right after detach
+		// shouldn't flip an acknowledged session back to YELLOW
+		if now.Sub(st.acknowledgedAt) > acknowledgeGracePeriod {
+			st.acknowledged = false
+		}
+		st.outputWindowStart = time.Time{}
+		st.outputEventCount = 0
+		s.lastStableStatus = "active"
+	}
+}
+
+// getStatusFromEvents computes status from log events without polling tmux.
+// Returns ok=false when the caller should fall back to the polling path
+// (first call, or periodic resync).
+func (s *Session) getStatusFromEvents() (status string, ok bool) {
+	shortName := s.DisplayName
+	if len(shortName) > 12 {
+		shortName = shortName[:12]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stateTracker
+	if st == nil || st.lastActivityTimestamp == 0 {
+		// Not initialized by the polling path yet
+		return "", false
+	}
+	now := time.Now()
+	if now.Sub(st.lastResync) >= eventResyncInterval {
+		st.lastResync = now
+		debugLog("%s: EVENT_RESYNC → polling path", shortName)
+		return "", false
+	}
+
+	// Lone write whose window expired = spike, forget it
+	if st.outputEventCount == 1 && now.Sub(st.outputWindowStart) > eventSpikeWindow {
+		st.outputWindowStart = time.Time{}
+		st.outputEventCount = 0
+	}
+
+	if now.Sub(st.lastChangeTime) < activityCooldown {
+		s.lastStableStatus = "active"
+		return "active", true
+	}
+
+	// Waiting to see whether a lone write turns into sustained output
+	if st.outputEventCount == 1 {
+		if s.lastStableStatus != "" {
+			return s.lastStableStatus, true
+		}
+		return "waiting", true
+	}
+
+	// Output settled: capture the pane once to catch tools that sit on a
+	// static busy indicator without producing output
+	if st.needsSettleCheck {
+		st.needsSettleCheck = false
+
+		s.mu.Unlock()
+		content, err := s.CapturePane()
+		s.mu.Lock()
+
+		if err == nil {
+			if s.hasBusyIndicator(content) {
+				st.lastChangeTime = time.Now()
+				st.acknowledged = false
+				st.needsSettleCheck = true // re-check after the next cooldown
+				s.lastStableStatus = "active"
+				debugLog("%s: EVENT_SETTLE busy → active", shortName)
+				return "active", true
+			}
+			// Baseline the settled screen so the polling fallback doesn't
+			// mistake it for new content on the next resync
+			st.lastHash = s.hashContent(s.normalizeContent(content))
+		}
+		debugLog("%s: EVENT_SETTLE quiet", shortName)
+	}
+
+	if st.acknowledged {
+		s.lastStableStatus = "idle"
+		return "idle", true
+	}
+	s.lastStableStatus = "waiting"
+	return "waiting", true
+}
@@ -0,0 +1,108 @@
+package tmux
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newEventTestSession returns a session whose tracker looks like it has
+// already been through one polling pass, with event-driven status enabled
+func newEventTestSession(t *testing.T, acknowledged bool) *Session {
+	t.Helper()
+	sess := NewSession("event-test", "/tmp")
+	sess.pipeEnabled = true
+	sess.stateTracker = &StateTracker{
+		lastChangeTime:        time.Now().Add(-10 * time.Second),
+		acknowledged:          acknowledged,
+		lastActivityTimestamp: 100,
+		lastResync:            time.Now(),
+	}
+	activeLogWatchers.Add(1)
+	t.Cleanup(func() { activeLogWatchers.Add(-1) })
+	return sess
+}
+
+func TestEventDrivenRequiresWatcherAndPipe(t *testing.T) {
+	sess := NewSession("event-gate", "/tmp")
+	assert.False(t, sess.usesEventStatusLocked(), "no pipe, no watcher")
+
+	sess.pipeEnabled = true
+	assert.False(t, sess.usesEventStatusLocked(), "pipe but no watcher")
+
+	activeLogWatchers.Add(1)
+	defer activeLogWatchers.Add(-1)
+	assert.True(t, sess.usesEventStatusLocked())
+}
+
+func TestSignalFileActivitySpikeFiltered(t *testing.T) {
+	sess := newEventTestSession(t, false)
+
+	// A single write (e.g. a title update) must not flash GREEN
+	sess.SignalFileActivity()
+	status, ok := sess.getStatusFromEvents()
+	assert.True(t, ok)
+	assert.NotEqual(t, "active", status)
+
+	// Once the spike window expires the session is simply waiting again
+	sess.stateTracker.outputWindowStart = time.Now().Add(-2 * eventSpikeWindow)
+	sess.stateTracker.needsSettleCheck = false
+	status, ok = sess.getStatusFromEvents()
+	assert.True(t, ok)
+	assert.Equal(t, "waiting", status)
+}
+
+func TestSignalFileActivitySustainedGoesActive(t *testing.T) {
+	sess := newEventTestSession(t, true)
+
+	sess.SignalFileActivity()
+	sess.SignalFileActivity()
+
+	status, ok := sess.getStatusFromEvents()
+	assert.True(t, ok)
+	assert.Equal(t, "active", status)
+	assert.False(t, sess.stateTracker.acknowledged, "sustained output should reset acknowledged")
+
+	// While active, every further write extends the cooldown
+	sess.stateTracker.lastChangeTime = time.Now().Add(-activityCooldown + 100*time.Millisecond)
+	sess.SignalFileActivity()
+	assert.WithinDuration(t, time.Now(), sess.stateTracker.lastChangeTime, 50*time.Millisecond)
+}
+
+func TestEventStatusSettlesToWaitingOrIdle(t *testing.T) {
+	for _, tc := range []struct {
+		acknowledged bool
+		want         string
+	}{
+		{false, "waiting"},
+		{true, "idle"},
+	} {
+		sess := newEventTestSession(t, tc.acknowledged)
+		// Settle check runs CapturePane, which fails for a session that
+		// was never started - status must still settle
+		sess.stateTracker.needsSettleCheck = true
+
+		status, ok := sess.getStatusFromEvents()
+		assert.True(t, ok)
+		assert.Equal(t, tc.want, status)
+		assert.False(t, sess.stateTracker.needsSettleCheck, "settle check should run only once")
+	}
+}
+
+func TestEventStatusFallsBackForResync(t *testing.T) {
+	sess := newEventTestSession(t, false)
+
+	// Uninitialized tracker → polling path
+	sess.stateTracker.lastActivityTimestamp = 0
+	_, ok := sess.getStatusFromEvents()
+	assert.False(t, ok)
+
+	// Resync interval elapsed → polling path once, then events again
+	sess.stateTracker.lastActivityTimestamp = 100
+	sess.stateTracker.lastResync = time.Now().Add(-eventResyncInterval)
+	_, ok = sess.getStatusFromEvents()
+	assert.False(t, ok)
+	_, ok = sess.getStatusFromEvents()
+	assert.True(t, ok)
+}
@@ -0,0 +1,137 @@
+package tmux
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+)
+
+// execCount counts every tmux subprocess spawned by this package
+// (including ssh processes running tmux on a remote host).
+// Exposed via ExecCount so benchmarks and debug logging can measure
+// how many subprocesses the status loop costs.
+var execCount atomic.Int64
+
+// controlCount counts commands served by the control-mode connection
+var controlCount atomic.Int64
+
+// tmuxCommand is a tmux invocation that runs over the control-mode connection
+// when one is active (see control.go) and falls back to spawning a tmux
+// subprocess otherwise. It mirrors the exec.Cmd methods call sites use.
+type tmuxCommand struct {
+	args []string
+
+	// host is the remote host name ("" = local tmux server). See remote.go.
+	host string
+}
+
+// tmuxCmd builds a tmux command for the local tmux server
+func tmuxCmd(args ...string) *tmuxCommand {
+	return &tmuxCommand{args: args}
+}
+
+// hostTmuxCmd builds a tmux command for the given host ("" = local)
+func hostTmuxCmd(host string, args ...string) *tmuxCommand {
+	return &tmuxCommand{args: args, host: host}
+}
+
+// Run runs the command and returns its error
+func (c *tmuxCommand) Run() error {
+	_, err := c.Output()
+	return err
+}
+
+// Output runs the command and returns its stdout
+func (c *tmuxCommand) Output() ([]byte, error) {
+	if out, err, ok := c.runControl(); ok {
+		return []byte(out), err
+	}
+	execCount.Add(1)
+	if c.host != "" {
+		cmd, err := c.remoteCommand(context.Background(), false)
+		if err != nil {
+			return nil, err
+		}
+		return cmd.Output()
+	}
+	return exec.Command("tmux", c.args...).Output()
+}
+
+// CombinedOutput runs the command and returns stdout and stderr.
+// In control mode the error message stands in for stderr.
+func (c *tmuxCommand) CombinedOutput() ([]byte, error) {
+	if out, err, ok := c.runControl(); ok {
+		if err != nil {
+			return []byte(err.Error()), err
+		}
+		return []byte(out), nil
+	}
+	execCount.Add(1)
+	if c.host != "" {
+		cmd, err := c.remoteCommand(context.Background(), false)
+		if err != nil {
+			return []byte(err.Error()), err
+		}
+		return cmd.CombinedOutput()
+	}
+	return exec.Command("tmux", c.args...).CombinedOutput()
+}
+
+// RunWithInput runs the command with input on its stdin (e.g. load-buffer -).
+// The control connection has no stdin, so this always spawns tmux (or ssh).
+func (c *tmuxCommand) RunWithInput(input []byte) error {
+	execCount.Add(1)
+	var cmd *exec.Cmd
+	if c.host != "" {
+		var err error
+		if cmd, err = c.remoteCommand(context.Background(), false); err != nil {
+			return err
+		}
+	} else {
+		cmd = exec.Command("tmux", c.args...)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// runControl runs the command over the control connection.
+// ok=false means the caller must use exec mode (remote host, no connection,
+// connection dropped mid-command, or a client flag like -V that isn't a tmux command).
+func (c *tmuxCommand) runControl() (out string, err error, ok bool) {
+	// Control mode only talks to the local tmux server
+	if c.host != "" || len(c.args) == 0 || strings.HasPrefix(c.args[0], "-") {
+		return "", nil, false
+	}
+	client := activeControlClient()
+	if client == nil {
+		return "", nil, false
+	}
+	out, err = client.Run(c.args...)
+	if errors.Is(err, ErrControlClosed) {
+		return "", nil, false
+	}
+	controlCount.Add(1)
+	return out, err, true
+}
+
+// ExecCount returns the number of tmux subprocesses spawned by this package
+// since process start
+func ExecCount() int64 {
+	return execCount.Load()
+}
+
+// ControlCommandCount returns the number of tmux commands served over the
+// control-mode connection since process start
+func ControlCommandCount() int64 {
+	return controlCount.Load()
+}
@@ -0,0 +1,156 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// agentPaneOption is the session user option recording the agent pane's ID.
+// Stored in tmux (not sessions.json) because pane IDs are only meaningful for
+// the lifetime of the tmux server.
+const agentPaneOption = "@agentdeck_agent_pane"
+
+// LayoutPane describes an extra pane or window created next to the agent pane
+type LayoutPane struct {
+	// Command is typed into the new pane's shell (empty = plain shell).
+	// Sent as keys rather than passed to tmux so the pane survives the command exiting.
+	Command string
+
+	// Window creates a new window instead of splitting the agent's window
+	Window bool
+
+	// Name is the window name (Window only)
+	Name string
+
+	// Split is "horizontal" (side by side, default) or "vertical" (stacked)
+	Split string
+
+	// Size is the new pane's size: lines/columns ("20") or a percentage ("30%")
+	Size string
+}
+
+// paneTarget returns the -t target for pane-level commands (capture, send-keys,
+// pipe-pane, respawn). Sessions with a layout target the agent pane by ID so
+// status, preview and input stay bound to it whichever pane the user focuses.
+func (s *Session) paneTarget() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.agentPane != "" {
+		return s.agentPane
+	}
+	return s.Name
+}
+
+// AgentPane returns the agent pane ID (e.g. "%3"), or "" if unknown
+func (s *Session) AgentPane() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.agentPane
+}
+
+// setAgentPane records the agent pane in memory and in the tmux session
+func (s *Session) setAgentPane(paneID string) {
+	s.mu.Lock()
+	s.agentPane = paneID
+	s.mu.Unlock()
+	_ = s.cmd("set-option", "-t", s.Name, agentPaneOption, paneID).Run()
+}
+
+// loadAgentPane reads the agent pane recorded by a previous agent-deck process.
+// Verifies the pane still exists so a stale ID never swallows commands.
+func (s *Session) loadAgentPane() {
+	out, err := s.cmd("show-options", "-qv", "-t", s.Name, agentPaneOption).Output()
+	if err != nil {
+		return
+	}
+	paneID := strings.TrimSpace(string(out))
+	if paneID == "" {
+		return
+	}
+	if err := s.cmd("display-message", "-p", "-t", paneID, "#{pane_id}").Run(); err != nil {
+		debugLog("%s: recorded agent pane %s is gone", s.DisplayName, paneID)
+		return
+	}
+	s.mu.Lock()
+	s.agentPane = paneID
+	s.mu.Unlock()
+}
+
+// PaneCount returns the number of panes across all windows of the session
+func (s *Session) PaneCount() int {
+	out, err := s.cmd("list-panes", "-s", "-t", s.Name, "-F", "#{pane_id}").Output()
+	if err != nil {
+		return 0
+	}
+	return len(strings.Fields(string(out)))
+}
+
+// ApplyLayout creates the extra panes and windows around the agent pane.
+// The agent pane stays selected. Call after Start; on restart via respawn the
+// existing panes are kept, so this is only needed for freshly created sessions.
+func (s *Session) ApplyLayout(panes []LayoutPane) error {
+	if len(panes) == 0 {
+		return nil
+	}
+
+	agent := s.AgentPane()
+	if agent == "" {
+		return fmt.Errorf("agent pane unknown for session %s", s.Name)
+	}
+
+	workDir := s.WorkDir
+	for idx, p := range panes {
+		args, err := layoutPaneArgs(p, s.Name, agent, workDir)
+		if err != nil {
+			return fmt.Errorf("pane %d: %w", idx+1, err)
+		}
+		out, err := s.cmd(args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("pane %d: %w (output: %s)", idx+1, err, strings.TrimSpace(string(out)))
+		}
+		paneID := strings.TrimSpace(string(out))
+		if p.Command != "" {
+			if err := s.cmd("send-keys", "-l", "-t", paneID, p.Command).Run(); err != nil {
+				return fmt.Errorf("pane %d: failed to send command: %w", idx+1, err)
+			}
+			_ = s.cmd("send-keys", "-t", paneID, "Enter").Run()
+		}
+	}
+
+	// -d keeps focus on the agent, but select explicitly in case a window was added
+	_ = s.cmd("select-window", "-t", agent).Run()
+	_ = s.cmd("select-pane", "-t", agent).Run()
+	return nil
+}
+
+// layoutPaneArgs builds the split-window/new-window command for a layout pane.
+// -d keeps the agent pane active; -P -F prints the new pane ID.
+func layoutPaneArgs(p LayoutPane, sessionName, agentPane, workDir string) ([]string, error) {
+	if p.Window {
+		args := []string{"new-window", "-d", "-P", "-F", "#{pane_id}", "-t", sessionName + ":"}
+		if workDir != "" {
+			args = append(args, "-c", workDir)
+		}
+		if p.Name != "" {
+			args = append(args, "-n", p.Name)
+		}
+		return args, nil
+	}
+
+	args := []string{"split-window", "-d", "-P", "-F", "#{pane_id}", "-t", agentPane}
+	switch strings.ToLower(p.Split) {
+	case "", "h", "horizontal":
+		args = append(args, "-h")
+	case "v", "vertical":
+		args = append(args, "-v")
+	default:
+		return nil, fmt.Errorf("invalid split %q (use horizontal or vertical)", p.Split)
+	}
+	if p.Size != "" {
+		args = append(args, "-l", p.Size)
+	}
+	if workDir != "" {
+		args = append(args, "-c", workDir)
+	}
+	return args, nil
+}
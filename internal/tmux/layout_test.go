@@ -0,0 +1,138 @@
+package tmux
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutPaneArgs(t *testing.T) {
+	args, err := layoutPaneArgs(LayoutPane{Split: "vertical", Size: "30%"}, "sess", "%4", "/tmp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"split-window", "-d", "-P", "-F", "#{pane_id}", "-t", "%4", "-v", "-l", "30%", "-c", "/tmp"}, args)
+
+	// Default split is side by side
+	args, err = layoutPaneArgs(LayoutPane{}, "sess", "%4", "")
+	require.NoError(t, err)
+	assert.Contains(t, args, "-h")
+
+	args, err = layoutPaneArgs(LayoutPane{Window: true, Name: "server"}, "sess", "%4", "/tmp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new-window", "-d", "-P", "-F", "#{pane_id}", "-t", "sess:", "-c", "/tmp", "-n", "server"}, args)
+
+	_, err = layoutPaneArgs(LayoutPane{Split: "diagonal"}, "sess", "%4", "")
+	assert.Error(t, err)
+}
+
+func TestLayoutKeepsAgentPaneTargeted(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	sess := NewSession("layout-test", t.TempDir())
+	require.NoError(t, sess.Start(""))
+	defer func() { _ = sess.Kill() }()
+
+	agent := sess.AgentPane()
+	require.True(t, strings.HasPrefix(agent, "%"), "agent pane = %q", agent)
+
+	require.NoError(t, sess.ApplyLayout([]LayoutPane{
+		{Command: "echo side-pane-output", Size: "30%"},
+		{Command: "echo window-output", Window: true, Name: "extra"},
+	}))
+	assert.Equal(t, 3, sess.PaneCount())
+
+	// Simulate the user focusing another pane; the agent pane must stay targeted
+	_ = exec.Command("tmux", "select-pane", "-t", sess.Name+":.1").Run()
+	require.NoError(t, sess.SendKeys("echo agent-pane-output"))
+	require.NoError(t, sess.SendEnter())
+
+	require.Eventually(t, func() bool {
+		content, err := sess.CapturePane()
+		return err == nil && strings.Contains(content, "agent-pane-output")
+	}, 3*time.Second, 50*time.Millisecond)
+	content, _ := sess.CapturePane()
+	assert.NotContains(t, content, "side-pane-output")
+
+	// Respawning the agent keeps the other panes
+	require.NoError(t, sess.RespawnPane(""))
+	assert.Equal(t, 3, sess.PaneCount())
+	assert.Equal(t, agent, sess.AgentPane())
+
+	// A new agent-deck process recovers the agent pane from tmux
+	reconnected := ReconnectSession(sess.Name, sess.DisplayName, sess.WorkDir, "")
+	assert.Equal(t, agent, reconnected.AgentPane())
+}
+
+func TestPasteTextAndKeyNames(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	sess := NewSession("paste-test", t.TempDir())
+	require.NoError(t, sess.Start("cat"))
+	defer func() { _ = sess.Kill() }()
+	// A paste that reaches the shell before cat runs is lost to the shell
+	require.Eventually(t, func() bool {
+		state, err := sess.GetPaneState()
+		return err == nil && state.CurrentCommand == "cat"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Every line of a multi-line paste arrives, not just the first
+	require.NoError(t, sess.PasteText("paste-line-one\npaste-line-two"))
+	require.NoError(t, sess.SendKeyNames("Enter"))
+	require.Eventually(t, func() bool {
+		content, err := sess.CapturePane()
+		return err == nil && strings.Count(content, "paste-line-two") == 2
+	}, 3*time.Second, 50*time.Millisecond)
+
+	// The paste buffer is removed after use
+	out, _ := exec.Command("tmux", "list-buffers", "-F", "#{buffer_name}").Output()
+	assert.NotContains(t, string(out), "agentdeck-")
+}
+
+func TestLayoutActivityFollowsAgentPane(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	sess := NewSession("layout-activity", t.TempDir())
+	require.NoError(t, sess.Start(""))
+	defer func() { _ = sess.Kill() }()
+	require.NoError(t, sess.ApplyLayout([]LayoutPane{{Size: "30%"}}))
+
+	out, err := exec.Command("tmux", "list-panes", "-t", sess.Name, "-F", "#{pane_id}").Output()
+	require.NoError(t, err)
+	var side string
+	for _, id := range strings.Fields(string(out)) {
+		if id != sess.AgentPane() {
+			side = id
+		}
+	}
+	require.NotEmpty(t, side)
+
+	// Pretend the agent pane last changed long ago
+	_, err = sess.GetWindowActivity()
+	require.NoError(t, err)
+	sess.mu.Lock()
+	sess.agentPaneActivity = 1
+	sess.mu.Unlock()
+
+	// Output in the side pane is not agent activity
+	require.NoError(t, exec.Command("tmux", "send-keys", "-t", side, "echo side-pane-output", "Enter").Run())
+	time.Sleep(300 * time.Millisecond)
+	activity, err := sess.GetWindowActivity()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), activity)
+
+	require.NoError(t, sess.SendKeys("echo agent-pane-output"))
+	require.NoError(t, sess.SendEnter())
+	require.Eventually(t, func() bool {
+		activity, err := sess.GetWindowActivity()
+		return err == nil && activity > 1
+	}, 3*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,181 @@
+package tmux
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Customization holds user-supplied tmux settings for a session
+// (config.toml [tmux] section, merged with per-tool and per-group overrides).
+// Applied after agent-deck's built-in defaults so user values win.
+type Customization struct {
+	// Options are set-option key/values applied to the session
+	Options map[string]string
+
+	// ConfigFile is an extra tmux config file to source-file.
+	// Note: tmux has no per-session source-file, so commands in the file
+	// apply to whatever they target (server-wide for `set -g`, bind-key, etc.)
+	ConfigFile string
+
+	// StatusLeft/StatusRight are status bar templates.
+	// Supports {title}, {group}, {tool}, {status}, {folder}, {path}
+	// in addition to regular tmux formats (#{...}, #[fg=...]).
+	StatusLeft  string
+	StatusRight string
+
+	// Vars are values for the template variables (title, group, tool, ...)
+	Vars map[string]string
+}
+
+// IsEmpty reports whether the customization changes nothing
+func (c *Customization) IsEmpty() bool {
+	return c == nil || (len(c.Options) == 0 && c.ConfigFile == "" &&
+		c.StatusLeft == "" && c.StatusRight == "")
+}
+
+// templateVarPattern matches {name} template variables
+var templateVarPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// statusVarOption is the tmux user option holding a template variable.
+// Variables live in session user options so tmux re-renders the status bar
+// when one changes, without rewriting status-left/right.
+func statusVarOption(name string) string {
+	return "@agentdeck_" + name
+}
+
+// expandStatusTemplate converts {var} placeholders into tmux user option formats.
+// Unknown names are left untouched so literal braces survive.
+func expandStatusTemplate(tmpl string, vars map[string]string) string {
+	return templateVarPattern.ReplaceAllStringFunc(tmpl, func(m string) string {
+		name := m[1 : len(m)-1]
+		if _, ok := vars[name]; !ok && name != "status" {
+			return m
+		}
+		return "#{" + statusVarOption(name) + "}"
+	})
+}
+
+// templateUsesVar reports whether a status template references {name}
+func templateUsesVar(tmpl, name string) bool {
+	return strings.Contains(tmpl, "{"+name+"}")
+}
+
+// SetCustomization sets the user tmux settings for this session.
+// They take effect on the next Start, or immediately via ApplyCustomization.
+// Pass nil to fall back to the built-in defaults.
+func (s *Session) SetCustomization(c *Customization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.customization = c
+	s.lastStatusVar = ""
+}
+
+// getCustomization returns the current customization (may be nil)
+func (s *Session) getCustomization() *Customization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.customization
+}
+
+// ApplyCustomization applies status bar templates and set-option overrides to
+// a running session. Used after reconnecting, where the built-in defaults were
+// just re-applied. No-op when nothing is configured.
+func (s *Session) ApplyCustomization() {
+	c := s.getCustomization()
+	if c.IsEmpty() {
+		return
+	}
+	s.applyStatusTemplates(c)
+	s.applyUserOptions(c)
+}
+
+// SourceConfigFile sources the user's extra tmux config file, if any.
+// Called on start and restart only, not on every reconnect.
+func (s *Session) SourceConfigFile() {
+	c := s.getCustomization()
+	if c == nil || c.ConfigFile == "" {
+		return
+	}
+	if s.IsRemote() {
+		// The file lives on this machine; the remote tmux server can't read it
+		debugLog("%s: skipping config_file for remote session", s.DisplayName)
+		return
+	}
+	path := expandHome(c.ConfigFile)
+	if out, err := s.cmd("source-file", path).CombinedOutput(); err != nil {
+		debugLog("%s: failed to source %s: %v (%s)", s.DisplayName, path, err, strings.TrimSpace(string(out)))
+	}
+}
+
+// applyUserOptions sets user option overrides in sorted order (deterministic)
+func (s *Session) applyUserOptions(c *Customization) {
+	if c == nil || len(c.Options) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(c.Options))
+	for k := range c.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if out, err := s.cmd("set-option", "-t", s.Name, k, c.Options[k]).CombinedOutput(); err != nil {
+			debugLog("%s: failed to set %s: %v (%s)", s.DisplayName, k, err, strings.TrimSpace(string(out)))
+		}
+	}
+}
+
+// applyStatusTemplates sets template variables and status-left/right from
+// the user templates. Returns false if no templates are configured.
+func (s *Session) applyStatusTemplates(c *Customization) bool {
+	if c == nil || (c.StatusLeft == "" && c.StatusRight == "") {
+		return false
+	}
+
+	names := make([]string, 0, len(c.Vars))
+	for name := range c.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if templateUsesVar(c.StatusLeft, name) || templateUsesVar(c.StatusRight, name) {
+			_ = s.cmd("set-option", "-t", s.Name, statusVarOption(name), c.Vars[name]).Run()
+		}
+	}
+
+	if c.StatusLeft != "" {
+		_ = s.cmd("set-option", "-t", s.Name, "status-left", expandStatusTemplate(c.StatusLeft, c.Vars)).Run()
+	}
+	if c.StatusRight != "" {
+		_ = s.cmd("set-option", "-t", s.Name, "status-right", expandStatusTemplate(c.StatusRight, c.Vars)).Run()
+	}
+	return true
+}
+
+// SetStatusVar updates the {status} template variable shown in the status bar.
+// Only spawns a tmux command when a template uses {status} and the value changed.
+func (s *Session) SetStatusVar(status string) {
+	s.mu.Lock()
+	c := s.customization
+	if c == nil || !(templateUsesVar(c.StatusLeft, "status") || templateUsesVar(c.StatusRight, "status")) ||
+		s.lastStatusVar == status {
+		s.mu.Unlock()
+		return
+	}
+	s.lastStatusVar = status
+	s.mu.Unlock()
+
+	_ = s.cmd("set-option", "-t", s.Name, statusVarOption("status"), status).Run()
+}
+
+// expandHome expands a leading ~/ to the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
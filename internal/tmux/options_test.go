@@ -0,0 +1,74 @@
+package tmux
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandStatusTemplate(t *testing.T) {
+	vars := map[string]string{"title": "api", "group": "work", "tool": "claude"}
+
+	assert.Equal(t, " #{@agentdeck_title} (#{@agentdeck_tool}) ",
+		expandStatusTemplate(" {title} ({tool}) ", vars))
+	// {status} is always available; it is updated as the session changes state
+	assert.Equal(t, "#{@agentdeck_status}", expandStatusTemplate("{status}", vars))
+	// Unknown names and tmux formats pass through untouched
+	assert.Equal(t, "{nope} #{session_name} #[fg=red]",
+		expandStatusTemplate("{nope} #{session_name} #[fg=red]", vars))
+}
+
+func TestCustomizationIsEmpty(t *testing.T) {
+	var nilCustom *Customization
+	assert.True(t, nilCustom.IsEmpty())
+	assert.True(t, (&Customization{Vars: map[string]string{"title": "x"}}).IsEmpty())
+	assert.False(t, (&Customization{Options: map[string]string{"mouse": "off"}}).IsEmpty())
+	assert.False(t, (&Customization{StatusRight: "{status}"}).IsEmpty())
+}
+
+func TestSessionCustomizationApplied(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	sess := NewSession("custom-opts-test", t.TempDir())
+	sess.SetCustomization(&Customization{
+		Options:     map[string]string{"mouse": "off", "history-limit": "12345"},
+		StatusLeft:  " {title}/{tool} ",
+		StatusRight: " {status} ",
+		Vars:        map[string]string{"title": "my-title", "tool": "shell", "status": "starting"},
+	})
+	require.NoError(t, sess.Start(""))
+	defer func() { _ = sess.Kill() }()
+
+	show := func(option string) string {
+		out, err := exec.Command("tmux", "show-options", "-v", "-t", sess.Name, option).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	display := func(format string) string {
+		out, err := exec.Command("tmux", "display-message", "-p", "-t", sess.Name, format).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+
+	// User options override built-in defaults (mouse on, history-limit 10000)
+	assert.Equal(t, "off", show("mouse"))
+	assert.Equal(t, "12345", show("history-limit"))
+
+	assert.Equal(t, "#{@agentdeck_title}/#{@agentdeck_tool}", show("status-left"))
+	assert.Equal(t, "my-title", display("#{@agentdeck_title}"))
+	assert.Equal(t, "starting", display("#{@agentdeck_status}"))
+
+	sess.SetStatusVar("waiting")
+	assert.Equal(t, "waiting", display("#{@agentdeck_status}"))
+
+	// Defaults re-applied on reconnect are overridden again by ApplyCustomization
+	require.NoError(t, sess.EnableMouseMode())
+	assert.Equal(t, "on", show("mouse"))
+	sess.ApplyCustomization()
+	assert.Equal(t, "off", show("mouse"))
+}
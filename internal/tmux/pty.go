@@ -29,8 +29,11 @@ func (s *Session) Attach(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Start tmux attach command with PTY
-	cmd := exec.CommandContext(ctx, "tmux", "attach-session", "-t", s.Name)
+	// Start tmux attach command with PTY (over ssh -t for remote sessions)
+	cmd, err := s.attachCommand(ctx, false)
+	if err != nil {
+		return err
+	}
 
 	// Start command with PTY
 	ptmx, err := pty.Start(cmd)
@@ -184,7 +187,7 @@ func (s *Session) Attach(ctx context.Context) error {
 // Resize changes the terminal size of the tmux session
 func (s *Session) Resize(cols, rows int) error {
 	// Resize the tmux window
-	cmd := exec.Command("tmux", "resize-window", "-t", s.Name, "-x", fmt.Sprintf("%d", cols), "-y", fmt.Sprintf("%d", rows))
+	cmd := s.cmd("resize-window", "-t", s.Name, "-x", fmt.Sprintf("%d", cols), "-y", fmt.Sprintf("%d", rows))
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to resize window: %w", err)
 	}
@@ -205,7 +208,10 @@ func (s *Session) AttachReadOnly(ctx context.Context) error {
 	defer func() { _ = term.Restore(int(os.Stdin.Fd()), oldState) }()
 
 	// Start tmux attach command in read-only mode
-	cmd := exec.CommandContext(ctx, "tmux", "attach-session", "-r", "-t", s.Name)
+	cmd, err := s.attachCommand(ctx, true)
+	if err != nil {
+		return err
+	}
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -236,7 +242,7 @@ func (s *Session) StreamOutput(ctx context.Context, w io.Writer) error {
 	}
 
 	// Use tmux pipe-pane to stream output
-	cmd := exec.CommandContext(ctx, "tmux", "pipe-pane", "-t", s.Name, "-o", "cat")
+	cmd := exec.CommandContext(ctx, "tmux", "pipe-pane", "-t", s.paneTarget(), "-o", "cat")
 	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
 
@@ -258,7 +264,7 @@ func (s *Session) StreamOutput(ctx context.Context, w io.Writer) error {
 	case <-ctx.Done():
 		// Stop pipe-pane - error is intentionally ignored since we're
 		// already returning ctx.Err() and cleanup failure is non-fatal
-		stopCmd := exec.Command("tmux", "pipe-pane", "-t", s.Name)
+		stopCmd := s.cmd("pipe-pane", "-t", s.paneTarget())
 		_ = stopCmd.Run()
 		// Wait for the goroutine to complete before returning
 		wg.Wait()
@@ -0,0 +1,316 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Host is a remote machine whose tmux server runs agent-deck sessions.
+// Commands go over SSH with ControlMaster multiplexing, so after the first
+// connection each tmux call costs one local ssh process and no new handshake.
+// Requires non-interactive (key or agent) SSH authentication.
+type Host struct {
+	Name string
+
+	// Target is what ssh connects to: "user@devbox" or an ~/.ssh/config alias
+	Target string
+
+	Port         int
+	IdentityFile string
+
+	// Options are extra ssh -o options, e.g. "ServerAliveInterval=30"
+	Options []string
+
+	// TmuxPath is the tmux binary on the remote host (default: "tmux")
+	TmuxPath string
+
+	// ControlPersist keeps the master connection open when idle (default: "10m")
+	ControlPersist string
+}
+
+// sshBinary is the ssh client. Tests swap in a shim that runs commands locally.
+var sshBinary = "ssh"
+
+var (
+	hostResolverMu sync.RWMutex
+	hostResolver   func(name string) (Host, bool)
+)
+
+// SetHostResolver sets the lookup for host names used by Session.Host.
+// The session package resolves them from config.toml [hosts.*].
+func SetHostResolver(fn func(name string) (Host, bool)) {
+	hostResolverMu.Lock()
+	defer hostResolverMu.Unlock()
+	hostResolver = fn
+}
+
+// LookupHost resolves a configured host by name
+func LookupHost(name string) (Host, error) {
+	hostResolverMu.RLock()
+	fn := hostResolver
+	hostResolverMu.RUnlock()
+
+	if fn != nil {
+		if h, ok := fn(name); ok {
+			if h.Name == "" {
+				h.Name = name
+			}
+			if h.Target == "" {
+				h.Target = name
+			}
+			return h, nil
+		}
+	}
+	return Host{}, fmt.Errorf("unknown host '%s' (configure it under [hosts.%s])", name, name)
+}
+
+// sshControlDir holds the ControlMaster sockets
+func sshControlDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "/tmp"
+	}
+	return filepath.Join(homeDir, ".agent-deck", "ssh")
+}
+
+// sshArgs builds the ssh client arguments up to and including the target.
+// tty requests a terminal (-t) for attach; otherwise BatchMode keeps
+// background commands from ever blocking on a password prompt.
+func (h Host) sshArgs(tty bool) []string {
+	persist := h.ControlPersist
+	if persist == "" {
+		persist = "10m"
+	}
+	// %C hashes host/port/user so the socket path stays under the unix socket limit
+	args := []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(sshControlDir(), "cm-%C"),
+		"-o", "ControlPersist=" + persist,
+		"-o", "ConnectTimeout=10",
+	}
+	if tty {
+		args = append(args, "-t")
+	} else {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	if h.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(h.Port))
+	}
+	if h.IdentityFile != "" {
+		args = append(args, "-i", expandHome(h.IdentityFile))
+	}
+	for _, opt := range h.Options {
+		args = append(args, "-o", opt)
+	}
+	return append(args, h.Target)
+}
+
+// remoteTmuxCommandLine joins tmux args into one command line for the remote
+// shell (ssh concatenates its arguments, so each one must be quoted)
+func (h Host) remoteTmuxCommandLine(args []string) string {
+	tmuxPath := h.TmuxPath
+	if tmuxPath == "" {
+		tmuxPath = "tmux"
+	}
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, tmuxPath)
+	for i, a := range args {
+		if i > 0 && args[i-1] == "-c" && startDirCommands[args[0]] {
+			parts = append(parts, remoteShellPath(a))
+			continue
+		}
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// startDirCommands take a -c start directory, in which tmux doesn't expand "~"
+var startDirCommands = map[string]bool{
+	"new-session":  true,
+	"new-window":   true,
+	"split-window": true,
+	"respawn-pane": true,
+}
+
+// remoteShellPath quotes a path for the remote shell, leaving a leading "~"
+// unquoted so the shell expands it to the remote user's home
+func remoteShellPath(p string) string {
+	if p == "~" {
+		return p
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return "~/" + shellQuote(rest)
+	}
+	return shellQuote(p)
+}
+
+// shellQuote single-quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// remoteCommand builds the ssh invocation for a remote tmux command
+func (c *tmuxCommand) remoteCommand(ctx context.Context, tty bool) (*exec.Cmd, error) {
+	h, err := LookupHost(c.host)
+	if err != nil {
+		return nil, err
+	}
+	_ = os.MkdirAll(sshControlDir(), 0700)
+	trackRemoteHost(c.host)
+
+	args := append(h.sshArgs(tty), "--", h.remoteTmuxCommandLine(c.args))
+	return exec.CommandContext(ctx, sshBinary, args...), nil
+}
+
+// cmd builds a tmux command for this session's host
+func (s *Session) cmd(args ...string) *tmuxCommand {
+	return hostTmuxCmd(s.Host, args...)
+}
+
+// IsRemote returns true if the session lives on a remote host
+func (s *Session) IsRemote() bool {
+	return s.Host != ""
+}
+
+// attachCommand builds the command that attaches the user's terminal:
+// plain `tmux attach` locally, `ssh -t host tmux attach` for remote sessions
+func (s *Session) attachCommand(ctx context.Context, readOnly bool) (*exec.Cmd, error) {
+	args := []string{"attach-session"}
+	if readOnly {
+		args = append(args, "-r")
+	}
+	args = append(args, "-t", s.Name)
+
+	if !s.IsRemote() {
+		return exec.CommandContext(ctx, "tmux", args...), nil
+	}
+	return s.cmd(args...).remoteCommand(ctx, true)
+}
+
+// Remote session caches mirror the local session cache (see RefreshSessionCache):
+// one `list-panes -a` per host per tick instead of `has-session` per session
+type remoteSessionCache struct {
+	data map[string]*cachedSession // session_name -> entry
+	at   time.Time
+}
+
+// remoteCacheValidity is how long a host's session list is used after its
+// last refresh. Hosts are refreshed in the background every tick, so this
+// only runs out when ssh hangs; it spans ssh's ConnectTimeout so a slow host
+// isn't queried session by session meanwhile.
+const remoteCacheValidity = 15 * time.Second
+
+var (
+	remoteCacheMu    sync.RWMutex
+	remoteCaches     = make(map[string]*remoteSessionCache)
+	remoteHostsInUse = make(map[string]bool)
+
+	remoteRefreshing atomic.Bool // A background refreshRemoteSessionCaches is running
+)
+
+// trackRemoteHost marks a host for refresh by RefreshSessionCache
+func trackRemoteHost(host string) {
+	remoteCacheMu.Lock()
+	remoteHostsInUse[host] = true
+	remoteCacheMu.Unlock()
+}
+
+// refreshRemoteSessionCachesAsync starts refreshRemoteSessionCaches in the
+// background unless the last one is still running, so ticks never wait for
+// ssh: they read the last session list of each host meanwhile
+func refreshRemoteSessionCachesAsync() {
+	if !remoteRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer remoteRefreshing.Store(false)
+		refreshRemoteSessionCaches()
+	}()
+}
+
+// refreshRemoteSessionCaches refreshes every host in use, in parallel so one
+// slow host doesn't add its latency to the others
+func refreshRemoteSessionCaches() {
+	remoteCacheMu.RLock()
+	hosts := make([]string, 0, len(remoteHostsInUse))
+	for h := range remoteHostsInUse {
+		hosts = append(hosts, h)
+	}
+	remoteCacheMu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			output, err := hostTmuxCmd(host, "list-panes", "-a", "-F", sessionCacheFormat).Output()
+			data := map[string]*cachedSession{}
+			if err == nil {
+				data = parseSessionCache(string(output))
+			} else if sshFailed(err) {
+				// Host unreachable: its sessions are most likely still there, so
+				// keep what we knew (unknown if nothing) until it answers again
+				debugLog("host %s: unreachable, keeping last session list: %v", host, err)
+				remoteCacheMu.Lock()
+				if c := remoteCaches[host]; c != nil {
+					c.at = time.Now()
+				}
+				remoteCacheMu.Unlock()
+				return
+			} else {
+				// tmux answered: no server or no sessions
+				debugLog("host %s: list-panes failed: %v", host, err)
+			}
+			remoteCacheMu.Lock()
+			remoteCaches[host] = &remoteSessionCache{data: data, at: time.Now()}
+			remoteCacheMu.Unlock()
+		}(host)
+	}
+	wg.Wait()
+}
+
+// sshFailed reports whether a remote command failed in ssh itself (connection,
+// authentication) rather than in tmux: ssh exits 255 on its own errors
+func sshFailed(err error) bool {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode() == 255
+	}
+	return true // ssh didn't run
+}
+
+// remoteSessionEntry returns (entry, cacheValid) for a remote session; entry
+// is nil if the session doesn't exist
+func remoteSessionEntry(host, name string) (*cachedSession, bool) {
+	remoteCacheMu.RLock()
+	defer remoteCacheMu.RUnlock()
+
+	c := remoteCaches[host]
+	if c == nil || time.Since(c.at) > remoteCacheValidity {
+		return nil, false
+	}
+	return c.data[name], true
+}
+
+// registerRemoteSessionInCache adds a newly created remote session to its host cache
+func registerRemoteSessionInCache(host, name string) {
+	remoteCacheMu.Lock()
+	defer remoteCacheMu.Unlock()
+
+	remoteHostsInUse[host] = true
+	c := remoteCaches[host]
+	if c == nil {
+		c = &remoteSessionCache{data: make(map[string]*cachedSession)}
+		remoteCaches[host] = c
+	}
+	c.data[name] = &cachedSession{activity: time.Now().Unix()}
+}
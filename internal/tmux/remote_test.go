@@ -0,0 +1,207 @@
+package tmux
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'plain'`, shellQuote("plain"))
+	assert.Equal(t, `'#{pane_id}'`, shellQuote("#{pane_id}"))
+	assert.Equal(t, `'it'\''s $HOME'`, shellQuote("it's $HOME"))
+}
+
+func TestHostSSHArgs(t *testing.T) {
+	h := Host{
+		Name:         "devbox",
+		Target:       "me@devbox",
+		Port:         2222,
+		IdentityFile: "/keys/id",
+		Options:      []string{"ServerAliveInterval=30"},
+		TmuxPath:     "/opt/bin/tmux",
+	}
+
+	args := h.sshArgs(false)
+	joined := strings.Join(args, " ")
+	assert.Contains(t, joined, "ControlMaster=auto")
+	assert.Contains(t, joined, "ControlPersist=10m")
+	assert.Contains(t, joined, "BatchMode=yes")
+	assert.Contains(t, joined, "-p 2222")
+	assert.Contains(t, joined, "-i /keys/id")
+	assert.Contains(t, joined, "-o ServerAliveInterval=30")
+	assert.NotContains(t, args, "-t")
+	assert.Equal(t, "me@devbox", args[len(args)-1])
+
+	ttyArgs := h.sshArgs(true)
+	assert.Contains(t, ttyArgs, "-t")
+	assert.NotContains(t, strings.Join(ttyArgs, " "), "BatchMode")
+
+	assert.Equal(t, `/opt/bin/tmux 'send-keys' '-t' 'agentdeck_x' 'echo '\''hi'\'''`,
+		h.remoteTmuxCommandLine([]string{"send-keys", "-t", "agentdeck_x", "echo 'hi'"}))
+
+	// The remote shell expands "~" in start directories, and only there
+	assert.Equal(t, `/opt/bin/tmux 'new-session' '-d' '-c' ~/'src/my api'`,
+		h.remoteTmuxCommandLine([]string{"new-session", "-d", "-c", "~/src/my api"}))
+	assert.Equal(t, `/opt/bin/tmux 'split-window' '-c' ~`,
+		h.remoteTmuxCommandLine([]string{"split-window", "-c", "~"}))
+	assert.Equal(t, `/opt/bin/tmux 'send-keys' '-c' '~'`,
+		h.remoteTmuxCommandLine([]string{"send-keys", "-c", "~"}))
+}
+
+func TestLookupHostUnknown(t *testing.T) {
+	SetHostResolver(nil)
+	_, err := LookupHost("nowhere")
+	assert.Error(t, err)
+
+	err = hostTmuxCmd("nowhere", "list-sessions").Run()
+	assert.Error(t, err)
+}
+
+// waitRemoteRefresh waits for a background remote refresh started by
+// RefreshSessionCache, so tests don't swap sshBinary under it
+func waitRemoteRefresh() {
+	for remoteRefreshing.Load() {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// useFakeSSH points the ssh client at a shim that ignores the ssh options and
+// runs the remote command line locally, so remote sessions hit the local tmux
+func useFakeSSH(t *testing.T) {
+	t.Helper()
+	waitRemoteRefresh()
+	shim := filepath.Join(t.TempDir(), "fake-ssh")
+	script := `#!/bin/sh
+while [ "$#" -gt 0 ] && [ "$1" != "--" ]; do shift; done
+shift
+echo "$*" >> "$FAKE_SSH_LOG"
+exec sh -c "$*"
+`
+	require.NoError(t, os.WriteFile(shim, []byte(script), 0755))
+	logFile := filepath.Join(t.TempDir(), "ssh.log")
+	t.Setenv("FAKE_SSH_LOG", logFile)
+
+	oldBinary := sshBinary
+	sshBinary = shim
+	SetHostResolver(func(name string) (Host, bool) {
+		return Host{Name: name, Target: "fake@" + name}, name == "fakehost"
+	})
+	t.Cleanup(func() {
+		waitRemoteRefresh()
+		sshBinary = oldBinary
+		SetHostResolver(nil)
+		remoteCacheMu.Lock()
+		delete(remoteHostsInUse, "fakehost")
+		delete(remoteCaches, "fakehost")
+		remoteCacheMu.Unlock()
+	})
+}
+
+func TestRemoteSessionOverSSH(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	useFakeSSH(t)
+
+	sess := NewSession("remote-test", t.TempDir())
+	sess.Host = "fakehost"
+	require.NoError(t, sess.Start(""))
+	defer func() { _ = sess.Kill() }()
+
+	// The whole lifecycle went through the ssh client
+	logData, err := os.ReadFile(os.Getenv("FAKE_SSH_LOG"))
+	require.NoError(t, err)
+	assert.Contains(t, string(logData), "'new-session'")
+
+	// Refreshed directly: RefreshSessionCache refreshes hosts in the background
+	refreshRemoteSessionCaches()
+	assert.True(t, sess.Exists())
+	entry, valid := remoteSessionEntry("fakehost", sess.Name)
+	assert.True(t, valid)
+	assert.NotNil(t, entry)
+
+	require.NoError(t, sess.SendKeys("echo remote-says-hi"))
+	require.NoError(t, sess.SendEnter())
+	require.Eventually(t, func() bool {
+		content, err := sess.CapturePane()
+		return err == nil && strings.Contains(content, "remote-says-hi")
+	}, 3*time.Second, 50*time.Millisecond)
+
+	// Polling status works without pipe-pane events
+	status, err := sess.GetStatus()
+	require.NoError(t, err)
+	assert.NotEmpty(t, status)
+	assert.False(t, sess.usesEventStatusLocked())
+
+	// Discovery finds it on the host
+	found, err := DiscoverTmuxSessionsOnHost("fakehost")
+	require.NoError(t, err)
+	var names []string
+	for _, s := range found {
+		assert.Equal(t, "fakehost", s.Host)
+		names = append(names, s.Name)
+	}
+	assert.Contains(t, names, sess.Name)
+
+	// Attach uses ssh -t ... tmux attach-session
+	cmd, err := sess.attachCommand(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, sshBinary, cmd.Path)
+	assert.Contains(t, cmd.Args, "-t")
+	assert.Contains(t, cmd.Args[len(cmd.Args)-1], "'attach-session' '-t' '"+sess.Name+"'")
+
+	// A reconnecting process finds the session over ssh too
+	refreshRemoteSessionCaches()
+	reconnected := ReconnectSessionOnHost("fakehost", sess.Name, sess.DisplayName, sess.WorkDir, "")
+	assert.True(t, reconnected.Exists())
+	assert.Equal(t, sess.AgentPane(), reconnected.AgentPane())
+}
+
+func TestRemoteCacheSurvivesSSHFailure(t *testing.T) {
+	useFakeSSH(t)
+	failWith := func(code int) {
+		shim := filepath.Join(t.TempDir(), "failing-ssh")
+		require.NoError(t, os.WriteFile(shim, []byte("#!/bin/sh\nexit "+strconv.Itoa(code)+"\n"), 0755))
+		sshBinary = shim
+	}
+	registerRemoteSessionInCache("fakehost", "agentdeck_kept")
+
+	// Connection lost: ssh exits 255, the last known sessions stay
+	failWith(255)
+	refreshRemoteSessionCaches()
+	entry, valid := remoteSessionEntry("fakehost", "agentdeck_kept")
+	assert.True(t, valid)
+	assert.NotNil(t, entry)
+
+	// tmux itself failed (no server): the sessions are gone
+	failWith(1)
+	refreshRemoteSessionCaches()
+	entry, valid = remoteSessionEntry("fakehost", "agentdeck_kept")
+	assert.True(t, valid)
+	assert.Nil(t, entry)
+}
+
+func TestRefreshSessionCacheDoesNotWaitForHosts(t *testing.T) {
+	useFakeSSH(t)
+	shim := filepath.Join(t.TempDir(), "slow-ssh")
+	require.NoError(t, os.WriteFile(shim, []byte("#!/bin/sh\nsleep 1\nexit 255\n"), 0755))
+	sshBinary = shim
+	registerRemoteSessionInCache("fakehost", "agentdeck_slow")
+	refreshRemoteSessionCaches() // Unreachable: marks the kept list fresh
+
+	start := time.Now()
+	RefreshSessionCache()
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "a slow host held up the local refresh")
+	entry, valid := remoteSessionEntry("fakehost", "agentdeck_slow")
+	assert.True(t, valid)
+	assert.NotNil(t, entry)
+}
@@ -0,0 +1,121 @@
+package tmux
+
+import (
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+// benchTickInterval mirrors the TUI tick (internal/ui tickInterval)
+const benchTickInterval = 500 * time.Millisecond
+
+// BenchmarkStatusLoopSubprocesses measures tmux subprocesses spawned by the
+// status loop for a mix of idle and busy sessions:
+//
+//   - per-event-update: the previous design, where every log write ran a full
+//     polling GetStatus (CapturePane while in cooldown)
+//   - event-driven: log writes only feed the state machine; CapturePane runs
+//     once when output settles
+//
+// Each iteration is one tick (cache refresh + GetStatus for every session).
+// Reports tmux-calls/min at the TUI's 500ms tick rate.
+//
+//	go test ./internal/tmux -run '^$' -bench StatusLoopSubprocesses -benchtime 60x
+func BenchmarkStatusLoopSubprocesses(b *testing.B) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		b.Skip("tmux not available")
+	}
+
+	b.Run("per-event-update", func(b *testing.B) {
+		benchmarkStatusLoop(b, false)
+	})
+	b.Run("event-driven", func(b *testing.B) {
+		benchmarkStatusLoop(b, true)
+	})
+}
+
+func benchmarkStatusLoop(b *testing.B, eventDriven bool) {
+	const idleSessions = 6
+	const busySessions = 2
+
+	var sessions []*Session
+	for n := 0; n < idleSessions+busySessions; n++ {
+		sess := NewSession("bench-status", b.TempDir())
+		command := ""
+		if n < busySessions {
+			// Steady output with a busy indicator, like Claude mid-response
+			command = `while true; do echo "Working... (esc to interrupt)"; sleep 0.2; done`
+		}
+		if err := sess.Start(command); err != nil {
+			b.Fatalf("Start failed: %v", err)
+		}
+		if !eventDriven {
+			sess.pipeEnabled = false // force the polling path
+		}
+		sessions = append(sessions, sess)
+	}
+	defer func() {
+		for _, sess := range sessions {
+			_ = sess.Kill()
+		}
+	}()
+
+	var inflight sync.WaitGroup
+	watcher, err := NewLogWatcher(LogDir(), func(sessionName string) {
+		for _, sess := range sessions {
+			if sess.Name != sessionName {
+				continue
+			}
+			sess.SignalFileActivity()
+			if !eventDriven {
+				inflight.Add(1)
+				go func(s *Session) {
+					defer inflight.Done()
+					_, _ = s.GetStatus()
+				}(sess)
+			}
+		}
+	})
+	if err != nil {
+		b.Fatalf("NewLogWatcher failed: %v", err)
+	}
+	go watcher.Start()
+	defer watcher.Close()
+
+	// Wait for shells to start and busy sessions to begin producing output
+	for _, sess := range sessions[:busySessions] {
+		deadline := time.Now().Add(15 * time.Second)
+		for time.Now().Before(deadline) {
+			if content, err := sess.CapturePane(); err == nil && sess.hasBusyIndicator(content) {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	// Warm up: first pass initializes trackers on both paths
+	RefreshSessionCache()
+	for _, sess := range sessions {
+		_, _ = sess.GetStatus()
+	}
+
+	// Ticks are scaled down 5x to keep the benchmark short; time-based
+	// thresholds (cooldown, spike window) still span several ticks
+	tick := benchTickInterval / 5
+	start := ExecCount()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		time.Sleep(tick)
+		RefreshSessionCache()
+		for _, sess := range sessions {
+			_, _ = sess.GetStatus()
+		}
+	}
+	b.StopTimer()
+	inflight.Wait()
+
+	perTick := float64(ExecCount()-start) / float64(b.N)
+	b.ReportMetric(perTick, "tmux-calls/tick")
+	b.ReportMetric(perTick*float64(time.Minute/benchTickInterval), "tmux-calls/min")
+}
@@ -7,9 +7,10 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -28,19 +29,37 @@ const SessionPrefix = "agentdeck_"
 
 // Session cache - reduces subprocess spawns from O(n) to O(1) per tick
 // Instead of calling `tmux has-session` and `tmux display-message` for each session,
-// we call `tmux list-sessions` ONCE and cache both existence and activity timestamps
+// we call `tmux list-panes -a` ONCE and cache existence, activity timestamps and
+// the state of every pane
 var (
 	sessionCacheMu   sync.RWMutex
-	sessionCacheData map[string]int64 // session_name -> activity_timestamp (0 if not in cache)
+	sessionCacheData map[string]*cachedSession // session_name -> entry (missing = doesn't exist)
 	sessionCacheTime time.Time
 )
 
-// RefreshSessionCache updates the cache of existing tmux sessions and their activity
-// Call this ONCE per tick, then use Session.Exists() and Session.GetWindowActivity()
-// which read from cache. This reduces 30+ subprocess spawns to just 1 per tick cycle.
+// cachedSession is what the session cache knows about one tmux session
+type cachedSession struct {
+	activity   int64                // #{window_activity} of the active window
+	panes      map[string]PaneState // By pane ID
+	activePane string               // Active pane of the active window (what the session name targets)
+
+	// paneActivity is each pane's #{pane_activity}, or its window's
+	// #{window_activity} on tmux versions that don't report pane activity
+	paneActivity map[string]int64
+}
+
+// sessionCacheFormat is the `list-panes -a` format parsed by parseSessionCache
+// (paneStateFormat goes last: it ends with free text)
+const sessionCacheFormat = "#{session_name}\t#{window_activity}\t#{pane_id}\t#{window_active}\t#{pane_active}\t#{pane_activity}\t" + paneStateFormat
+
+// RefreshSessionCache updates the cache of existing tmux sessions, their activity
+// and their panes' state. Call this ONCE per tick, then use Session.Exists(),
+// Session.GetWindowActivity() and Session.GetPaneState() which read from cache.
+// This reduces 30+ subprocess spawns to just 1 per tick cycle.
 func RefreshSessionCache() {
-	// Get both session name AND activity timestamp in single call
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}\t#{session_activity}")
+	// One line per pane, each with its session's name and window's activity
+	// (#{session_activity} only moves on client input, not on output)
+	cmd := tmuxCmd("list-panes", "-a", "-F", sessionCacheFormat)
 	output, err := cmd.Output()
 	if err != nil {
 		// tmux not running or error - clear cache
@@ -48,28 +67,54 @@ func RefreshSessionCache() {
 		sessionCacheData = nil
 		sessionCacheTime = time.Time{}
 		sessionCacheMu.Unlock()
+		refreshRemoteSessionCachesAsync()
 		return
 	}
 
-	newCache := make(map[string]int64)
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+	newCache := parseSessionCache(string(output))
+
+	sessionCacheMu.Lock()
+	sessionCacheData = newCache
+	sessionCacheTime = time.Now()
+	sessionCacheMu.Unlock()
+
+	// Sessions on remote hosts get the same one-call-per-tick treatment, in
+	// the background: ssh can take seconds and must not hold up local sessions
+	refreshRemoteSessionCachesAsync()
+}
+
+// parseSessionCache parses `list-panes -a -F sessionCacheFormat`
+func parseSessionCache(output string) map[string]*cachedSession {
+	result := make(map[string]*cachedSession)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
 		if line == "" {
 			continue
 		}
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
+		parts := strings.SplitN(line, "\t", 7)
+		if len(parts) != 7 {
 			continue
 		}
 		name := parts[0]
-		var activity int64
-		_, _ = fmt.Sscanf(parts[1], "%d", &activity) // ignore error, 0 is valid default
-		newCache[name] = activity
+		entry := result[name]
+		if entry == nil {
+			entry = &cachedSession{panes: make(map[string]PaneState), paneActivity: make(map[string]int64)}
+			result[name] = entry
+		}
+		entry.panes[parts[2]] = parsePaneState(parts[6])
+		windowActivity, _ := strconv.ParseInt(parts[1], 10, 64) // 0 is a valid default
+		paneActivity, err := strconv.ParseInt(parts[5], 10, 64)
+		if err != nil {
+			paneActivity = windowActivity
+		}
+		entry.paneActivity[parts[2]] = paneActivity
+		if parts[3] == "1" {
+			entry.activity = windowActivity
+			if parts[4] == "1" {
+				entry.activePane = parts[2]
+			}
+		}
 	}
-
-	sessionCacheMu.Lock()
-	sessionCacheData = newCache
-	sessionCacheTime = time.Now()
-	sessionCacheMu.Unlock()
+	return result
 }
 
 // RefreshExistingSessions is an alias for RefreshSessionCache for backwards compatibility
@@ -77,59 +122,55 @@ func RefreshExistingSessions() {
 	RefreshSessionCache()
 }
 
-// sessionExistsFromCache checks if a session exists using the cached data
-// Returns (exists, cacheValid) - if cache is stale/empty, cacheValid is false
-func sessionExistsFromCache(name string) (bool, bool) {
+// cachedSessionEntry returns a session's cache entry (nil if it doesn't exist)
+// Returns (entry, cacheValid) - if cache is stale/empty, cacheValid is false
+func cachedSessionEntry(host, name string) (*cachedSession, bool) {
+	if host != "" {
+		return remoteSessionEntry(host, name)
+	}
+
 	sessionCacheMu.RLock()
 	defer sessionCacheMu.RUnlock()
 
 	// Cache is valid for 2 seconds (4 ticks at 500ms)
 	if sessionCacheData == nil || time.Since(sessionCacheTime) > 2*time.Second {
-		return false, false // Cache invalid
+		return nil, false // Cache invalid
 	}
+	return sessionCacheData[name], true
+}
 
-	_, exists := sessionCacheData[name]
-	return exists, true
+// sessionExistsFromCache checks if a session exists using the cached data
+// Returns (exists, cacheValid) - if cache is stale/empty, cacheValid is false
+func sessionExistsFromCache(host, name string) (bool, bool) {
+	entry, valid := cachedSessionEntry(host, name)
+	return entry != nil, valid
 }
 
 // registerSessionInCache adds a newly created session to the cache
 // This prevents the race condition where a new session isn't found
 // because the cache was refreshed before the session was created
-func registerSessionInCache(name string) {
+func registerSessionInCache(host, name string) {
+	if host != "" {
+		registerRemoteSessionInCache(host, name)
+		return
+	}
+
 	sessionCacheMu.Lock()
 	defer sessionCacheMu.Unlock()
 
 	// Initialize cache if nil
 	if sessionCacheData == nil {
-		sessionCacheData = make(map[string]int64)
+		sessionCacheData = make(map[string]*cachedSession)
 	}
 
-	// Add session with current time as activity
-	sessionCacheData[name] = time.Now().Unix()
-}
-
-// sessionActivityFromCache gets session activity timestamp from cache
-// Returns (activity, cacheValid) - if cache is stale/empty, cacheValid is false
-func sessionActivityFromCache(name string) (int64, bool) {
-	sessionCacheMu.RLock()
-	defer sessionCacheMu.RUnlock()
-
-	// Cache is valid for 2 seconds (4 ticks at 500ms)
-	if sessionCacheData == nil || time.Since(sessionCacheTime) > 2*time.Second {
-		return 0, false // Cache invalid
-	}
-
-	activity, exists := sessionCacheData[name]
-	if !exists {
-		return 0, false // Session not in cache (doesn't exist)
-	}
-	return activity, true
+	// Add session with current time as activity (pane state comes with the next refresh)
+	sessionCacheData[name] = &cachedSession{activity: time.Now().Unix()}
 }
 
 // IsTmuxAvailable checks if tmux is installed and accessible
 // Returns nil if tmux is available, otherwise returns an error with details
 func IsTmuxAvailable() error {
-	cmd := exec.Command("tmux", "-V")
+	cmd := tmuxCmd("-V")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("tmux not found or not working: %w (output: %s)", err, string(output))
@@ -329,6 +370,13 @@ type StateTracker struct {
 	// Non-blocking spike detection: track changes across tick cycles
 	activityCheckStart  time.Time // When we started tracking for sustained activity
 	activityChangeCount int       // How many timestamp changes seen in current window
+
+	// Event-driven detection (see events.go): fed by LogWatcher via SignalFileActivity
+	lastOutputAt      time.Time // Last pipe-pane log write
+	outputWindowStart time.Time // Start of current log-write spike window
+	outputEventCount  int       // Log writes seen in current spike window
+	needsSettleCheck  bool      // Capture pane once after output settles
+	lastResync        time.Time // Last time the polling path ran as a safety net
 }
 
 // acknowledgeGracePeriod is how long after user detaches before content changes
@@ -352,6 +400,14 @@ type Session struct {
 	Command     string
 	Created     time.Time
 
+	// Host is the remote host running this session ("" = local tmux server).
+	// All tmux commands for the session go over SSH. See remote.go.
+	Host string
+
+	// Env is extra environment for the agent, passed to new-session and
+	// respawn-pane with -e
+	Env map[string]string
+
 	// mu protects all mutable fields below from concurrent access
 	mu sync.Mutex
 
@@ -369,6 +425,57 @@ type Session struct {
 
 	// Last status returned (for debugging)
 	lastStableStatus string
+
+	// pipeEnabled is true once pipe-pane logging is active for this session,
+	// making it eligible for event-driven status detection
+	pipeEnabled bool
+
+	// customization holds user tmux settings (options, status templates)
+	// applied on top of the built-in defaults. See options.go.
+	customization *Customization
+
+	// lastStatusVar is the last value written to the {status} template variable
+	lastStatusVar string
+
+	// agentPane is the tmux pane ID (e.g. "%3") running the agent.
+	// Empty means target the session's active pane. See layout.go.
+	agentPane string
+
+	// Activity of the agent pane alone, for sessions with a layout (tmux only
+	// tracks activity per window and session): the hash of the pane content,
+	// when it last changed, and the tmux activity it was captured at. See
+	// paneActivity.
+	agentPaneHash     string
+	agentPaneActivity int64
+	agentPaneSeen     int64
+}
+
+// CarryStatusState copies the status tracking of old, the same tmux session
+// loaded before sessions.json was reloaded, so a reload doesn't lose the
+// activity history or whether the user has seen the session
+func (s *Session) CarryStatusState(old *Session) {
+	if old == nil || old == s || old.Name != s.Name {
+		return
+	}
+	old.mu.Lock()
+	var tracker *StateTracker
+	if old.stateTracker != nil {
+		t := *old.stateTracker
+		tracker = &t
+	}
+	lastHash, lastContent := old.lastHash, old.lastContent
+	lastStableStatus, lastStatusVar := old.lastStableStatus, old.lastStatusVar
+	paneHash, paneActivity, paneSeen := old.agentPaneHash, old.agentPaneActivity, old.agentPaneSeen
+	old.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tracker != nil {
+		s.stateTracker = tracker
+	}
+	s.lastHash, s.lastContent = lastHash, lastContent
+	s.lastStableStatus, s.lastStatusVar = lastStableStatus, lastStatusVar
+	s.agentPaneHash, s.agentPaneActivity, s.agentPaneSeen = paneHash, paneActivity, paneSeen
 }
 
 // ensureStateTrackerLocked lazily allocates the tracker so callers can safely
@@ -424,7 +531,13 @@ func NewSession(name, workDir string) *Session {
 // This is used when loading sessions from storage - it properly initializes
 // all fields needed for status detection to work correctly
 func ReconnectSession(tmuxName, displayName, workDir, command string) *Session {
+	return ReconnectSessionOnHost("", tmuxName, displayName, workDir, command)
+}
+
+// ReconnectSessionOnHost is ReconnectSession for a session on a remote host ("" = local)
+func ReconnectSessionOnHost(host, tmuxName, displayName, workDir, command string) *Session {
 	sess := &Session{
+		Host:             host,
 		Name:             tmuxName,
 		DisplayName:      displayName,
 		WorkDir:          workDir,
@@ -437,6 +550,8 @@ func ReconnectSession(tmuxName, displayName, workDir, command string) *Session {
 
 	// Enable pipe-pane for event-driven status detection
 	if sess.Exists() {
+		// Restore the agent pane first so pipe-pane attaches to the right pane
+		sess.loadAgentPane()
 		if err := sess.EnablePipePane(); err != nil {
 			debugLog("Warning: failed to enable pipe-pane for %s: %v", tmuxName, err)
 		}
@@ -453,7 +568,13 @@ func ReconnectSession(tmuxName, displayName, workDir, command string) *Session {
 //   - "waiting" (yellow): acknowledged=false, cooldown expired
 //   - "active" (green): will be recalculated based on actual content changes
 func ReconnectSessionWithStatus(tmuxName, displayName, workDir, command string, previousStatus string) *Session {
-	sess := ReconnectSession(tmuxName, displayName, workDir, command)
+	return ReconnectSessionWithStatusOnHost("", tmuxName, displayName, workDir, command, previousStatus)
+}
+
+// ReconnectSessionWithStatusOnHost is ReconnectSessionWithStatus for a session
+// on a remote host ("" = local)
+func ReconnectSessionWithStatusOnHost(host, tmuxName, displayName, workDir, command string, previousStatus string) *Session {
+	sess := ReconnectSessionOnHost(host, tmuxName, displayName, workDir, command)
 
 	switch previousStatus {
 	case "idle":
@@ -480,14 +601,6 @@ func ReconnectSessionWithStatus(tmuxName, displayName, workDir, command string,
 		sess.lastStableStatus = "waiting"
 	}
 
-	// Enable pipe-pane for event-driven status detection
-	// (Note: Also called in ReconnectSession, but we ensure it's enabled after state restoration)
-	if sess.Exists() {
-		if err := sess.EnablePipePane(); err != nil {
-			debugLog("Warning: failed to enable pipe-pane for %s: %v", tmuxName, err)
-		}
-	}
-
 	return sess
 }
 
@@ -503,14 +616,14 @@ func generateShortID() string {
 
 // SetEnvironment sets an environment variable for this tmux session
 func (s *Session) SetEnvironment(key, value string) error {
-	cmd := exec.Command("tmux", "set-environment", "-t", s.Name, key, value)
+	cmd := s.cmd("set-environment", "-t", s.Name, key, value)
 	return cmd.Run()
 }
 
 // GetEnvironment gets an environment variable from this tmux session
 // Returns the value or error if not found
 func (s *Session) GetEnvironment(key string) (string, error) {
-	cmd := exec.Command("tmux", "show-environment", "-t", s.Name, key)
+	cmd := s.cmd("show-environment", "-t", s.Name, key)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("variable not found or session doesn't exist: %s", key)
@@ -524,6 +637,20 @@ func (s *Session) GetEnvironment(key string) (string, error) {
 	return "", fmt.Errorf("variable not found: %s", key)
 }
 
+// envArgs turns env into sorted "-e KEY=VALUE" arguments (tmux 3.0+)
+func envArgs(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		args = append(args, "-e", k+"="+env[k])
+	}
+	return args
+}
+
 // sanitizeName converts a display name to a valid tmux session name
 func sanitizeName(name string) string {
 	// Replace spaces and special characters with hyphens
@@ -543,26 +670,37 @@ func (s *Session) Start(command string) error {
 	}
 
 	// Ensure working directory exists
+	// (remote sessions without a path start in the remote user's home)
 	workDir := s.WorkDir
-	if workDir == "" {
+	if workDir == "" && !s.IsRemote() {
 		workDir = os.Getenv("HOME")
 	}
 
 	// Create new tmux session in detached mode
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", s.Name, "-c", workDir)
+	// -P -F prints the first pane's ID so later commands can target the agent
+	// pane even after layout panes are added
+	args := []string{"new-session", "-d", "-P", "-F", "#{pane_id}", "-s", s.Name}
+	if workDir != "" {
+		args = append(args, "-c", workDir)
+	}
+	args = append(args, envArgs(s.Env)...)
+	cmd := s.cmd(args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to create tmux session: %w (output: %s)", err, string(output))
 	}
+	if paneID := strings.TrimSpace(string(output)); strings.HasPrefix(paneID, "%") {
+		s.setAgentPane(paneID)
+	}
 
 	// Register session in cache immediately to prevent race condition
 	// where Exists() returns false because cache was refreshed before session creation
-	registerSessionInCache(s.Name)
+	registerSessionInCache(s.Host, s.Name)
 
 	// Set default window/pane styles to prevent color issues in some terminals (Warp, etc.)
 	// This ensures no unexpected background colors are applied
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "window-style", "default").Run()
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "window-active-style", "default").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "window-style", "default").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "window-active-style", "default").Run()
 
 	// Enable mouse mode for proper scrolling (per-session, doesn't affect user's other sessions)
 	// This allows:
@@ -571,7 +709,7 @@ func (s *Session) Start(command string) error {
 	// - Pane resizing with mouse
 	// Non-fatal: session still works, just without mouse support
 	// This can fail on very old tmux versions
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "mouse", "on").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "mouse", "on").Run()
 
 	// Enable escape sequence passthrough for modern terminal features (tmux 3.2+)
 	// This allows:
@@ -579,31 +717,43 @@ func (s *Session) Start(command string) error {
 	// - OSC 52: Clipboard integration (copy/paste from remote sessions)
 	// - Image protocols: Inline images in terminals that support it
 	// Uses -q flag to silently ignore on older tmux versions (< 3.2)
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "-q", "allow-passthrough", "on").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "-q", "allow-passthrough", "on").Run()
 
 	// Enable hyperlink support in terminal features (tmux 3.4+, server-wide option)
 	// This tells tmux to track hyperlinks like it tracks colors/attributes
 	// Required for OSC 8 hyperlinks to work - passthrough alone isn't enough
 	// Uses -as to append to existing terminal-features, -q to ignore if unsupported
-	_ = exec.Command("tmux", "set", "-asq", "terminal-features", ",*:hyperlinks").Run()
+	_ = s.cmd("set", "-asq", "terminal-features", ",*:hyperlinks").Run()
 
 	// Enable OSC 52 clipboard integration for seamless copy/paste
 	// Works with: Warp, iTerm2, kitty, Alacritty, WezTerm, Windows Terminal, VS Code
 	// The 'on' value (tmux 2.6+) allows apps inside tmux to set the clipboard
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "set-clipboard", "on").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "set-clipboard", "on").Run()
 
 	// Set large history buffer for AI agent sessions (default is 2000)
 	// AI agents produce extensive output, 10000 lines is a good balance
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "history-limit", "10000").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "history-limit", "10000").Run()
 
 	// Reduce escape-time for responsive Vim/editor usage (default 500ms is too slow)
 	// 10ms is a good balance between responsiveness and SSH reliability
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "escape-time", "10").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "escape-time", "10").Run()
+
+	// Keep agent panes around when their process exits (a restarted agent is
+	// the pane's own process) so the exit status can be read and the pane
+	// respawned. Plain shells still close on `exit`.
+	if command != "" {
+		_ = s.cmd("set-option", "-w", "-t", s.Name, "remain-on-exit", "on").Run()
+	}
 
 	// Configure status bar with session info for easy identification
 	// Shows: session title on left, project folder on right
 	s.ConfigureStatusBar()
 
+	// Apply user tmux settings last so they override the defaults above
+	// (status bar templates were already handled by ConfigureStatusBar)
+	s.applyUserOptions(s.getCustomization())
+	s.SourceConfigFile()
+
 	// Send the command to the session
 	if command != "" {
 		if err := s.SendKeys(command); err != nil {
@@ -628,12 +778,12 @@ func (s *Session) Start(command string) error {
 // Falls back to direct tmux call if cache is stale
 func (s *Session) Exists() bool {
 	// Try cache first (O(1) map lookup, no subprocess)
-	if exists, cacheValid := sessionExistsFromCache(s.Name); cacheValid {
+	if exists, cacheValid := sessionExistsFromCache(s.Host, s.Name); cacheValid {
 		return exists
 	}
 
 	// Cache miss/stale - fall back to direct check (spawns subprocess)
-	cmd := exec.Command("tmux", "has-session", "-t", s.Name)
+	cmd := s.cmd("has-session", "-t", s.Name)
 	return cmd.Run() == nil
 }
 
@@ -648,25 +798,38 @@ func (s *Session) ConfigureStatusBar() {
 	}
 
 	// Enable status bar
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "status", "on").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "status", "on").Run()
 
 	// Style: dark background with accent colors (Tokyo Night inspired)
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "status-style", "bg=#1a1b26,fg=#a9b1d6").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "status-style", "bg=#1a1b26,fg=#a9b1d6").Run()
+
+	_ = s.cmd("set-option", "-t", s.Name, "status-left-length", "40").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "status-right-length", "30").Run()
+
+	// User status bar templates replace the default left/right content
+	// (lengths can be raised via [tmux.options])
+	if s.applyStatusTemplates(s.getCustomization()) {
+		return
+	}
 
 	// Left side: session title with icon
 	leftStatus := fmt.Sprintf(" 📁 %s ", s.DisplayName)
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "status-left", leftStatus).Run()
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "status-left-length", "40").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "status-left", leftStatus).Run()
 
 	// Right side: project folder path
 	rightStatus := fmt.Sprintf(" %s ", folderName)
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "status-right", rightStatus).Run()
-	_ = exec.Command("tmux", "set-option", "-t", s.Name, "status-right-length", "30").Run()
+	_ = s.cmd("set-option", "-t", s.Name, "status-right", rightStatus).Run()
 }
 
 // EnablePipePane enables tmux pipe-pane to stream output to a log file
 // This is used for event-driven status detection via fsnotify
 func (s *Session) EnablePipePane() error {
+	// The log file would land on the remote host where the LogWatcher can't
+	// see it, so remote sessions use polling status detection instead
+	if s.IsRemote() {
+		return nil
+	}
+
 	logFile := s.LogFile()
 
 	// Ensure log directory exists
@@ -676,17 +839,27 @@ func (s *Session) EnablePipePane() error {
 	}
 
 	// Enable pipe-pane: stream pane output to log file
-	cmd := exec.Command("tmux", "pipe-pane", "-t", s.Name, "-o", fmt.Sprintf("cat >> '%s'", logFile))
+	// No -o flag: with -o an existing pipe is toggled OFF instead of replaced,
+	// so reconnecting to an already-piped session would silently stop logging
+	cmd := s.cmd("pipe-pane", "-t", s.paneTarget(), fmt.Sprintf("cat >> '%s'", logFile))
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to enable pipe-pane: %w", err)
 	}
 
+	s.mu.Lock()
+	s.pipeEnabled = true
+	s.mu.Unlock()
+
 	return nil
 }
 
 // DisablePipePane disables pipe-pane logging
 func (s *Session) DisablePipePane() error {
-	cmd := exec.Command("tmux", "pipe-pane", "-t", s.Name)
+	s.mu.Lock()
+	s.pipeEnabled = false
+	s.mu.Unlock()
+
+	cmd := s.cmd("pipe-pane", "-t", s.paneTarget())
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to disable pipe-pane for %s: %w", s.Name, err)
 	}
@@ -712,7 +885,7 @@ func (s *Session) DisablePipePane() error {
 // instead of tmux's selection (useful for copying to system clipboard in some terminals)
 func (s *Session) EnableMouseMode() error {
 	// Enable mouse support
-	mouseCmd := exec.Command("tmux", "set-option", "-t", s.Name, "mouse", "on")
+	mouseCmd := s.cmd("set-option", "-t", s.Name, "mouse", "on")
 	if err := mouseCmd.Run(); err != nil {
 		return err
 	}
@@ -720,7 +893,7 @@ func (s *Session) EnableMouseMode() error {
 	// Enable OSC 52 clipboard integration
 	// This allows tmux to copy directly to system clipboard in supported terminals
 	// (Warp, iTerm2, Alacritty, kitty, WezTerm, Windows Terminal, VS Code, etc.)
-	clipboardCmd := exec.Command("tmux", "set-option", "-t", s.Name, "set-clipboard", "on")
+	clipboardCmd := s.cmd("set-option", "-t", s.Name, "set-clipboard", "on")
 	if err := clipboardCmd.Run(); err != nil {
 		// Non-fatal: older tmux versions may not support this
 		debugLog("%s: failed to enable clipboard: %v", s.DisplayName, err)
@@ -732,7 +905,7 @@ func (s *Session) EnableMouseMode() error {
 	// - OSC 52: Clipboard integration (apps inside tmux can set clipboard)
 	// - Image protocols: Inline images in supported terminals
 	// Uses -q flag to silently ignore on older tmux versions
-	passthroughCmd := exec.Command("tmux", "set-option", "-t", s.Name, "-q", "allow-passthrough", "on")
+	passthroughCmd := s.cmd("set-option", "-t", s.Name, "-q", "allow-passthrough", "on")
 	if err := passthroughCmd.Run(); err != nil {
 		// Non-fatal: tmux < 3.2 doesn't support this option
 		debugLog("%s: failed to enable passthrough (tmux < 3.2?): %v", s.DisplayName, err)
@@ -741,7 +914,7 @@ func (s *Session) EnableMouseMode() error {
 	// Enable hyperlink support in terminal features (tmux 3.4+, server-wide option)
 	// This tells tmux to track hyperlinks like it tracks colors/attributes
 	// Required for OSC 8 hyperlinks to work - passthrough alone isn't enough
-	hyperlinkCmd := exec.Command("tmux", "set", "-asq", "terminal-features", ",*:hyperlinks")
+	hyperlinkCmd := s.cmd("set", "-asq", "terminal-features", ",*:hyperlinks")
 	if err := hyperlinkCmd.Run(); err != nil {
 		// Non-fatal: tmux < 3.4 doesn't support hyperlinks in terminal-features
 		debugLog("%s: failed to enable hyperlinks (tmux < 3.4?): %v", s.DisplayName, err)
@@ -749,7 +922,7 @@ func (s *Session) EnableMouseMode() error {
 
 	// Set large history limit for AI agent sessions (default is 2000)
 	// AI agents produce a lot of output, so we need more scrollback
-	historyCmd := exec.Command("tmux", "set-option", "-t", s.Name, "history-limit", "10000")
+	historyCmd := s.cmd("set-option", "-t", s.Name, "history-limit", "10000")
 	if err := historyCmd.Run(); err != nil {
 		// Non-fatal: history limit is a nice-to-have
 		debugLog("%s: failed to set history-limit: %v", s.DisplayName, err)
@@ -757,7 +930,7 @@ func (s *Session) EnableMouseMode() error {
 
 	// Reduce escape-time for responsive Vim/editor usage (default 500ms is too slow)
 	// 10ms is a good balance between responsiveness and SSH reliability
-	escapeCmd := exec.Command("tmux", "set-option", "-t", s.Name, "escape-time", "10")
+	escapeCmd := s.cmd("set-option", "-t", s.Name, "escape-time", "10")
 	if err := escapeCmd.Run(); err != nil {
 		// Non-fatal: escape-time is a nice-to-have
 		debugLog("%s: failed to set escape-time: %v", s.DisplayName, err)
@@ -776,7 +949,7 @@ func (s *Session) Kill() error {
 	os.Remove(logFile) // Ignore errors
 
 	// Kill the tmux session
-	cmd := exec.Command("tmux", "kill-session", "-t", s.Name)
+	cmd := s.cmd("kill-session", "-t", s.Name)
 	return cmd.Run()
 }
 
@@ -790,16 +963,21 @@ func (s *Session) RespawnPane(command string) error {
 
 	// Build respawn-pane command
 	// -k: Kill current process
-	// -t: Target pane (session:window.pane format, use session: for active pane)
+	// -t: Target pane (the agent pane ID, or session: for the active pane)
 	// command: New command to run
-	target := s.Name + ":"  // Append colon to target the active pane
+	// Other layout panes are left running
+	target := s.Name + ":" // Append colon to target the active pane
+	if agent := s.AgentPane(); agent != "" {
+		target = agent
+	}
 	args := []string{"respawn-pane", "-k", "-t", target}
+	args = append(args, envArgs(s.Env)...)
 	if command != "" {
 		args = append(args, command)
 	}
 
 	log.Printf("[MCP-DEBUG] RespawnPane executing: tmux %v", args)
-	cmd := exec.Command("tmux", args...)
+	cmd := s.cmd(args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		log.Printf("[MCP-DEBUG] RespawnPane error: %v, output: %s", err, string(output))
@@ -810,33 +988,155 @@ func (s *Session) RespawnPane(command string) error {
 	return nil
 }
 
+// PaneState is what tmux reports about the process in the agent pane
+type PaneState struct {
+	CurrentCommand string // Foreground process name (#{pane_current_command})
+	StartCommand   string // Command the pane was (re)spawned with; "" = a shell
+	Dead           bool   // The process exited and the pane was kept (remain-on-exit)
+	DeadStatus     int    // Exit status when Dead; -1 if not reported (e.g. killed by a signal)
+}
+
+// paneStateFormat is the display-message format parsed by parsePaneState
+// (the start command goes last: it is free text)
+const paneStateFormat = "#{pane_dead}\t#{pane_dead_status}\t#{pane_current_command}\t#{pane_start_command}"
+
+// GetPaneState returns the state of the agent pane's process
+// Uses cached data when available (refreshed by RefreshSessionCache)
+func (s *Session) GetPaneState() (PaneState, error) {
+	if entry, valid := cachedSessionEntry(s.Host, s.Name); valid && entry != nil {
+		pane := s.AgentPane()
+		if pane == "" {
+			pane = entry.activePane
+		}
+		if state, ok := entry.panes[pane]; ok {
+			return state, nil
+		}
+	}
+
+	// Cache miss/stale - fall back to direct check (spawns subprocess)
+	cmd := s.cmd("display-message", "-t", s.paneTarget(), "-p", paneStateFormat)
+	output, err := cmd.Output()
+	if err != nil {
+		return PaneState{}, fmt.Errorf("failed to get pane state: %w", err)
+	}
+	return parsePaneState(string(output)), nil
+}
+
+// parsePaneState parses display-message output in paneStateFormat
+func parsePaneState(output string) PaneState {
+	fields := strings.SplitN(strings.TrimRight(output, "\n"), "\t", 4)
+	for len(fields) < 4 {
+		fields = append(fields, "")
+	}
+	state := PaneState{
+		Dead:           fields[0] == "1",
+		CurrentCommand: fields[2],
+		StartCommand:   strings.Trim(fields[3], `"`),
+	}
+	if state.Dead {
+		status, err := strconv.Atoi(fields[1])
+		if err != nil {
+			status = -1
+		}
+		state.DeadStatus = status
+	}
+	return state
+}
+
+// Exited returns true if the pane's program quit: the pane is dead, or a pane
+// started as a shell (the program was typed into it) is back at the shell
+func (p PaneState) Exited() bool {
+	return p.Dead || (p.StartCommand == "" && IsShell(p.CurrentCommand))
+}
+
+// shellNames are the processes a pane falls back to when its agent exits
+var shellNames = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "fish": true, "dash": true,
+	"ksh": true, "tcsh": true, "csh": true, "nu": true, "pwsh": true,
+}
+
+// IsShell returns true if name (a pane_current_command or command word) is an
+// interactive shell; login shells are reported as "-zsh"
+func IsShell(name string) bool {
+	return shellNames[strings.TrimPrefix(filepath.Base(name), "-")]
+}
+
 // GetWindowActivity returns Unix timestamp of last tmux window activity
 // Uses cached data when available (refreshed by RefreshSessionCache)
 // Falls back to direct tmux call if cache is stale
+// Sessions with more than one pane (a layout) report the agent pane's own
+// activity instead, so output in side panes (dev server, test watcher) doesn't count
 func (s *Session) GetWindowActivity() (int64, error) {
 	// Try cache first (O(1) map lookup, no subprocess)
-	if activity, cacheValid := sessionActivityFromCache(s.Name); cacheValid {
-		return activity, nil
+	if entry, cacheValid := cachedSessionEntry(s.Host, s.Name); cacheValid && entry != nil {
+		if len(entry.panes) > 1 {
+			pane := s.AgentPane()
+			if pane == "" {
+				pane = entry.activePane
+			}
+			return s.paneActivity(entry.paneActivity[pane])
+		}
+		return entry.activity, nil
 	}
 
 	// Cache miss/stale - fall back to direct check (spawns subprocess)
-	cmd := exec.Command("tmux", "display-message", "-t", s.Name, "-p", "#{window_activity}")
+	cmd := s.cmd("display-message", "-t", s.paneTarget(), "-p", "#{window_activity} #{session_windows} #{window_panes}")
 	output, err := cmd.Output()
 	if err != nil {
 		return 0, fmt.Errorf("failed to get window activity: %w", err)
 	}
 	var ts int64
-	_, err = fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &ts)
+	var windows, panes int
+	_, err = fmt.Sscanf(strings.TrimSpace(string(output)), "%d %d %d", &ts, &windows, &panes)
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse timestamp: %w", err)
 	}
+	if windows > 1 || panes > 1 {
+		return s.paneActivity(0)
+	}
 	return ts, nil
 }
 
+// paneActivity returns when the agent pane's content last changed (Unix
+// seconds, like tmux's activity timestamps), comparing it with the previous
+// capture. tmuxActivity is the pane's activity from the session cache: the
+// pane is only captured again once that moved (0 = unknown, always capture).
+func (s *Session) paneActivity(tmuxActivity int64) (int64, error) {
+	s.mu.Lock()
+	unchanged := tmuxActivity != 0 && tmuxActivity == s.agentPaneSeen && s.agentPaneHash != ""
+	last := s.agentPaneActivity
+	s.mu.Unlock()
+	if unchanged {
+		return last, nil
+	}
+
+	content, err := s.CapturePane()
+	if err != nil {
+		return 0, err
+	}
+	hash := sha256.Sum256([]byte(content))
+	hashStr := hex.EncodeToString(hash[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// tmux activity has one-second resolution: output later in the same
+	// second wouldn't move it, so capture again next time in that case
+	now := time.Now().Unix()
+	s.agentPaneSeen = tmuxActivity
+	if now <= tmuxActivity {
+		s.agentPaneSeen = 0
+	}
+	if hashStr != s.agentPaneHash {
+		s.agentPaneHash = hashStr
+		s.agentPaneActivity = now
+	}
+	return s.agentPaneActivity, nil
+}
+
 // CapturePane captures the visible pane content
 func (s *Session) CapturePane() (string, error) {
 	// -J joins wrapped lines and trims trailing spaces so hashes don't change on resize
-	cmd := exec.Command("tmux", "capture-pane", "-t", s.Name, "-p", "-J")
+	cmd := s.cmd("capture-pane", "-t", s.paneTarget(), "-p", "-J")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to capture pane: %w", err)
@@ -849,7 +1149,7 @@ func (s *Session) CaptureFullHistory() (string, error) {
 	// Limit to last 2000 lines to balance content availability with memory usage
 	// AI agent conversations can be long - 2000 lines captures ~40-80 screens of content
 	// -J joins wrapped lines and trims trailing spaces so hashes don't change on resize
-	cmd := exec.Command("tmux", "capture-pane", "-t", s.Name, "-p", "-J", "-S", "-2000")
+	cmd := s.cmd("capture-pane", "-t", s.paneTarget(), "-p", "-J", "-S", "-2000")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to capture history: %w", err)
@@ -913,6 +1213,8 @@ func (s *Session) DetectTool() string {
 			tool = "opencode"
 		} else if strings.Contains(cmdLower, "codex") {
 			tool = "codex"
+		} else if strings.Contains(cmdLower, "aider") {
+			tool = "aider"
 		}
 		if tool != "" {
 			s.mu.Lock()
@@ -1038,6 +1340,10 @@ func (s *Session) AcknowledgeWithSnapshot() {
 // Real AI work causes multiple timestamp changes over 1 second (sustained).
 // This filters spikes to prevent false GREEN flashes.
 //
+// When a LogWatcher is running and pipe-pane is enabled, status is derived
+// from log-write events instead (see events.go) and the steps below only run
+// on the first call and on periodic resync.
+//
 // Logic:
 // 1. Check busy indicator (immediate GREEN if present)
 // 2. Get activity timestamp (fast ~4ms)
@@ -1059,6 +1365,16 @@ func (s *Session) GetStatus() (string, error) {
 		return "inactive", nil
 	}
 
+	// Event-driven path: no tmux calls unless output just settled
+	s.mu.Lock()
+	eventDriven := s.usesEventStatusLocked()
+	s.mu.Unlock()
+	if eventDriven {
+		if status, ok := s.getStatusFromEvents(); ok {
+			return status, nil
+		}
+	}
+
 	// Get current activity timestamp (fast: ~4ms)
 	currentTS, err := s.GetWindowActivity()
 	if err != nil {
@@ -1306,19 +1622,6 @@ func (s *Session) ResetAcknowledged() {
 	s.lastStableStatus = "waiting"
 }
 
-// SignalFileActivity signals that file output was detected (from LogWatcher)
-// This directly triggers GREEN status by updating the cooldown timer
-// Call this when pipe-pane log file is written to
-func (s *Session) SignalFileActivity() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.ensureStateTrackerLocked()
-	s.stateTracker.lastChangeTime = time.Now()
-	s.stateTracker.acknowledged = false
-	s.lastStableStatus = "active"
-}
-
 // GetLastActivityTime returns when the session content last changed
 // Returns zero time if no activity has been tracked
 func (s *Session) GetLastActivityTime() time.Time {
@@ -1603,28 +1906,53 @@ func (s *Session) SendKeys(keys string) error {
 	// The -l flag makes tmux treat the string as literal text, not key names
 	// This prevents issues like "Enter" being interpreted as the Enter key
 	// and provides a layer of safety against tmux special sequences
-	cmd := exec.Command("tmux", "send-keys", "-l", "-t", s.Name, keys)
+	cmd := s.cmd("send-keys", "-l", "-t", s.paneTarget(), keys)
 	return cmd.Run()
 }
 
 // SendEnter sends an Enter key to the tmux session
 func (s *Session) SendEnter() error {
-	cmd := exec.Command("tmux", "send-keys", "-t", s.Name, "Enter")
+	cmd := s.cmd("send-keys", "-t", s.paneTarget(), "Enter")
 	return cmd.Run()
 }
 
 // SendCtrlC sends Ctrl+C (interrupt signal) to the tmux session
 func (s *Session) SendCtrlC() error {
-	cmd := exec.Command("tmux", "send-keys", "-t", s.Name, "C-c")
+	cmd := s.cmd("send-keys", "-t", s.paneTarget(), "C-c")
 	return cmd.Run()
 }
 
 // SendCtrlU sends Ctrl+U (clear line) to the tmux session
 func (s *Session) SendCtrlU() error {
-	cmd := exec.Command("tmux", "send-keys", "-t", s.Name, "C-u")
+	cmd := s.cmd("send-keys", "-t", s.paneTarget(), "C-u")
 	return cmd.Run()
 }
 
+// SendKeyNames presses tmux keys by name (e.g. "Escape", "C-c", "Enter")
+func (s *Session) SendKeyNames(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := append([]string{"send-keys", "-t", s.paneTarget()}, keys...)
+	return s.cmd(args...).Run()
+}
+
+// PasteText pastes text into the agent pane as one bracketed paste, so
+// applications that support it receive newlines as part of the text instead
+// of as Enter key presses
+func (s *Session) PasteText(text string) error {
+	buffer := fmt.Sprintf("agentdeck-%d", time.Now().UnixNano())
+	if err := s.cmd("load-buffer", "-b", buffer, "-").RunWithInput([]byte(text)); err != nil {
+		return fmt.Errorf("failed to load paste buffer: %w", err)
+	}
+	// -p: bracketed paste when the application asked for it; -d: delete the buffer
+	if err := s.cmd("paste-buffer", "-p", "-d", "-b", buffer, "-t", s.paneTarget()).Run(); err != nil {
+		_ = s.cmd("delete-buffer", "-b", buffer).Run()
+		return err
+	}
+	return nil
+}
+
 // WaitForShellPrompt polls the terminal until a shell prompt is detected
 // Returns true if shell prompt found, false if timeout
 // Shell prompts: $, #, %, ❯, ➜, or bare > at end of line
@@ -1797,7 +2125,7 @@ func (s *Session) GetWorkDir() string {
 		return ""
 	}
 
-	cmd := exec.Command("tmux", "display-message", "-t", s.Name, "-p", "#{pane_current_path}")
+	cmd := s.cmd("display-message", "-t", s.paneTarget(), "-p", "#{pane_current_path}")
 	output, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -1807,7 +2135,7 @@ func (s *Session) GetWorkDir() string {
 
 // ListAllSessions returns all Agent Deck tmux sessions
 func ListAllSessions() ([]*Session, error) {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
+	cmd := tmuxCmd("list-sessions", "-F", "#{session_name}")
 	output, err := cmd.Output()
 	if err != nil {
 		// No sessions exist
@@ -1830,7 +2158,7 @@ func ListAllSessions() ([]*Session, error) {
 				DisplayName: displayName,
 			}
 			// Try to get working directory
-			workDirCmd := exec.Command("tmux", "display-message", "-t", line, "-p", "#{pane_current_path}")
+			workDirCmd := tmuxCmd("display-message", "-t", line, "-p", "#{pane_current_path}")
 			if workDirOutput, err := workDirCmd.Output(); err == nil {
 				sess.WorkDir = strings.TrimSpace(string(workDirOutput))
 			}
@@ -2007,7 +2335,12 @@ func RunLogMaintenance(maxSizeMB int, maxLines int, removeOrphans bool) {
 
 // DiscoverAllTmuxSessions returns all tmux sessions (including non-Agent Deck ones)
 func DiscoverAllTmuxSessions() ([]*Session, error) {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}:#{pane_current_path}")
+	return DiscoverTmuxSessionsOnHost("")
+}
+
+// DiscoverTmuxSessionsOnHost returns all tmux sessions on a host ("" = local)
+func DiscoverTmuxSessionsOnHost(host string) ([]*Session, error) {
+	cmd := hostTmuxCmd(host, "list-sessions", "-F", "#{session_name}:#{pane_current_path}")
 	output, err := cmd.Output()
 	if err != nil {
 		// No sessions exist
@@ -2033,8 +2366,14 @@ func DiscoverAllTmuxSessions() ([]*Session, error) {
 			workDir = parts[1]
 		}
 
+		// Skip our own control-mode session (see control.go)
+		if sessionName == ControlSessionName {
+			continue
+		}
+
 		// Create session object
 		sess := &Session{
+			Host:        host,
 			Name:        sessionName,
 			DisplayName: sessionName,
 			WorkDir:     workDir,
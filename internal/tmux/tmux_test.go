@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSanitizeName(t *testing.T) {
@@ -30,6 +31,79 @@ func TestSanitizeName(t *testing.T) {
 	}
 }
 
+func TestEnvArgs(t *testing.T) {
+	assert.Empty(t, envArgs(nil))
+	assert.Equal(t,
+		[]string{"-e", "A=1", "-e", "B=two words"},
+		envArgs(map[string]string{"B": "two words", "A": "1"}))
+}
+
+func TestParsePaneState(t *testing.T) {
+	running := parsePaneState("0\t\tclaude\t\n")
+	assert.Equal(t, PaneState{CurrentCommand: "claude"}, running)
+	assert.False(t, running.Exited())
+
+	backAtShell := parsePaneState("0\t\tzsh\t\n")
+	assert.True(t, backAtShell.Exited())
+
+	// A respawned agent runs under `sh -c`: the shell is the agent, not a fallback
+	respawned := parsePaneState("0\t\tzsh\t\"claude --resume abc\"\n")
+	assert.Equal(t, "claude --resume abc", respawned.StartCommand)
+	assert.False(t, respawned.Exited())
+
+	dead := parsePaneState("1\t137\tnode\tclaude --resume abc\n")
+	assert.Equal(t, PaneState{CurrentCommand: "node", StartCommand: "claude --resume abc", Dead: true, DeadStatus: 137}, dead)
+	assert.True(t, dead.Exited())
+
+	killed := parsePaneState("1\t\tnode\tclaude\n")
+	assert.Equal(t, -1, killed.DeadStatus)
+}
+
+func TestParseSessionCache(t *testing.T) {
+	output := "agentdeck_a\t1700000000\t%1\t1\t1\t1699999990\t0\t\tclaude\t\n" +
+		"agentdeck_a\t1700000000\t%2\t1\t0\t1700000000\t0\t\tnpm\tnpm run dev\n" +
+		"agentdeck_a\t1700000500\t%4\t0\t1\t1700000500\t0\t\tzsh\t\n" +
+		"agentdeck_b\t1700000100\t%3\t1\t1\t\t1\t137\tnode\tclaude --resume abc\n"
+	cache := parseSessionCache(output)
+	require.Len(t, cache, 2)
+
+	a := cache["agentdeck_a"]
+	assert.Equal(t, int64(1700000000), a.activity)
+	assert.Equal(t, "%1", a.activePane)
+	assert.Equal(t, PaneState{CurrentCommand: "claude"}, a.panes["%1"])
+	assert.Equal(t, "npm run dev", a.panes["%2"].StartCommand)
+	assert.Equal(t, int64(1699999990), a.paneActivity["%1"])
+
+	b := cache["agentdeck_b"]
+	assert.True(t, b.panes[b.activePane].Exited())
+	assert.Equal(t, 137, b.panes[b.activePane].DeadStatus)
+	// tmux without #{pane_activity}: the window's activity stands in
+	assert.Equal(t, int64(1700000100), b.paneActivity["%3"])
+}
+
+func TestPaneActivityCapturesOnlyWhenTmuxActivityMoves(t *testing.T) {
+	// No such tmux session: any capture fails
+	sess := NewSession("pane-activity-nocapture", t.TempDir())
+	sess.agentPaneHash = "abc"
+	sess.agentPaneActivity = 42
+	sess.agentPaneSeen = 1700000000
+
+	activity, err := sess.paneActivity(1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), activity)
+
+	_, err = sess.paneActivity(1700000001)
+	assert.Error(t, err)
+}
+
+func TestIsShell(t *testing.T) {
+	assert.True(t, IsShell("zsh"))
+	assert.True(t, IsShell("-zsh"))
+	assert.True(t, IsShell("/bin/bash"))
+	assert.False(t, IsShell("claude"))
+	assert.False(t, IsShell("node"))
+}
+
 func TestNewSession(t *testing.T) {
 	sess := NewSession("test-session", "/tmp")
 	if sess.DisplayName != "test-session" {
@@ -1908,3 +1982,20 @@ func TestSession_SendCommand(t *testing.T) {
 		t.Errorf("Expected 'hello' in output, got: %s", content)
 	}
 }
+
+func TestCarryStatusState(t *testing.T) {
+	old := NewSession("carry-status", t.TempDir())
+	old.Acknowledge()
+
+	reloaded := &Session{Name: old.Name}
+	reloaded.CarryStatusState(old)
+	require.NotNil(t, reloaded.stateTracker)
+	assert.True(t, reloaded.stateTracker.acknowledged)
+	assert.Equal(t, "idle", reloaded.lastStableStatus)
+	assert.NotSame(t, old.stateTracker, reloaded.stateTracker)
+
+	// Another tmux session (e.g. restarted under a new name) starts fresh
+	other := &Session{Name: old.Name + "-other"}
+	other.CarryStatusState(old)
+	assert.Nil(t, other.stateTracker)
+}
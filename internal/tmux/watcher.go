@@ -48,7 +48,11 @@ func NewLogWatcher(logDir string, callback func(sessionName string)) (*LogWatche
 
 // Start begins watching for file changes (blocking)
 // Call this in a goroutine
+// While running, sessions with pipe-pane enabled use event-driven status detection
 func (lw *LogWatcher) Start() {
+	activeLogWatchers.Add(1)
+	defer activeLogWatchers.Add(-1)
+
 	for {
 		select {
 		case <-lw.done:
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AccountDialog is the quick switcher for the Claude account of a session or group
+type AccountDialog struct {
+	visible    bool
+	width      int
+	height     int
+	targetID   string   // Session ID or group path
+	targetName string   // Display name
+	isGroup    bool     // true = switching a group's account
+	options    []string // "" (inherit) first, then [claude.accounts] names
+	selected   int
+}
+
+// NewAccountDialog creates a new account switcher
+func NewAccountDialog() *AccountDialog {
+	return &AccountDialog{}
+}
+
+// Show opens the switcher for a session (isGroup false) or group with its
+// current account value ("" = inherited)
+func (d *AccountDialog) Show(targetID, targetName string, isGroup bool, current string) {
+	d.visible = true
+	d.targetID = targetID
+	d.targetName = targetName
+	d.isGroup = isGroup
+	d.options = append([]string{""}, session.GetClaudeAccountNames()...)
+	d.selected = 0
+	for i, name := range d.options {
+		if name == current {
+			d.selected = i
+			break
+		}
+	}
+	// A path set via the CLI that isn't a named account
+	if current != "" && d.options[d.selected] != current {
+		d.options = append(d.options, current)
+		d.selected = len(d.options) - 1
+	}
+}
+
+// Hide hides the dialog
+func (d *AccountDialog) Hide() {
+	d.visible = false
+}
+
+// IsVisible returns whether the dialog is visible
+func (d *AccountDialog) IsVisible() bool {
+	return d.visible
+}
+
+// TargetID returns the session ID or group path being switched
+func (d *AccountDialog) TargetID() string {
+	return d.targetID
+}
+
+// IsGroup returns true if a group's account is being switched
+func (d *AccountDialog) IsGroup() bool {
+	return d.isGroup
+}
+
+// Selected returns the chosen account ("" = inherit)
+func (d *AccountDialog) Selected() string {
+	if d.selected < len(d.options) {
+		return d.options[d.selected]
+	}
+	return ""
+}
+
+// SetSize sets the dialog size
+func (d *AccountDialog) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+}
+
+// Update handles navigation (enter/esc are handled by the parent)
+func (d *AccountDialog) Update(msg tea.KeyMsg) (*AccountDialog, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if d.selected > 0 {
+			d.selected--
+		}
+	case "down", "j":
+		if d.selected < len(d.options)-1 {
+			d.selected++
+		}
+	}
+	return d, nil
+}
+
+// View renders the dialog
+func (d *AccountDialog) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	dirStyle := lipgloss.NewStyle().Foreground(ColorComment)
+	var items []string
+	for i, name := range d.options {
+		label := name
+		dir := session.ResolveClaudeConfigDir(name)
+		if name == "" {
+			label = "inherit"
+			if d.isGroup {
+				dir = "parent group or profile"
+			} else {
+				dir = "group or profile"
+			}
+		}
+		style := lipgloss.NewStyle().Foreground(ColorText).Padding(0, 1)
+		if i == d.selected {
+			style = lipgloss.NewStyle().
+				Foreground(ColorBg).
+				Background(ColorAccent).
+				Bold(true).
+				Padding(0, 1)
+		}
+		items = append(items, style.Render(label)+" "+dirStyle.Render(truncatePath(dir, 30)))
+	}
+
+	dialogWidth := 52
+	if d.width > 0 && d.width < dialogWidth+10 {
+		dialogWidth = d.width - 10
+		if dialogWidth < 30 {
+			dialogWidth = 30
+		}
+	}
+
+	target := lipgloss.NewStyle().Foreground(ColorCyan).Render(d.targetName)
+	hint := lipgloss.NewStyle().Foreground(ColorComment).
+		Render("Enter switch (applies on restart) │ Esc cancel")
+
+	dialogContent := lipgloss.JoinVertical(
+		lipgloss.Center,
+		DialogTitleStyle.Width(dialogWidth-4).Render("Claude Account"),
+		target,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		hint,
+	)
+
+	dialog := DialogBoxStyle.
+		Width(dialogWidth).
+		Render(strings.TrimRight(dialogContent, "\n"))
+
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialog,
+	)
+}
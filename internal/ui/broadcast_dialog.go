@@ -0,0 +1,386 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// BroadcastDialog composes one prompt for several sessions
+type BroadcastDialog struct {
+	visible bool
+	width   int
+	height  int
+	input   textinput.Model
+	targets []*session.Instance
+}
+
+// NewBroadcastDialog creates a new broadcast dialog
+func NewBroadcastDialog() *BroadcastDialog {
+	ti := textinput.New()
+	ti.Placeholder = "Message for every session"
+	ti.CharLimit = 4000
+	ti.Width = 56
+
+	return &BroadcastDialog{input: ti}
+}
+
+// Show opens the dialog for targets
+func (d *BroadcastDialog) Show(targets []*session.Instance) {
+	d.visible = true
+	d.targets = targets
+	d.input.SetValue("")
+	d.input.Focus()
+}
+
+// Hide hides the dialog
+func (d *BroadcastDialog) Hide() {
+	d.visible = false
+	d.input.Blur()
+}
+
+// IsVisible returns whether the dialog is visible
+func (d *BroadcastDialog) IsVisible() bool {
+	return d.visible
+}
+
+// Message returns the entered prompt
+func (d *BroadcastDialog) Message() string {
+	return strings.TrimSpace(d.input.Value())
+}
+
+// Targets returns the sessions the prompt goes to
+func (d *BroadcastDialog) Targets() []*session.Instance {
+	return d.targets
+}
+
+// SetSize sets the dialog size
+func (d *BroadcastDialog) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+}
+
+// Update handles text input (enter/esc are handled by the parent)
+func (d *BroadcastDialog) Update(msg tea.KeyMsg) (*BroadcastDialog, tea.Cmd) {
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return d, cmd
+}
+
+// View renders the dialog
+func (d *BroadcastDialog) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	dialogWidth := 64
+	if d.width > 0 && d.width < dialogWidth+10 {
+		dialogWidth = d.width - 10
+		if dialogWidth < 40 {
+			dialogWidth = 40
+		}
+	}
+	dimStyle := lipgloss.NewStyle().Foreground(ColorComment)
+
+	titles := make([]string, len(d.targets))
+	for i, inst := range d.targets {
+		titles[i] = inst.Title
+	}
+	to := runewidth.Truncate(strings.Join(titles, ", "), dialogWidth-10, "...")
+
+	dialogContent := lipgloss.JoinVertical(
+		lipgloss.Left,
+		DialogTitleStyle.Width(dialogWidth-4).Render(fmt.Sprintf("Broadcast to %d sessions", len(d.targets))),
+		"",
+		dimStyle.Render("To: "+to),
+		"",
+		d.input.View(),
+		"",
+		dimStyle.Render("Each agent gets it once ready │ Enter send │ Esc cancel"),
+	)
+
+	dialog := DialogBoxStyle.
+		Width(dialogWidth).
+		Render(dialogContent)
+
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialog,
+	)
+}
+
+// Delivery states of one broadcast target
+const (
+	deliveryPending = iota // Waiting for the agent to be ready
+	deliverySent
+	deliveryFailed
+	deliverySkipped // Not running
+)
+
+// broadcastTarget is one recipient of a broadcast
+type broadcastTarget struct {
+	inst   *session.Instance
+	state  int
+	err    string
+	sentAt time.Time
+}
+
+// BroadcastPanel shows the last broadcast: which sessions received it and
+// what their agents have been doing since
+type BroadcastPanel struct {
+	visible   bool
+	width     int
+	height    int
+	id        int // Increments per broadcast so late results of an older one are ignored
+	message   string
+	startedAt time.Time
+	targets   []*broadcastTarget
+	selected  int
+
+	// lookup finds a session's current instance (instances are replaced on reload)
+	lookup func(id string) *session.Instance
+}
+
+// NewBroadcastPanel creates a new broadcast results panel
+func NewBroadcastPanel(lookup func(id string) *session.Instance) *BroadcastPanel {
+	return &BroadcastPanel{lookup: lookup}
+}
+
+// Start records a new broadcast of message to targets and shows the panel.
+// Targets that aren't running are marked skipped; the rest are pending.
+// Returns the broadcast's ID for Delivered.
+func (p *BroadcastPanel) Start(message string, targets []*session.Instance, running func(*session.Instance) bool) int {
+	p.id++
+	p.message = message
+	p.startedAt = time.Now()
+	p.selected = 0
+	p.targets = make([]*broadcastTarget, len(targets))
+	for i, inst := range targets {
+		t := &broadcastTarget{inst: inst}
+		if !running(inst) {
+			t.state = deliverySkipped
+			t.err = "not running"
+		}
+		p.targets[i] = t
+	}
+	p.visible = true
+	return p.id
+}
+
+// Pending returns the targets still waiting for delivery
+func (p *BroadcastPanel) Pending() []*session.Instance {
+	var pending []*session.Instance
+	for _, t := range p.targets {
+		if t.state == deliveryPending {
+			pending = append(pending, t.inst)
+		}
+	}
+	return pending
+}
+
+// Delivered records the outcome of sending broadcast id to a session
+func (p *BroadcastPanel) Delivered(id int, sessionID string, err error) {
+	if id != p.id {
+		return
+	}
+	for _, t := range p.targets {
+		if t.inst.ID != sessionID {
+			continue
+		}
+		if err != nil {
+			t.state, t.err = deliveryFailed, err.Error()
+		} else {
+			t.state, t.sentAt = deliverySent, time.Now()
+		}
+	}
+}
+
+// Counts returns how many targets were sent, failed, skipped and are pending
+func (p *BroadcastPanel) Counts() (sent, failed, skipped, pending int) {
+	for _, t := range p.targets {
+		switch t.state {
+		case deliverySent:
+			sent++
+		case deliveryFailed:
+			failed++
+		case deliverySkipped:
+			skipped++
+		default:
+			pending++
+		}
+	}
+	return
+}
+
+// HasResults reports whether a broadcast has been made
+func (p *BroadcastPanel) HasResults() bool {
+	return p.id > 0
+}
+
+// Show shows the panel
+func (p *BroadcastPanel) Show() {
+	p.visible = true
+}
+
+// Hide hides the panel
+func (p *BroadcastPanel) Hide() {
+	p.visible = false
+}
+
+// IsVisible returns whether the panel is visible
+func (p *BroadcastPanel) IsVisible() bool {
+	return p.visible
+}
+
+// Selected returns the selected target's session (nil if there are none)
+func (p *BroadcastPanel) Selected() *session.Instance {
+	if p.selected < len(p.targets) {
+		return p.targets[p.selected].inst
+	}
+	return nil
+}
+
+// SetSize sets the panel size
+func (p *BroadcastPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// Update handles navigation (enter/esc are handled by the parent)
+func (p *BroadcastPanel) Update(msg tea.KeyMsg) (*BroadcastPanel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down", "j":
+		if p.selected < len(p.targets)-1 {
+			p.selected++
+		}
+	}
+	return p, nil
+}
+
+// agentActivity describes a session's status after the broadcast
+func agentActivity(status session.Status) (string, lipgloss.Color) {
+	switch status {
+	case session.StatusRunning:
+		return "● working", ColorGreen
+	case session.StatusWaiting:
+		return "◐ waiting", ColorYellow
+	case session.StatusIdle:
+		return "○ idle", ColorTextDim
+	case session.StatusExited:
+		return "⊘ exited", ColorOrange
+	case session.StatusError:
+		return "✕ error", ColorRed
+	default:
+		return "○ " + string(status), ColorTextDim
+	}
+}
+
+// View renders the panel
+func (p *BroadcastPanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	dialogWidth := 72
+	if p.width > 0 && p.width < dialogWidth+10 {
+		dialogWidth = p.width - 10
+		if dialogWidth < 40 {
+			dialogWidth = 40
+		}
+	}
+	dimStyle := lipgloss.NewStyle().Foreground(ColorComment)
+
+	var items []string
+	for i, t := range p.targets {
+		var delivery string
+		var color lipgloss.Color
+		switch t.state {
+		case deliverySent:
+			delivery, color = "✓ sent "+formatRelativeTime(t.sentAt), ColorGreen
+		case deliveryFailed:
+			delivery, color = "✗ failed", ColorRed
+		case deliverySkipped:
+			delivery, color = "- skipped", ColorComment
+		default:
+			delivery, color = "… waiting for agent", ColorYellow
+		}
+
+		activity := ""
+		activityColor := ColorTextDim
+		if t.state == deliverySent {
+			inst := t.inst
+			if current := p.lookup(inst.ID); current != nil {
+				inst = current
+			}
+			activity, activityColor = agentActivity(inst.Status)
+		}
+
+		titleStyle := lipgloss.NewStyle().Foreground(ColorText)
+		deliveryStyle := lipgloss.NewStyle().Foreground(color)
+		activityStyle := lipgloss.NewStyle().Foreground(activityColor)
+		prefix := "  "
+		if i == p.selected {
+			prefix = lipgloss.NewStyle().Foreground(ColorAccent).Bold(true).Render("▶ ")
+			titleStyle = titleStyle.Bold(true)
+		}
+		title := fmt.Sprintf("%-22s", runewidth.Truncate(t.inst.Title, 22, "..."))
+		line := prefix + titleStyle.Render(title) + " " +
+			deliveryStyle.Render(fmt.Sprintf("%-22s", delivery)) + " " +
+			activityStyle.Render(activity)
+		items = append(items, line)
+		if t.err != "" && t.state == deliveryFailed {
+			items = append(items, lipgloss.NewStyle().Foreground(ColorRed).Render(
+				"    "+runewidth.Truncate(t.err, dialogWidth-10, "...")))
+		}
+	}
+
+	sent, failed, skipped, pending := p.Counts()
+	summary := fmt.Sprintf("%d sent", sent)
+	if pending > 0 {
+		summary += fmt.Sprintf(", %d pending", pending)
+	}
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d failed", failed)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d not running", skipped)
+	}
+
+	message := runewidth.Truncate(strings.ReplaceAll(p.message, "\n", " "), dialogWidth-8, "...")
+	dialogContent := lipgloss.JoinVertical(
+		lipgloss.Left,
+		DialogTitleStyle.Width(dialogWidth-4).Render("Broadcast Results"),
+		"",
+		dimStyle.Render(fmt.Sprintf("%q", message)),
+		dimStyle.Render(fmt.Sprintf("Broadcast %s · %s", formatRelativeTime(p.startedAt), summary)),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		dimStyle.Render("Enter go to session │ B/Esc close"),
+	)
+
+	dialog := DialogBoxStyle.
+		Width(dialogWidth).
+		Render(dialogContent)
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialog,
+	)
+}
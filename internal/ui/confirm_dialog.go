@@ -23,6 +23,7 @@ type ConfirmDialog struct {
 	confirmType ConfirmType
 	targetID    string // Session ID or group path
 	targetName  string // Display name
+	worktree    string // Session's git worktree, offered for deletion with 'w'
 	width       int
 	height      int
 }
@@ -38,6 +39,14 @@ func (c *ConfirmDialog) ShowDeleteSession(sessionID, sessionName string) {
 	c.confirmType = ConfirmDeleteSession
 	c.targetID = sessionID
 	c.targetName = sessionName
+	c.worktree = ""
+}
+
+// ShowDeleteWorktreeSession shows confirmation for deleting a session that runs
+// in a git worktree, with the option to delete the worktree as well
+func (c *ConfirmDialog) ShowDeleteWorktreeSession(sessionID, sessionName, worktreePath string) {
+	c.ShowDeleteSession(sessionID, sessionName)
+	c.worktree = worktreePath
 }
 
 // ShowDeleteGroup shows confirmation for group deletion
@@ -61,6 +70,7 @@ func (c *ConfirmDialog) Hide() {
 	c.visible = false
 	c.targetID = ""
 	c.targetName = ""
+	c.worktree = ""
 }
 
 // IsVisible returns whether the dialog is visible
@@ -73,6 +83,11 @@ func (c *ConfirmDialog) GetTargetID() string {
 	return c.targetID
 }
 
+// HasWorktree returns true if the dialog offers to delete a worktree
+func (c *ConfirmDialog) HasWorktree() bool {
+	return c.worktree != ""
+}
+
 // GetConfirmType returns the type of confirmation
 func (c *ConfirmDialog) GetConfirmType() ConfirmType {
 	return c.confirmType
@@ -104,6 +119,9 @@ func (c *ConfirmDialog) View() string {
 		title = "⚠️  Delete Session?"
 		warning = fmt.Sprintf("This will PERMANENTLY KILL the tmux session:\n\n  \"%s\"", c.targetName)
 		details = "• The tmux session will be terminated\n• Any running processes will be killed\n• Terminal history will be lost\n• This cannot be undone"
+		if c.worktree != "" {
+			details += fmt.Sprintf("\n\nGit worktree: %s\n• y keeps it, w deletes it too (branch is kept)\n• w is refused if it has unmerged changes", c.worktree)
+		}
 
 	case ConfirmDeleteGroup:
 		title = "⚠️  Delete Group?"
@@ -148,6 +166,17 @@ func (c *ConfirmDialog) View() string {
 		Bold(true).
 		Render("n Cancel")
 
+	buttons := []string{buttonYes, "  "}
+	if c.confirmType == ConfirmDeleteSession && c.worktree != "" {
+		buttonWorktree := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorRed).
+			Padding(0, 2).
+			Bold(true).
+			Render("w + Worktree")
+		buttons = append(buttons, buttonWorktree, "  ")
+	}
+
 	escHint := lipgloss.NewStyle().
 		Foreground(ColorTextDim).
 		Render("(Esc to cancel)")
@@ -158,7 +187,7 @@ func (c *ConfirmDialog) View() string {
 		warningStyle.Render(warning),
 		detailsStyle.Render(details),
 		"",
-		lipgloss.JoinHorizontal(lipgloss.Center, buttonYes, "  ", buttonNo, "  ", escHint),
+		lipgloss.JoinHorizontal(lipgloss.Center, append(buttons, buttonNo, "  ", escHint)...),
 	)
 
 	// Dialog box
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -11,6 +13,8 @@ type ForkDialog struct {
 	visible     bool
 	nameInput   textinput.Model
 	groupInput  textinput.Model
+	branchInput textinput.Model // Non-empty = fork into a new git worktree on this branch
+	carry       bool            // Copy uncommitted changes into the new worktree
 	focusIndex  int
 	width       int
 	height      int
@@ -29,9 +33,15 @@ func NewForkDialog() *ForkDialog {
 	groupInput.CharLimit = 64
 	groupInput.Width = 40
 
+	branchInput := textinput.New()
+	branchInput.Placeholder = "Worktree branch (optional)"
+	branchInput.CharLimit = 100
+	branchInput.Width = 40
+
 	return &ForkDialog{
-		nameInput:  nameInput,
-		groupInput: groupInput,
+		nameInput:   nameInput,
+		groupInput:  groupInput,
+		branchInput: branchInput,
 	}
 }
 
@@ -41,8 +51,10 @@ func (d *ForkDialog) Show(originalName, projectPath, groupPath string) {
 	d.projectPath = projectPath
 	d.nameInput.SetValue(originalName + " (fork)")
 	d.groupInput.SetValue(groupPath)
+	d.branchInput.SetValue("")
+	d.carry = false
 	d.focusIndex = 0
-	d.nameInput.Focus()
+	d.updateFocus()
 }
 
 // Hide hides the dialog
@@ -50,6 +62,7 @@ func (d *ForkDialog) Hide() {
 	d.visible = false
 	d.nameInput.Blur()
 	d.groupInput.Blur()
+	d.branchInput.Blur()
 }
 
 // IsVisible returns whether the dialog is visible
@@ -62,6 +75,16 @@ func (d *ForkDialog) GetValues() (name, group string) {
 	return d.nameInput.Value(), d.groupInput.Value()
 }
 
+// GetWorktreeBranch returns the branch to fork into a new worktree ("" = same directory)
+func (d *ForkDialog) GetWorktreeBranch() string {
+	return strings.TrimSpace(d.branchInput.Value())
+}
+
+// CarryChanges returns whether uncommitted changes should be copied into the worktree
+func (d *ForkDialog) CarryChanges() bool {
+	return d.carry
+}
+
 // SetSize sets the dialog dimensions
 func (d *ForkDialog) SetSize(width, height int) {
 	d.width = width
@@ -78,14 +101,19 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "tab", "down":
-			d.focusIndex = (d.focusIndex + 1) % 2
+			d.focusIndex = (d.focusIndex + 1) % 4
 			d.updateFocus()
 		case "shift+tab", "up":
 			d.focusIndex = (d.focusIndex - 1)
 			if d.focusIndex < 0 {
-				d.focusIndex = 1
+				d.focusIndex = 3
 			}
 			d.updateFocus()
+		case " ", "left", "right":
+			if d.focusIndex == 3 {
+				d.carry = !d.carry
+				return d, nil
+			}
 		case "esc":
 			d.Hide()
 			return d, nil
@@ -97,22 +125,29 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 	}
 
 	var cmd tea.Cmd
-	if d.focusIndex == 0 {
+	switch d.focusIndex {
+	case 0:
 		d.nameInput, cmd = d.nameInput.Update(msg)
-	} else {
+	case 1:
 		d.groupInput, cmd = d.groupInput.Update(msg)
+	case 2:
+		d.branchInput, cmd = d.branchInput.Update(msg)
 	}
 
 	return d, cmd
 }
 
 func (d *ForkDialog) updateFocus() {
-	if d.focusIndex == 0 {
+	d.nameInput.Blur()
+	d.groupInput.Blur()
+	d.branchInput.Blur()
+	switch d.focusIndex {
+	case 0:
 		d.nameInput.Focus()
-		d.groupInput.Blur()
-	} else {
-		d.nameInput.Blur()
+	case 1:
 		d.groupInput.Focus()
+	case 2:
+		d.branchInput.Focus()
 	}
 }
 
@@ -149,20 +184,29 @@ func (d *ForkDialog) View() string {
 		Width(dialogWidth)
 
 	// Build content
-	var nameLabel, groupLabel string
-	if d.focusIndex == 0 {
-		nameLabel = activeLabelStyle.Render("▶ Name:")
-		groupLabel = labelStyle.Render("  Group:")
-	} else {
-		nameLabel = labelStyle.Render("  Name:")
-		groupLabel = activeLabelStyle.Render("▶ Group:")
+	label := func(idx int, text string) string {
+		if d.focusIndex == idx {
+			return activeLabelStyle.Render("▶ " + text)
+		}
+		return labelStyle.Render("  " + text)
+	}
+	nameLabel := label(0, "Name:")
+	groupLabel := label(1, "Group:")
+	branchLabel := label(2, "New worktree branch:")
+	checkbox := "[ ]"
+	if d.carry {
+		checkbox = "[x]"
 	}
+	carryLabel := label(3, checkbox+" Carry uncommitted changes")
 
 	content := titleStyle.Render("Fork Session") + "\n\n" +
 		nameLabel + "\n" +
 		d.nameInput.View() + "\n\n" +
 		groupLabel + "\n" +
 		d.groupInput.View() + "\n\n" +
+		branchLabel + "\n" +
+		d.branchInput.View() + "\n\n" +
+		carryLabel + "\n\n" +
 		lipgloss.NewStyle().Foreground(ColorComment).
 			Render("Enter create │ Esc cancel │ Tab next")
 
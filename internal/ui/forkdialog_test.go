@@ -2,6 +2,8 @@ package ui
 
 import (
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestNewForkDialog(t *testing.T) {
@@ -87,3 +89,25 @@ func TestForkDialog_EmptyProjectPath(t *testing.T) {
 		t.Errorf("Group = %s, want ''", group)
 	}
 }
+
+func TestForkDialog_WorktreeBranch(t *testing.T) {
+	d := NewForkDialog()
+	d.Show("Test", "/path", "group")
+	if d.GetWorktreeBranch() != "" {
+		t.Errorf("worktree branch should start empty, got %q", d.GetWorktreeBranch())
+	}
+
+	// Tab twice to reach the branch field
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("try-b")})
+	if got := d.GetWorktreeBranch(); got != "try-b" {
+		t.Errorf("GetWorktreeBranch() = %q, want try-b", got)
+	}
+
+	// Re-showing clears the previous branch
+	d.Show("Test", "/path", "group")
+	if d.GetWorktreeBranch() != "" {
+		t.Error("Show() should reset the worktree branch")
+	}
+}
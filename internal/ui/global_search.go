@@ -43,6 +43,7 @@ type GlobalSearchResult struct {
 	Snippet     string
 	Content     string    // Full conversation content for preview
 	CWD         string
+	ConfigDir   string    // Claude config dir (account) the conversation belongs to
 	ModTime     time.Time // Last modified time
 	Score       int       // Fuzzy match score (higher = better match)
 	MatchCount  int       // Number of query matches in content
@@ -262,6 +263,7 @@ func (gs *GlobalSearch) updateResults() {
 			Snippet:    sr.Snippet,
 			Content:    sr.Entry.Content, // Full content for preview
 			CWD:        sr.Entry.CWD,
+			ConfigDir:  sr.Entry.ConfigDir(),
 			ModTime:    sr.Entry.ModTime,
 			Score:      sr.Score,
 			MatchCount: matchCount,
@@ -84,6 +84,7 @@ func (h *HelpOverlay) View() string {
 				{"d", "Delete session"},
 				{"m", "Move to group"},
 				{"Shift+M", "MCP Manager (Claude)"},
+				{"Shift+C", "Claude account (session/group)"},
 				{"u", "Mark unread"},
 				{"K / J", "Reorder up/down"},
 				{"f", "Quick fork (Claude only)"},
@@ -98,6 +99,15 @@ func (h *HelpOverlay) View() string {
 				{"Tab", "Toggle expand"},
 			},
 		},
+		{
+			title: "BROADCAST",
+			items: [][2]string{
+				{"Space", "Mark session/group"},
+				{"b", "Send to marked"},
+				{"Shift+B", "Broadcast results"},
+				{"Esc", "Clear marks"},
+			},
+		},
 		{
 			title: "SEARCH & FILTER",
 			items: [][2]string{
@@ -122,6 +132,7 @@ func (h *HelpOverlay) View() string {
 		{
 			title: "OTHER",
 			items: [][2]string{
+				{"Shift+S", "Scheduled prompts"},
 				{"Ctrl+R", "Reload from disk"},
 				{"i", "Import tmux sessions"},
 				{"Ctrl+Q", "Detach from session"},
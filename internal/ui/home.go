@@ -18,8 +18,11 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mattn/go-runewidth"
 
+	"github.com/asheshgoplani/agent-deck/internal/daemon"
 	"github.com/asheshgoplani/agent-deck/internal/database"
 	"github.com/asheshgoplani/agent-deck/internal/ledger"
+	"github.com/asheshgoplani/agent-deck/internal/mailbox"
+	"github.com/asheshgoplani/agent-deck/internal/scheduler"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/asheshgoplani/agent-deck/internal/tmux"
 	"github.com/asheshgoplani/agent-deck/internal/update"
@@ -111,6 +114,10 @@ type Home struct {
 	confirmDialog *ConfirmDialog // For confirming destructive actions
 	helpOverlay    *HelpOverlay    // For showing keyboard shortcuts
 	mcpDialog      *MCPDialog      // For managing MCPs
+	accountDialog  *AccountDialog  // For switching Claude accounts
+	scheduleDialog *ScheduleDialog // For listing scheduled prompts
+	broadcastDialog *BroadcastDialog // For sending one prompt to marked sessions
+	broadcastPanel  *BroadcastPanel  // For tracking the last broadcast
 	decisionDialog *DecisionDialog // For logging decisions (Ctrl+D)
 	decisionPanel  *DecisionListPanel  // For viewing decisions list
 
@@ -144,11 +151,16 @@ type Home struct {
 	statusTrigger    chan statusUpdateRequest // Triggers background status update
 	statusWorkerDone chan struct{}            // Signals worker has stopped
 
+	// Exited sessions the status worker found due for an automatic restart;
+	// the tick handler restarts them off the worker (see autoRestartSession)
+	dueRestartsMu sync.Mutex
+	dueRestarts   []*session.Instance
+
 	// Event-driven status detection (Priority 2)
 	logWatcher *tmux.LogWatcher
 
 	// File watcher for external changes (auto-reload)
-	storageWatcher *StorageWatcher
+	storageWatcher *session.StorageWatcher
 
 	// Storage warning (shown if storage initialization failed)
 	storageWarning string
@@ -171,6 +183,27 @@ type Home struct {
 	lastLogMaintenance time.Time
 	lastLogCheck       time.Time // Fast 10-second check for oversized logs
 
+	// Sessions marked (Space) for a broadcast, by ID
+	marked map[string]bool
+
+	// daemon is set when `agent-deck daemon` runs for the profile: it owns
+	// status tracking, automatic restarts, scheduled prompts, message delivery
+	// and log maintenance, and this TUI (one of possibly several) is its
+	// client, taking statuses from it and refreshing on its events
+	daemon       *daemon.Client
+	daemonEvents chan daemon.Event
+
+	// Scheduled prompts (nil store if the profile dir is unavailable)
+	scheduleStore     *scheduler.Store
+	lastScheduleCheck time.Time
+	schedulesRunning  bool // A RunDue pass is in flight (deliveries can take a minute)
+
+	// Messages between sessions (nil store if the profile dir is unavailable)
+	mailboxStore     *mailbox.Store
+	lastMailboxCheck time.Time
+	mailboxRunning   bool
+	unread           map[string]int // Unread messages by session ID
+
 	// Cached status counts (invalidated on instance changes)
 	cachedStatusCounts struct {
 		running, waiting, idle, errored int
@@ -219,6 +252,19 @@ type sessionCreatedMsg struct {
 	err      error
 }
 
+// broadcastSentMsg reports delivery of a broadcast to one session
+type broadcastSentMsg struct {
+	broadcastID int
+	sessionID   string
+	err         error
+}
+
+// initialMessageSentMsg reports delivery of a template's first prompt
+type initialMessageSentMsg struct {
+	title string
+	err   error
+}
+
 type sessionForkedMsg struct {
 	instance *session.Instance
 	sourceID string // ID of the source session that was forked (for cleanup)
@@ -287,6 +333,9 @@ func NewHomeWithProfile(profile string) *Home {
 		confirmDialog:     NewConfirmDialog(),
 		helpOverlay:       NewHelpOverlay(),
 		mcpDialog:         NewMCPDialog(),
+		accountDialog:     NewAccountDialog(),
+		scheduleDialog:    NewScheduleDialog(),
+		broadcastDialog:   NewBroadcastDialog(),
 		decisionDialog:    NewDecisionDialog(),
 		decisionPanel:     NewDecisionListPanel(),
 		viewMode:          ViewModeSessions,
@@ -296,6 +345,7 @@ func NewHomeWithProfile(profile string) *Home {
 		cancel:            cancel,
 		instances:         []*session.Instance{},
 		instanceByID:      make(map[string]*session.Instance),
+		marked:            make(map[string]bool),
 		groupTree:         session.NewGroupTree([]*session.Instance{}),
 		flatItems:         []session.Item{},
 		previewCache:       make(map[string]string),
@@ -308,19 +358,43 @@ func NewHomeWithProfile(profile string) *Home {
 		statusWorkerDone:  make(chan struct{}),
 	}
 
+	if daemon.Running(actualProfile) {
+		if client, err := daemon.Connect(actualProfile); err == nil {
+			h.daemon = client
+			h.daemonEvents = make(chan daemon.Event, 64)
+		}
+	}
+
+	h.broadcastPanel = NewBroadcastPanel(h.getInstanceByID)
+
+	if store, err := scheduler.NewStore(actualProfile); err == nil {
+		h.scheduleStore = store
+	} else {
+		log.Printf("Warning: scheduled prompts disabled: %v", err)
+	}
+	if store, err := mailbox.NewStore(actualProfile); err == nil {
+		h.mailboxStore = store
+	} else {
+		log.Printf("Warning: session messages disabled: %v", err)
+	}
+
+	// Route tmux commands through one persistent control-mode connection
+	// instead of a subprocess per command ([tmux] control_mode = false opts out)
+	if session.GetTmuxSettings().UseControlMode() {
+		if err := tmux.EnableControlMode(); err != nil {
+			log.Printf("Warning: tmux control mode unavailable: %v (using exec mode)", err)
+		}
+	}
+
 	// Initialize event-driven log watcher
+	// Log writes feed each session's status state machine directly (no tmux calls);
+	// the status worker picks up the new state on its next pass
 	logWatcher, err := tmux.NewLogWatcher(tmux.LogDir(), func(sessionName string) {
 		// Find session by tmux name and signal file activity
 		h.instancesMu.RLock()
 		for _, inst := range h.instances {
-			if inst.GetTmuxSession() != nil && inst.GetTmuxSession().Name == sessionName {
-				// Signal file activity (triggers GREEN) then update status
-				go func(i *session.Instance) {
-					if tmuxSess := i.GetTmuxSession(); tmuxSess != nil {
-						tmuxSess.SignalFileActivity() // Directly triggers GREEN
-					}
-					_ = i.UpdateStatus()
-				}(inst)
+			if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil && tmuxSess.Name == sessionName {
+				tmuxSess.SignalFileActivity()
 				break
 			}
 		}
@@ -338,10 +412,11 @@ func NewHomeWithProfile(profile string) *Home {
 
 	// Initialize global search
 	h.globalSearch = NewGlobalSearch()
-	claudeDir := session.GetClaudeConfigDir()
+	// Index every configured Claude account, not just the default one
+	claudeDirs := session.GetClaudeConfigDirs()
 	userConfig, _ := session.LoadUserConfig()
 	if userConfig != nil && userConfig.GlobalSearch.Enabled {
-		globalSearchIndex, err := session.NewGlobalSearchIndex(claudeDir, userConfig.GlobalSearch)
+		globalSearchIndex, err := session.NewGlobalSearchIndex(claudeDirs, userConfig.GlobalSearch)
 		if err != nil {
 			log.Printf("Warning: failed to initialize global search: %v", err)
 		} else {
@@ -362,7 +437,7 @@ func NewHomeWithProfile(profile string) *Home {
 		if err != nil {
 			log.Printf("Warning: failed to get storage path for watcher: %v", err)
 		} else {
-			watcher, err := NewStorageWatcher(storagePath)
+			watcher, err := session.NewStorageWatcher(storagePath)
 			if err != nil {
 				// Log warning but continue (fallback to manual refresh with Ctrl+R)
 				log.Printf("Warning: failed to initialize storage watcher: %v", err)
@@ -378,10 +453,12 @@ func NewHomeWithProfile(profile string) *Home {
 	// Also initializes lastLogMaintenance and lastLogCheck so periodic checks start from now
 	h.lastLogMaintenance = time.Now()
 	h.lastLogCheck = time.Now()
-	go func() {
-		logSettings := session.GetLogSettings()
-		tmux.RunLogMaintenance(logSettings.MaxSizeMB, logSettings.MaxLines, logSettings.RemoveOrphans)
-	}()
+	if h.daemon == nil {
+		go func() {
+			logSettings := session.GetLogSettings()
+			tmux.RunLogMaintenance(logSettings.MaxSizeMB, logSettings.MaxLines, logSettings.RemoveOrphans)
+		}()
+	}
 
 	return h
 }
@@ -661,6 +738,12 @@ func (h *Home) Init() tea.Cmd {
 		cmds = append(cmds, listenForReloads(h.storageWatcher))
 	}
 
+	// Follow the daemon's status events
+	if h.daemon != nil {
+		go h.subscribeDaemon()
+		cmds = append(cmds, listenForDaemonEvents(h.daemonEvents))
+	}
+
 	return tea.Batch(cmds...)
 }
 
@@ -674,7 +757,7 @@ func (h *Home) checkForUpdate() tea.Cmd {
 }
 
 // listenForReloads waits for storage change notification
-func listenForReloads(sw *StorageWatcher) tea.Cmd {
+func listenForReloads(sw *session.StorageWatcher) tea.Cmd {
 	return func() tea.Msg {
 		if sw == nil {
 			return nil
@@ -684,6 +767,35 @@ func listenForReloads(sw *StorageWatcher) tea.Cmd {
 	}
 }
 
+// daemonEventMsg is an event from the daemon's /v1/events stream
+type daemonEventMsg struct {
+	event daemon.Event
+}
+
+// subscribeDaemon forwards the daemon's events to daemonEvents until the TUI
+// quits, reconnecting while the daemon runs
+func (h *Home) subscribeDaemon() {
+	for h.ctx.Err() == nil {
+		_ = h.daemon.Subscribe(h.ctx, func(ev daemon.Event) {
+			select {
+			case h.daemonEvents <- ev:
+			default: // The status worker catches up on the next tick
+			}
+		})
+		select {
+		case <-h.ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// listenForDaemonEvents waits for the next daemon event
+func listenForDaemonEvents(ch <-chan daemon.Event) tea.Cmd {
+	return func() tea.Msg {
+		return daemonEventMsg{event: <-ch}
+	}
+}
+
 // loadSessions loads sessions from storage and initializes the pool
 func (h *Home) loadSessions() tea.Msg {
 	if h.storage == nil {
@@ -971,6 +1083,13 @@ func (h *Home) processStatusUpdate(req statusUpdateRequest) {
 	copy(instancesCopy, h.instances)
 	h.instancesMu.RUnlock()
 
+	// With a daemon, statuses (and restarts) are its job; fall back to
+	// tracking them here if it stopped answering
+	if h.daemon != nil && h.daemon.ApplyStatuses(instancesCopy) == nil {
+		h.cachedStatusCounts.valid = false
+		return
+	}
+
 	// Build set of visible session IDs for quick lookup
 	visibleIDs := make(map[string]bool)
 
@@ -1010,6 +1129,16 @@ func (h *Home) processStatusUpdate(req statusUpdateRequest) {
 		h.statusUpdateIndex.Store(int32((idx + 1) % instanceCount))
 	}
 
+	// Queue exited agents whose restart policy allows a restart (after the
+	// backoff), unless a daemon owns restarts
+	for _, inst := range instancesCopy {
+		if h.daemon == nil && inst.DueRestart() {
+			h.dueRestartsMu.Lock()
+			h.dueRestarts = append(h.dueRestarts, inst)
+			h.dueRestartsMu.Unlock()
+		}
+	}
+
 	// Invalidate status counts cache (statuses may have changed)
 	h.cachedStatusCounts.valid = false
 }
@@ -1170,10 +1299,32 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			h.saveInstances()
 
 			// Start fetching preview for the new session
+			// (and deliver the template's first prompt once the agent is ready)
+			if msg.instance.InitialMessage != "" {
+				return h, tea.Batch(h.fetchPreview(msg.instance), sendInitialMessage(msg.instance))
+			}
 			return h, h.fetchPreview(msg.instance)
 		}
 		return h, nil
 
+	case broadcastSentMsg:
+		h.broadcastPanel.Delivered(msg.broadcastID, msg.sessionID, msg.err)
+		h.invalidatePreviewCache(msg.sessionID)
+		if sent, failed, _, pending := h.broadcastPanel.Counts(); pending == 0 && !h.broadcastPanel.IsVisible() {
+			if failed > 0 {
+				h.setError(fmt.Errorf("broadcast sent to %d sessions, %d failed (B for details)", sent, failed))
+			} else {
+				h.setSuccess(fmt.Sprintf("Broadcast sent to %d sessions", sent))
+			}
+		}
+		return h, nil
+
+	case initialMessageSentMsg:
+		if msg.err != nil {
+			h.setError(fmt.Errorf("initial message for %s: %w", msg.title, msg.err))
+		}
+		return h, nil
+
 	case sessionForkedMsg:
 		// Clean up forking state for source session
 		if msg.sourceID != "" {
@@ -1240,6 +1391,9 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.killErr != nil {
 			h.setError(fmt.Errorf("warning: tmux session may still be running: %w", msg.killErr))
 		}
+		if msg.worktreeErr != nil {
+			h.setError(fmt.Errorf("warning: worktree not deleted: %w", msg.worktreeErr))
+		}
 
 		// Find and remove from list
 		var deletedInstance *session.Instance
@@ -1253,6 +1407,7 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		delete(h.instanceByID, msg.deletedID)
 		h.instancesMu.Unlock()
+		delete(h.marked, msg.deletedID)
 		// Invalidate status counts cache
 		h.cachedStatusCounts.valid = false
 		// Invalidate preview cache for deleted session
@@ -1332,6 +1487,10 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Continue listening for next change
 		return h, tea.Batch(cmd, listenForReloads(h.storageWatcher))
 
+	case daemonEventMsg:
+		h.triggerStatusUpdate()
+		return h, listenForDaemonEvents(h.daemonEvents)
+
 	case statusUpdateMsg:
 		// Clear attach flag - we've returned from the attached session
 		h.isAttaching = false
@@ -1416,6 +1575,42 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		h.previewCacheMu.Unlock()
 		return h, nil
 
+	case schedulesRanMsg:
+		h.schedulesRunning = false
+		if msg.err != nil {
+			h.setError(fmt.Errorf("scheduled prompts: %w", msg.err))
+			return h, nil
+		}
+		started := false
+		for _, r := range msg.results {
+			if r.Started {
+				started = true
+				h.invalidatePreviewCache(r.Job.SessionID)
+			}
+			if r.Err != nil {
+				h.setError(fmt.Errorf("scheduled prompt for %s failed: %w", r.Title, r.Err))
+			} else {
+				h.setSuccess(fmt.Sprintf("Sent scheduled prompt to '%s'", r.Title))
+			}
+		}
+		if started {
+			h.saveInstances()
+		}
+		h.refreshScheduleDialog()
+		return h, nil
+
+	case mailboxCheckedMsg:
+		h.mailboxRunning = false
+		if msg.err != nil {
+			log.Printf("[MAILBOX] %v", msg.err)
+			return h, nil
+		}
+		h.unread = msg.unread
+		if msg.delivered > 0 {
+			h.setSuccess(fmt.Sprintf("Delivered %d message(s) between sessions", msg.delivered))
+		}
+		return h, nil
+
 	case tickMsg:
 		// Auto-dismiss errors after 5 seconds
 		if h.err != nil && !h.errTime.IsZero() && time.Since(h.errTime) > 5*time.Second {
@@ -1436,7 +1631,7 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Fast log size check every 10 seconds (catches runaway logs before they cause issues)
 		// This is much faster than full maintenance - just checks file sizes
-		if time.Since(h.lastLogCheck) >= logCheckInterval {
+		if h.daemon == nil && time.Since(h.lastLogCheck) >= logCheckInterval {
 			h.lastLogCheck = time.Now()
 			go func() {
 				logSettings := session.GetLogSettings()
@@ -1446,7 +1641,7 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Full log maintenance (orphan cleanup, etc) every 5 minutes
-		if time.Since(h.lastLogMaintenance) >= logMaintenanceInterval {
+		if h.daemon == nil && time.Since(h.lastLogMaintenance) >= logMaintenanceInterval {
 			h.lastLogMaintenance = time.Now()
 			go func() {
 				logSettings := session.GetLogSettings()
@@ -1485,7 +1680,31 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			h.previewCacheMu.Unlock()
 		}
-		return h, tea.Batch(h.tick(), previewCmd)
+		// Deliver due scheduled prompts
+		var scheduleCmd tea.Cmd
+		if h.scheduleStore != nil && h.daemon == nil && !h.schedulesRunning && time.Since(h.lastScheduleCheck) >= scheduler.CheckInterval {
+			h.lastScheduleCheck = time.Now()
+			h.schedulesRunning = true
+			scheduleCmd = h.runSchedules()
+		}
+		// Deliver messages between sessions and refresh unread counts
+		var mailboxCmd tea.Cmd
+		if h.mailboxStore != nil && !h.mailboxRunning && time.Since(h.lastMailboxCheck) >= mailbox.CheckInterval {
+			h.lastMailboxCheck = time.Now()
+			h.mailboxRunning = true
+			mailboxCmd = h.checkMailbox()
+		}
+		// Restart the sessions the status worker found due
+		h.dueRestartsMu.Lock()
+		due := h.dueRestarts
+		h.dueRestarts = nil
+		h.dueRestartsMu.Unlock()
+		restartCmds := make([]tea.Cmd, 0, len(due))
+		for _, inst := range due {
+			h.resumingSessions[inst.ID] = time.Now()
+			restartCmds = append(restartCmds, h.autoRestartSession(inst))
+		}
+		return h, tea.Batch(append(restartCmds, h.tick(), previewCmd, scheduleCmd, mailboxCmd)...)
 
 	case tea.KeyMsg:
 		// Handle overlays first
@@ -1515,6 +1734,18 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if h.mcpDialog.IsVisible() {
 			return h.handleMCPDialogKey(msg)
 		}
+		if h.accountDialog.IsVisible() {
+			return h.handleAccountDialogKey(msg)
+		}
+		if h.scheduleDialog.IsVisible() {
+			return h.handleScheduleDialogKey(msg)
+		}
+		if h.broadcastDialog.IsVisible() {
+			return h.handleBroadcastDialogKey(msg)
+		}
+		if h.broadcastPanel.IsVisible() {
+			return h.handleBroadcastPanelKey(msg)
+		}
 		if h.decisionDialog.IsVisible() {
 			return h.handleDecisionDialogKey(msg)
 		}
@@ -1658,6 +1889,11 @@ func (h *Home) createSessionFromGlobalSearch(result *GlobalSearchResult) tea.Cmd
 			dangerousMode = userConfig.Claude.DangerousMode
 			configDir = userConfig.Claude.ConfigDir
 		}
+		// Resume under the account the conversation was found in
+		if result.ConfigDir != "" && result.ConfigDir != session.GetClaudeConfigDir() {
+			inst.ClaudeConfigDir = result.ConfigDir
+			configDir = result.ConfigDir
+		}
 
 		// Build command - use CLAUDE_CONFIG_DIR env var (not CLI flag)
 		var cmdBuilder strings.Builder
@@ -1686,6 +1922,19 @@ func (h *Home) createSessionFromGlobalSearch(result *GlobalSearchResult) tea.Cmd
 	}
 }
 
+// findSessionByTitleOrID returns the top-level session with this exact title
+// or ID (nil if none). Used for template parents; sub-sessions can't nest.
+func (h *Home) findSessionByTitleOrID(ref string) *session.Instance {
+	h.instancesMu.RLock()
+	defer h.instancesMu.RUnlock()
+	for _, inst := range h.instances {
+		if (inst.Title == ref || inst.ID == ref) && !inst.IsSubSession() {
+			return inst
+		}
+	}
+	return nil
+}
+
 // getCurrentGroupPath returns the group path of the currently selected item
 func (h *Home) getCurrentGroupPath() string {
 	if h.cursor >= 0 && h.cursor < len(h.flatItems) {
@@ -1713,9 +1962,29 @@ func (h *Home) handleNewDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Create session (enter works from any field)
 		name, path, command := h.newDialog.GetValues()
 		groupPath := h.newDialog.GetSelectedGroup()
+		worktreeBranch := h.newDialog.GetWorktreeBranch()
+		env, _ := h.newDialog.GetEnv() // checked by Validate
+
+		// Template extras the dialog has no fields for
+		var tmpl *session.TemplateDef
+		parentID := ""
+		if tmplName := h.newDialog.GetTemplate(); tmplName != "" {
+			if def, ok := session.GetTemplate(tmplName); ok {
+				tmpl = &def
+				if def.Parent != "" {
+					parent := h.findSessionByTitleOrID(def.Parent)
+					if parent == nil {
+						h.setError(fmt.Errorf("template %s: parent session '%s' not found", tmplName, def.Parent))
+						return h, nil
+					}
+					parentID, groupPath = parent.ID, parent.GroupPath
+				}
+			}
+		}
+
 		h.newDialog.Hide()
 		h.clearError() // Clear any previous validation error
-		return h, h.createSessionInGroup(name, path, command, groupPath)
+		return h, h.createSessionInGroup(name, path, command, groupPath, worktreeBranch, env, tmpl, parentID)
 
 	case "esc":
 		h.newDialog.Hide()
@@ -1738,6 +2007,8 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if h.logWatcher != nil {
 			h.logWatcher.Close()
 		}
+		// Close tmux control-mode connection (no-op in exec mode)
+		tmux.DisableControlMode()
 		// Close storage watcher
 		if h.storageWatcher != nil {
 			h.storageWatcher.Close()
@@ -1925,19 +2196,92 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case "M", "shift+m":
-		// MCP Manager - for Claude and Gemini sessions
+		// MCP Manager - for Claude, Gemini and custom tools with a JSON MCP config
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil &&
-				(item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+				session.CanWriteToolMCPs(item.Session.Tool) {
 				h.mcpDialog.SetSize(h.width, h.height)
-				if err := h.mcpDialog.Show(item.Session.ProjectPath, item.Session.ID, item.Session.Tool); err != nil {
+				if err := h.mcpDialog.Show(item.Session.ProjectPath, item.Session.ID, item.Session.Tool, item.Session.GetClaudeConfigDir()); err != nil {
 					h.setError(err)
 				}
 			}
 		}
 		return h, nil
 
+	case "C", "shift+c":
+		// Claude account switcher - for Claude sessions and groups
+		if h.cursor < len(h.flatItems) {
+			item := h.flatItems[h.cursor]
+			isGroup := item.Type == session.ItemTypeGroup && item.Group != nil
+			isClaude := item.Type == session.ItemTypeSession && item.Session != nil && item.Session.Tool == "claude"
+			if !isGroup && !isClaude {
+				return h, nil
+			}
+			if len(session.GetClaudeAccountNames()) == 0 {
+				h.setError(fmt.Errorf("no Claude accounts configured: add [claude.accounts] to config.toml"))
+				return h, nil
+			}
+			h.accountDialog.SetSize(h.width, h.height)
+			if isGroup {
+				h.accountDialog.Show(item.Path, item.Group.Name, true, item.Group.ClaudeConfigDir)
+			} else {
+				h.accountDialog.Show(item.Session.ID, item.Session.Title, false, item.Session.ClaudeConfigDir)
+			}
+		}
+		return h, nil
+
+	case "S", "shift+s":
+		// Scheduled prompts list
+		if h.scheduleStore == nil {
+			h.setError(fmt.Errorf("scheduled prompts unavailable"))
+			return h, nil
+		}
+		jobs, err := h.scheduleStore.List()
+		if err != nil {
+			h.setError(err)
+			return h, nil
+		}
+		h.scheduleDialog.SetSize(h.width, h.height)
+		h.scheduleDialog.Show(jobs, h.sessionTitles())
+		return h, nil
+
+	case " ":
+		// Mark the session (or every session in the group) for a broadcast
+		if h.viewMode == ViewModeSessions && h.cursor < len(h.flatItems) {
+			h.toggleMark(h.flatItems[h.cursor])
+		}
+		return h, nil
+
+	case "b":
+		// Broadcast a prompt to the marked sessions (or the selected group)
+		if h.viewMode != ViewModeSessions {
+			return h, nil
+		}
+		targets := h.broadcastTargets()
+		if len(targets) == 0 {
+			h.setError(fmt.Errorf("mark sessions with Space (or select a group) to broadcast"))
+			return h, nil
+		}
+		h.broadcastDialog.SetSize(h.width, h.height)
+		h.broadcastDialog.Show(targets)
+		return h, nil
+
+	case "B", "shift+b":
+		// Results of the last broadcast
+		if h.broadcastPanel.HasResults() {
+			h.broadcastPanel.SetSize(h.width, h.height)
+			h.broadcastPanel.Show()
+		}
+		return h, nil
+
+	case "esc":
+		// Clear broadcast marks
+		if len(h.marked) > 0 {
+			h.marked = make(map[string]bool)
+		}
+		return h, nil
+
 	case "g":
 		// Create new group (or subgroup if a group is selected)
 		if h.cursor < len(h.flatItems) {
@@ -2048,6 +2392,7 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		// Apply user's preferred default tool from config
 		h.newDialog.SetDefaultTool(session.GetDefaultTool())
+		h.newDialog.SetTemplates(session.GetTemplateNames())
 
 		// Auto-select parent group from current cursor position
 		groupPath := session.DefaultGroupName
@@ -2080,7 +2425,11 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil {
-				h.confirmDialog.ShowDeleteSession(item.Session.ID, item.Session.Title)
+				if item.Session.IsWorktree() {
+					h.confirmDialog.ShowDeleteWorktreeSession(item.Session.ID, item.Session.Title, item.Session.WorktreePath)
+				} else {
+					h.confirmDialog.ShowDeleteSession(item.Session.ID, item.Session.Title)
+				}
 			} else if item.Type == session.ItemTypeGroup && item.Path != session.DefaultGroupPath {
 				h.confirmDialog.ShowDeleteGroup(item.Path, item.Group.Name)
 			}
@@ -2237,7 +2586,7 @@ func (h *Home) handleConfirmDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			sessionID := h.confirmDialog.GetTargetID()
 			if inst := h.getInstanceByID(sessionID); inst != nil {
 				h.confirmDialog.Hide()
-				return h, h.deleteSession(inst)
+				return h, h.deleteSession(inst, false)
 			}
 		case ConfirmDeleteGroup:
 			groupPath := h.confirmDialog.GetTargetID()
@@ -2255,6 +2604,21 @@ func (h *Home) handleConfirmDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		h.confirmDialog.Hide()
 		return h, nil
 
+	case "w", "W":
+		// Delete session and its worktree, refusing if the worktree has unmerged work
+		if h.confirmDialog.GetConfirmType() == ConfirmDeleteSession && h.confirmDialog.HasWorktree() {
+			sessionID := h.confirmDialog.GetTargetID()
+			h.confirmDialog.Hide()
+			if inst := h.getInstanceByID(sessionID); inst != nil {
+				if err := inst.CheckWorktreeRemovable(); err != nil {
+					h.setError(fmt.Errorf("not deleted: %w", err))
+					return h, nil
+				}
+				return h, h.deleteSession(inst, true)
+			}
+		}
+		return h, nil
+
 	case "n", "N", "esc":
 		// User cancelled
 		h.confirmDialog.Hide()
@@ -2613,6 +2977,7 @@ func (h *Home) handleGroupDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					for _, g := range h.groupTree.GroupList {
 						if g.Name == groupName {
 							h.groupTree.MoveSessionToGroup(item.Session, g.Path)
+							h.groupTree.RefreshClaudeConfigDirs(h.profile)
 							h.instancesMu.Lock()
 							h.instances = h.groupTree.GetAllInstances()
 							h.instancesMu.Unlock()
@@ -2650,12 +3015,265 @@ func (h *Home) handleGroupDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return h, cmd
 }
 
+// handleAccountDialogKey handles keys when the Claude account switcher is visible
+func (h *Home) handleAccountDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		value := h.accountDialog.Selected()
+		if h.accountDialog.IsGroup() {
+			h.groupTree.SetClaudeConfigDir(h.accountDialog.TargetID(), value, h.profile)
+		} else if inst := h.getInstanceByID(h.accountDialog.TargetID()); inst != nil {
+			inst.SetClaudeConfigDir(value)
+			h.invalidatePreviewCache(inst.ID)
+		}
+		h.saveInstances()
+		h.accountDialog.Hide()
+		h.setSuccess("Claude account switched (applies on next start/restart)")
+		return h, nil
+	case "esc":
+		h.accountDialog.Hide()
+		return h, nil
+	}
+
+	var cmd tea.Cmd
+	h.accountDialog, cmd = h.accountDialog.Update(msg)
+	return h, cmd
+}
+
+// handleScheduleDialogKey handles keys when the scheduled prompts list is visible
+func (h *Home) handleScheduleDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "d", "delete":
+		job := h.scheduleDialog.Selected()
+		if job == nil {
+			return h, nil
+		}
+		if _, err := h.scheduleStore.Remove(job.ID); err != nil {
+			h.setError(err)
+			return h, nil
+		}
+		h.refreshScheduleDialog()
+		return h, nil
+	case "esc", "q", "S", "shift+s":
+		h.scheduleDialog.Hide()
+		return h, nil
+	}
+
+	var cmd tea.Cmd
+	h.scheduleDialog, cmd = h.scheduleDialog.Update(msg)
+	return h, cmd
+}
+
+// toggleMark marks or unmarks a session for a broadcast. On a group, all of
+// its sessions (and its subgroups') are marked, or unmarked if they all were.
+func (h *Home) toggleMark(item session.Item) {
+	if item.Type == session.ItemTypeSession && item.Session != nil {
+		if h.marked[item.Session.ID] {
+			delete(h.marked, item.Session.ID)
+		} else {
+			h.marked[item.Session.ID] = true
+		}
+		return
+	}
+	if item.Type != session.ItemTypeGroup {
+		return
+	}
+	sessions := h.groupSessions(item.Path)
+	allMarked := len(sessions) > 0
+	for _, inst := range sessions {
+		allMarked = allMarked && h.marked[inst.ID]
+	}
+	for _, inst := range sessions {
+		if allMarked {
+			delete(h.marked, inst.ID)
+		} else {
+			h.marked[inst.ID] = true
+		}
+	}
+}
+
+// groupSessions returns the sessions in a group and its subgroups
+func (h *Home) groupSessions(path string) []*session.Instance {
+	h.instancesMu.RLock()
+	defer h.instancesMu.RUnlock()
+	var sessions []*session.Instance
+	for _, inst := range h.instances {
+		if inst.GroupPath == path || strings.HasPrefix(inst.GroupPath, path+"/") {
+			sessions = append(sessions, inst)
+		}
+	}
+	return sessions
+}
+
+// broadcastTargets returns the marked sessions, or the selected group's
+// sessions when none are marked
+func (h *Home) broadcastTargets() []*session.Instance {
+	if len(h.marked) == 0 {
+		if h.cursor < len(h.flatItems) && h.flatItems[h.cursor].Type == session.ItemTypeGroup {
+			return h.groupSessions(h.flatItems[h.cursor].Path)
+		}
+		return nil
+	}
+	h.instancesMu.RLock()
+	defer h.instancesMu.RUnlock()
+	var targets []*session.Instance
+	for _, inst := range h.instances {
+		if h.marked[inst.ID] {
+			targets = append(targets, inst)
+		}
+	}
+	return targets
+}
+
+// handleBroadcastDialogKey handles keys when the broadcast dialog is visible
+func (h *Home) handleBroadcastDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		message := h.broadcastDialog.Message()
+		if message == "" {
+			return h, nil
+		}
+		targets := h.broadcastDialog.Targets()
+		h.broadcastDialog.Hide()
+		h.marked = make(map[string]bool)
+		return h, h.broadcast(message, targets)
+	case "esc":
+		h.broadcastDialog.Hide()
+		return h, nil
+	}
+
+	var cmd tea.Cmd
+	h.broadcastDialog, cmd = h.broadcastDialog.Update(msg)
+	return h, cmd
+}
+
+// handleBroadcastPanelKey handles keys when the broadcast results are visible
+func (h *Home) handleBroadcastPanelKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if inst := h.broadcastPanel.Selected(); inst != nil {
+			if current := h.getInstanceByID(inst.ID); current != nil {
+				h.jumpToSession(current)
+			}
+		}
+		h.broadcastPanel.Hide()
+		return h, nil
+	case "esc", "q", "B", "shift+b":
+		h.broadcastPanel.Hide()
+		return h, nil
+	}
+
+	var cmd tea.Cmd
+	h.broadcastPanel, cmd = h.broadcastPanel.Update(msg)
+	return h, cmd
+}
+
+// broadcast sends message to every running target at once, each once its
+// agent is ready (like `agent-deck session send`), and shows the results panel
+func (h *Home) broadcast(message string, targets []*session.Instance) tea.Cmd {
+	h.broadcastPanel.SetSize(h.width, h.height)
+	id := h.broadcastPanel.Start(message, targets, func(inst *session.Instance) bool {
+		return inst.Exists()
+	})
+	var cmds []tea.Cmd
+	for _, inst := range h.broadcastPanel.Pending() {
+		inst := inst
+		cmds = append(cmds, func() tea.Msg {
+			return broadcastSentMsg{broadcastID: id, sessionID: inst.ID, err: inst.Send(message, false)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshScheduleDialog reloads the jobs shown in the schedule list
+func (h *Home) refreshScheduleDialog() {
+	if h.scheduleStore == nil || !h.scheduleDialog.IsVisible() {
+		return
+	}
+	jobs, err := h.scheduleStore.List()
+	if err != nil {
+		h.setError(err)
+		return
+	}
+	h.scheduleDialog.SetJobs(jobs, h.sessionTitles())
+}
+
+// sessionTitles maps session IDs to titles
+func (h *Home) sessionTitles() map[string]string {
+	h.instancesMu.RLock()
+	defer h.instancesMu.RUnlock()
+	titles := make(map[string]string, len(h.instances))
+	for _, inst := range h.instances {
+		titles[inst.ID] = inst.Title
+	}
+	return titles
+}
+
+// schedulesRanMsg reports the scheduled prompts delivered by runSchedules
+type schedulesRanMsg struct {
+	results []scheduler.Result
+	err     error
+}
+
+// runSchedules delivers the scheduled prompts that are due in the background
+func (h *Home) runSchedules() tea.Cmd {
+	store := h.scheduleStore
+	h.instancesMu.RLock()
+	byID := make(map[string]*session.Instance, len(h.instanceByID))
+	for id, inst := range h.instanceByID {
+		byID[id] = inst
+	}
+	h.instancesMu.RUnlock()
+
+	return func() tea.Msg {
+		results, err := scheduler.RunDue(store, time.Now(), session.Unlocked(func(id string) *session.Instance {
+			return byID[id]
+		}))
+		return schedulesRanMsg{results: results, err: err}
+	}
+}
+
+// mailboxCheckedMsg reports a checkMailbox pass
+type mailboxCheckedMsg struct {
+	unread    map[string]int
+	delivered int
+	err       error
+}
+
+// checkMailbox injects messages into sessions that are waiting (unless the
+// daemon does that) and counts the unread ones, in the background
+func (h *Home) checkMailbox() tea.Cmd {
+	store := h.mailboxStore
+	deliver := h.daemon == nil
+	h.instancesMu.RLock()
+	byID := make(map[string]*session.Instance, len(h.instanceByID))
+	for id, inst := range h.instanceByID {
+		byID[id] = inst
+	}
+	h.instancesMu.RUnlock()
+
+	return func() tea.Msg {
+		var result mailboxCheckedMsg
+		if deliver {
+			result.delivered, result.err = mailbox.DeliverReady(store, session.Unlocked(func(id string) *session.Instance {
+				return byID[id]
+			}))
+		}
+		if result.err == nil {
+			result.unread, result.err = store.UnreadCounts()
+		}
+		return result
+	}
+}
+
 // handleForkDialogKey handles keyboard input for the fork dialog
 func (h *Home) handleForkDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
 		// Get fork parameters from dialog
 		title, groupPath := h.forkDialog.GetValues()
+		worktreeBranch := h.forkDialog.GetWorktreeBranch()
+		carryChanges := h.forkDialog.CarryChanges()
 		if title == "" {
 			h.setError(fmt.Errorf("session name cannot be empty"))
 			return h, nil
@@ -2667,7 +3285,7 @@ func (h *Home) handleForkDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil {
 				h.forkDialog.Hide()
-				return h, h.forkSessionCmd(item.Session, title, groupPath)
+				return h, h.forkSessionCmd(item.Session, title, groupPath, worktreeBranch, carryChanges)
 			}
 		}
 		h.forkDialog.Hide()
@@ -2756,8 +3374,15 @@ func (h *Home) getUsedClaudeSessionIDs() map[string]bool {
 	return usedIDs
 }
 
-// createSessionInGroup creates a new session in a specific group
-func (h *Home) createSessionInGroup(name, path, command, groupPath string) tea.Cmd {
+// createSessionInGroup creates a new session in a specific group.
+// A non-empty worktreeBranch runs the session in a new git worktree of path.
+// env is the session environment as entered (template env is pre-filled there).
+// tmpl (optional) adds the template's tool, MCPs and first prompt;
+// a non-empty parentID creates a sub-session.
+func (h *Home) createSessionInGroup(name, path, command, groupPath, worktreeBranch string, env map[string]string, tmpl *session.TemplateDef, parentID string) tea.Cmd {
+	// Read on the UI goroutine: the new session inherits its group's Claude account
+	groupDirs := h.groupTree.ClaudeConfigDirs()
+	profile := h.profile
 	return func() tea.Msg {
 		// Check tmux availability before creating session
 		if err := tmux.IsTmuxAvailable(); err != nil {
@@ -2776,6 +3401,13 @@ func (h *Home) createSessionInGroup(name, path, command, groupPath string) tea.C
 			tool = "aider"
 		case "codex":
 			tool = "codex"
+		case "opencode":
+			tool = "opencode"
+		default:
+			// Custom agents from [tools.<name>] in config.toml
+			if custom, cmd, ok := session.ResolveCustomTool(command); ok {
+				tool, command = custom, cmd
+			}
 		}
 
 		var inst *session.Instance
@@ -2785,6 +3417,29 @@ func (h *Home) createSessionInGroup(name, path, command, groupPath string) tea.C
 			inst = session.NewInstanceWithTool(name, path, tool)
 		}
 		inst.Command = command
+		if parentID != "" {
+			inst.SetParent(parentID)
+		}
+		if tmpl != nil {
+			// The template's tool wins when the template's command is used
+			if tmpl.Tool != "" && command == tmpl.CommandLine() {
+				inst.Tool = tmpl.Tool
+			}
+			inst.ApplyTemplate(*tmpl)
+		}
+		// The dialog's env wins, so template keys the user removed stay removed
+		inst.Env = env
+		session.ApplyClaudeConfigDirs([]*session.Instance{inst}, groupDirs, profile)
+		if worktreeBranch != "" {
+			if err := inst.CreateWorktree(worktreeBranch); err != nil {
+				return sessionCreatedMsg{err: fmt.Errorf("cannot create worktree: %w", err)}
+			}
+		}
+		if tmpl != nil && len(tmpl.MCPs) > 0 {
+			if err := session.WriteMCPJsonFromConfig(inst.ProjectPath, tmpl.MCPs); err != nil {
+				return sessionCreatedMsg{err: fmt.Errorf("cannot attach MCPs: %w", err)}
+			}
+		}
 		if err := inst.Start(); err != nil {
 			return sessionCreatedMsg{err: err}
 		}
@@ -2792,6 +3447,16 @@ func (h *Home) createSessionInGroup(name, path, command, groupPath string) tea.C
 	}
 }
 
+// sendInitialMessage delivers a new session's pending first prompt in the
+// background once its agent is ready
+func sendInitialMessage(inst *session.Instance) tea.Cmd {
+	message := inst.InitialMessage
+	inst.InitialMessage = ""
+	return func() tea.Msg {
+		return initialMessageSentMsg{title: inst.Title, err: inst.SendMessageWhenReady(message)}
+	}
+}
+
 // quickForkSession performs a quick fork with default title suffix " (fork)"
 func (h *Home) quickForkSession(source *session.Instance) tea.Cmd {
 	if source == nil {
@@ -2800,7 +3465,7 @@ func (h *Home) quickForkSession(source *session.Instance) tea.Cmd {
 	// Use source title with " (fork)" suffix
 	title := source.Title + " (fork)"
 	groupPath := source.GroupPath
-	return h.forkSessionCmd(source, title, groupPath)
+	return h.forkSessionCmd(source, title, groupPath, "", false)
 }
 
 // forkSessionWithDialog opens the fork dialog to customize title and group
@@ -2815,7 +3480,9 @@ func (h *Home) forkSessionWithDialog(source *session.Instance) tea.Cmd {
 
 // forkSessionCmd creates a forked session with the given title and group
 // Shows immediate UI feedback by tracking the source session in forkingSessions
-func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string) tea.Cmd {
+// A non-empty worktreeBranch forks into a new git worktree on that branch,
+// optionally carrying the source's uncommitted changes
+func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath, worktreeBranch string, carryChanges bool) tea.Cmd {
 	if source == nil {
 		return nil
 	}
@@ -2835,7 +3502,13 @@ func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string)
 		}
 
 		// Use CreateForkedInstance to get the proper fork command
-		inst, _, err := source.CreateForkedInstance(title, groupPath)
+		var inst *session.Instance
+		var err error
+		if worktreeBranch != "" {
+			inst, _, err = source.CreateForkedInstanceInWorktree(title, groupPath, worktreeBranch, carryChanges)
+		} else {
+			inst, _, err = source.CreateForkedInstance(title, groupPath)
+		}
 		if err != nil {
 			return sessionForkedMsg{err: fmt.Errorf("cannot create forked instance: %w", err), sourceID: sourceID}
 		}
@@ -2858,16 +3531,27 @@ func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string)
 
 // sessionDeletedMsg signals that a session was deleted
 type sessionDeletedMsg struct {
-	deletedID string
-	killErr   error // Error from Kill() if any
+	deletedID   string
+	killErr     error // Error from Kill() if any
+	worktreeErr error // Error from RemoveWorktree() if any
 }
 
-// deleteSession deletes a session
-func (h *Home) deleteSession(inst *session.Instance) tea.Cmd {
+// deleteSession deletes a session, and its git worktree if removeWorktree is set
+func (h *Home) deleteSession(inst *session.Instance, removeWorktree bool) tea.Cmd {
 	id := inst.ID
 	return func() tea.Msg {
 		killErr := inst.Kill()
-		return sessionDeletedMsg{deletedID: id, killErr: killErr}
+		if h.scheduleStore != nil {
+			_, _ = h.scheduleStore.RemoveSession(id)
+		}
+		if h.mailboxStore != nil {
+			_, _ = h.mailboxStore.RemoveSession(id)
+		}
+		var worktreeErr error
+		if removeWorktree {
+			worktreeErr = inst.RemoveWorktree(false)
+		}
+		return sessionDeletedMsg{deletedID: id, killErr: killErr, worktreeErr: worktreeErr}
 	}
 }
 
@@ -2895,6 +3579,16 @@ func (h *Home) restartSession(inst *session.Instance) tea.Cmd {
 	}
 }
 
+// autoRestartSession restarts an exited session its restart policy says is due
+func (h *Home) autoRestartSession(inst *session.Instance) tea.Cmd {
+	id := inst.ID
+	return func() tea.Msg {
+		err := inst.Restart()
+		inst.RestartDone(err) // Logged by the session
+		return sessionRestartedMsg{sessionID: id, err: err}
+	}
+}
+
 // attachSession attaches to a session using custom PTY with Ctrl+Q detection
 func (h *Home) attachSession(inst *session.Instance) tea.Cmd {
 	tmuxSess := inst.GetTmuxSession()
@@ -3148,6 +3842,10 @@ func (h *Home) updateSizes() {
 	h.newDialog.SetSize(h.width, h.height)
 	h.groupDialog.SetSize(h.width, h.height)
 	h.confirmDialog.SetSize(h.width, h.height)
+	h.accountDialog.SetSize(h.width, h.height)
+	h.scheduleDialog.SetSize(h.width, h.height)
+	h.broadcastDialog.SetSize(h.width, h.height)
+	h.broadcastPanel.SetSize(h.width, h.height)
 	h.decisionDialog.SetSize(h.width, h.height)
 }
 
@@ -3208,6 +3906,18 @@ func (h *Home) View() string {
 	if h.mcpDialog.IsVisible() {
 		return h.mcpDialog.View()
 	}
+	if h.accountDialog.IsVisible() {
+		return h.accountDialog.View()
+	}
+	if h.scheduleDialog.IsVisible() {
+		return h.scheduleDialog.View()
+	}
+	if h.broadcastDialog.IsVisible() {
+		return h.broadcastDialog.View()
+	}
+	if h.broadcastPanel.IsVisible() {
+		return h.broadcastPanel.View()
+	}
 	if h.decisionDialog.IsVisible() {
 		return h.decisionDialog.View()
 	}
@@ -3236,6 +3946,9 @@ func (h *Home) View() string {
 			Bold(true)
 		titleText = "Agent Deck " + profileStyle.Render("["+h.profile+"]")
 	}
+	if h.daemon != nil {
+		titleText += lipgloss.NewStyle().Foreground(ColorComment).Render(" (daemon)")
+	}
 	title := titleStyle.Render(titleText)
 
 	// Status-based stats (more useful than group/session counts)
@@ -3714,7 +4427,14 @@ func (h *Home) renderHelpBar() string {
 	var secondaryHints []string // Edit actions (rename, move, delete)
 	var contextTitle string
 
-	if len(h.flatItems) == 0 {
+	if len(h.marked) > 0 {
+		contextTitle = fmt.Sprintf("%d Marked", len(h.marked))
+		primaryHints = []string{
+			h.helpKey("b", "Broadcast"),
+			h.helpKey("Space", "Mark"),
+			h.helpKey("Esc", "Clear"),
+		}
+	} else if len(h.flatItems) == 0 {
 		contextTitle = "Empty"
 		primaryHints = []string{
 			h.helpKey("n", "New"),
@@ -3746,8 +4466,8 @@ func (h *Home) renderHelpBar() string {
 			if item.Session != nil && item.Session.CanFork() {
 				primaryHints = append(primaryHints, h.helpKey("f/F", "Fork"))
 			}
-			// Show MCP Manager hint for Claude sessions
-			if item.Session != nil && item.Session.Tool == "claude" {
+			// Show MCP Manager hint for sessions whose MCPs it can edit
+			if item.Session != nil && session.CanWriteToolMCPs(item.Session.Tool) {
 				primaryHints = append(primaryHints, h.helpKey("M", "MCP"))
 			}
 			secondaryHints = []string{
@@ -4035,6 +4755,9 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	case session.StatusError:
 		statusIcon = "✕"
 		statusColor = ColorRed
+	case session.StatusExited:
+		statusIcon = "⊘"
+		statusColor = ColorOrange
 	default:
 		statusIcon = "○"
 		statusColor = ColorTextDim
@@ -4049,7 +4772,7 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	case session.StatusRunning, session.StatusWaiting:
 		// Bold for active states (distinguishable without color)
 		titleStyle = titleStyle.Bold(true)
-	case session.StatusError:
+	case session.StatusError, session.StatusExited:
 		// Underline for error (distinguishable without color)
 		titleStyle = titleStyle.Underline(true)
 	}
@@ -4091,6 +4814,14 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 
 	title := titleStyle.Render(inst.Title)
 	tool := toolStyle.Render(" " + inst.Tool)
+	if h.marked[inst.ID] {
+		// Marked for a broadcast
+		tool += lipgloss.NewStyle().Foreground(ColorAccent).Bold(true).Render(" ✓")
+	}
+	if n := h.unread[inst.ID]; n > 0 {
+		// Unread messages from other sessions
+		tool += lipgloss.NewStyle().Foreground(ColorYellow).Render(fmt.Sprintf(" ✉%d", n))
+	}
 
 	// Build row: [baseIndent][selection][tree][status] [title] [tool]
 	// Format: " ├─ ● session-name tool" or "▶└─ ● session-name tool"
@@ -4380,6 +5111,9 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	case session.StatusError:
 		statusIcon = "✕"
 		statusColor = ColorRed
+	case session.StatusExited:
+		statusIcon = "⊘"
+		statusColor = ColorOrange
 	}
 
 	// Header with session name and status
@@ -4393,6 +5127,9 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	// Info lines: path and activity time
 	infoStyle := lipgloss.NewStyle().Foreground(ColorText)
 	pathStr := truncatePath(selected.ProjectPath, width-4)
+	if selected.IsRemote() {
+		pathStr = truncatePath(selected.Host+":"+selected.ProjectPath, width-4)
+	}
 	b.WriteString(infoStyle.Render("📁 " + pathStr))
 	b.WriteString("\n")
 
@@ -4448,6 +5185,13 @@ func (h *Home) renderPreviewPane(width, height int) string {
 			b.WriteString("\n")
 		}
 
+		// Account line (only when not on the default account)
+		if account := selected.ClaudeAccount(); account != "default" {
+			b.WriteString(labelStyle.Render("Account: "))
+			b.WriteString(valueStyle.Render(account))
+			b.WriteString("\n")
+		}
+
 		// MCP servers - compact format with source indicators and sync status
 		mcpInfo := selected.GetMCPInfo()
 		hasLoadedMCPs := len(selected.LoadedMCPNames) > 0
@@ -4600,6 +5344,19 @@ func (h *Home) renderPreviewPane(width, height int) string {
 			b.WriteString(hintStyle.Render(" fork with options"))
 			b.WriteString("\n")
 		}
+	} else if session.GetToolAdapter(selected.Tool) != nil {
+		// Other agents with a tool adapter: show the conversation restart resumes
+		b.WriteString(renderSectionDivider(selected.Tool, width-4))
+		b.WriteString("\n")
+
+		labelStyle := lipgloss.NewStyle().Foreground(ColorText)
+		b.WriteString(labelStyle.Render("Session: "))
+		if id := selected.AgentSessionID(); id != "" {
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorGreen).Render(id))
+		} else {
+			b.WriteString(labelStyle.Render("○ Not detected"))
+		}
+		b.WriteString("\n")
 	}
 
 	// Ledger section - show relevant decisions for this project
@@ -4661,6 +5418,29 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	b.WriteString("\n")
 
 	// Special handling for error state - show guidance instead of output
+	// Exited agent: say how it ended and what happens next (output stays below)
+	if selected.Status == session.StatusExited {
+		exitStyle := lipgloss.NewStyle().Foreground(ColorOrange)
+		dimStyle := lipgloss.NewStyle().Foreground(ColorText)
+		code, known, _ := selected.LastExit()
+		exitMsg := "⊘ Agent exited (status unknown)"
+		if known {
+			exitMsg = fmt.Sprintf("⊘ Agent exited with status %d", code)
+		}
+		b.WriteString(exitStyle.Render(exitMsg))
+		b.WriteString("\n")
+		if next := selected.NextAutoRestart(); !next.IsZero() {
+			wait := time.Until(next).Round(time.Second)
+			if wait < 0 {
+				wait = 0
+			}
+			b.WriteString(dimStyle.Render(fmt.Sprintf("Restarting in %s (%s)", wait, selected.GetRestartPolicy())))
+		} else {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("Restart policy: %s • R to restart", selected.GetRestartPolicy())))
+		}
+		b.WriteString("\n\n")
+	}
+
 	if selected.Status == session.StatusError {
 		errorHeader := renderSectionDivider("Session Disconnected", width-4)
 		b.WriteString(errorHeader)
@@ -5002,8 +5782,15 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 	b.WriteString(countStyle.Render(fmt.Sprintf("%d sessions", len(group.Sessions))))
 	b.WriteString("\n\n")
 
+	// Claude account set on this group (sessions and subgroups inherit it)
+	if group.ClaudeConfigDir != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorText).Render("Claude account: "))
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorCyan).Render(group.ClaudeConfigDir))
+		b.WriteString("\n\n")
+	}
+
 	// Status breakdown with inline badges
-	running, waiting, idle, errored := 0, 0, 0, 0
+	running, waiting, idle, errored, exited := 0, 0, 0, 0, 0
 	for _, sess := range group.Sessions {
 		switch sess.Status {
 		case session.StatusRunning:
@@ -5014,6 +5801,8 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 			idle++
 		case session.StatusError:
 			errored++
+		case session.StatusExited:
+			exited++
 		}
 	}
 
@@ -5028,6 +5817,9 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 	if idle > 0 {
 		statuses = append(statuses, lipgloss.NewStyle().Foreground(ColorText).Render(fmt.Sprintf("○ %d idle", idle)))
 	}
+	if exited > 0 {
+		statuses = append(statuses, lipgloss.NewStyle().Foreground(ColorOrange).Render(fmt.Sprintf("⊘ %d exited", exited)))
+	}
 	if errored > 0 {
 		statuses = append(statuses, lipgloss.NewStyle().Foreground(ColorRed).Render(fmt.Sprintf("✕ %d error", errored)))
 	}
@@ -5068,6 +5860,8 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 				statusIcon, statusColor = "◐", ColorYellow
 			case session.StatusError:
 				statusIcon, statusColor = "✕", ColorRed
+			case session.StatusExited:
+				statusIcon, statusColor = "⊘", ColorOrange
 			}
 			status := lipgloss.NewStyle().Foreground(statusColor).Render(statusIcon)
 			name := lipgloss.NewStyle().Foreground(ColorText).Render(sess.Title)
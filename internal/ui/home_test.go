@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -284,7 +286,7 @@ func TestHomeSearchOpensGlobalWhenAvailable(t *testing.T) {
 		MemoryLimitMB:  100,
 		IndexRateLimit: 100,
 	}
-	index, err := session.NewGlobalSearchIndex(tmpDir, config)
+	index, err := session.NewGlobalSearchIndex([]string{tmpDir}, config)
 	if err != nil {
 		t.Fatalf("Failed to create test index: %v", err)
 	}
@@ -346,7 +348,7 @@ func TestHomeGlobalSearchEscape(t *testing.T) {
 		MemoryLimitMB:  100,
 		IndexRateLimit: 100,
 	}
-	index, err := session.NewGlobalSearchIndex(tmpDir, config)
+	index, err := session.NewGlobalSearchIndex([]string{tmpDir}, config)
 	if err != nil {
 		t.Fatalf("Failed to create test index: %v", err)
 	}
@@ -375,3 +377,110 @@ func TestHomeGlobalSearchEscape(t *testing.T) {
 		t.Error("Global search should be hidden after pressing Escape")
 	}
 }
+
+func TestHomeBroadcastMarking(t *testing.T) {
+	home := NewHome()
+	home.width = 100
+	home.height = 30
+
+	a := session.NewInstance("api", "/tmp/api")
+	a.GroupPath = "work"
+	b := session.NewInstance("web", "/tmp/web")
+	b.GroupPath = "work/frontend"
+	c := session.NewInstance("notes", "/tmp/notes")
+	c.GroupPath = "personal"
+	home.instancesMu.Lock()
+	home.instances = []*session.Instance{a, b, c}
+	home.instancesMu.Unlock()
+	home.groupTree = session.NewGroupTree(home.instances)
+	home.rebuildFlatItems()
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	cursorTo := func(match func(session.Item) bool) {
+		t.Helper()
+		for i, item := range home.flatItems {
+			if match(item) {
+				home.cursor = i
+				return
+			}
+		}
+		t.Fatal("item not found in flatItems")
+	}
+
+	// Space on a group marks its sessions, including subgroups'; again unmarks
+	cursorTo(func(item session.Item) bool { return item.Type == session.ItemTypeGroup && item.Path == "work" })
+	home.Update(space)
+	if !home.marked[a.ID] || !home.marked[b.ID] || home.marked[c.ID] {
+		t.Fatalf("marked = %v, want api and web", home.marked)
+	}
+	home.Update(space)
+	if len(home.marked) != 0 {
+		t.Fatalf("marked = %v, want none after toggling the group again", home.marked)
+	}
+
+	// b opens the broadcast dialog for the marked sessions
+	cursorTo(func(item session.Item) bool { return item.Session == c })
+	home.Update(space)
+	home.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	if !home.broadcastDialog.IsVisible() {
+		t.Fatal("broadcast dialog should be visible after b")
+	}
+	if targets := home.broadcastDialog.Targets(); len(targets) != 1 || targets[0] != c {
+		t.Errorf("targets = %v, want [notes]", targets)
+	}
+	home.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if home.broadcastDialog.IsVisible() {
+		t.Error("Esc should close the broadcast dialog")
+	}
+
+	// Esc in the list clears the marks
+	home.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(home.marked) != 0 {
+		t.Errorf("marked = %v, want none after Esc", home.marked)
+	}
+}
+
+func TestBroadcastPanelDelivery(t *testing.T) {
+	a := session.NewInstance("api", "/tmp/api")
+	b := session.NewInstance("web", "/tmp/web")
+	c := session.NewInstance("notes", "/tmp/notes")
+	panel := NewBroadcastPanel(func(string) *session.Instance { return nil })
+
+	old := panel.Start("first", []*session.Instance{a}, func(*session.Instance) bool { return true })
+	id := panel.Start("run the tests", []*session.Instance{a, b, c}, func(inst *session.Instance) bool { return inst != c })
+	if pending := panel.Pending(); len(pending) != 2 {
+		t.Fatalf("pending = %d, want 2", len(pending))
+	}
+
+	panel.Delivered(old, a.ID, nil) // Late result of the earlier broadcast
+	panel.Delivered(id, b.ID, errors.New("agent not ready"))
+	if sent, failed, skipped, pending := panel.Counts(); sent != 0 || failed != 1 || skipped != 1 || pending != 1 {
+		t.Errorf("counts = %d sent, %d failed, %d skipped, %d pending", sent, failed, skipped, pending)
+	}
+	panel.Delivered(id, a.ID, nil)
+	if sent, _, _, pending := panel.Counts(); sent != 1 || pending != 0 {
+		t.Errorf("after delivery: %d sent, %d pending", sent, pending)
+	}
+	if view := panel.View(); !strings.Contains(view, "agent not ready") || !strings.Contains(view, "skipped") {
+		t.Errorf("view missing failure or skip:\n%s", view)
+	}
+}
+
+func TestHomeUnreadBadge(t *testing.T) {
+	home := NewHome()
+	inst := session.NewInstance("api", "/tmp/api")
+	item := session.Item{Type: session.ItemTypeSession, Session: inst, Level: 1}
+
+	var b strings.Builder
+	home.renderSessionItem(&b, item, false)
+	if strings.Contains(b.String(), "✉") {
+		t.Errorf("row without messages shows a badge: %q", b.String())
+	}
+
+	home.Update(mailboxCheckedMsg{unread: map[string]int{inst.ID: 2}})
+	b.Reset()
+	home.renderSessionItem(&b, item, false)
+	if !strings.Contains(b.String(), "✉2") {
+		t.Errorf("row with 2 unread messages = %q, want a ✉2 badge", b.String())
+	}
+}
@@ -39,7 +39,8 @@ type MCPDialog struct {
 	height      int
 	projectPath string
 	sessionID   string // ID of the session being managed (for restart)
-	tool        string // "claude" or "gemini"
+	tool        string // "claude", "gemini" or a [tools] entry
+	configDir   string // Claude config dir of the session's account (global scope)
 
 	// Current scope and column
 	scope  MCPScope
@@ -69,14 +70,16 @@ func NewMCPDialog() *MCPDialog {
 	return &MCPDialog{}
 }
 
-// Show displays the MCP dialog for a project
-func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) error {
+// Show displays the MCP dialog for a project. configDir is the Claude config
+// directory whose global MCPs are managed (the session's account).
+func (m *MCPDialog) Show(projectPath string, sessionID string, tool string, configDir string) error {
 	// Reload config to pick up any changes to config.toml
 	_, _ = session.ReloadUserConfig()
 
 	// Store session ID and tool for restart
 	m.sessionID = sessionID
 	m.tool = tool
+	m.configDir = configDir
 
 	// Get all available MCPs from config.toml (the pool)
 	availableMCPs := session.GetAvailableMCPs()
@@ -106,12 +109,16 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	m.globalAttached = nil
 	m.globalAvailable = nil
 
-	if tool == "gemini" {
-		// Gemini: Only global MCPs from settings.json
-		mcpInfo := session.GetGeminiMCPInfo(projectPath)
+	if tool != "claude" {
+		// Gemini and custom tools: a single MCP config file
+		// (~/.gemini/settings.json, or the file from [tools.<name>.mcp])
 		globalAttachedNames := make(map[string]bool)
-		for _, name := range mcpInfo.Global {
-			globalAttachedNames[name] = true
+		if a := session.GetToolAdapter(tool); a != nil {
+			if mcpInfo := a.MCPInfo(projectPath); mcpInfo != nil {
+				for _, name := range append(mcpInfo.Global, mcpInfo.Project...) {
+					globalAttachedNames[name] = true
+				}
+			}
 		}
 
 		// Build attached/available lists for GLOBAL only
@@ -137,18 +144,18 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	} else {
 		// Claude: Load LOCAL attached from .mcp.json
 		localAttachedNames := make(map[string]bool)
-		mcpInfo := session.GetMCPInfo(projectPath)
+		mcpInfo := session.GetMCPInfoForConfigDir(configDir, projectPath)
 		for _, name := range mcpInfo.Local() {
 			localAttachedNames[name] = true
 		}
 
 		// Load GLOBAL attached from Claude config (includes both global and project-specific MCPs)
 		globalAttachedNames := make(map[string]bool)
-		for _, name := range session.GetGlobalMCPNames() {
+		for _, name := range session.GetGlobalMCPNames(configDir) {
 			globalAttachedNames[name] = true
 		}
 		// Also include project-specific MCPs from Claude's config (projects[path].mcpServers)
-		for _, name := range session.GetProjectMCPNames(projectPath) {
+		for _, name := range session.GetProjectMCPNames(configDir, projectPath) {
 			globalAttachedNames[name] = true
 		}
 
@@ -199,8 +206,8 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 
 	m.visible = true
 	m.projectPath = projectPath
-	// Gemini only has global scope, Claude starts with local
-	if tool == "gemini" {
+	// Gemini and custom tools have one scope, Claude starts with local
+	if m.singleScope() {
 		m.scope = MCPScopeGlobal
 	} else {
 		m.scope = MCPScopeLocal
@@ -217,6 +224,20 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	return nil
 }
 
+// singleScope returns true for tools with one MCP config file
+// (everything except Claude's LOCAL/GLOBAL split)
+func (m *MCPDialog) singleScope() bool {
+	return m.tool != "claude"
+}
+
+// configPath returns the MCP config file of a single-scope tool
+func (m *MCPDialog) configPath() string {
+	if a := session.GetToolAdapter(m.tool); a != nil {
+		return a.MCPConfigPath(m.projectPath)
+	}
+	return ""
+}
+
 // Hide hides the dialog
 func (m *MCPDialog) Hide() {
 	m.visible = false
@@ -334,15 +355,21 @@ func (m *MCPDialog) Apply() error {
 	log.Printf("[MCP-DEBUG] Apply() called - tool=%q, localChanged=%v, globalChanged=%v, projectPath=%q",
 		m.tool, m.localChanged, m.globalChanged, m.projectPath)
 
-	if m.tool == "gemini" {
-		// Gemini: Only global scope, write to settings.json
+	if m.singleScope() {
+		// Gemini and custom tools: only global scope, write the tool's config file
 		if m.globalChanged {
 			enabledNames := make([]string, len(m.globalAttached))
 			for i, item := range m.globalAttached {
 				enabledNames[i] = item.Name
 			}
 
-			if err := session.WriteGeminiMCPSettings(enabledNames); err != nil {
+			var err error
+			if m.tool == "gemini" {
+				err = session.WriteGeminiMCPSettings(enabledNames)
+			} else {
+				err = session.WriteToolMCPs(m.tool, m.projectPath, enabledNames)
+			}
+			if err != nil {
 				m.err = err
 				return err
 			}
@@ -379,14 +406,14 @@ func (m *MCPDialog) Apply() error {
 		}
 
 		// Write to Claude's global config
-		if err := session.WriteGlobalMCP(enabledNames); err != nil {
+		if err := session.WriteGlobalMCP(m.configDir, enabledNames); err != nil {
 			m.err = err
 			return err
 		}
 
 		// Also clear project-specific MCPs (they were shown in global view)
 		// This ensures removed MCPs are actually removed
-		if err := session.ClearProjectMCPs(m.projectPath); err != nil {
+		if err := session.ClearProjectMCPs(m.configDir, m.projectPath); err != nil {
 			m.err = err
 			return err
 		}
@@ -405,8 +432,8 @@ func (m *MCPDialog) Update(msg tea.KeyMsg) (*MCPDialog, tea.Cmd) {
 	switch msg.String() {
 	case "tab":
 		// Switch scope: LOCAL <-> GLOBAL (Claude only)
-		// Gemini only has global scope, so Tab does nothing
-		if m.tool != "gemini" {
+		// Gemini and custom tools have a single scope, so Tab does nothing
+		if !m.singleScope() {
 			if m.scope == MCPScopeLocal {
 				m.scope = MCPScopeGlobal
 			} else {
@@ -449,12 +476,14 @@ func (m *MCPDialog) View() string {
 	title := "MCP Manager"
 	if m.tool == "gemini" {
 		title = "MCP Manager (Gemini)"
+	} else if m.singleScope() {
+		title = "MCP Manager (" + m.tool + ")"
 	}
 
-	// Scope tabs - Gemini only has global
+	// Scope tabs - Gemini and custom tools only have one
 	var tabs string
-	if m.tool == "gemini" {
-		// Gemini: Only show GLOBAL (centered)
+	if m.singleScope() {
+		// Single scope: only show GLOBAL (centered)
 		globalTab := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent).Render("[GLOBAL]")
 		tabs = "──────────────── " + globalTab + " ────────────────"
 	} else {
@@ -496,6 +525,8 @@ func (m *MCPDialog) View() string {
 	var scopeDesc string
 	if m.tool == "gemini" {
 		scopeDesc = DimStyle.Render("Writes to: ~/.gemini/settings.json")
+	} else if m.singleScope() {
+		scopeDesc = DimStyle.Render("Writes to: " + m.configPath())
 	} else if m.scope == MCPScopeLocal {
 		scopeDesc = DimStyle.Render("Writes to: .mcp.json (this project only)")
 	} else {
@@ -511,7 +542,7 @@ func (m *MCPDialog) View() string {
 	// Hint with consistent styling
 	hintStyle := lipgloss.NewStyle().Foreground(ColorComment)
 	var hint string
-	if m.tool == "gemini" {
+	if m.singleScope() {
 		hint = hintStyle.Render("←→ column │ Space move │ Enter apply │ Esc cancel")
 	} else {
 		hint = hintStyle.Render("Tab scope │ ←→ column │ Space move │ Enter apply │ Esc cancel")
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -16,6 +17,8 @@ type NewDialog struct {
 	nameInput            textinput.Model
 	pathInput            textinput.Model
 	commandInput         textinput.Model
+	worktreeInput        textinput.Model // Non-empty = run in a new git worktree on this branch
+	envInput             textinput.Model // Space-separated KEY=VALUE pairs
 	focusIndex           int
 	width                int
 	height               int
@@ -26,8 +29,17 @@ type NewDialog struct {
 	parentGroupName      string
 	pathSuggestions      []string // stores all available path suggestions
 	pathSuggestionCursor int      // tracks selected suggestion in dropdown
+	templates            []string // [templates] names from config.toml ("" = none first)
+	templateCursor       int
 }
 
+// fieldEnv is the environment input's focus index
+const fieldEnv = 4
+
+// fieldTemplate is the template picker's focus index. It is rendered above
+// the name field, so tabbing from the last field wraps around to it.
+const fieldTemplate = 5
+
 // NewNewDialog creates a new NewDialog instance
 func NewNewDialog() *NewDialog {
 	// Create name input
@@ -56,10 +68,24 @@ func NewNewDialog() *NewDialog {
 	commandInput.CharLimit = 100
 	commandInput.Width = 40
 
+	// Create worktree branch input
+	worktreeInput := textinput.New()
+	worktreeInput.Placeholder = "branch (optional, creates a git worktree)"
+	worktreeInput.CharLimit = 100
+	worktreeInput.Width = 40
+
+	// Create environment input
+	envInput := textinput.New()
+	envInput.Placeholder = "KEY=VALUE TOKEN=${MY_TOKEN} (optional)"
+	envInput.CharLimit = 512
+	envInput.Width = 40
+
 	return &NewDialog{
 		nameInput:       nameInput,
 		pathInput:       pathInput,
 		commandInput:    commandInput,
+		worktreeInput:   worktreeInput,
+		envInput:        envInput,
 		focusIndex:      0,
 		visible:         false,
 		presetCommands:  []string{"", "claude", "gemini", "opencode", "codex"},
@@ -80,7 +106,14 @@ func (d *NewDialog) ShowInGroup(groupPath, groupName string) {
 	d.visible = true
 	d.focusIndex = 0
 	d.nameInput.SetValue("")
-	d.nameInput.Focus()
+	d.worktreeInput.SetValue("")
+	d.commandInput.SetValue("")
+	d.envInput.SetValue("")
+	d.templateCursor = 0
+	if d.hasTemplates() {
+		d.focusIndex = fieldTemplate
+	}
+	d.updateFocus()
 	// Keep commandCursor at previously set default (don't reset to 0)
 }
 
@@ -104,6 +137,72 @@ func (d *NewDialog) SetDefaultTool(tool string) {
 	d.commandCursor = 0
 }
 
+// SetTemplates sets the session templates offered by the picker
+// Call before Show/ShowInGroup; no templates hides the picker
+func (d *NewDialog) SetTemplates(names []string) {
+	d.templates = nil
+	if len(names) > 0 {
+		d.templates = append([]string{""}, names...)
+	}
+	d.templateCursor = 0
+}
+
+// hasTemplates returns true if the template picker is shown
+func (d *NewDialog) hasTemplates() bool {
+	return len(d.templates) > 0
+}
+
+// fieldCount returns the number of focusable fields
+func (d *NewDialog) fieldCount() int {
+	if d.hasTemplates() {
+		return 6
+	}
+	return 5
+}
+
+// GetTemplate returns the selected template name ("" = none)
+func (d *NewDialog) GetTemplate() string {
+	if d.templateCursor < len(d.templates) {
+		return d.templates[d.templateCursor]
+	}
+	return ""
+}
+
+// applyTemplate pre-fills the fields from the selected template.
+// Fields the template doesn't set keep what the user entered.
+func (d *NewDialog) applyTemplate() {
+	tmpl, ok := session.GetTemplate(d.GetTemplate())
+	if !ok {
+		return
+	}
+	_, path, _ := d.GetValues()
+
+	if tmpl.Title != "" {
+		d.nameInput.SetValue(session.ExpandTemplateVars(tmpl.Title, path))
+	}
+	if cmd := tmpl.CommandLine(); cmd != "" {
+		d.commandCursor = 0
+		d.commandInput.SetValue(cmd)
+		for i, preset := range d.presetCommands {
+			if preset != "" && preset == cmd {
+				d.commandCursor = i
+				d.commandInput.SetValue("")
+				break
+			}
+		}
+	}
+	if tmpl.Group != "" {
+		d.parentGroupPath = tmpl.Group
+		d.parentGroupName = tmpl.Group
+	}
+	if tmpl.Worktree != "" {
+		d.worktreeInput.SetValue(session.ExpandTemplateVars(tmpl.Worktree, path))
+	}
+	if len(tmpl.Env) > 0 {
+		d.envInput.SetValue(strings.Join(session.FormatEnv(tmpl.Env), " "))
+	}
+}
+
 // GetSelectedGroup returns the parent group path
 func (d *NewDialog) GetSelectedGroup() string {
 	return d.parentGroupPath
@@ -173,6 +272,27 @@ func (d *NewDialog) GetValues() (name, path, command string) {
 	return name, path, command
 }
 
+// GetWorktreeBranch returns the branch for a new git worktree ("" = use the path as-is)
+func (d *NewDialog) GetWorktreeBranch() string {
+	return strings.TrimSpace(d.worktreeInput.Value())
+}
+
+// GetEnv parses the environment input ("KEY=VALUE KEY2=VALUE2")
+func (d *NewDialog) GetEnv() (map[string]string, error) {
+	var env map[string]string
+	for _, pair := range strings.Fields(d.envInput.Value()) {
+		key, value, err := session.ParseEnvAssignment(pair)
+		if err != nil {
+			return nil, err
+		}
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
 // Validate checks if the dialog values are valid and returns an error message if not
 func (d *NewDialog) Validate() string {
 	name := strings.TrimSpace(d.nameInput.Value())
@@ -193,6 +313,10 @@ func (d *NewDialog) Validate() string {
 		return "Project path cannot be empty"
 	}
 
+	if _, err := d.GetEnv(); err != nil {
+		return "Env: " + err.Error()
+	}
+
 	return "" // Valid
 }
 
@@ -201,6 +325,8 @@ func (d *NewDialog) updateFocus() {
 	d.nameInput.Blur()
 	d.pathInput.Blur()
 	d.commandInput.Blur()
+	d.worktreeInput.Blur()
+	d.envInput.Blur()
 
 	switch d.focusIndex {
 	case 0:
@@ -209,6 +335,12 @@ func (d *NewDialog) updateFocus() {
 		d.pathInput.Focus()
 	case 2:
 		// Command selection (no text input focus needed for presets)
+	case 3:
+		d.worktreeInput.Focus()
+	case fieldEnv:
+		d.envInput.Focus()
+	case fieldTemplate:
+		// Template selection (no text input)
 	}
 }
 
@@ -231,7 +363,7 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 				}
 			}
 			// Move to next field
-			d.focusIndex = (d.focusIndex + 1) % 3
+			d.focusIndex = (d.focusIndex + 1) % d.fieldCount()
 			d.updateFocus()
 			return d, cmd
 
@@ -254,14 +386,14 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 
 		case "down":
 			// Down always navigates fields
-			d.focusIndex = (d.focusIndex + 1) % 3
+			d.focusIndex = (d.focusIndex + 1) % d.fieldCount()
 			d.updateFocus()
 			return d, nil
 
 		case "shift+tab", "up":
 			d.focusIndex--
 			if d.focusIndex < 0 {
-				d.focusIndex = 2
+				d.focusIndex = d.fieldCount() - 1
 			}
 			d.updateFocus()
 			return d, nil
@@ -275,6 +407,15 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 			return d, nil
 
 		case "left":
+			// Template selection
+			if d.focusIndex == fieldTemplate {
+				d.templateCursor--
+				if d.templateCursor < 0 {
+					d.templateCursor = len(d.templates) - 1
+				}
+				d.applyTemplate()
+				return d, nil
+			}
 			// Command selection
 			if d.focusIndex == 2 {
 				d.commandCursor--
@@ -285,6 +426,12 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 			}
 
 		case "right":
+			// Template selection
+			if d.focusIndex == fieldTemplate {
+				d.templateCursor = (d.templateCursor + 1) % len(d.templates)
+				d.applyTemplate()
+				return d, nil
+			}
 			// Command selection
 			if d.focusIndex == 2 {
 				d.commandCursor = (d.commandCursor + 1) % len(d.presetCommands)
@@ -299,6 +446,10 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 		d.nameInput, cmd = d.nameInput.Update(msg)
 	case 1:
 		d.pathInput, cmd = d.pathInput.Update(msg)
+	case 3:
+		d.worktreeInput, cmd = d.worktreeInput.Update(msg)
+	case fieldEnv:
+		d.envInput, cmd = d.envInput.Update(msg)
 	}
 
 	return d, cmd
@@ -350,6 +501,36 @@ func (d *NewDialog) View() string {
 	content.WriteString(groupInfoStyle.Render("  in group: " + d.parentGroupName))
 	content.WriteString("\n\n")
 
+	// Template picker (only when config.toml defines templates)
+	if d.hasTemplates() {
+		if d.focusIndex == fieldTemplate {
+			content.WriteString(activeLabelStyle.Render("▶ Template:"))
+		} else {
+			content.WriteString(labelStyle.Render("  Template:"))
+		}
+		content.WriteString("\n  ")
+		var tmplButtons []string
+		for i, name := range d.templates {
+			if name == "" {
+				name = "none"
+			}
+			btnStyle := lipgloss.NewStyle().
+				Foreground(ColorTextDim).
+				Background(ColorSurface).
+				Padding(0, 2)
+			if i == d.templateCursor {
+				btnStyle = lipgloss.NewStyle().
+					Foreground(ColorBg).
+					Background(ColorAccent).
+					Bold(true).
+					Padding(0, 2)
+			}
+			tmplButtons = append(tmplButtons, btnStyle.Render(name))
+		}
+		content.WriteString(lipgloss.JoinHorizontal(lipgloss.Left, tmplButtons...))
+		content.WriteString("\n\n")
+	}
+
 	// Name input
 	if d.focusIndex == 0 {
 		content.WriteString(activeLabelStyle.Render("▶ Name:"))
@@ -453,6 +634,26 @@ func (d *NewDialog) View() string {
 		content.WriteString("\n\n")
 	}
 
+	// Worktree branch input
+	if d.focusIndex == 3 {
+		content.WriteString(activeLabelStyle.Render("▶ New worktree:"))
+	} else {
+		content.WriteString(labelStyle.Render("  New worktree:"))
+	}
+	content.WriteString("\n  ")
+	content.WriteString(d.worktreeInput.View())
+	content.WriteString("\n\n")
+
+	// Environment input
+	if d.focusIndex == fieldEnv {
+		content.WriteString(activeLabelStyle.Render("▶ Env:"))
+	} else {
+		content.WriteString(labelStyle.Render("  Env:"))
+	}
+	content.WriteString("\n  ")
+	content.WriteString(d.envInput.View())
+	content.WriteString("\n\n")
+
 	// Help text with better contrast
 	helpStyle := lipgloss.NewStyle().
 		Foreground(ColorComment). // Use consistent theme color
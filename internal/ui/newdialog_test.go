@@ -4,6 +4,8 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestNewNewDialog(t *testing.T) {
@@ -233,3 +235,56 @@ func TestNewDialog_MalformedPathFix(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDialog_TemplatePicker(t *testing.T) {
+	d := NewNewDialog()
+	d.Show()
+	if d.hasTemplates() || d.fieldCount() != 5 {
+		t.Error("picker should be hidden without templates")
+	}
+
+	d.SetTemplates([]string{"review"})
+	d.Show()
+	if d.focusIndex != fieldTemplate {
+		t.Errorf("focus should start on the picker, got %d", d.focusIndex)
+	}
+	if d.GetTemplate() != "" {
+		t.Errorf("no template should be selected initially, got %q", d.GetTemplate())
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if d.GetTemplate() != "review" {
+		t.Errorf("GetTemplate() = %q, want review", d.GetTemplate())
+	}
+
+	// Tab wraps from the picker to the name field
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if d.focusIndex != 0 {
+		t.Errorf("focus after tab = %d, want 0", d.focusIndex)
+	}
+	if !strings.Contains(d.View(), "Template:") {
+		t.Error("View should show the template picker")
+	}
+}
+
+func TestNewDialog_Env(t *testing.T) {
+	d := NewNewDialog()
+	d.nameInput.SetValue("test")
+	d.envInput.SetValue("DEBUG=1  TOKEN=${MY_TOKEN}")
+
+	env, err := d.GetEnv()
+	if err != nil {
+		t.Fatalf("GetEnv() error: %v", err)
+	}
+	if len(env) != 2 || env["DEBUG"] != "1" || env["TOKEN"] != "${MY_TOKEN}" {
+		t.Errorf("GetEnv() = %v", env)
+	}
+	if msg := d.Validate(); msg != "" {
+		t.Errorf("Validate() = %q, want valid", msg)
+	}
+
+	d.envInput.SetValue("not-a-pair")
+	if msg := d.Validate(); !strings.HasPrefix(msg, "Env:") {
+		t.Errorf("Validate() = %q, want env error", msg)
+	}
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/scheduler"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// ScheduleDialog lists the profile's scheduled prompts
+type ScheduleDialog struct {
+	visible  bool
+	width    int
+	height   int
+	jobs     []*scheduler.Job
+	titles   map[string]string // Session ID -> title
+	selected int
+}
+
+// NewScheduleDialog creates a new schedule list
+func NewScheduleDialog() *ScheduleDialog {
+	return &ScheduleDialog{}
+}
+
+// Show opens the list with jobs; titles maps session IDs to titles
+func (d *ScheduleDialog) Show(jobs []*scheduler.Job, titles map[string]string) {
+	d.visible = true
+	d.selected = 0
+	d.SetJobs(jobs, titles)
+}
+
+// SetJobs replaces the listed jobs, keeping the selection where possible
+func (d *ScheduleDialog) SetJobs(jobs []*scheduler.Job, titles map[string]string) {
+	d.jobs = jobs
+	d.titles = titles
+	if d.selected >= len(d.jobs) {
+		d.selected = len(d.jobs) - 1
+	}
+	if d.selected < 0 {
+		d.selected = 0
+	}
+}
+
+// Hide hides the dialog
+func (d *ScheduleDialog) Hide() {
+	d.visible = false
+}
+
+// IsVisible returns whether the dialog is visible
+func (d *ScheduleDialog) IsVisible() bool {
+	return d.visible
+}
+
+// Selected returns the selected job (nil if there are none)
+func (d *ScheduleDialog) Selected() *scheduler.Job {
+	if d.selected < len(d.jobs) {
+		return d.jobs[d.selected]
+	}
+	return nil
+}
+
+// SetSize sets the dialog size
+func (d *ScheduleDialog) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+}
+
+// Update handles navigation (d/esc are handled by the parent)
+func (d *ScheduleDialog) Update(msg tea.KeyMsg) (*ScheduleDialog, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if d.selected > 0 {
+			d.selected--
+		}
+	case "down", "j":
+		if d.selected < len(d.jobs)-1 {
+			d.selected++
+		}
+	}
+	return d, nil
+}
+
+// formatNextRun describes when a job runs next, e.g. "in 5m" or "done"
+func formatNextRun(job *scheduler.Job, now time.Time) string {
+	if job.NextRun.IsZero() {
+		return "done"
+	}
+	until := job.NextRun.Sub(now)
+	switch {
+	case until <= 0:
+		return "due"
+	case until < time.Hour:
+		return fmt.Sprintf("in %dm", int(until.Minutes())+1)
+	case until < 24*time.Hour:
+		return "at " + job.NextRun.Local().Format("15:04")
+	default:
+		return job.NextRun.Local().Format("Jan 2 15:04")
+	}
+}
+
+// View renders the dialog
+func (d *ScheduleDialog) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	dialogWidth := 72
+	if d.width > 0 && d.width < dialogWidth+10 {
+		dialogWidth = d.width - 10
+		if dialogWidth < 40 {
+			dialogWidth = 40
+		}
+	}
+
+	dimStyle := lipgloss.NewStyle().Foreground(ColorComment)
+	errStyle := lipgloss.NewStyle().Foreground(ColorRed)
+	now := time.Now()
+
+	var items []string
+	if len(d.jobs) == 0 {
+		items = append(items, dimStyle.Render("No scheduled prompts."),
+			dimStyle.Render("Add one with: agent-deck schedule add <session> --cron \"0 9 * * *\" \"message\""))
+	}
+	for i, job := range d.jobs {
+		title := d.titles[job.SessionID]
+		if title == "" {
+			title = "(missing session)"
+		}
+		schedule := job.Cron
+		if !job.Recurring() {
+			schedule = "once"
+		}
+		line := fmt.Sprintf("%-18s %-16s %-12s %s",
+			runewidth.Truncate(title, 18, "..."), runewidth.Truncate(schedule, 16, "..."), formatNextRun(job, now), job.Message)
+		line = runewidth.Truncate(strings.ReplaceAll(line, "\n", " "), dialogWidth-6, "...")
+
+		style := lipgloss.NewStyle().Foreground(ColorText).Padding(0, 1)
+		if i == d.selected {
+			style = lipgloss.NewStyle().
+				Foreground(ColorBg).
+				Background(ColorAccent).
+				Bold(true).
+				Padding(0, 1)
+		}
+		items = append(items, style.Render(line))
+		if job.LastError != "" {
+			items = append(items, errStyle.Render("   ✗ "+runewidth.Truncate(job.LastError, dialogWidth-10, "...")))
+		}
+	}
+
+	hint := dimStyle.Render("d delete │ Esc close")
+
+	dialogContent := lipgloss.JoinVertical(
+		lipgloss.Center,
+		DialogTitleStyle.Width(dialogWidth-4).Render("Scheduled Prompts"),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		hint,
+	)
+
+	dialog := DialogBoxStyle.
+		Width(dialogWidth).
+		Render(strings.TrimRight(dialogContent, "\n"))
+
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialog,
+	)
+}
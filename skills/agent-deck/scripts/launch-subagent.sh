@@ -5,7 +5,7 @@
 #
 # Options:
 #   --mcp <name>     Attach MCP (can repeat)
-#   --wait           Poll until complete, return output
+#   --wait           Wait until complete, print the final status JSON and output
 #   --timeout <sec>  Wait timeout (default: 300)
 #
 # Examples:
@@ -111,30 +111,27 @@ fi
 echo ""
 echo "Check output with: agent-deck session output \"$TITLE\""
 
-# If --wait, poll until complete
+# If --wait, block until complete
 if [ "$WAIT" = "true" ]; then
     echo ""
     echo "Waiting for completion (timeout: ${TIMEOUT}s)..."
 
-    START_TIME=$(date +%s)
-    while true; do
-        STATUS=$(agent-deck -p "$PROFILE" session show "$TITLE" 2>/dev/null | grep '^Status:' | awk '{print $2}')
-
-        if [ "$STATUS" = "◐" ] || [ "$STATUS" = "waiting" ]; then
-            echo "Complete!"
-            echo ""
-            echo "=== Response ==="
-            agent-deck -p "$PROFILE" session output "$TITLE"
-            exit 0
-        fi
-
-        ELAPSED=$(($(date +%s) - START_TIME))
-        if [ $ELAPSED -ge $TIMEOUT ]; then
-            echo "Timeout after ${TIMEOUT}s (session still running)" >&2
-            echo "Check later with: agent-deck session output \"$TITLE\""
-            exit 1
-        fi
-
-        sleep 5
-    done
+    WAIT_CODE=0
+    STATUS_JSON=$(agent-deck -p "$PROFILE" session wait --json --after-active --until waiting --timeout "${TIMEOUT}s" "$TITLE") || WAIT_CODE=$?
+    if [ $WAIT_CODE -eq 0 ]; then
+        echo "Complete!"
+        echo ""
+        echo "=== Status ==="
+        echo "$STATUS_JSON"
+        echo ""
+        echo "=== Response ==="
+        agent-deck -p "$PROFILE" session output "$TITLE"
+        exit 0
+    fi
+    echo "$STATUS_JSON"
+    if [ $WAIT_CODE -eq 3 ]; then
+        echo "Timeout after ${TIMEOUT}s (session still running)" >&2
+        echo "Check later with: agent-deck session output \"$TITLE\""
+    fi
+    exit 1
 fi